@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -36,13 +38,56 @@ type EmailConfig struct {
 	AppBaseURL     string
 }
 
+type RateLimitConfig struct {
+	PublicRateLimit		int
+	PublicRateWindow	time.Duration
+	TrustedProxies		[]string
+}
+
+type ReservationConfig struct {
+	ReservationTTL		time.Duration
+	ReservationSweep	time.Duration
+}
 
+type AntreanConfig struct {
+	AntreanDurasiDefault	time.Duration
+	LiveTokenSecret			[]byte
+	LiveTokenTTL			time.Duration
+}
+
+type StorageConfig struct {
+	UploadDir		string
+	UploadMaxSize	int64
+	UploadMaxFiles	int
+	FileURLSecret	[]byte
+	FileURLTTL		time.Duration
+}
+
+type KuponConfig struct {
+	KuponSecret		[]byte
+}
+
+type DistribusiConfig struct {
+	DistribusiMaksPerPenerima	int
+}
+
+type CancellationConfig struct {
+	FullRefundBefore		*time.Time
+	PartialRefundPercent	int
+}
 
 type Config struct {
 	DBConfig
 	ApiConfig
 	TokenConfig
 	EmailConfig
+	RateLimitConfig
+	ReservationConfig
+	CancellationConfig
+	AntreanConfig
+	StorageConfig
+	KuponConfig
+	DistribusiConfig
 }
 
 func (c *Config) ReadConfig() error {
@@ -72,6 +117,51 @@ func (c *Config) ReadConfig() error {
 		return errors.New("Email config is empty")
 	}
 
+	c.RateLimitConfig = RateLimitConfig{
+		PublicRateLimit:	envInt("PUBLIC_RATE_LIMIT", 60),
+		PublicRateWindow:	time.Minute,
+		// IP klien untuk rate limit hanya diambil dari X-Forwarded-For bila request datang dari proxy ini
+		TrustedProxies:		envList("TRUSTED_PROXIES"),
+	}
+
+	c.ReservationConfig = ReservationConfig{
+		ReservationTTL:		envDuration("RESERVATION_TTL", 24*time.Hour),
+		ReservationSweep:	envDuration("RESERVATION_SWEEP_INTERVAL", 5*time.Minute),
+	}
+
+	c.CancellationConfig = CancellationConfig{
+		FullRefundBefore:		envDate("CANCEL_FULL_REFUND_BEFORE"),
+		PartialRefundPercent:	envInt("CANCEL_PARTIAL_REFUND_PERCENT", 50),
+	}
+	c.AntreanConfig = AntreanConfig{
+		AntreanDurasiDefault:	envDuration("ANTREAN_DURASI_DEFAULT", 30*time.Minute),
+		// token stream live dikirim lewat query sehingga dibuat berumur pendek; default memakai kunci JWT
+		LiveTokenSecret:		[]byte(envString("LIVE_TOKEN_SECRET", os.Getenv("ACCESS_TOKEN"))),
+		LiveTokenTTL:			envDuration("LIVE_TOKEN_TTL", 15*time.Minute),
+	}
+	c.StorageConfig = StorageConfig{
+		UploadDir:		envString("UPLOAD_DIR", "uploads"),
+		UploadMaxSize:	int64(envInt("UPLOAD_MAX_MB", 50)) << 20,
+		UploadMaxFiles:	envInt("UPLOAD_MAX_FILES", 10),
+		// tautan file bukti ditandatangani; default memakai kunci JWT dan berlaku seminggu agar tautan di email tetap bisa dibuka
+		FileURLSecret:	[]byte(envString("FILE_URL_SECRET", os.Getenv("ACCESS_TOKEN"))),
+		FileURLTTL:		envDuration("FILE_URL_TTL", 7*24*time.Hour),
+	}
+
+	// kunci tanda tangan QR kupon; default memakai kunci JWT
+	c.KuponConfig = KuponConfig{
+		KuponSecret:	[]byte(envString("KUPON_SECRET", os.Getenv("ACCESS_TOKEN"))),
+	}
+
+	// jumlah distribusi per penerima dalam satu tahun Hijriah, mis. daging segar lalu sisanya
+	c.DistribusiConfig = DistribusiConfig{
+		DistribusiMaksPerPenerima:	envInt("DISTRIBUSI_MAKS_PER_PENERIMA", 2),
+	}
+
+	if c.PartialRefundPercent > 100 {
+		c.PartialRefundPercent = 100
+	}
+
 	accessTokenLifetime := time.Duration(10) * time.Minute
 
 	c.TokenConfig = TokenConfig{
@@ -88,6 +178,49 @@ func (c *Config) ReadConfig() error {
 	return nil
 }
 
+func envInt(key string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
+
+func envString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
+
+// envList membaca daftar yang dipisah koma; nil jika kosong
+func envList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// envDate membaca tanggal format YYYY-MM-DD; nil jika kosong atau tidak valid
+func envDate(key string) *time.Time {
+	v, err := time.ParseInLocation("2006-01-02", os.Getenv(key), time.Local)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
 func NewConfig() (*Config, error) {
 	config := &Config{}
 
@@ -0,0 +1,420 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type BatchAntarController struct {
+	service service.BatchAntarService
+}
+
+func NewBatchAntarController(s service.BatchAntarService) *BatchAntarController {
+	return &BatchAntarController{service: s}
+}
+
+// Create godoc
+// @Summary Buat batch antar
+// @Description Susun batch pengantaran daging ke rumah penerima lansia/difabel secara manual. Urutan stop mengikuti urutan daftar. Penerima yang masih punya stop terbuka di batch lain ditolak.
+// @Tags Antar Rumah
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBatchAntarRequest true "Create batch antar request"
+// @Success 201 {object} dto.BatchAntarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar [post]
+func (c *BatchAntarController) Create(ctx *gin.Context) {
+	var req dto.CreateBatchAntarRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Batch antar created successfully",
+	})
+}
+
+// Kelompokkan godoc
+// @Summary Kelompokkan batch antar otomatis
+// @Description Buat batch per RW atau per RT dari penerima antar_rumah yang belum terdistribusi pada tahun Hijriah tanggal tersebut dan belum terjadwal di batch lain. Jumlah paket mengikuti sisa alokasi, atau jumlah_paket untuk penerima tanpa alokasi.
+// @Tags Antar Rumah
+// @Accept json
+// @Produce json
+// @Param request body dto.KelompokkanBatchAntarRequest true "Kelompokkan batch antar request"
+// @Success 201 {array} dto.BatchAntarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/kelompokkan [post]
+func (c *BatchAntarController) Kelompokkan(ctx *gin.Context) {
+	var req dto.KelompokkanBatchAntarRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Kelompokkan(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Batch antar grouped successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all batch antar
+// @Description Daftar batch pengantaran beserta jumlah stop dan yang sudah selesai
+// @Tags Antar Rumah
+// @Produce json
+// @Param tanggal query string false "Filter tanggal (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar [get]
+func (c *BatchAntarController) GetAll(ctx *gin.Context) {
+	tanggal, ok := queryTanggal(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.service.GetAll(ctx.Request.Context(), tanggal)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Batch antar retrieved successfully",
+	})
+}
+
+// GetMine godoc
+// @Summary Batch antar saya
+// @Description Kurir melihat batch yang ditugaskan kepadanya lengkap dengan alamat dan telepon penerima, melalui akun yang ditautkan ke profil petugas
+// @Tags Antar Rumah
+// @Produce json
+// @Param tanggal query string false "Filter tanggal (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/me [get]
+func (c *BatchAntarController) GetMine(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "unauthorized"})
+		return
+	}
+
+	currentUser := userRaw.(model.User)
+
+	tanggal, ok := queryTanggal(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.service.GetMine(ctx.Request.Context(), currentUser.ID, tanggal)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Batch antar retrieved successfully",
+	})
+}
+
+// GetById godoc
+// @Summary Get batch antar by ID
+// @Description Detail batch beserta stop sesuai urutan rute
+// @Tags Antar Rumah
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} dto.BatchAntarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/{id} [get]
+func (c *BatchAntarController) GetById(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	res, err := c.service.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		code := 500
+		if errors.Is(err, service.ErrBatchAntarNotFound) {
+			code = 404
+		}
+		ctx.JSON(code, gin.H{
+			"status": code,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Batch antar retrieved successfully",
+	})
+}
+
+// AssignKurir godoc
+// @Summary Tugaskan kurir batch antar
+// @Description Tugaskan petugas berperan kurir ke batch, atau kosongkan kurir_id untuk melepasnya
+// @Tags Antar Rumah
+// @Accept json
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Param request body dto.AssignKurirRequest true "Assign kurir request"
+// @Success 200 {object} dto.BatchAntarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/{id}/kurir [put]
+func (c *BatchAntarController) AssignKurir(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.AssignKurirRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.AssignKurir(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Kurir assigned successfully",
+	})
+}
+
+// Manifest godoc
+// @Summary Cetak manifest pengantaran
+// @Description PDF A4 lanskap berisi daftar alamat, telepon, jumlah paket, dan kolom keterangan/paraf untuk diisi kurir. Admin/panitia bisa mencetak semua batch, kurir hanya batch miliknya.
+// @Tags Antar Rumah
+// @Produce application/pdf
+// @Param id path string true "Batch ID"
+// @Success 200 {file} file "PDF"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/{id}/manifest [get]
+func (c *BatchAntarController) Manifest(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "unauthorized"})
+		return
+	}
+
+	currentUser := userRaw.(model.User)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := c.service.CetakManifest(ctx.Request.Context(), currentUser, id, &buf); err != nil {
+		code := 500
+		switch {
+		case errors.Is(err, service.ErrBatchAntarNotFound):
+			code = 404
+		case errors.Is(err, service.ErrBukanKurirBatch):
+			code = 403
+		}
+		ctx.JSON(code, gin.H{
+			"status": code,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="manifest-antar.pdf"`)
+	ctx.Data(200, "application/pdf", buf.Bytes())
+}
+
+// UpdateStop godoc
+// @Summary Perbarui status stop antar
+// @Description Dipanggil kurir di lapangan. terkirim mencatat distribusi daging dengan asal hewan dipilih otomatis, tidak_di_rumah masih bisa dicoba lagi, ditolak menutup stop. Admin/panitia bisa memperbarui semua batch, kurir hanya batch miliknya.
+// @Tags Antar Rumah
+// @Accept json
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Param stop_id path string true "Stop ID"
+// @Param request body dto.UpdateStopAntarRequest true "Update stop request"
+// @Success 200 {object} dto.UpdateStopAntarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/{id}/stop/{stop_id} [put]
+func (c *BatchAntarController) UpdateStop(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "unauthorized"})
+		return
+	}
+
+	currentUser := userRaw.(model.User)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+	stopID, err := uuid.Parse(ctx.Param("stop_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid stop id"})
+		return
+	}
+
+	var req dto.UpdateStopAntarRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.UpdateStop(ctx.Request.Context(), currentUser, id, stopID, req)
+	if err != nil {
+		code := 422
+		switch {
+		case errors.Is(err, service.ErrBatchAntarNotFound), errors.Is(err, service.ErrStopAntarNotFound):
+			code = 404
+		case errors.Is(err, service.ErrBukanKurirBatch):
+			code = 403
+		case errors.Is(err, service.ErrStopAntarSelesai):
+			code = 409
+		}
+		ctx.JSON(code, gin.H{
+			"status": code,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Stop updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete batch antar
+// @Description Hapus batch yang belum punya stop terkirim
+// @Tags Antar Rumah
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /antar/{id} [delete]
+func (c *BatchAntarController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Batch antar deleted successfully",
+	})
+}
+
+// queryTanggal membaca query tanggal (YYYY-MM-DD) opsional
+func queryTanggal(ctx *gin.Context) (*time.Time, bool) {
+	v := ctx.Query("tanggal")
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid date format. Use YYYY-MM-DD"})
+		return nil, false
+	}
+	return &t, true
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type BuktiPenyembelihanController struct {
+	service service.BuktiPenyembelihanService
+	serv    service.PekurbanService
+	maxBody int64
+}
+
+// NewBuktiPenyembelihanController membatasi body unggahan sebesar maxFiles file berukuran maxSize
+// ditambah 1 MB untuk header multipart, sehingga body tidak ditulis ke disk tanpa batas sebelum divalidasi
+func NewBuktiPenyembelihanController(s service.BuktiPenyembelihanService, serv service.PekurbanService, maxSize int64, maxFiles int) *BuktiPenyembelihanController {
+	return &BuktiPenyembelihanController{service: s, serv: serv, maxBody: maxSize*int64(maxFiles) + 1<<20}
+}
+
+// Upload godoc
+// @Summary Upload bukti penyembelihan
+// @Description Unggah satu atau beberapa foto (jpeg/png/webp) atau video pendek (mp4/webm/mov) sebagai bukti penyembelihan. Hanya setelah penyembelihan dimulai dan sebelum bukti ditandai lengkap.
+// @Tags Penyembelihan
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param file formData file true "Foto atau video (boleh lebih dari satu)"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/bukti [post]
+// @Security BearerAuth
+func (c *BuktiPenyembelihanController) Upload(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBody)
+	form, err := ctx.MultipartForm()
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(413, gin.H{
+			"status": 413,
+			"error": "Upload is too large"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid multipart form"})
+		return
+	}
+
+	var uploadedBy *uuid.UUID
+	if userRaw, exists := ctx.Get("user"); exists {
+		currentUser := userRaw.(model.User)
+		uploadedBy = &currentUser.ID
+	}
+
+	data, err := c.service.Upload(ctx.Request.Context(), id, uploadedBy, form.File["file"])
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Bukti penyembelihan uploaded successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get bukti penyembelihan
+// @Description Daftar foto/video bukti penyembelihan. User hanya dapat melihat bukti hewan yang porsinya ia miliki.
+// @Tags Penyembelihan
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/bukti [get]
+// @Security BearerAuth
+func (c *BuktiPenyembelihanController) GetAll(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var pekurbanID *uuid.UUID
+	if userRaw, exists := ctx.Get("user"); exists {
+		currentUser := userRaw.(model.User)
+		if currentUser.Role == "user" {
+			p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+			if err != nil || p == nil {
+				ctx.JSON(403, gin.H{
+					"status": 403,
+					"error": "You are not registered as pekurban"})
+				return
+			}
+			pid, err := uuid.Parse(p.ID)
+			if err != nil {
+				ctx.JSON(403, gin.H{
+					"status": 403,
+					"error": "You are not registered as pekurban"})
+				return
+			}
+			pekurbanID = &pid
+		}
+	}
+
+	data, err := c.service.GetAll(ctx.Request.Context(), id, pekurbanID)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Bukti penyembelihan retrieved successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete bukti penyembelihan
+// @Description Hapus satu foto/video bukti sebelum bukti ditandai lengkap
+// @Tags Penyembelihan
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param bukti_id path string true "Bukti ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/bukti/{bukti_id} [delete]
+// @Security BearerAuth
+func (c *BuktiPenyembelihanController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	buktiID, err := uuid.Parse(ctx.Param("bukti_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid bukti_id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id, buktiID); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Bukti penyembelihan deleted successfully",
+	})
+}
+
+// TandaiLengkap godoc
+// @Summary Tandai bukti penyembelihan lengkap
+// @Description Tandai bukti lengkap setelah penyembelihan selesai, lalu kirim email berisi tautan bukti dan waktu penyembelihan ke setiap pekurban hewan tersebut
+// @Tags Penyembelihan
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/bukti/lengkap [post]
+// @Security BearerAuth
+func (c *BuktiPenyembelihanController) TandaiLengkap(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	data, err := c.service.TandaiLengkap(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Bukti penyembelihan completed and pekurban notified",
+	})
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -17,7 +19,7 @@ func NewDistribusiDagingController(service service.DistribusiDagingService) *Dis
 
 // Create godoc
 // @Summary Create distribusi daging
-// @Description Membuat distribusi daging baru
+// @Description Membuat distribusi daging baru. Asal daging wajib diisi: hewan_id (semua paket dari satu hewan) atau hewan[] berisi paket per hewan. Hewan harus sudah disembelih dan paketnya tidak boleh melebihi jumlah paket hasil penyembelihan yang tercatat.
 // @Tags DistribusiDaging
 // @Accept json
 // @Produce json
@@ -51,6 +53,81 @@ func (c *DistribusiDagingController) Create(ctx *gin.Context) {
 	})
 }
 
+// Rencanakan godoc
+// @Summary Rencana alokasi paket daging
+// @Description Usulkan jumlah paket per penerima dari sisa paket hewan yang sudah disembelih. Pekurban mendapat bagian dari porsi hewannya (default 1/3, maksimal 1/3), sisanya dibagi ke penerima lain sebanding bobot kategori (default dhuafa 3, warga 1, panitia 1, pekurban 1). simpan=false hanya pratinjau; simpan=true menyimpan semua usulan sebagai distribusi.
+// @Tags DistribusiDaging
+// @Accept json
+// @Produce json
+// @Param request body dto.RencanaDistribusiRequest true "Rencana distribusi request"
+// @Success 200 {object} dto.RencanaDistribusiResponse
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/rencana [post]
+func (c *DistribusiDagingController) Rencanakan(ctx *gin.Context) {
+	var req dto.RencanaDistribusiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.Rencanakan(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	message := "Rencana distribusi preview generated successfully"
+	if data.Disimpan {
+		message = "Rencana distribusi saved successfully"
+	}
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": message,
+	})
+}
+
+// CreateBulk godoc
+// @Summary Create distribusi daging sekaligus
+// @Description Simpan banyak distribusi dalam satu transaksi, misalnya hasil rencana alokasi yang sudah disunting panitia. Satu baris yang tidak valid membatalkan semuanya.
+// @Tags DistribusiDaging
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkDistribusiRequest true "Bulk distribusi request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/bulk [post]
+func (c *DistribusiDagingController) CreateBulk(ctx *gin.Context) {
+	var req dto.BulkDistribusiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.CreateBulk(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(422, gin.H{
+			"status": 422,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Distribusi daging created successfully",
+	})
+}
+
 // GetAll godoc
 // @Summary Get all distribusi daging
 // @Description Mengambil semua distribusi daging
@@ -137,17 +214,49 @@ func (c *DistribusiDagingController) GetTotalPaket(ctx *gin.Context) {
 	})
 }
 
+// GetRekapHewan godoc
+// @Summary Rekap distribusi per hewan
+// @Description Paket yang dihasilkan setiap hewan yang sudah disembelih dibandingkan dengan paket yang sudah didistribusikan
+// @Tags DistribusiDaging
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/rekap-hewan [get]
+func (c *DistribusiDagingController) GetRekapHewan(ctx *gin.Context) {
+	data, err := c.service.GetRekapHewan(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Rekap distribusi per hewan retrieved successfully",
+	})
+}
+
 // GetPenerimaBelumDistribusi godoc
 // @Summary Get penerima yang belum menerima distribusi
-// @Description Mengambil daftar penerima daging yang belum terdistribusi
+// @Description Mengambil daftar penerima yang paket diterimanya pada tahun Hijriah tersebut belum memenuhi alokasi. Penerima tanpa alokasi dianggap belum terdistribusi bila belum menerima apa pun.
 // @Tags DistribusiDaging
 // @Produce json
+// @Param tahun_hijri query int false "Tahun Hijriah, default tahun berjalan"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /distribusi/belum-terdistribusi [get]
 func (c *DistribusiDagingController) GetPenerimaBelumDistribusi(ctx *gin.Context) {
-	data, err := c.service.GetPenerimaBelumTerdistribusi(ctx.Request.Context())
+	tahun, ok := queryTahunHijri(ctx)
+	if !ok {
+		return
+	}
+
+	data, err := c.service.GetPenerimaBelumTerdistribusi(ctx.Request.Context(), tahun)
 	if err != nil {
 		ctx.JSON(500, gin.H{
 			"status": 500,
@@ -161,6 +270,124 @@ func (c *DistribusiDagingController) GetPenerimaBelumDistribusi(ctx *gin.Context
 	})
 }
 
+// GetAlokasi godoc
+// @Summary Get alokasi distribusi per penerima
+// @Description Alokasi paket setiap penerima pada tahun Hijriah tersebut beserta paket yang sudah diterima dan sisanya
+// @Tags DistribusiDaging
+// @Produce json
+// @Param tahun_hijri query int false "Tahun Hijriah, default tahun berjalan"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/alokasi [get]
+func (c *DistribusiDagingController) GetAlokasi(ctx *gin.Context) {
+	tahun, ok := queryTahunHijri(ctx)
+	if !ok {
+		return
+	}
+
+	data, err := c.service.GetRekapPenerima(ctx.Request.Context(), tahun)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Alokasi distribusi retrieved successfully",
+	})
+}
+
+// SaveAlokasi godoc
+// @Summary Simpan alokasi distribusi
+// @Description Simpan rencana jumlah paket per penerima untuk satu tahun Hijriah; alokasi bisa dibagikan dalam beberapa gelombang. jumlah_paket 0 menghapus alokasi.
+// @Tags DistribusiDaging
+// @Accept json
+// @Produce json
+// @Param request body dto.SaveAlokasiRequest true "Alokasi request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/alokasi [put]
+func (c *DistribusiDagingController) SaveAlokasi(ctx *gin.Context) {
+	var req dto.SaveAlokasiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.SaveAlokasi(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Alokasi distribusi saved successfully",
+	})
+}
+
+// GetRiwayatPenerima godoc
+// @Summary Riwayat distribusi penerima
+// @Description Semua distribusi seorang penerima dikelompokkan per tahun Hijriah, beserta alokasi dan total paket yang diterima
+// @Tags DistribusiDaging
+// @Produce json
+// @Param id path string true "Penerima ID"
+// @Success 200 {object} dto.RiwayatDistribusiPenerimaResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /distribusi/penerima/{id} [get]
+func (c *DistribusiDagingController) GetRiwayatPenerima(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid ID"})
+		return
+	}
+
+	data, err := c.service.GetRiwayatPenerima(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Riwayat distribusi retrieved successfully",
+	})
+}
+
+// queryTahunHijri membaca ?tahun_hijri, 0 jika kosong; menulis respons 400 dan mengembalikan false jika tidak valid
+func queryTahunHijri(ctx *gin.Context) (int, bool) {
+	v := ctx.Query("tahun_hijri")
+	if v == "" {
+		return 0, true
+	}
+	tahun, err := strconv.Atoi(v)
+	if err != nil || tahun < 1400 || tahun > 1600 {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "tahun_hijri must be between 1400 and 1600"})
+		return 0, false
+	}
+	return tahun, true
+}
+
 // Delete godoc
 // @Summary Delete distribusi daging
 // @Description Menghapus distribusi daging berdasarkan ID
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	utilsservice "github.com/wahyujatirestu/sahabat-kurban/utils/service"
+)
+
+type FileController struct {
+	storage utilsservice.FileStorage
+}
+
+func NewFileController(storage utilsservice.FileStorage) *FileController {
+	return &FileController{storage: storage}
+}
+
+// Serve godoc
+// @Summary Get file bukti
+// @Description Sajikan file unggahan lewat tautan bertanda tangan (`exp` dan `sig`) dari respons atau email bukti penyembelihan. Tautan ditolak setelah kedaluwarsa.
+// @Tags Penyembelihan
+// @Param filepath path string true "Path file"
+// @Param exp query string true "Waktu kedaluwarsa (unix)"
+// @Param sig query string true "Tanda tangan"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Router /files/{filepath} [get]
+func (c *FileController) Serve(ctx *gin.Context) {
+	p, err := c.storage.Resolve(ctx.Param("filepath"), ctx.Query("exp"), ctx.Query("sig"))
+	if err != nil {
+		ctx.JSON(403, gin.H{
+			"status": 403,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.File(p)
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -53,17 +55,18 @@ func (c *HewanKurbanController) Create(ctx *gin.Context) {
 
 // GetAll godoc
 // @Summary Get all Hewan Kurban
-// @Description Ambil semua data hewan kurban
+// @Description Ambil semua data hewan kurban, dapat difilter berdasarkan status
 // @Tags HewanKurban
 // @Produce json
+// @Param status query string false "Status hewan (terdaftar, terisi, lunas, dijadwalkan, disembelih, dicacah, didistribusikan)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /hewan-kurban [get]
 func (c *HewanKurbanController) GetAll(ctx *gin.Context) {
-	list, err := c.service.GetAll(ctx.Request.Context())
+	list, err := c.service.GetAll(ctx.Request.Context(), ctx.Query("status"))
 	if err != nil {
-		ctx.JSON(500, gin.H{
+		ctx.JSON(400, gin.H{
 			"status": 500,
 			"error": err.Error()})
 		return
@@ -190,4 +193,123 @@ func (c *HewanKurbanController) Delete(ctx *gin.Context) {
 		"status": 200,
 		"message": "Hewan kurban deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// UpdateStatus godoc
+// @Summary Update status Hewan Kurban
+// @Description Ubah status hewan secara manual untuk tahap disembelih, dicacah, dan didistribusikan
+// @Tags HewanKurban
+// @Accept json
+// @Produce json
+// @Param id path string true "Hewan Kurban ID"
+// @Param request body dto.UpdateStatusHewanRequest true "Status request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /hewan-kurban/{id}/status [put]
+func (c *HewanKurbanController) UpdateStatus(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdateStatusHewanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.UpdateStatus(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Hewan kurban status updated successfully",
+	})
+}
+
+// GetRiwayatStatus godoc
+// @Summary Riwayat status Hewan Kurban
+// @Description Ambil riwayat perubahan status hewan kurban beserta pengubah dan waktunya
+// @Tags HewanKurban
+// @Produce json
+// @Param id path string true "Hewan Kurban ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /hewan-kurban/{id}/riwayat-status [get]
+func (c *HewanKurbanController) GetRiwayatStatus(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	list, err := c.service.GetRiwayatStatus(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": list,
+		"message": "Riwayat status hewan retrieved successfully",
+	})
+}
+// GetPublicCatalog godoc
+// @Summary Public katalog hewan kurban
+// @Description Daftar hewan non-private beserta harga per porsi dan sisa slot patungan, tanpa identitas pekurban
+// @Tags Public
+// @Produce json
+// @Param jenis query string false "Jenis hewan (nama pada master jenis hewan)"
+// @Param min_harga query number false "Harga per porsi minimum"
+// @Param max_harga query number false "Harga per porsi maksimum"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /public/hewan [get]
+func (c *HewanKurbanController) GetPublicCatalog(ctx *gin.Context) {
+	var q dto.PublicHewanQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	list, err := c.service.GetPublicCatalog(ctx.Request.Context(), q)
+	if err != nil {
+		code := 500
+		if errors.Is(err, service.ErrRentangHargaInvalid) {
+			code = 400
+		}
+		ctx.JSON(code, gin.H{
+			"status": code,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": list,
+		"message": "Hewan kurban retrieved successfully",
+	})
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type JenisHewanController struct {
+	service service.JenisHewanService
+}
+
+func NewJenisHewanController(s service.JenisHewanService) *JenisHewanController {
+	return &JenisHewanController{service: s}
+}
+
+// Create godoc
+// @Summary Create jenis hewan
+// @Description Tambah jenis hewan baru beserta kapasitas porsi, umur minimal, dan harga default (admin)
+// @Tags JenisHewan
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateJenisHewanRequest true "Jenis Hewan request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /jenis-hewan [post]
+func (c *JenisHewanController) Create(ctx *gin.Context) {
+	var req dto.CreateJenisHewanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Jenis hewan created successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all jenis hewan
+// @Description Ambil daftar jenis hewan beserta kapasitas porsinya
+// @Tags JenisHewan
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /jenis-hewan [get]
+func (c *JenisHewanController) GetAll(ctx *gin.Context) {
+	res, err := c.service.GetAll(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Jenis hewan retrieved successfully",
+	})
+}
+
+// Update godoc
+// @Summary Update jenis hewan
+// @Description Ubah kapasitas porsi, umur minimal, dan harga default jenis hewan (admin)
+// @Tags JenisHewan
+// @Accept json
+// @Produce json
+// @Param nama path string true "Nama jenis"
+// @Param request body dto.UpdateJenisHewanRequest true "Jenis Hewan request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /jenis-hewan/{nama} [put]
+func (c *JenisHewanController) Update(ctx *gin.Context) {
+	var req dto.UpdateJenisHewanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Update(ctx.Request.Context(), ctx.Param("nama"), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Jenis hewan updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete jenis hewan
+// @Description Hapus jenis hewan yang belum dipakai (admin)
+// @Tags JenisHewan
+// @Produce json
+// @Param nama path string true "Nama jenis"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /jenis-hewan/{nama} [delete]
+func (c *JenisHewanController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("nama")); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Jenis hewan deleted successfully",
+	})
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/ical"
+)
+
+type KalenderController struct {
+	service service.KalenderService
+}
+
+func NewKalenderController(s service.KalenderService) *KalenderController {
+	return &KalenderController{service: s}
+}
+
+// Calendar godoc
+// @Summary Export jadwal ke iCalendar
+// @Description Semua jadwal penyembelihan dan distribusi dalam format iCalendar (.ics) untuk diimpor ke Google Calendar, Outlook, dsb.
+// @Tags Kalender
+// @Produce text/calendar
+// @Success 200 {string} string "iCalendar"
+// @Failure 500 {object} map[string]interface{}
+// @Router /penyembelihan/calendar.ics [get]
+// @Security BearerAuth
+func (c *KalenderController) Calendar(ctx *gin.Context) {
+	cal, err := c.service.Feed(ctx.Request.Context(), nil)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	writeCalendar(ctx, cal, "jadwal-kurban.ics")
+}
+
+// Feed godoc
+// @Summary Feed kalender rahasia
+// @Description Feed iCalendar yang bisa dilanggan (subscribe) tanpa login. Token panitia berisi semua jadwal, token pekurban hanya berisi hewan dan distribusi miliknya.
+// @Tags Kalender
+// @Produce text/calendar
+// @Param token path string true "Token feed (boleh diakhiri .ics)"
+// @Success 200 {string} string "iCalendar"
+// @Failure 404 {object} map[string]interface{}
+// @Router /kalender/feed/{token} [get]
+func (c *KalenderController) Feed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	cal, err := c.service.FeedByToken(ctx.Request.Context(), token)
+	if errors.Is(err, service.ErrKalenderTokenInvalid) {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	writeCalendar(ctx, cal, "jadwal-kurban.ics")
+}
+
+// CreateToken godoc
+// @Summary Buat URL feed kalender
+// @Description Buat URL feed kalender rahasia untuk akun yang login. Token lama otomatis tidak berlaku. Admin/panitia mendapat feed lengkap, user mendapat feed miliknya sebagai pekurban.
+// @Tags Kalender
+// @Produce json
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /kalender/token [post]
+// @Security BearerAuth
+func (c *KalenderController) CreateToken(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	data, err := c.service.CreateToken(ctx.Request.Context(), currentUser)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Calendar feed created successfully",
+	})
+}
+
+// CreateTokenPekurban godoc
+// @Summary Buat URL feed kalender pekurban
+// @Description Buat URL feed kalender rahasia untuk pekurban tertentu (mis. pekurban tanpa akun), untuk dibagikan panitia. Token lama pekurban tersebut otomatis tidak berlaku.
+// @Tags Kalender
+// @Produce json
+// @Param id path string true "Pekurban ID"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /kalender/pekurban/{id}/token [post]
+// @Security BearerAuth
+func (c *KalenderController) CreateTokenPekurban(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	data, err := c.service.CreateTokenPekurban(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Calendar feed created successfully",
+	})
+}
+
+func writeCalendar(ctx *gin.Context, cal *ical.Calendar, filename string) {
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Data(200, "text/calendar; charset=utf-8", buf.Bytes())
+}
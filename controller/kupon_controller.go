@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/security"
+)
+
+type KuponController struct {
+	service service.KuponService
+}
+
+func NewKuponController(s service.KuponService) *KuponController {
+	return &KuponController{service: s}
+}
+
+// Generate godoc
+// @Summary Generate kupon daging
+// @Description Buat kupon pengambilan daging ber-QR untuk banyak penerima sekaligus. Daftar kupon bisa diambil dari alokasi rencana distribusi. Penerima yang masih punya kupon belum ditukar dilewati.
+// @Tags Kupon
+// @Accept json
+// @Produce json
+// @Param request body dto.GenerateKuponRequest true "Generate kupon request"
+// @Success 201 {object} dto.GenerateKuponResponse
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kupon [post]
+func (c *KuponController) Generate(ctx *gin.Context) {
+	var req dto.GenerateKuponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Generate(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Kupon generated successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all kupon
+// @Description Daftar kupon beserta isi QR-nya. belum_ditukar=true hanya kupon yang belum dipakai.
+// @Tags Kupon
+// @Produce json
+// @Param belum_ditukar query bool false "Hanya kupon yang belum ditukar"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kupon [get]
+func (c *KuponController) GetAll(ctx *gin.Context) {
+	res, err := c.service.GetAll(ctx.Request.Context(), ctx.Query("belum_ditukar") == "true")
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Kupon retrieved successfully",
+	})
+}
+
+// Cetak godoc
+// @Summary Cetak kartu kupon
+// @Description PDF A4 berisi kartu kupon ber-QR, delapan per halaman, siap dipotong. belum_ditukar=true hanya mencetak kupon yang belum dipakai.
+// @Tags Kupon
+// @Produce application/pdf
+// @Param belum_ditukar query bool false "Hanya kupon yang belum ditukar"
+// @Success 200 {file} file "PDF"
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kupon/cetak [get]
+func (c *KuponController) Cetak(ctx *gin.Context) {
+	var buf bytes.Buffer
+	if err := c.service.CetakPDF(ctx.Request.Context(), &buf, ctx.Query("belum_ditukar") == "true"); err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="kupon-daging.pdf"`)
+	ctx.Data(200, "application/pdf", buf.Bytes())
+}
+
+// Scan godoc
+// @Summary Tukar kupon
+// @Description Dipanggil dari ponsel panitia setelah memindai QR kupon. Tanda tangan QR diperiksa, kupon hanya bisa ditukar sekali, dan distribusi daging langsung dicatat atas nama panitia yang memindai dengan asal hewan dipilih otomatis.
+// @Tags Kupon
+// @Accept json
+// @Produce json
+// @Param request body dto.ScanKuponRequest true "Isi QR kupon"
+// @Success 201 {object} dto.ScanKuponResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kupon/scan [post]
+func (c *KuponController) Scan(ctx *gin.Context) {
+	var req dto.ScanKuponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Scan(ctx.Request.Context(), req)
+	if err != nil {
+		code := 422
+		switch {
+		case errors.Is(err, security.ErrKuponInvalid):
+			code = 400
+		case errors.Is(err, service.ErrKuponNotFound):
+			code = 404
+		case errors.Is(err, service.ErrKuponSudahDitukar):
+			code = 409
+		}
+		ctx.JSON(code, gin.H{
+			"status": code,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Kupon redeemed successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete kupon
+// @Description Hapus kupon yang belum ditukar, misalnya karena kartu hilang lalu dibuat ulang
+// @Tags Kupon
+// @Produce json
+// @Param id path string true "Kupon ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kupon/{id} [delete]
+func (c *KuponController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Kupon deleted successfully",
+	})
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type LokasiController struct {
+	service service.LokasiService
+}
+
+func NewLokasiController(s service.LokasiService) *LokasiController {
+	return &LokasiController{service: s}
+}
+
+// Create godoc
+// @Summary Create lokasi
+// @Description Tambah lokasi penyembelihan dan/atau distribusi (admin)
+// @Tags Lokasi
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateLokasiRequest true "Lokasi request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi [post]
+func (c *LokasiController) Create(ctx *gin.Context) {
+	var req dto.CreateLokasiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Lokasi created successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all lokasi
+// @Description Ambil daftar lokasi, dapat difilter berdasarkan tipe (lokasi bertipe keduanya selalu ikut)
+// @Tags Lokasi
+// @Produce json
+// @Param tipe query string false "penyembelihan atau distribusi"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi [get]
+func (c *LokasiController) GetAll(ctx *gin.Context) {
+	res, err := c.service.GetAll(ctx.Request.Context(), ctx.Query("tipe"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Lokasi retrieved successfully",
+	})
+}
+
+// GetById godoc
+// @Summary Get lokasi by ID
+// @Description Ambil detail lokasi
+// @Tags Lokasi
+// @Produce json
+// @Param id path string true "Lokasi ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi/{id} [get]
+func (c *LokasiController) GetById(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	res, err := c.service.GetById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Lokasi retrieved successfully",
+	})
+}
+
+// Update godoc
+// @Summary Update lokasi
+// @Description Ubah data lokasi (admin)
+// @Tags Lokasi
+// @Accept json
+// @Produce json
+// @Param id path string true "Lokasi ID"
+// @Param request body dto.UpdateLokasiRequest true "Lokasi request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi/{id} [put]
+func (c *LokasiController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdateLokasiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Update(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Lokasi updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete lokasi
+// @Description Hapus lokasi yang belum dipakai penyembelihan atau distribusi (admin)
+// @Tags Lokasi
+// @Produce json
+// @Param id path string true "Lokasi ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi/{id} [delete]
+func (c *LokasiController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Lokasi deleted successfully",
+	})
+}
+
+// Merge godoc
+// @Summary Merge lokasi
+// @Description Pindahkan semua penyembelihan dan distribusi ke lokasi tujuan lalu hapus lokasi ini, untuk menyatukan lokasi ganda (admin)
+// @Tags Lokasi
+// @Accept json
+// @Produce json
+// @Param id path string true "Lokasi ID yang akan digabung"
+// @Param request body dto.MergeLokasiRequest true "Merge request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lokasi/{id}/gabung [post]
+func (c *LokasiController) Merge(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.MergeLokasiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Merge(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Lokasi merged successfully",
+	})
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -27,6 +29,7 @@ func NewPekurbanHewanController(s service.PekurbanHewanService, serv service.Pek
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /patungan [post]
 // @Security BearerAuth
@@ -66,6 +69,12 @@ func (c *PekurbanHewanController) Create(ctx *gin.Context) {
 	}	
 
 	data, err := c.service.Create(ctx.Request.Context(), req)
+	if errors.Is(err, service.ErrPorsiExceeded) {
+		ctx.JSON(409, gin.H{
+			"status": 409,
+			"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(500, gin.H{
 			"status": 500,
@@ -229,6 +238,7 @@ func (c *PekurbanHewanController) GetByPekurbanID(ctx *gin.Context) {
 // @Param request body dto.UpdatePekurbanHewanRequest true "Request Body"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /patungan/{pekurban_id}/{hewan_id} [put]
@@ -261,6 +271,12 @@ func (c *PekurbanHewanController) Update(ctx *gin.Context) {
 	}
 
 	data, err := c.service.Update(ctx.Request.Context(), pekurbanID, hewanID, req)
+	if errors.Is(err, service.ErrPorsiExceeded) {
+		ctx.JSON(409, gin.H{
+			"status": 409,
+			"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(400, gin.H{
 			"status": 400,
@@ -315,4 +331,159 @@ func (c *PekurbanHewanController) Delete(ctx *gin.Context) {
 		"status": 200,
 		"message": "Joint contribution relation has been deleted successfully",
 	})
-}
\ No newline at end of file
+}
+// UpdateAtasNama godoc
+// @Summary Update atas nama patungan
+// @Description Ganti daftar nama yang diniatkan (atas nama) pada satu porsi, sebelum hewan disembelih
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param pekurban_id path string true "Pekurban ID"
+// @Param hewan_id path string true "Hewan ID"
+// @Param request body dto.UpdateAtasNamaRequest true "Request Body"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /patungan/{pekurban_id}/{hewan_id}/atas-nama [put]
+// @Security BearerAuth
+func (c *PekurbanHewanController) UpdateAtasNama(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	pekurbanID, err := uuid.Parse(ctx.Param("pekurban_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid pekurban_id"})
+		return
+	}
+	hewanID, err := uuid.Parse(ctx.Param("hewan_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid hewan_id"})
+		return
+	}
+
+	var req dto.UpdateAtasNamaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if currentUser.Role == "user" {
+		p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if err != nil || p == nil {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You have no registered pekurban data"})
+			return
+		}
+		if p.ID != pekurbanID.String() {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You can only change atas nama for your own patungan"})
+			return
+		}
+	}
+
+	data, err := c.service.UpdateAtasNama(ctx.Request.Context(), pekurbanID, hewanID, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Atas nama updated successfully",
+	})
+}
+
+// UpdateKehadiran godoc
+// @Summary Update permintaan hadir penyembelihan
+// @Description Tandai pekurban ingin menyaksikan penyembelihan hewannya; dipakai sebagai prioritas penjadwalan otomatis
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param pekurban_id path string true "Pekurban ID"
+// @Param hewan_id path string true "Hewan ID"
+// @Param request body dto.UpdateKehadiranRequest true "Request Body"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /patungan/{pekurban_id}/{hewan_id}/kehadiran [put]
+// @Security BearerAuth
+func (c *PekurbanHewanController) UpdateKehadiran(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	pekurbanID, err := uuid.Parse(ctx.Param("pekurban_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid pekurban_id"})
+		return
+	}
+	hewanID, err := uuid.Parse(ctx.Param("hewan_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid hewan_id"})
+		return
+	}
+
+	var req dto.UpdateKehadiranRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if currentUser.Role == "user" {
+		p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if err != nil || p == nil {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You have no registered pekurban data"})
+			return
+		}
+		if p.ID != pekurbanID.String() {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You can only change kehadiran for your own patungan"})
+			return
+		}
+	}
+
+	if err := c.service.UpdateKehadiran(ctx.Request.Context(), pekurbanID, hewanID, req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Kehadiran updated successfully",
+	})
+}
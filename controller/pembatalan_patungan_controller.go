@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type PembatalanPatunganController struct {
+	service service.PembatalanPatunganService
+	serv    service.PekurbanService
+}
+
+func NewPembatalanPatunganController(s service.PembatalanPatunganService, serv service.PekurbanService) *PembatalanPatunganController {
+	return &PembatalanPatunganController{service: s, serv: serv}
+}
+
+// Create godoc
+// @Summary Request patungan cancellation
+// @Description Ajukan pembatalan porsi dengan alasan. Perkiraan refund mengikuti kebijakan: penuh sebelum batas tanggal, sebagian setelahnya, tidak bisa setelah hewan disembelih.
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param pekurban_id path string true "Pekurban ID"
+// @Param hewan_id path string true "Hewan ID"
+// @Param request body dto.CreatePembatalanRequest true "Request Body"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /patungan/{pekurban_id}/{hewan_id}/pembatalan [post]
+// @Security BearerAuth
+func (c *PembatalanPatunganController) Create(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	pekurbanID, err := uuid.Parse(ctx.Param("pekurban_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid pekurban_id"})
+		return
+	}
+	hewanID, err := uuid.Parse(ctx.Param("hewan_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid hewan_id"})
+		return
+	}
+
+	var req dto.CreatePembatalanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if currentUser.Role == "user" {
+		p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if err != nil || p == nil {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You have no registered pekurban data"})
+			return
+		}
+		if p.ID != pekurbanID.String() {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You can only cancel your own patungan"})
+			return
+		}
+	}
+
+	data, err := c.service.Create(ctx.Request.Context(), pekurbanID, hewanID, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Cancellation request submitted successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get cancellation requests
+// @Description Admin/panitia melihat semua pengajuan pembatalan, user hanya miliknya
+// @Tags Patungan
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /patungan/pembatalan [get]
+// @Security BearerAuth
+func (c *PembatalanPatunganController) GetAll(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	var (
+		data []dto.PembatalanResponse
+		err  error
+	)
+
+	if currentUser.Role == "admin" || currentUser.Role == "panitia" {
+		data, err = c.service.GetAll(ctx.Request.Context())
+	} else {
+		pekurban, findErr := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if findErr != nil || pekurban == nil {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "Pekurban not found for this user"})
+			return
+		}
+
+		pekurbanID, parseErr := uuid.Parse(pekurban.ID)
+		if parseErr != nil {
+			ctx.JSON(500, gin.H{
+				"status": 500,
+				"error": "Invalid UUID format for pekurban ID"})
+			return
+		}
+		data, err = c.service.GetByPekurbanId(ctx.Request.Context(), pekurbanID)
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Cancellation requests retrieved successfully",
+	})
+}
+
+// Approve godoc
+// @Summary Approve cancellation request
+// @Description Setujui pembatalan: porsi dilepas dan refund dicatat sesuai kebijakan pada tanggal pengajuan (admin)
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param id path string true "Pembatalan ID"
+// @Param request body dto.ProsesPembatalanRequest false "Request Body"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /patungan/pembatalan/{id}/approve [put]
+// @Security BearerAuth
+func (c *PembatalanPatunganController) Approve(ctx *gin.Context) {
+	c.process(ctx, c.service.Approve, "Cancellation request approved successfully")
+}
+
+// Reject godoc
+// @Summary Reject cancellation request
+// @Description Tolak pengajuan pembatalan; porsi tetap milik pekurban (admin)
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param id path string true "Pembatalan ID"
+// @Param request body dto.ProsesPembatalanRequest false "Request Body"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /patungan/pembatalan/{id}/reject [put]
+// @Security BearerAuth
+func (c *PembatalanPatunganController) Reject(ctx *gin.Context) {
+	c.process(ctx, c.service.Reject, "Cancellation request rejected successfully")
+}
+
+type prosesPembatalanFunc func(ctx context.Context, id, adminID uuid.UUID, req dto.ProsesPembatalanRequest) (*dto.PembatalanResponse, error)
+
+func (c *PembatalanPatunganController) process(ctx *gin.Context, fn prosesPembatalanFunc, message string) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid id"})
+		return
+	}
+
+	var req dto.ProsesPembatalanRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": err.Error()})
+			return
+		}
+	}
+
+	data, err := fn(ctx.Request.Context(), id, currentUser.ID, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": message,
+	})
+}
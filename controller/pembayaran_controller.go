@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -251,3 +253,43 @@ func (c *PembayaranController) GetProgressPembayaran(ctx *gin.Context) {
 		"message": "Pembayaran retrieved successfully",
 	})
 }
+
+// Notification godoc
+// @Summary Midtrans payment notification
+// @Description Webhook notifikasi status transaksi dari Midtrans
+// @Tags Pembayaran
+// @Accept json
+// @Produce json
+// @Param request body dto.MidtransNotificationRequest true "Midtrans Notification"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /pembayaran/notification [post]
+func (c *PembayaranController) Notification(ctx *gin.Context) {
+	var req dto.MidtransNotificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if err := c.service.HandleNotification(ctx.Request.Context(), req); err != nil {
+		if errors.Is(err, service.ErrInvalidSignature) {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": err.Error()})
+			return
+		}
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Notification processed",
+	})
+}
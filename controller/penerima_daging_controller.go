@@ -1,6 +1,10 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -9,10 +13,12 @@ import (
 
 type PenerimaDagingController struct {
 	service service.PenerimaDagingService
+	maxBody int64
 }
 
-func NewPenerimaDagingController(service service.PenerimaDagingService) *PenerimaDagingController {
-	return &PenerimaDagingController{service: service}
+// NewPenerimaDagingController membatasi body impor sebesar satu berkas maxSize ditambah 1 MB untuk field form lainnya
+func NewPenerimaDagingController(service service.PenerimaDagingService, maxSize int64) *PenerimaDagingController {
+	return &PenerimaDagingController{service: service, maxBody: maxSize + 1<<20}
 }
 
 // Create godoc
@@ -195,4 +201,76 @@ func (c *PenerimaDagingController) Delete(ctx *gin.Context) {
 		"status": 200,
 		"message": "Penerima daging deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// Import godoc
+// @Summary Import penerima daging dari CSV/XLSX
+// @Description Impor daftar penerima dari berkas ketua RT. Kolom dicari dari judul yang lazim (Nama, Alamat, No HP, Status/Kategori, RT, RW, Antar Rumah) atau dipetakan lewat mapping. Setiap baris divalidasi (nama wajib, status harus warga/dhuafa/panitia/pekurban, nomor telepon, RT/RW) dan dicocokkan dengan penerima yang sudah ada berdasarkan nomor telepon atau nama+alamat. Secara default hanya dry run; dry_run=false menyimpan semua baris valid dalam satu transaksi.
+// @Tags Penerima Daging
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Berkas .csv atau .xlsx, baris pertama judul kolom"
+// @Param mapping formData string false "JSON kolom tujuan -> judul kolom, mis. {\"name\":\"Nama KK\",\"phone\":\"HP\"}"
+// @Param sheet formData string false "Nama sheet XLSX, default sheet pertama"
+// @Param status_default formData string false "Status untuk sel status yang kosong"
+// @Param dry_run formData bool false "false untuk menyimpan, default true"
+// @Success 200 {object} dto.ImportPenerimaResponse
+// @Success 201 {object} dto.ImportPenerimaResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Router /penerima/import [post]
+// @Security BearerAuth
+func (c *PenerimaDagingController) Import(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBody)
+	fh, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(413, gin.H{
+			"status": 413,
+			"error": "File is too large"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "File is required"})
+		return
+	}
+
+	req := dto.ImportPenerimaRequest{
+		Sheet: ctx.PostForm("sheet"),
+		StatusDefault: ctx.PostForm("status_default"),
+		DryRun: ctx.PostForm("dry_run") != "false",
+	}
+	if m := ctx.PostForm("mapping"); m != "" {
+		if err := json.Unmarshal([]byte(m), &req.Mapping); err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "Invalid mapping JSON"})
+			return
+		}
+	}
+
+	res, err := c.service.Import(ctx.Request.Context(), fh, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		ctx.JSON(200, gin.H{
+			"status": 200,
+			"data": res,
+			"message": "Import validated, nothing saved",
+		})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Penerima daging imported successfully",
+	})
+}
@@ -1,18 +1,25 @@
 package controller
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
 	"github.com/wahyujatirestu/sahabat-kurban/service"
 )
 
 type PenyembelihanController struct {
 	service service.PenyembelihanService
+	antrean service.AntreanPenyembelihanService
+	serv    service.PekurbanService
 }
 
-func NewPenyembelihanController(s service.PenyembelihanService) *PenyembelihanController {
-	return &PenyembelihanController{service: s}
+func NewPenyembelihanController(s service.PenyembelihanService, antrean service.AntreanPenyembelihanService, serv service.PekurbanService) *PenyembelihanController {
+	return &PenyembelihanController{service: s, antrean: antrean, serv: serv}
 }
 
 // Create godoc
@@ -162,6 +169,380 @@ func (c *PenyembelihanController) Update(ctx *gin.Context) {
 	})
 }
 
+// UpdateHasil godoc
+// @Summary Update hasil penyembelihan
+// @Description Mencatat berat hidup, karkas, daging, tulang, jeroan, dan jumlah paket setelah hewan disembelih
+// @Tags Penyembelihan
+// @Accept json
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param request body dto.UpdateHasilPenyembelihanRequest true "Hasil Penyembelihan Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/hasil [put]
+// @Security BearerAuth
+func (c *PenyembelihanController) UpdateHasil(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdateHasilPenyembelihanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.UpdateHasil(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Hasil penyembelihan updated successfully",
+	})
+}
+
+// Mulai godoc
+// @Summary Check-in mulai penyembelihan
+// @Description Mencatat waktu mulai penyembelihan beserta jagal dan saksi. Jagal kosong memakai jagal dari jadwal; waktu kosong berarti sekarang.
+// @Tags Penyembelihan
+// @Accept json
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param request body dto.MulaiPenyembelihanRequest true "Check-in Mulai Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/mulai [post]
+// @Security BearerAuth
+func (c *PenyembelihanController) Mulai(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.MulaiPenyembelihanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.Mulai(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Penyembelihan started",
+	})
+}
+
+// AssignJagal godoc
+// @Summary Assign jagal penyembelihan
+// @Description Menugaskan petugas jagal ke penyembelihan. Jagal harus memiliki shift jagal di lokasi penyembelihan yang mencakup rencana mulai, atau pada tanggal yang sama jika rencana mulai belum diisi.
+// @Tags Penyembelihan
+// @Accept json
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param request body dto.AssignJagalRequest true "Assign Jagal Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/jagal [put]
+// @Security BearerAuth
+func (c *PenyembelihanController) AssignJagal(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.AssignJagalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.AssignJagal(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Jagal assigned successfully",
+	})
+}
+
+// Selesai godoc
+// @Summary Check-in selesai penyembelihan
+// @Description Mencatat waktu selesai penyembelihan. Urutan aktual diberikan otomatis sesuai urutan selesai di lokasi dan tanggal yang sama, dan status hewan menjadi disembelih.
+// @Tags Penyembelihan
+// @Accept json
+// @Produce json
+// @Param id path string true "Penyembelihan ID"
+// @Param request body dto.SelesaiPenyembelihanRequest true "Check-in Selesai Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/{id}/selesai [post]
+// @Security BearerAuth
+func (c *PenyembelihanController) Selesai(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.SelesaiPenyembelihanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.Selesai(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Penyembelihan finished",
+	})
+}
+
+// RekapHarian godoc
+// @Summary Rekap harian penyembelihan
+// @Description Ringkasan check-in satu tanggal: jumlah selesai/berlangsung/belum mulai, rata-rata durasi per jenis, dan keterlambatan mulai terhadap rencana
+// @Tags Penyembelihan
+// @Produce json
+// @Param tanggal query string false "Tanggal penyembelihan (YYYY-MM-DD), default hari ini"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/rekap-harian [get]
+// @Security BearerAuth
+func (c *PenyembelihanController) RekapHarian(ctx *gin.Context) {
+	tanggal := time.Now()
+	if v := ctx.Query("tanggal"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "Invalid tanggal, use YYYY-MM-DD"})
+			return
+		}
+		tanggal = t
+	}
+
+	data, err := c.service.GetRekapHarian(ctx.Request.Context(), tanggal)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Rekap harian penyembelihan retrieved successfully",
+	})
+}
+
+// GenerateJadwal godoc
+// @Summary Generate jadwal penyembelihan
+// @Description Menyusun tanggal, lokasi, dan urutan rencana untuk semua hewan lunas berdasarkan kapasitas lokasi, ketersediaan jagal, dan aturan prioritas. Set simpan=false untuk pratinjau.
+// @Tags Penyembelihan
+// @Accept json
+// @Produce json
+// @Param request body dto.GenerateJadwalRequest true "Generate Jadwal Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/jadwal [post]
+// @Security BearerAuth
+func (c *PenyembelihanController) GenerateJadwal(ctx *gin.Context) {
+	var req dto.GenerateJadwalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.GenerateJadwal(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	message := "Jadwal penyembelihan preview generated successfully"
+	if data.Disimpan {
+		message = "Jadwal penyembelihan saved successfully"
+	}
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": message,
+	})
+}
+
+// LiveToken godoc
+// @Summary Token stream antrean live
+// @Description Membuat token berumur pendek (LIVE_TOKEN_TTL) untuk membuka GET /penyembelihan/live?token=, karena EventSource tidak bisa mengirim header Authorization. Token user menandai hewan milik pekurbannya.
+// @Tags Penyembelihan
+// @Produce json
+// @Success 201 {object} dto.LiveTokenResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/live/token [post]
+// @Security BearerAuth
+func (c *PenyembelihanController) LiveToken(ctx *gin.Context) {
+	var pekurbanID *uuid.UUID
+	currentUser := ctx.MustGet("user").(model.User)
+	if currentUser.Role == "user" {
+		if p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID); err == nil && p != nil {
+			if id, err := uuid.Parse(p.ID); err == nil {
+				pekurbanID = &id
+			}
+		}
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": c.antrean.CreateLiveToken(pekurbanID),
+		"message": "Live token created successfully",
+	})
+}
+
+// Live godoc
+// @Summary Live antrean penyembelihan
+// @Description Stream Server-Sent Events berisi antrean penyembelihan per lokasi: hewan terakhir disembelih, hewan saat ini, N hewan berikutnya, dan perkiraan jam. Event "antrean" dikirim saat terhubung dan setiap kali urutan aktual atau status hewan berubah. Untuk pekurban, hewan miliknya ditandai milik_saya dan dirangkum di hewan_saya. Akses memakai token dari POST /penyembelihan/live/token; stream ditutup dengan event "expired" saat token habis.
+// @Tags Penyembelihan
+// @Produce text/event-stream
+// @Param token query string true "Token dari POST /penyembelihan/live/token"
+// @Param tanggal query string false "Tanggal penyembelihan (YYYY-MM-DD), default hari ini"
+// @Param n query int false "Jumlah hewan berikutnya per lokasi (default 5, maks 20)"
+// @Success 200 {object} dto.AntreanPenyembelihanResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /penyembelihan/live [get]
+func (c *PenyembelihanController) Live(ctx *gin.Context) {
+	pekurbanID, expiresAt, err := c.antrean.VerifyLiveToken(ctx.Query("token"))
+	if err != nil {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": err.Error()})
+		return
+	}
+
+	tanggal := time.Now()
+	if v := ctx.Query("tanggal"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "Invalid tanggal, use YYYY-MM-DD"})
+			return
+		}
+		tanggal = t
+	}
+
+	n := 5
+	if v := ctx.Query("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 20 {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "n must be between 1 and 20"})
+			return
+		}
+		n = parsed
+	}
+
+	updates, unsubscribe := c.antrean.Subscribe(tanggal)
+	defer unsubscribe()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	kirim := func() {
+		data, err := c.antrean.GetAntrean(ctx.Request.Context(), tanggal, n, pekurbanID)
+		if err != nil {
+			ctx.SSEvent("error", gin.H{"error": err.Error()})
+		} else {
+			ctx.SSEvent("antrean", data)
+		}
+		ctx.Writer.Flush()
+	}
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	// stream tidak boleh hidup lebih lama dari tokennya; klien meminta token baru lalu menyambung ulang
+	kedaluwarsa := time.NewTimer(time.Until(expiresAt))
+	defer kedaluwarsa.Stop()
+
+	kirim()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-kedaluwarsa.C:
+			ctx.SSEvent("expired", gin.H{"error": "Live token expired"})
+			ctx.Writer.Flush()
+			return
+		case <-updates:
+			kirim()
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": ping\n\n")
+			ctx.Writer.Flush()
+		}
+	}
+}
+
 // Delete godoc
 // @Summary Delete penyembelihan
 // @Description Menghapus data penyembelihan berdasarkan ID
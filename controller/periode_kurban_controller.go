@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type PeriodeKurbanController struct {
+	service service.PeriodeKurbanService
+}
+
+func NewPeriodeKurbanController(s service.PeriodeKurbanService) *PeriodeKurbanController {
+	return &PeriodeKurbanController{service: s}
+}
+
+// Create godoc
+// @Summary Create periode kurban
+// @Description Tambah periode kurban per tahun Hijriah. offset_hari menyesuaikan kalender dengan hasil sidang isbat (mis. -1 jika Idul Adha resmi sehari lebih awal). Periode aktif dipakai untuk validasi tanggal penyembelihan.
+// @Tags Periode Kurban
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePeriodeKurbanRequest true "Periode request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /periode-kurban [post]
+func (c *PeriodeKurbanController) Create(ctx *gin.Context) {
+	var req dto.CreatePeriodeKurbanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Periode kurban created successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all periode kurban
+// @Description Ambil daftar periode kurban, terbaru lebih dulu
+// @Tags Periode Kurban
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /periode-kurban [get]
+func (c *PeriodeKurbanController) GetAll(ctx *gin.Context) {
+	res, err := c.service.GetAll(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Periode kurban retrieved successfully",
+	})
+}
+
+// Update godoc
+// @Summary Update periode kurban
+// @Description Ubah tahun, offset isbat, atau status aktif periode. Mengaktifkan periode menonaktifkan periode lain.
+// @Tags Periode Kurban
+// @Accept json
+// @Produce json
+// @Param id path string true "Periode ID"
+// @Param request body dto.UpdatePeriodeKurbanRequest true "Periode request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /periode-kurban/{id} [put]
+func (c *PeriodeKurbanController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdatePeriodeKurbanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Update(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Periode kurban updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete periode kurban
+// @Description Hapus periode kurban
+// @Tags Periode Kurban
+// @Produce json
+// @Param id path string true "Periode ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /periode-kurban/{id} [delete]
+func (c *PeriodeKurbanController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Periode kurban deleted successfully",
+	})
+}
+
+// GetJendela godoc
+// @Summary Jendela hari kurban
+// @Description Tanggal Masehi 10-13 Dzulhijjah (Idul Adha dan hari tasyrik) beserta tanggal Hijriahnya untuk periode aktif atau tahun Hijriah tertentu
+// @Tags Periode Kurban
+// @Produce json
+// @Param tahun_hijri query int false "Tahun Hijriah, default periode aktif"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /periode-kurban/jendela [get]
+func (c *PeriodeKurbanController) GetJendela(ctx *gin.Context) {
+	tahun := 0
+	if v := ctx.Query("tahun_hijri"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1400 || parsed > 1600 {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "tahun_hijri must be between 1400 and 1600"})
+			return
+		}
+		tahun = parsed
+	}
+
+	res, err := c.service.GetJendela(ctx.Request.Context(), tahun)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Jendela hari kurban retrieved successfully",
+	})
+}
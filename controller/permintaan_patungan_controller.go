@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type PermintaanPatunganController struct {
+	service service.PermintaanPatunganService
+	serv    service.PekurbanService
+}
+
+func NewPermintaanPatunganController(s service.PermintaanPatunganService, serv service.PekurbanService) *PermintaanPatunganController {
+	return &PermintaanPatunganController{service: s, serv: serv}
+}
+
+// Create godoc
+// @Summary Create permintaan patungan
+// @Description Masuk antrean patungan untuk N porsi jenis hewan tertentu, otomatis dipasangkan ke hewan yang cocok
+// @Tags AntreanPatungan
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePermintaanPatunganRequest true "Request Body"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /antrean-patungan [post]
+// @Security BearerAuth
+func (c *PermintaanPatunganController) Create(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	var req dto.CreatePermintaanPatunganRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	if currentUser.Role == "user" {
+		p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if err != nil || p == nil {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You have no registered pekurban data"})
+			return
+		}
+
+		if p.ID != req.PekurbanID {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You can only request patungan for your own data"})
+			return
+		}
+	}
+
+	data, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Permintaan patungan added successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get antrean patungan
+// @Description Ambil semua permintaan patungan (admin/panitia), atau hanya milik user sendiri
+// @Tags AntreanPatungan
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /antrean-patungan [get]
+// @Security BearerAuth
+func (c *PermintaanPatunganController) GetAll(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	if currentUser.Role == "admin" || currentUser.Role == "panitia" {
+		list, err := c.service.GetAll(ctx.Request.Context())
+		if err != nil {
+			ctx.JSON(500, gin.H{
+				"status": 500,
+				"error": err.Error()})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"status": 200,
+			"data": list,
+			"message": "Permintaan patungan retrieved successfully",
+		})
+		return
+	}
+
+	pekurban, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+	if err != nil || pekurban == nil {
+		ctx.JSON(403, gin.H{
+			"status": 403,
+			"error": "Pekurban not found for this user"})
+		return
+	}
+
+	pekurbanID, err := uuid.Parse(pekurban.ID)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": "Invalid UUID format for pekurban ID"})
+		return
+	}
+
+	list, err := c.service.GetByPekurbanID(ctx.Request.Context(), pekurbanID)
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": list,
+		"message": "Permintaan patungan retrieved successfully",
+	})
+}
+
+// Cancel godoc
+// @Summary Cancel permintaan patungan
+// @Description Batalkan permintaan patungan yang masih menunggu
+// @Tags AntreanPatungan
+// @Produce json
+// @Param id path string true "Permintaan Patungan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /antrean-patungan/{id} [delete]
+// @Security BearerAuth
+func (c *PermintaanPatunganController) Cancel(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	currentUser := ctx.MustGet("user").(model.User)
+	if currentUser.Role == "user" {
+		existing, err := c.service.GetByID(ctx.Request.Context(), id)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": err.Error()})
+			return
+		}
+
+		p, err := c.serv.GetByUserId(ctx.Request.Context(), currentUser.ID)
+		if err != nil || p == nil || p.ID != existing.PekurbanID {
+			ctx.JSON(403, gin.H{
+				"status": 403,
+				"error": "You can only cancel your own permintaan patungan"})
+			return
+		}
+	}
+
+	if err := c.service.Cancel(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Permintaan patungan cancelled successfully",
+	})
+}
+
+// Match godoc
+// @Summary Jalankan pencocokan antrean patungan
+// @Description Pasangkan antrean permintaan ke hewan yang masih punya slot atau ke hewan kosong jika porsi sudah terkumpul penuh
+// @Tags AntreanPatungan
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /antrean-patungan/match [post]
+// @Security BearerAuth
+func (c *PermintaanPatunganController) Match(ctx *gin.Context) {
+	res, err := c.service.Match(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Antrean patungan matched successfully",
+	})
+}
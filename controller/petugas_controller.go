@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type PetugasController struct {
+	service service.PetugasService
+}
+
+func NewPetugasController(s service.PetugasService) *PetugasController {
+	return &PetugasController{service: s}
+}
+
+// Create godoc
+// @Summary Create petugas
+// @Description Tambah profil jagal atau relawan, opsional ditautkan ke akun user
+// @Tags Petugas
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePetugasRequest true "Petugas request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas [post]
+func (c *PetugasController) Create(ctx *gin.Context) {
+	var req dto.CreatePetugasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Petugas created successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all petugas
+// @Description Ambil daftar petugas, dapat difilter berdasarkan peran
+// @Tags Petugas
+// @Produce json
+// @Param peran query string false "jagal, pencacah, pengemas, atau kurir"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas [get]
+func (c *PetugasController) GetAll(ctx *gin.Context) {
+	res, err := c.service.GetAll(ctx.Request.Context(), ctx.Query("peran"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Petugas retrieved successfully",
+	})
+}
+
+// GetById godoc
+// @Summary Get petugas by ID
+// @Description Ambil detail petugas
+// @Tags Petugas
+// @Produce json
+// @Param id path string true "Petugas ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas/{id} [get]
+func (c *PetugasController) GetById(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	res, err := c.service.GetById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Petugas retrieved successfully",
+	})
+}
+
+// Update godoc
+// @Summary Update petugas
+// @Description Ubah profil petugas. Kirim user_id kosong untuk melepas tautan akun.
+// @Tags Petugas
+// @Accept json
+// @Produce json
+// @Param id path string true "Petugas ID"
+// @Param request body dto.UpdatePetugasRequest true "Petugas request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas/{id} [put]
+func (c *PetugasController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdatePetugasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Update(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Petugas updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete petugas
+// @Description Hapus petugas beserta penugasan shift-nya. Penyembelihan yang ditugaskan tetap menyimpan nama jagal.
+// @Tags Petugas
+// @Produce json
+// @Param id path string true "Petugas ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas/{id} [delete]
+func (c *PetugasController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Petugas deleted successfully",
+	})
+}
+
+// GetJadwal godoc
+// @Summary Jadwal petugas
+// @Description Ambil shift dan penyembelihan yang ditugaskan ke petugas mulai tanggal tertentu
+// @Tags Petugas
+// @Produce json
+// @Param id path string true "Petugas ID"
+// @Param dari query string false "Tanggal awal (YYYY-MM-DD), default hari ini"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas/{id}/jadwal [get]
+func (c *PetugasController) GetJadwal(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	dari, ok := parseDari(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.service.GetJadwal(ctx.Request.Context(), id, dari)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Jadwal petugas retrieved successfully",
+	})
+}
+
+// GetMyJadwal godoc
+// @Summary Jadwal saya
+// @Description Relawan melihat shift dan penyembelihan miliknya sendiri melalui akun yang ditautkan ke profil petugas
+// @Tags Petugas
+// @Produce json
+// @Param dari query string false "Tanggal awal (YYYY-MM-DD), default hari ini"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /petugas/me/jadwal [get]
+func (c *PetugasController) GetMyJadwal(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "unauthorized"})
+		return
+	}
+
+	currentUser := userRaw.(model.User)
+
+	dari, ok := parseDari(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.service.GetJadwalByUserId(ctx.Request.Context(), currentUser.ID, dari)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Jadwal retrieved successfully",
+	})
+}
+
+// parseDari membaca query dari (YYYY-MM-DD), default awal hari ini
+func parseDari(ctx *gin.Context) (time.Time, bool) {
+	v := ctx.Query("dari")
+	if v == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), true
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid dari, use YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return t, true
+}
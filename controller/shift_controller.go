@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type ShiftController struct {
+	service service.ShiftService
+}
+
+func NewShiftController(s service.ShiftService) *ShiftController {
+	return &ShiftController{service: s}
+}
+
+// Create godoc
+// @Summary Create shift
+// @Description Buat slot shift per lokasi, tanggal, dan peran beserta jumlah petugas yang dibutuhkan. Jam selesai lebih awal dari jam mulai berarti melewati tengah malam.
+// @Tags Shift
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateShiftRequest true "Shift request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift [post]
+func (c *ShiftController) Create(ctx *gin.Context) {
+	var req dto.CreateShiftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": res,
+		"message": "Shift created successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get all shift
+// @Description Ambil daftar shift beserta petugas yang ditugaskan, dapat difilter tanggal dan lokasi
+// @Tags Shift
+// @Produce json
+// @Param tanggal query string false "Tanggal (YYYY-MM-DD)"
+// @Param lokasi_id query string false "Lokasi ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift [get]
+func (c *ShiftController) GetAll(ctx *gin.Context) {
+	var tanggal *time.Time
+	if v := ctx.Query("tanggal"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "Invalid tanggal, use YYYY-MM-DD"})
+			return
+		}
+		tanggal = &t
+	}
+
+	var lokasiID *uuid.UUID
+	if v := ctx.Query("lokasi_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "Invalid lokasi_id"})
+			return
+		}
+		lokasiID = &id
+	}
+
+	res, err := c.service.GetAll(ctx.Request.Context(), tanggal, lokasiID)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Shift retrieved successfully",
+	})
+}
+
+// GetById godoc
+// @Summary Get shift by ID
+// @Description Ambil detail shift beserta petugas yang ditugaskan
+// @Tags Shift
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift/{id} [get]
+func (c *ShiftController) GetById(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	res, err := c.service.GetById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(404, gin.H{
+			"status": 404,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Shift retrieved successfully",
+	})
+}
+
+// Update godoc
+// @Summary Update shift
+// @Description Ubah slot shift. Perubahan waktu atau peran ditolak jika bentrok dengan shift lain milik petugas yang sudah ditugaskan.
+// @Tags Shift
+// @Accept json
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Param request body dto.UpdateShiftRequest true "Shift request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift/{id} [put]
+func (c *ShiftController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.UpdateShiftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Update(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Shift updated successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete shift
+// @Description Hapus shift beserta penugasannya
+// @Tags Shift
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift/{id} [delete]
+func (c *ShiftController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"message": "Shift deleted successfully",
+	})
+}
+
+// Assign godoc
+// @Summary Assign petugas to shift
+// @Description Tugaskan petugas ke shift. Ditolak jika petugas tidak memiliki peran shift, shift sudah penuh, atau bentrok dengan shift lain yang tumpang tindih.
+// @Tags Shift
+// @Accept json
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Param request body dto.AssignShiftRequest true "Assign request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift/{id}/petugas [post]
+func (c *ShiftController) Assign(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	var req dto.AssignShiftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	res, err := c.service.Assign(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Petugas assigned successfully",
+	})
+}
+
+// Unassign godoc
+// @Summary Unassign petugas from shift
+// @Description Lepas penugasan petugas dari shift
+// @Tags Shift
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Param petugas_id path string true "Petugas ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /shift/{id}/petugas/{petugas_id} [delete]
+func (c *ShiftController) Unassign(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid id"})
+		return
+	}
+
+	petugasID, err := uuid.Parse(ctx.Param("petugas_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "Invalid petugas_id"})
+		return
+	}
+
+	res, err := c.service.Unassign(ctx.Request.Context(), id, petugasID)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": res,
+		"message": "Petugas unassigned successfully",
+	})
+}
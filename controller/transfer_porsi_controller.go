@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+)
+
+type TransferPorsiController struct {
+	service service.TransferPorsiService
+}
+
+func NewTransferPorsiController(s service.TransferPorsiService) *TransferPorsiController {
+	return &TransferPorsiController{service: s}
+}
+
+// Transfer godoc
+// @Summary Transfer patungan share
+// @Description Alihkan satu porsi (beserta pembayaran dan atas nama) ke pekurban lain. Dicatat sebagai riwayat dengan penyetuju.
+// @Tags Patungan
+// @Accept json
+// @Produce json
+// @Param pekurban_id path string true "Pekurban ID asal"
+// @Param hewan_id path string true "Hewan ID"
+// @Param request body dto.TransferPorsiRequest true "Request Body"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /patungan/{pekurban_id}/{hewan_id}/transfer [post]
+// @Security BearerAuth
+func (c *TransferPorsiController) Transfer(ctx *gin.Context) {
+	userRaw, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(401, gin.H{
+			"status": 401,
+			"error": "Unauthorized"})
+		return
+	}
+	currentUser := userRaw.(model.User)
+
+	pekurbanID, err := uuid.Parse(ctx.Param("pekurban_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid pekurban_id"})
+		return
+	}
+	hewanID, err := uuid.Parse(ctx.Param("hewan_id"))
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": "invalid hewan_id"})
+		return
+	}
+
+	var req dto.TransferPorsiRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	data, err := c.service.Transfer(ctx.Request.Context(), pekurbanID, hewanID, currentUser.ID, req)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"status": 400,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"status": 201,
+		"data": data,
+		"message": "Share transferred successfully",
+	})
+}
+
+// GetAll godoc
+// @Summary Get transfer history
+// @Description Riwayat pengalihan porsi, bisa difilter dengan query hewan_id
+// @Tags Patungan
+// @Produce json
+// @Param hewan_id query string false "Hewan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /patungan/transfer [get]
+// @Security BearerAuth
+func (c *TransferPorsiController) GetAll(ctx *gin.Context) {
+	var (
+		data []dto.TransferPorsiResponse
+		err  error
+	)
+
+	if hewanIDStr := ctx.Query("hewan_id"); hewanIDStr != "" {
+		hewanID, parseErr := uuid.Parse(hewanIDStr)
+		if parseErr != nil {
+			ctx.JSON(400, gin.H{
+				"status": 400,
+				"error": "invalid hewan_id"})
+			return
+		}
+		data, err = c.service.GetByHewanId(ctx.Request.Context(), hewanID)
+	} else {
+		data, err = c.service.GetAll(ctx.Request.Context())
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{
+			"status": 500,
+			"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status": 200,
+		"data": data,
+		"message": "Transfer history retrieved successfully",
+	})
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// AntreanItemResponse adalah satu hewan pada antrean. Posisi 0 berarti sudah disembelih, 1 berarti hewan
+// yang sedang/akan segera disembelih, dan seterusnya.
+type AntreanItemResponse struct {
+	HewanID       string     `json:"hewan_id"`
+	Jenis         string     `json:"jenis"`
+	Status        string     `json:"status"`
+	Lokasi        string     `json:"lokasi"`
+	UrutanRencana int        `json:"urutan_rencana"`
+	UrutanAktual  *int       `json:"urutan_aktual,omitempty"`
+	Posisi        int        `json:"posisi"`
+	PerkiraanJam  *time.Time `json:"perkiraan_jam,omitempty"`
+	MulaiAt       *time.Time `json:"mulai_at,omitempty"`
+	DisembelihAt  *time.Time `json:"disembelih_at,omitempty"`
+	MilikSaya     bool       `json:"milik_saya"`
+}
+
+type AntreanLokasiResponse struct {
+	LokasiID      string                `json:"lokasi_id"`
+	Lokasi        string                `json:"lokasi"`
+	TotalHewan    int                   `json:"total_hewan"`
+	Selesai       int                   `json:"selesai"`
+	IntervalMenit float64               `json:"interval_menit"` // perkiraan menit per hewan
+	Terakhir      *AntreanItemResponse  `json:"terakhir,omitempty"`
+	SaatIni       *AntreanItemResponse  `json:"saat_ini,omitempty"`
+	Berikutnya    []AntreanItemResponse `json:"berikutnya"`
+}
+
+type AntreanPenyembelihanResponse struct {
+	Tanggal    string                  `json:"tanggal"`
+	Diperbarui time.Time               `json:"diperbarui"`
+	Lokasi     []AntreanLokasiResponse `json:"lokasi"`
+	HewanSaya  []AntreanItemResponse   `json:"hewan_saya,omitempty"`
+}
+
+// LiveTokenResponse berisi token berumur pendek untuk membuka stream antrean live lewat query ?token=
+type LiveTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "github.com/wahyujatirestu/sahabat-kurban/model"
+
+type AtasNamaRequest struct {
+	Nama		string	`json:"nama" binding:"required,max=100"`
+	Hubungan	*string	`json:"hubungan" binding:"omitempty,max=50"`
+	MasihHidup	*bool	`json:"masih_hidup" binding:"required"`
+}
+
+type UpdateAtasNamaRequest struct {
+	AtasNama	[]AtasNamaRequest	`json:"atas_nama" binding:"dive"`
+}
+
+type AtasNamaResponse struct {
+	ID			string	`json:"id"`
+	Nama		string	`json:"nama"`
+	Hubungan	*string	`json:"hubungan,omitempty"`
+	MasihHidup	bool	`json:"masih_hidup"`
+}
+
+func ToAtasNamaResponse(a *model.AtasNama) AtasNamaResponse {
+	return AtasNamaResponse{
+		ID:         a.ID.String(),
+		Nama:       a.Nama,
+		Hubungan:   a.Hubungan,
+		MasihHidup: a.MasihHidup,
+	}
+}
+
+// GroupAtasNamaByShare mengelompokkan atas nama per porsi dengan kunci "pekurban_id/hewan_id"
+func GroupAtasNamaByShare(list []*model.AtasNama) map[string][]AtasNamaResponse {
+	grouped := map[string][]AtasNamaResponse{}
+	for _, a := range list {
+		key := ShareKey(a.PekurbanID.String(), a.HewanID.String())
+		grouped[key] = append(grouped[key], ToAtasNamaResponse(a))
+	}
+	return grouped
+}
+
+func ShareKey(pekurbanID, hewanID string) string {
+	return pekurbanID + "/" + hewanID
+}
@@ -0,0 +1,140 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// CreateBatchAntarRequest menyusun batch secara manual; urutan stop mengikuti urutan daftar
+type CreateBatchAntarRequest struct {
+	Nama     string                  `json:"nama" binding:"required"`
+	Tanggal  string                  `json:"tanggal" binding:"required"` // YYYY-MM-DD
+	LokasiID *string                 `json:"lokasi_id" binding:"omitempty,uuid"`
+	RT       *string                 `json:"rt" binding:"omitempty,max=3"`
+	RW       *string                 `json:"rw" binding:"omitempty,max=3"`
+	KurirID  *string                 `json:"kurir_id" binding:"omitempty,uuid"`
+	Catatan  *string                 `json:"catatan"`
+	Stop     []BatchAntarStopRequest `json:"stop" binding:"required,min=1,dive"`
+}
+
+type BatchAntarStopRequest struct {
+	PenerimaID  string `json:"penerima_id" binding:"required,uuid"`
+	JumlahPaket int    `json:"jumlah_paket" binding:"required,min=1"`
+}
+
+// KelompokkanBatchAntarRequest membuat batch otomatis dari penerima antar_rumah yang belum terdistribusi,
+// satu batch per RW (kelompok=rw) atau per RT dalam RW (kelompok=rt)
+type KelompokkanBatchAntarRequest struct {
+	Tanggal     string  `json:"tanggal" binding:"required"` // YYYY-MM-DD, juga menentukan tahun Hijriah alokasi
+	LokasiID    *string `json:"lokasi_id" binding:"omitempty,uuid"`
+	Kelompok    string  `json:"kelompok" binding:"required,oneof=rt rw"`
+	JumlahPaket int     `json:"jumlah_paket" binding:"required,min=1"` // untuk penerima tanpa alokasi
+	MaksStop    int     `json:"maks_stop" binding:"omitempty,min=1"`   // batch dipecah jika melebihi ini
+}
+
+// AssignKurirRequest: kurir_id kosong melepas kurir dari batch
+type AssignKurirRequest struct {
+	KurirID *string `json:"kurir_id" binding:"omitempty,uuid"`
+}
+
+type UpdateStopAntarRequest struct {
+	Status  string  `json:"status" binding:"required,oneof=terkirim tidak_di_rumah ditolak"`
+	Catatan *string `json:"catatan"`
+}
+
+type BatchAntarStopResponse struct {
+	ID             string     `json:"id"`
+	PenerimaID     string     `json:"penerima_id"`
+	PenerimaName   string     `json:"penerima_name"`
+	Alamat         *string    `json:"alamat,omitempty"`
+	Phone          *string    `json:"phone,omitempty"`
+	RT             *string    `json:"rt,omitempty"`
+	RW             *string    `json:"rw,omitempty"`
+	Urutan         int        `json:"urutan"`
+	JumlahPaket    int        `json:"jumlah_paket"`
+	Status         string     `json:"status"`
+	Catatan        *string    `json:"catatan,omitempty"`
+	DistribusiID   *string    `json:"distribusi_id,omitempty"`
+	DiperbaruiOleh *string    `json:"diperbarui_oleh,omitempty"`
+	DiperbaruiAt   *time.Time `json:"diperbarui_at,omitempty"`
+}
+
+type BatchAntarResponse struct {
+	ID          string                   `json:"id"`
+	Nama        string                   `json:"nama"`
+	Tanggal     time.Time                `json:"tanggal"`
+	LokasiID    *string                  `json:"lokasi_id,omitempty"`
+	Lokasi      *string                  `json:"lokasi,omitempty"`
+	RT          *string                  `json:"rt,omitempty"`
+	RW          *string                  `json:"rw,omitempty"`
+	KurirID     *string                  `json:"kurir_id,omitempty"`
+	Kurir       *string                  `json:"kurir,omitempty"`
+	KurirPhone  *string                  `json:"kurir_phone,omitempty"`
+	Catatan     *string                  `json:"catatan,omitempty"`
+	JumlahStop  int                      `json:"jumlah_stop"`
+	StopSelesai int                      `json:"stop_selesai"`
+	Stop        []BatchAntarStopResponse `json:"stop,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// UpdateStopAntarResponse menyertakan distribusi yang tercatat saat stop terkirim
+type UpdateStopAntarResponse struct {
+	Stop       BatchAntarStopResponse `json:"stop"`
+	Distribusi *DistribusiResponse    `json:"distribusi,omitempty"`
+}
+
+func ToBatchAntarStopResponse(s *model.BatchAntarStop) BatchAntarStopResponse {
+	res := BatchAntarStopResponse{
+		ID:           s.ID.String(),
+		PenerimaID:   s.PenerimaID.String(),
+		PenerimaName: s.PenerimaNama,
+		Alamat:       s.PenerimaAlamat,
+		Phone:        s.PenerimaPhone,
+		RT:           s.PenerimaRT,
+		RW:           s.PenerimaRW,
+		Urutan:       s.Urutan,
+		JumlahPaket:  s.JumlahPaket,
+		Status:       s.Status,
+		Catatan:      s.Catatan,
+		DiperbaruiAt: s.DiperbaruiAt,
+	}
+	if s.DistribusiID != nil {
+		id := s.DistribusiID.String()
+		res.DistribusiID = &id
+	}
+	if s.DiperbaruiOleh != nil {
+		id := s.DiperbaruiOleh.String()
+		res.DiperbaruiOleh = &id
+	}
+	return res
+}
+
+func ToBatchAntarResponse(b *model.BatchAntar) BatchAntarResponse {
+	res := BatchAntarResponse{
+		ID:          b.ID.String(),
+		Nama:        b.Nama,
+		Tanggal:     b.Tanggal,
+		Lokasi:      b.LokasiNama,
+		RT:          b.RT,
+		RW:          b.RW,
+		Kurir:       b.KurirNama,
+		KurirPhone:  b.KurirPhone,
+		Catatan:     b.Catatan,
+		JumlahStop:  b.JumlahStop,
+		StopSelesai: b.StopSelesai,
+		CreatedAt:   b.Created_At,
+	}
+	if b.LokasiID != nil {
+		id := b.LokasiID.String()
+		res.LokasiID = &id
+	}
+	if b.KurirID != nil {
+		id := b.KurirID.String()
+		res.KurirID = &id
+	}
+	for i := range b.Stop {
+		res.Stop = append(res.Stop, ToBatchAntarStopResponse(&b.Stop[i]))
+	}
+	return res
+}
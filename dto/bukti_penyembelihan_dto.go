@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type BuktiPenyembelihanResponse struct {
+	ID              string    `json:"id"`
+	PenyembelihanID string    `json:"penyembelihan_id"`
+	Tipe            string    `json:"tipe"`
+	URL             string    `json:"url"`
+	NamaAsli        string    `json:"nama_asli"`
+	ContentType     string    `json:"content_type"`
+	Ukuran          int64     `json:"ukuran"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BuktiLengkapResponse adalah hasil penandaan bukti lengkap beserta ringkasan email ke pekurban
+type BuktiLengkapResponse struct {
+	Penyembelihan PenyembelihanResponse        `json:"penyembelihan"`
+	Bukti         []BuktiPenyembelihanResponse `json:"bukti"`
+	Terkirim      int                          `json:"terkirim"`
+	TanpaEmail    []string                     `json:"tanpa_email"` // nama pekurban yang tidak punya email
+	Gagal         []string                     `json:"gagal"`       // nama pekurban yang emailnya gagal dikirim
+}
+
+func ToBuktiPenyembelihanResponse(b *model.BuktiPenyembelihan, url string) BuktiPenyembelihanResponse {
+	return BuktiPenyembelihanResponse{
+		ID:              b.ID.String(),
+		PenyembelihanID: b.PenyembelihanID.String(),
+		Tipe:            b.Tipe,
+		URL:             url,
+		NamaAsli:        b.NamaAsli,
+		ContentType:     b.ContentType,
+		Ukuran:          b.Ukuran,
+		CreatedAt:       b.Created_At,
+	}
+}
@@ -6,27 +6,145 @@ import (
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 )
 
+// CreateDistribusiRequest wajib menyebut asal daging: hewan_id jika semua paket dari satu hewan,
+// atau hewan[] berisi paket per hewan yang jumlahnya sama dengan jumlah_paket
 type CreateDistribusiRequest struct {
 	PenerimaID        string    `json:"penerima_id" binding:"required,uuid"`
+	LokasiID          *string   `json:"lokasi_id" binding:"omitempty,uuid"`
 	JumlahPaket       int       `json:"jumlah_paket" binding:"required,min=1"`
 	TanggalDistribusi string 	`json:"tanggal_distribusi" binding:"required"`
+	HewanID           *string   `json:"hewan_id" binding:"omitempty,uuid"`
+	Hewan             []DistribusiHewanRequest `json:"hewan" binding:"omitempty,dive"`
+}
+
+type DistribusiHewanRequest struct {
+	HewanID     string `json:"hewan_id" binding:"required,uuid"`
+	JumlahPaket int    `json:"jumlah_paket" binding:"required,min=1"`
+}
+
+type DistribusiHewanResponse struct {
+	HewanID     string `json:"hewan_id"`
+	Jenis       string `json:"jenis"`
+	JumlahPaket int    `json:"jumlah_paket"`
+}
+
+// RekapDistribusiHewanResponse membandingkan paket yang dihasilkan satu hewan dengan yang sudah dibagikan
+type RekapDistribusiHewanResponse struct {
+	HewanID              string     `json:"hewan_id"`
+	Jenis                string     `json:"jenis"`
+	Status               string     `json:"status"`
+	TanggalPenyembelihan *time.Time `json:"tanggal_penyembelihan,omitempty"`
+	PaketDihasilkan      *int       `json:"paket_dihasilkan"`
+	PaketDidistribusikan int        `json:"paket_didistribusikan"`
+	SisaPaket            *int       `json:"sisa_paket"`
+	TotalDistribusi      int        `json:"total_distribusi"`
 }
 
 type DistribusiResponse struct {
 	ID                string    `json:"id"`
 	PenerimaID        string    `json:"penerima_id"`
 	PenerimaName      string    `json:"penerima_name"`
+	LokasiID          *string   `json:"lokasi_id,omitempty"`
+	Lokasi            *string   `json:"lokasi,omitempty"`
 	JumlahPaket       int       `json:"jumlah_paket"`
 	TanggalDistribusi time.Time `json:"tanggal_distribusi"`
+	Hewan             []DistribusiHewanResponse `json:"hewan"`
+	DicatatOleh       *string   `json:"dicatat_oleh,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 
 func ToDistribusiResponse(d *model.DistribusiDaging) DistribusiResponse {
-	return DistribusiResponse{
+	res := DistribusiResponse{
 		ID: d.ID.String(),
 		PenerimaID: d.PenerimaID.String(),
 		PenerimaName: d.PenerimaName,
+		Lokasi: d.LokasiNama,
 		JumlahPaket: d.JumlahPaket,
 		TanggalDistribusi: d.TanggalDistribusi,
+		Hewan: []DistribusiHewanResponse{},
+		CreatedAt: d.Created_At,
+	}
+	if d.DicatatOleh != nil {
+		id := d.DicatatOleh.String()
+		res.DicatatOleh = &id
 	}
-}
\ No newline at end of file
+	if d.LokasiID != nil {
+		id := d.LokasiID.String()
+		res.LokasiID = &id
+	}
+	for _, h := range d.Hewan {
+		res.Hewan = append(res.Hewan, DistribusiHewanResponse{
+			HewanID: h.HewanID.String(),
+			Jenis: string(h.Jenis),
+			JumlahPaket: h.JumlahPaket,
+		})
+	}
+	return res
+}
+
+func ToRekapDistribusiHewanResponse(r *model.RekapDistribusiHewan) RekapDistribusiHewanResponse {
+	res := RekapDistribusiHewanResponse{
+		HewanID: r.HewanID.String(),
+		Jenis: string(r.Jenis),
+		Status: r.Status,
+		TanggalPenyembelihan: r.TanggalPenyembelihan,
+		PaketDihasilkan: r.PaketDihasilkan,
+		PaketDidistribusikan: r.PaketDidistribusikan,
+		TotalDistribusi: r.TotalDistribusi,
+	}
+	if r.PaketDihasilkan != nil {
+		sisa := *r.PaketDihasilkan - r.PaketDidistribusikan
+		res.SisaPaket = &sisa
+	}
+	return res
+}
+// SaveAlokasiRequest menyimpan rencana paket per penerima untuk satu tahun Hijriah; jumlah_paket 0 menghapus
+// alokasi. Daftar alokasi bisa diambil dari hasil POST /distribusi/rencana.
+type SaveAlokasiRequest struct {
+	TahunHijri int                `json:"tahun_hijri" binding:"omitempty,gte=1400,lte=1600"` // default tahun Hijriah berjalan
+	Alokasi    []AlokasiPaketItem `json:"alokasi" binding:"required,min=1,dive"`
+}
+
+type AlokasiPaketItem struct {
+	PenerimaID  string `json:"penerima_id" binding:"required,uuid"`
+	JumlahPaket int    `json:"jumlah_paket" binding:"gte=0"`
+}
+
+// RekapDistribusiPenerimaResponse membandingkan alokasi penerima dengan paket yang sudah diterimanya dalam satu tahun
+type RekapDistribusiPenerimaResponse struct {
+	PenerimaResponse
+	TahunHijri      int  `json:"tahun_hijri"`
+	AlokasiPaket    *int `json:"alokasi_paket"`
+	PaketDiterima   int  `json:"paket_diterima"`
+	SisaPaket       *int `json:"sisa_paket"`
+	TotalDistribusi int  `json:"total_distribusi"`
+}
+
+// PeriodeDistribusiResponse adalah distribusi seorang penerima dalam satu tahun Hijriah
+type PeriodeDistribusiResponse struct {
+	TahunHijri    int                  `json:"tahun_hijri"`
+	AlokasiPaket  *int                 `json:"alokasi_paket"`
+	PaketDiterima int                  `json:"paket_diterima"`
+	Distribusi    []DistribusiResponse `json:"distribusi"`
+}
+
+type RiwayatDistribusiPenerimaResponse struct {
+	Penerima PenerimaResponse            `json:"penerima"`
+	Periode  []PeriodeDistribusiResponse `json:"periode"`
+}
+
+func ToRekapDistribusiPenerimaResponse(r *model.RekapDistribusiPenerima) RekapDistribusiPenerimaResponse {
+	res := RekapDistribusiPenerimaResponse{
+		PenerimaResponse: ToPenerimaResponse(&r.Penerima),
+		TahunHijri: r.TahunHijri,
+		AlokasiPaket: r.AlokasiPaket,
+		PaketDiterima: r.PaketDiterima,
+		TotalDistribusi: r.TotalDistribusi,
+	}
+	if r.AlokasiPaket != nil {
+		sisa := max(*r.AlokasiPaket-r.PaketDiterima, 0)
+		res.SisaPaket = &sisa
+	}
+	return res
+}
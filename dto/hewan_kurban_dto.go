@@ -1,24 +1,29 @@
 package dto
 
 import (
+	"math"
 	"time"
 
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 )
 
 type CreateHewanKurbanRequest struct {
-	Jenis           string  `json:"jenis" binding:"required,oneof=sapi kambing domba"`
+	Jenis           string  `json:"jenis" binding:"required"`
 	Berat           float64 `json:"berat" binding:"required,gt=0"`
+	UmurBulan       *int    `json:"umur_bulan" binding:"omitempty,gt=0"`
 	Harga           *float64 `json:"harga"`
 	IsPrivate       *bool   `json:"is_private"`
+	FotoURL         *string `json:"foto_url" binding:"omitempty,url"`
 	TglPendaftaran  string  `json:"tanggal_pendaftaran" binding:"required"`
 }
 
 type UpdateHewanKurbanRequest struct {
-	Jenis           string   `json:"jenis" binding:"omitempty,oneof=sapi kambing domba"`
+	Jenis           string   `json:"jenis" binding:"omitempty"`
 	Berat           float64  `json:"berat" binding:"omitempty,gt=0"`
+	UmurBulan       *int     `json:"umur_bulan" binding:"omitempty,gt=0"`
 	Harga           float64  `json:"harga" binding:"omitempty,gt=0"`
 	IsPrivate       *bool    `json:"is_private"`
+	FotoURL         *string  `json:"foto_url" binding:"omitempty,url"`
 	TglPendaftaran  string   `json:"tanggal_pendaftaran" binding:"omitempty"`
 }
 
@@ -26,10 +31,13 @@ type HewanKurbanResponse struct {
 	ID              	string  `json:"id"`
 	Jenis           	string  `json:"jenis"`
 	Berat           	float64 `json:"berat"`
+	UmurBulan       	*int    `json:"umur_bulan,omitempty"`
 	Harga           	float64 `json:"harga"`
 	IsPrivate       	bool    `json:"is_private"`
+	FotoURL         	*string `json:"foto_url,omitempty"`
 	TglPendaftaran  	string  `json:"tanggal_pendaftaran"`
 	StatusPenyembelihan string  `json:"status_penyembelihan"`
+	Status				string	`json:"status"`
 	CreatedAt       	string  `json:"created_at"`
 	UpdatedAt       	string  `json:"updated_at"`
 }
@@ -44,11 +52,90 @@ func ToHewanKurbanResponse(h *model.HewanKurban, sudahDisembelih bool) HewanKurb
 		ID:             h.ID.String(),
 		Jenis:          string(h.Jenis),
 		Berat:          h.Berat,
+		UmurBulan:      h.UmurBulan,
 		Harga:          h.Harga,
 		IsPrivate:      h.IsPrivate,
+		FotoURL:        h.FotoURL,
 		TglPendaftaran: h.TanggalPendaftaran.Format("2006-01-02"),
 		StatusPenyembelihan: status,
+		Status:			h.Status,
 		CreatedAt:      h.Created_At.Format(time.RFC3339),
 		UpdatedAt:      h.Updated_At.Format(time.RFC3339),
 	}
 }
+
+type UpdateStatusHewanRequest struct {
+	Status		string	`json:"status" binding:"required"`
+	Keterangan	*string	`json:"keterangan"`
+}
+
+type RiwayatStatusHewanResponse struct {
+	DariStatus		*string		`json:"dari_status"`
+	KeStatus		string		`json:"ke_status"`
+	DiubahOleh		*string		`json:"diubah_oleh,omitempty"`
+	NamaPengubah	*string		`json:"nama_pengubah,omitempty"`
+	Keterangan		*string		`json:"keterangan,omitempty"`
+	CreatedAt		time.Time	`json:"created_at"`
+}
+
+func ToRiwayatStatusHewanResponse(r *model.RiwayatStatusHewan) RiwayatStatusHewanResponse {
+	var diubahOleh *string
+	if r.DiubahOleh != nil {
+		id := r.DiubahOleh.String()
+		diubahOleh = &id
+	}
+
+	return RiwayatStatusHewanResponse{
+		DariStatus:   r.DariStatus,
+		KeStatus:     r.KeStatus,
+		DiubahOleh:   diubahOleh,
+		NamaPengubah: r.NamaPengubah,
+		Keterangan:   r.Keterangan,
+		CreatedAt:    r.Created_At,
+	}
+}
+
+type PublicHewanQuery struct {
+	Jenis    string   `form:"jenis"`
+	MinHarga *float64 `form:"min_harga" binding:"omitempty,gte=0"`
+	MaxHarga *float64 `form:"max_harga" binding:"omitempty,gte=0"`
+}
+
+type PublicHewanResponse struct {
+	ID                   string  `json:"id"`
+	Jenis                string  `json:"jenis"`
+	Berat                float64 `json:"berat"`
+	HargaPerPorsi        float64 `json:"harga_per_porsi"`
+	KapasitasPorsi       int     `json:"kapasitas_porsi"`
+	PorsiTerisi          int     `json:"porsi_terisi"`
+	SisaPorsi            int     `json:"sisa_porsi"`
+	FotoURL              *string `json:"foto_url,omitempty"`
+	TanggalPenyembelihan *string `json:"tanggal_penyembelihan,omitempty"`
+	LokasiPenyembelihan  *string `json:"lokasi_penyembelihan,omitempty"`
+}
+
+func ToPublicHewanResponse(h model.PublicHewan) PublicHewanResponse {
+	sisa := h.KapasitasPorsi - h.PorsiTerisi
+	if sisa < 0 {
+		sisa = 0
+	}
+
+	var tanggal *string
+	if h.TanggalPenyembelihan != nil {
+		t := h.TanggalPenyembelihan.Format("2006-01-02")
+		tanggal = &t
+	}
+
+	return PublicHewanResponse{
+		ID:                   h.ID.String(),
+		Jenis:                string(h.Jenis),
+		Berat:                h.Berat,
+		HargaPerPorsi:        math.Round(h.Harga/float64(h.KapasitasPorsi)*100) / 100,
+		KapasitasPorsi:       h.KapasitasPorsi,
+		PorsiTerisi:          h.PorsiTerisi,
+		SisaPorsi:            sisa,
+		FotoURL:              h.FotoURL,
+		TanggalPenyembelihan: tanggal,
+		LokasiPenyembelihan:  h.Lokasi,
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+// JadwalLokasiRequest menggambarkan kapasitas satu lokasi: jumlah titik pemotongan yang bisa berjalan
+// paralel (default kapasitas lokasi) dan lama penyembelihan per jenis. Jenis yang tidak tercantum tidak
+// dilayani di lokasi ini.
+type JadwalLokasiRequest struct {
+	LokasiID        string         `json:"lokasi_id" binding:"required,uuid"`
+	TitikPemotongan int            `json:"titik_pemotongan" binding:"omitempty,gt=0,lte=50"`
+	DurasiMenit     map[string]int `json:"durasi_menit" binding:"required,min=1"`
+}
+
+// JagalRequest adalah ketersediaan satu jagal pada satu tanggal; lokasi kosong berarti bisa di lokasi mana pun
+type JagalRequest struct {
+	Nama       string  `json:"nama" binding:"required,max=100"`
+	Tanggal    string  `json:"tanggal" binding:"required"`
+	JamMulai   string  `json:"jam_mulai" binding:"required"`
+	JamSelesai string  `json:"jam_selesai" binding:"required"`
+	LokasiID   *string `json:"lokasi_id" binding:"omitempty,uuid"`
+}
+
+// GenerateJadwalRequest membuat jadwal untuk semua hewan lunas. Tanpa daftar jagal, setiap titik
+// pemotongan dianggap punya jagal sendiri sepanjang jam kerja. Prioritas diurutkan dari yang terpenting:
+// private (hewan private lebih dulu), lunas (lebih awal lunas lebih dulu), hadir (pekurban ingin hadir).
+type GenerateJadwalRequest struct {
+	Tanggal    []string              `json:"tanggal" binding:"required,min=1,dive,required"`
+	JamMulai   string                `json:"jam_mulai" binding:"required"`
+	JamSelesai string                `json:"jam_selesai" binding:"required"`
+	Lokasi     []JadwalLokasiRequest `json:"lokasi" binding:"required,min=1,dive"`
+	Jagal      []JagalRequest        `json:"jagal" binding:"dive"`
+	Prioritas  []string              `json:"prioritas" binding:"dive,oneof=private lunas hadir"`
+	Simpan     bool                  `json:"simpan"` // false: hanya pratinjau
+}
+
+type JadwalItemResponse struct {
+	HewanID       string  `json:"hewan_id"`
+	Jenis         string  `json:"jenis"`
+	IsPrivate     bool    `json:"is_private"`
+	InginHadir    bool    `json:"ingin_hadir"`
+	Tanggal       string  `json:"tanggal"`
+	TanggalHijri  string  `json:"tanggal_hijri"`
+	LokasiID      string  `json:"lokasi_id"`
+	Lokasi        string  `json:"lokasi"`
+	Titik         int     `json:"titik"`
+	Jagal         *string `json:"jagal,omitempty"`
+	JamMulai      string  `json:"jam_mulai"`
+	JamSelesai    string  `json:"jam_selesai"`
+	UrutanRencana int     `json:"urutan_rencana"`
+}
+
+type JadwalGagalResponse struct {
+	HewanID string `json:"hewan_id"`
+	Jenis   string `json:"jenis"`
+	Alasan  string `json:"alasan"`
+}
+
+type GenerateJadwalResponse struct {
+	Disimpan       bool                  `json:"disimpan"`
+	Jadwal         []JadwalItemResponse  `json:"jadwal"`
+	TidakTerjadwal []JadwalGagalResponse `json:"tidak_terjadwal"`
+}
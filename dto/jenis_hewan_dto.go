@@ -0,0 +1,32 @@
+package dto
+
+import "github.com/wahyujatirestu/sahabat-kurban/model"
+
+type CreateJenisHewanRequest struct {
+	Nama			string		`json:"nama" binding:"required,max=50"`
+	MaxPorsi		int			`json:"max_porsi" binding:"required,gte=1"`
+	MinUmurBulan	int			`json:"min_umur_bulan" binding:"omitempty,gte=0"`
+	HargaDefault	*float64	`json:"harga_default" binding:"omitempty,gt=0"`
+}
+
+type UpdateJenisHewanRequest struct {
+	MaxPorsi		int			`json:"max_porsi" binding:"required,gte=1"`
+	MinUmurBulan	int			`json:"min_umur_bulan" binding:"omitempty,gte=0"`
+	HargaDefault	*float64	`json:"harga_default" binding:"omitempty,gt=0"`
+}
+
+type JenisHewanResponse struct {
+	Nama			string		`json:"nama"`
+	MaxPorsi		int			`json:"max_porsi"`
+	MinUmurBulan	int			`json:"min_umur_bulan"`
+	HargaDefault	*float64	`json:"harga_default,omitempty"`
+}
+
+func ToJenisHewanResponse(j *model.JenisHewanMaster) JenisHewanResponse {
+	return JenisHewanResponse{
+		Nama:         string(j.Nama),
+		MaxPorsi:     j.MaxPorsi,
+		MinUmurBulan: j.MinUmurBulan,
+		HargaDefault: j.HargaDefault,
+	}
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// KalenderTokenResponse berisi URL feed kalender rahasia; token hanya ditampilkan sekali saat dibuat
+type KalenderTokenResponse struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// GenerateKuponRequest membuat kupon untuk banyak penerima sekaligus; daftar kupon bisa diambil dari
+// alokasi hasil POST /distribusi/rencana. Penerima yang masih punya kupon belum ditukar dilewati.
+type GenerateKuponRequest struct {
+	TanggalDistribusi string              `json:"tanggal_distribusi"` // opsional, YYYY-MM-DD
+	LokasiID          *string             `json:"lokasi_id" binding:"omitempty,uuid"`
+	Kupon             []GenerateKuponItem `json:"kupon" binding:"required,min=1,dive"`
+}
+
+type GenerateKuponItem struct {
+	PenerimaID  string `json:"penerima_id" binding:"required,uuid"`
+	JumlahPaket int    `json:"jumlah_paket" binding:"required,min=1"`
+}
+
+// ScanKuponRequest berisi teks QR yang dibaca kamera ponsel panitia
+type ScanKuponRequest struct {
+	Kode string `json:"kode" binding:"required"`
+}
+
+type KuponResponse struct {
+	ID                string     `json:"id"`
+	Nomor             string     `json:"nomor"` // nomor pendek di kartu untuk dicocokkan manual
+	Kode              string     `json:"kode"`  // isi QR bertanda tangan
+	PenerimaID        string     `json:"penerima_id"`
+	PenerimaName      string     `json:"penerima_name"`
+	PenerimaStatus    string     `json:"penerima_status"`
+	JumlahPaket       int        `json:"jumlah_paket"`
+	TanggalDistribusi *string    `json:"tanggal_distribusi,omitempty"`
+	LokasiID          *string    `json:"lokasi_id,omitempty"`
+	Lokasi            *string    `json:"lokasi,omitempty"`
+	DistribusiID      *string    `json:"distribusi_id,omitempty"`
+	DitukarAt         *time.Time `json:"ditukar_at,omitempty"`
+	DitukarOleh       *string    `json:"ditukar_oleh,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// GenerateKuponResponse memisahkan kupon baru dari penerima yang dilewati karena sudah punya kupon
+type GenerateKuponResponse struct {
+	Dibuat   []KuponResponse `json:"dibuat"`
+	Dilewati []KuponResponse `json:"dilewati"`
+}
+
+// ScanKuponResponse adalah hasil penukaran kupon beserta distribusi yang tercatat
+type ScanKuponResponse struct {
+	Kupon      KuponResponse      `json:"kupon"`
+	Distribusi DistribusiResponse `json:"distribusi"`
+}
+
+// NomorKupon adalah 8 digit heksadesimal pertama id kupon, huruf besar
+func NomorKupon(k *model.KuponDistribusi) string {
+	return strings.ToUpper(k.ID.String()[:8])
+}
+
+func ToKuponResponse(k *model.KuponDistribusi, kode string) KuponResponse {
+	res := KuponResponse{
+		ID:             k.ID.String(),
+		Nomor:          NomorKupon(k),
+		Kode:           kode,
+		PenerimaID:     k.PenerimaID.String(),
+		PenerimaName:   k.PenerimaNama,
+		PenerimaStatus: k.PenerimaStatus,
+		JumlahPaket:    k.JumlahPaket,
+		Lokasi:         k.LokasiNama,
+		DitukarAt:      k.DitukarAt,
+		CreatedAt:      k.Created_At,
+	}
+	if k.TanggalDistribusi != nil {
+		t := k.TanggalDistribusi.Format("2006-01-02")
+		res.TanggalDistribusi = &t
+	}
+	if k.LokasiID != nil {
+		id := k.LokasiID.String()
+		res.LokasiID = &id
+	}
+	if k.DistribusiID != nil {
+		id := k.DistribusiID.String()
+		res.DistribusiID = &id
+	}
+	if k.DitukarOleh != nil {
+		id := k.DitukarOleh.String()
+		res.DitukarOleh = &id
+	}
+	return res
+}
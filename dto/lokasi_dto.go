@@ -0,0 +1,56 @@
+package dto
+
+import "github.com/wahyujatirestu/sahabat-kurban/model"
+
+type CreateLokasiRequest struct {
+	Nama        string   `json:"nama" binding:"required,max=255"`
+	Alamat      *string  `json:"alamat"`
+	Latitude    *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Kapasitas   int      `json:"kapasitas" binding:"omitempty,gt=0"`
+	KontakNama  *string  `json:"kontak_nama" binding:"omitempty,max=100"`
+	KontakPhone *string  `json:"kontak_phone" binding:"omitempty,max=20"`
+	Tipe        string   `json:"tipe" binding:"required,oneof=penyembelihan distribusi keduanya"`
+}
+
+type UpdateLokasiRequest struct {
+	Nama        string   `json:"nama" binding:"omitempty,max=255"`
+	Alamat      *string  `json:"alamat"`
+	Latitude    *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Kapasitas   int      `json:"kapasitas" binding:"omitempty,gt=0"`
+	KontakNama  *string  `json:"kontak_nama" binding:"omitempty,max=100"`
+	KontakPhone *string  `json:"kontak_phone" binding:"omitempty,max=20"`
+	Tipe        string   `json:"tipe" binding:"omitempty,oneof=penyembelihan distribusi keduanya"`
+}
+
+// MergeLokasiRequest menyatukan lokasi ganda ke lokasi tujuan
+type MergeLokasiRequest struct {
+	KeLokasiID string `json:"ke_lokasi_id" binding:"required,uuid"`
+}
+
+type LokasiResponse struct {
+	ID          string   `json:"id"`
+	Nama        string   `json:"nama"`
+	Alamat      *string  `json:"alamat,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	Kapasitas   int      `json:"kapasitas"`
+	KontakNama  *string  `json:"kontak_nama,omitempty"`
+	KontakPhone *string  `json:"kontak_phone,omitempty"`
+	Tipe        string   `json:"tipe"`
+}
+
+func ToLokasiResponse(l *model.Lokasi) LokasiResponse {
+	return LokasiResponse{
+		ID:          l.ID.String(),
+		Nama:        l.Nama,
+		Alamat:      l.Alamat,
+		Latitude:    l.Latitude,
+		Longitude:   l.Longitude,
+		Kapasitas:   l.Kapasitas,
+		KontakNama:  l.KontakNama,
+		KontakPhone: l.KontakPhone,
+		Tipe:        l.Tipe,
+	}
+}
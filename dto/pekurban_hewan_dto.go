@@ -1,11 +1,18 @@
 package dto
 
-import "github.com/wahyujatirestu/sahabat-kurban/model"
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
 
 type CreatePekurbanHewanRequest struct {
 	PekurbanID	 string	`json:"pekurban_id" binding:"required,uuid"`
 	HewanID		 string	`json:"hewan_id" binding:"required,uuid"`
-	JumlahOrang  int    `json:"jumlah_orang" binding:"required,gt=0,lte=7"`
+	JumlahOrang  int    `json:"jumlah_orang" binding:"omitempty,gt=0"`
+	// pembagian khusus pemilik, mis. 7/14 untuk 3.5 dari 7 bagian sapi; dipakai jika jumlah_orang kosong
+	PorsiPembilang int64 `json:"porsi_pembilang" binding:"omitempty,gt=0"`
+	PorsiPenyebut  int64 `json:"porsi_penyebut" binding:"omitempty,gt=0,lte=1000"`
 }
 
 type PekurbanHewanResponse struct {
@@ -14,18 +21,31 @@ type PekurbanHewanResponse struct {
 	HewanID    	string  `json:"hewan_id"`
 	Hewan      	string  `json:"hewan"`
 	Porsi      	float64 `json:"porsi"`
+	PorsiPecahan string `json:"porsi_pecahan"`
 	JumlahOrang int     `json:"jumlah_orang"`
+	Status		string	`json:"status"`
+	ExpiresAt	*time.Time `json:"expires_at,omitempty"`
+	AtasNama	[]AtasNamaResponse `json:"atas_nama"`
 }
 
 type UpdatePekurbanHewanRequest struct {
-	JumlahOrang int `json:"jumlah_orang" binding:"required,gt=0,lte=7"`
+	JumlahOrang 	int 	`json:"jumlah_orang" binding:"omitempty,gt=0"`
+	PorsiPembilang 	int64 	`json:"porsi_pembilang" binding:"omitempty,gt=0"`
+	PorsiPenyebut  	int64 	`json:"porsi_penyebut" binding:"omitempty,gt=0,lte=1000"`
 }
 
+// UpdateKehadiranRequest menandai pekurban ingin menyaksikan penyembelihan hewannya
+type UpdateKehadiranRequest struct {
+	InginHadir	*bool	`json:"ingin_hadir" binding:"required"`
+}
 
 func ToPekurbanHewanResponse(ph *model.PekurbanHewan) PekurbanHewanResponse {
 	return PekurbanHewanResponse{
 		PekurbanID: ph.PekurbanID.String(),
 		HewanID: ph.HewanID.String(),
-		Porsi: ph.Porsi,
+		Porsi: ph.Porsi.Float64(),
+		PorsiPecahan: ph.Porsi.String(),
+		Status: ph.Status,
+		ExpiresAt: ph.ExpiresAt,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type CreatePembatalanRequest struct {
+	Alasan	string	`json:"alasan" binding:"required,max=1000"`
+}
+
+type ProsesPembatalanRequest struct {
+	Catatan	*string	`json:"catatan"`
+}
+
+type PembatalanResponse struct {
+	ID					string		`json:"id"`
+	PekurbanID			string		`json:"pekurban_id"`
+	Pekurban			string		`json:"pekurban,omitempty"`
+	HewanID				string		`json:"hewan_id"`
+	Hewan				string		`json:"hewan,omitempty"`
+	Porsi				float64		`json:"porsi"`
+	PorsiPecahan		string		`json:"porsi_pecahan"`
+	Alasan				string		`json:"alasan"`
+	Status				string		`json:"status"`
+	Kebijakan			string		`json:"kebijakan"`
+	PersentaseRefund	int			`json:"persentase_refund"`
+	JumlahDibayar		float64		`json:"jumlah_dibayar"`
+	JumlahRefund		float64		`json:"jumlah_refund"`
+	DiprosesOleh		*string		`json:"diproses_oleh,omitempty"`
+	CatatanAdmin		*string		`json:"catatan_admin,omitempty"`
+	DiprosesAt			*time.Time	`json:"diproses_at,omitempty"`
+	CreatedAt			time.Time	`json:"created_at"`
+}
+
+func ToPembatalanResponse(p *model.PembatalanPatungan) PembatalanResponse {
+	var diprosesOleh *string
+	if p.DiprosesOleh != nil {
+		id := p.DiprosesOleh.String()
+		diprosesOleh = &id
+	}
+
+	return PembatalanResponse{
+		ID:               p.ID.String(),
+		PekurbanID:       p.PekurbanID.String(),
+		Pekurban:         p.Pekurban,
+		HewanID:          p.HewanID.String(),
+		Hewan:            p.Hewan,
+		Porsi:            p.Porsi.Float64(),
+		PorsiPecahan:     p.Porsi.String(),
+		Alasan:           p.Alasan,
+		Status:           p.Status,
+		Kebijakan:        p.Kebijakan,
+		PersentaseRefund: p.PersentaseRefund,
+		JumlahDibayar:    p.JumlahDibayar,
+		JumlahRefund:     p.JumlahRefund,
+		DiprosesOleh:     diprosesOleh,
+		CatatanAdmin:     p.CatatanAdmin,
+		DiprosesAt:       p.DiprosesAt,
+		CreatedAt:        p.Created_At,
+	}
+}
@@ -13,6 +13,14 @@ type CreatePaymentRequest struct {
 	Bank          string    `json:"bank,omitempty"`
 }
 
+type MidtransNotificationRequest struct {
+	OrderID           string  `json:"order_id" binding:"required"`
+	StatusCode        string  `json:"status_code" binding:"required"`
+	GrossAmount       string  `json:"gross_amount" binding:"required"`
+	SignatureKey      string  `json:"signature_key" binding:"required"`
+	TransactionStatus string  `json:"transaction_status" binding:"required"`
+	FraudStatus       *string `json:"fraud_status,omitempty"`
+}
 
 type PaymentResponse struct {
 	ID              string   `json:"id"`
@@ -28,6 +36,8 @@ type PaymentResponse struct {
 	TransactionTime *string  `json:"transaction_time,omitempty"`
 	RedirectURL     *string  `json:"redirect_url,omitempty"`
 	Jumlah          float64  `json:"jumlah"`
+	AsalPembayaranID *string `json:"asal_pembayaran_id,omitempty"`
+	PerluRefund     bool     `json:"perlu_refund"`
 }
 
 func ToPaymentResponse(p *model.PembayaranKurban, jumlah float64, mid *payment.MidtransChargeResponse) PaymentResponse {
@@ -42,6 +52,12 @@ func ToPaymentResponse(p *model.PembayaranKurban, jumlah float64, mid *payment.M
 		redirectURL = mid.QRUrl
 	}
 
+	var asal *string
+	if p.AsalPembayaranID != nil {
+		id := p.AsalPembayaranID.String()
+		asal = &id
+	}
+
 	return PaymentResponse{
 		ID:              p.ID.String(),
 		OrderID:         p.OrderID,
@@ -56,6 +72,8 @@ func ToPaymentResponse(p *model.PembayaranKurban, jumlah float64, mid *payment.M
 		TransactionTime: trxTime,
 		RedirectURL:     redirectURL,
 		Jumlah:          jumlah,
+		AsalPembayaranID: asal,
+		PerluRefund:     p.PerluRefund,
 	}
 }
 
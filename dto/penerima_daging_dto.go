@@ -6,6 +6,9 @@ type CreatePenerimaRequest struct {
 	Name       string  `json:"name"`
 	Alamat     *string `json:"alamat,omitempty"`
 	Phone      *string `json:"phone,omitempty"`
+	RT         *string `json:"rt,omitempty" binding:"omitempty,max=3"`
+	RW         *string `json:"rw,omitempty" binding:"omitempty,max=3"`
+	AntarRumah *bool   `json:"antar_rumah,omitempty"` // lansia/difabel yang dagingnya diantar ke rumah
 	Status     string  `json:"status" binding:"required,oneof=warga dhuafa panitia pekurban"`
 	PekurbanID *string `json:"pekurban_id,omitempty"`
 }
@@ -14,6 +17,9 @@ type UpdatePenerimaRequest struct {
 	Name       *string `json:"name,omitempty"`
 	Alamat     *string `json:"alamat,omitempty"`
 	Phone      *string `json:"phone,omitempty"`
+	RT         *string `json:"rt,omitempty" binding:"omitempty,max=3"`
+	RW         *string `json:"rw,omitempty" binding:"omitempty,max=3"`
+	AntarRumah *bool   `json:"antar_rumah,omitempty"` // lansia/difabel yang dagingnya diantar ke rumah
 	Status     string  `json:"status" binding:"required,oneof=warga dhuafa panitia pekurban"`
 	PekurbanID *string `json:"pekurban_id,omitempty"`
 }
@@ -23,6 +29,9 @@ type PenerimaResponse struct {
 	Name       string  `json:"name"`
 	Alamat     *string `json:"alamat,omitempty"`
 	Phone      *string `json:"phone,omitempty"`
+	RT         *string `json:"rt,omitempty"`
+	RW         *string `json:"rw,omitempty"`
+	AntarRumah bool    `json:"antar_rumah"`
 	Status     string  `json:"status"`
 	PekurbanID *string `json:"pekurban_id,omitempty"`
 }
@@ -40,7 +49,46 @@ func ToPenerimaResponse(p *model.PenerimaDaging) PenerimaResponse {
 		Name: p.Name,
 		Alamat: p.Alamat,
 		Phone: p.Phone,
+		RT: p.RT,
+		RW: p.RW,
+		AntarRumah: p.AntarRumah,
 		Status: p.Status,
 		PekurbanID: pekurbanID,
 	}
-}
\ No newline at end of file
+}
+// ImportPenerimaRequest adalah isian form selain berkas pada impor penerima
+type ImportPenerimaRequest struct {
+	// Mapping: kolom tujuan (name, alamat, phone, status, rt, rw, antar_rumah) -> judul kolom di berkas.
+	// Kolom yang tidak disebut dicari dari judul yang lazim (mis. "Nama", "No HP", "Kategori").
+	Mapping       map[string]string
+	Sheet         string // XLSX saja, default sheet pertama
+	StatusDefault string // dipakai jika sel status kosong
+	DryRun        bool   // true: hanya laporan validasi, tidak ada yang disimpan
+}
+
+// ImportPenerimaBaris adalah hasil validasi satu baris berkas
+type ImportPenerimaBaris struct {
+	Baris          int      `json:"baris"` // nomor baris di berkas, judul kolom = 1
+	Hasil          string   `json:"hasil"` // valid, invalid, duplikat, atau dibuat
+	Name           string   `json:"name"`
+	Alamat         *string  `json:"alamat,omitempty"`
+	Phone          *string  `json:"phone,omitempty"`
+	RT             *string  `json:"rt,omitempty"`
+	RW             *string  `json:"rw,omitempty"`
+	AntarRumah     bool     `json:"antar_rumah"`
+	Status         string   `json:"status"`
+	Errors         []string `json:"errors,omitempty"`
+	DuplikatDengan *string  `json:"duplikat_dengan,omitempty"` // penerima yang sudah ada atau baris sebelumnya
+	PenerimaID     *string  `json:"penerima_id,omitempty"`     // terisi setelah disimpan
+}
+
+type ImportPenerimaResponse struct {
+	DryRun     bool                  `json:"dry_run"`
+	Kolom      map[string]string     `json:"kolom"` // pemetaan kolom yang dipakai
+	TotalBaris int                   `json:"total_baris"`
+	Valid      int                   `json:"valid"`
+	Invalid    int                   `json:"invalid"`
+	Duplikat   int                   `json:"duplikat"`
+	Dibuat     int                   `json:"dibuat"`
+	Baris      []ImportPenerimaBaris `json:"baris"`
+}
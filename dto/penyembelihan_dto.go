@@ -4,20 +4,52 @@ import (
 	"time"
 
 	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
 )
 
 type CreatePenyembelihanRequest struct {
 	HewanID              string    `json:"hewan_id" binding:"required,uuid"`
 	TanggalPenyembelihan time.Time `json:"tanggal_penyembelihan" binding:"required"`
-	Lokasi               string    `json:"lokasi" binding:"required"`
+	LokasiID             string    `json:"lokasi_id" binding:"required,uuid"`
 	UrutanRencana        int       `json:"urutan_rencana" binding:"omitempty,min=1"`
+	RencanaMulai         *time.Time `json:"rencana_mulai"`
+	Jagal                *string   `json:"jagal" binding:"omitempty,max=100"`
 }
 
 type UpdatePenyembelihanRequest struct {
 	TanggalPenyembelihan time.Time `json:"tanggal_penyembelihan" binding:"required"`
-	Lokasi               string    `json:"lokasi" binding:"required"`
+	LokasiID             string    `json:"lokasi_id" binding:"omitempty,uuid"`
 	UrutanRencana        int       `json:"urutan_rencana" binding:"omitempty,min=1"`
 	UrutanAktual         *int      `json:"urutan_aktual"`
+	RencanaMulai         *time.Time `json:"rencana_mulai"` // kosong: tidak diubah
+}
+
+// MulaiPenyembelihanRequest adalah check-in awal; jagal kosong memakai jagal dari jadwal, waktu kosong berarti sekarang
+type MulaiPenyembelihanRequest struct {
+	Jagal string     `json:"jagal" binding:"omitempty,max=100"`
+	Saksi string     `json:"saksi" binding:"required,max=100"`
+	Waktu *time.Time `json:"waktu"`
+}
+
+// AssignJagalRequest menugaskan petugas berperan jagal ke satu penyembelihan
+type AssignJagalRequest struct {
+	PetugasID string `json:"petugas_id" binding:"required,uuid"`
+}
+
+// SelesaiPenyembelihanRequest adalah check-in akhir; saksi kosong memakai saksi saat mulai
+type SelesaiPenyembelihanRequest struct {
+	Saksi string     `json:"saksi" binding:"omitempty,max=100"`
+	Waktu *time.Time `json:"waktu"`
+}
+
+// UpdateHasilPenyembelihanRequest mencatat hasil penyembelihan dalam kg; field kosong tidak diubah
+type UpdateHasilPenyembelihanRequest struct {
+	BeratHidup  *float64 `json:"berat_hidup" binding:"omitempty,gt=0"`
+	BeratKarkas *float64 `json:"berat_karkas" binding:"omitempty,gte=0"`
+	BeratDaging *float64 `json:"berat_daging" binding:"omitempty,gte=0"`
+	BeratTulang *float64 `json:"berat_tulang" binding:"omitempty,gte=0"`
+	BeratJeroan *float64 `json:"berat_jeroan" binding:"omitempty,gte=0"`
+	JumlahPaket *int     `json:"jumlah_paket" binding:"omitempty,gte=0"`
 }
 
 type PenyembelihanResponse struct {
@@ -25,20 +57,84 @@ type PenyembelihanResponse struct {
 	HewanID              string    `json:"hewan_id"`
 	JenisHewan           string    `json:"jenis_hewan"`
 	TanggalPenyembelihan time.Time `json:"tanggal_penyembelihan"`
+	TanggalHijri         string    `json:"tanggal_penyembelihan_hijri"`
+	LokasiID             string    `json:"lokasi_id"`
 	Lokasi               string    `json:"lokasi"`
 	UrutanRencana        int       `json:"urutan_rencana"`
 	UrutanAktual         *int      `json:"urutan_aktual"`
+	RencanaMulai         *time.Time `json:"rencana_mulai"`
+	MulaiAt              *time.Time `json:"mulai_at"`
+	SelesaiAt            *time.Time `json:"selesai_at"`
+	DurasiMenit          *float64  `json:"durasi_menit"`
+	KeterlambatanMenit   *float64  `json:"keterlambatan_menit"` // mulai aktual - rencana; negatif berarti lebih awal
+	JagalID              *string   `json:"jagal_id"`
+	Jagal                *string   `json:"jagal"`
+	Saksi                *string   `json:"saksi"`
+	BuktiLengkapAt       *time.Time `json:"bukti_lengkap_at"`
+	BeratHidup           *float64  `json:"berat_hidup"`
+	BeratKarkas          *float64  `json:"berat_karkas"`
+	BeratDaging          *float64  `json:"berat_daging"`
+	BeratTulang          *float64  `json:"berat_tulang"`
+	BeratJeroan          *float64  `json:"berat_jeroan"`
+	PersentaseKarkas     *float64  `json:"persentase_karkas"`
+	JumlahPaket          *int      `json:"jumlah_paket"`
+	AtasNama             []AtasNamaResponse `json:"atas_nama"`
 }
 
-
-func ToPenyembelihanResponse(p *model.Penyembelihan) PenyembelihanResponse {
-	return PenyembelihanResponse{
+// ToPenyembelihanResponse memformat tanggal_hijri dengan offsetHari periode kurban aktif
+func ToPenyembelihanResponse(p *model.Penyembelihan, offsetHari int) PenyembelihanResponse {
+	res := PenyembelihanResponse{
 		ID:                   p.ID.String(),
 		HewanID:              p.HewanID.String(),
 		JenisHewan:           string(p.JenisHewan),
 		TanggalPenyembelihan: p.TglPenyembelihan,
+		TanggalHijri:         hijri.Format(p.TglPenyembelihan, offsetHari),
+		LokasiID:             p.LokasiID.String(),
 		Lokasi:               p.Lokasi,
 		UrutanRencana:        p.UrutanRencana,
 		UrutanAktual:         p.UrutanAktual,
+		RencanaMulai:         p.RencanaMulai,
+		MulaiAt:              p.MulaiAt,
+		SelesaiAt:            p.SelesaiAt,
+		DurasiMenit:          p.DurasiMenit(),
+		KeterlambatanMenit:   p.KeterlambatanMenit(),
+		Jagal:                p.Jagal,
+		Saksi:                p.Saksi,
+		BuktiLengkapAt:       p.BuktiLengkapAt,
+		BeratHidup:           p.BeratHidup,
+		BeratKarkas:          p.BeratKarkas,
+		BeratDaging:          p.BeratDaging,
+		BeratTulang:          p.BeratTulang,
+		BeratJeroan:          p.BeratJeroan,
+		PersentaseKarkas:     p.PersentaseKarkas(),
+		JumlahPaket:          p.JumlahPaket,
+		AtasNama:             []AtasNamaResponse{},
 	}
+	if p.JagalID != nil {
+		id := p.JagalID.String()
+		res.JagalID = &id
+	}
+	return res
+}
+
+type RekapDurasiJenisResponse struct {
+	Jenis           string  `json:"jenis"`
+	Selesai         int     `json:"selesai"`
+	RataDurasiMenit float64 `json:"rata_durasi_menit"`
+	MinDurasiMenit  float64 `json:"min_durasi_menit"`
+	MaxDurasiMenit  float64 `json:"max_durasi_menit"`
+}
+
+// RekapHarianPenyembelihanResponse merangkum check-in penyembelihan satu hari
+type RekapHarianPenyembelihanResponse struct {
+	Tanggal                string                     `json:"tanggal"`
+	TotalJadwal            int                        `json:"total_jadwal"`
+	Selesai                int                        `json:"selesai"`
+	Berlangsung            int                        `json:"berlangsung"`
+	BelumMulai             int                        `json:"belum_mulai"`
+	Terlambat              int                        `json:"terlambat"` // mulai setelah rencana_mulai
+	RataKeterlambatanMenit *float64                   `json:"rata_keterlambatan_menit"`
+	MaxKeterlambatanMenit  *float64                   `json:"max_keterlambatan_menit"`
+	DurasiPerJenis         []RekapDurasiJenisResponse `json:"durasi_per_jenis"`
+	Hewan                  []PenyembelihanResponse    `json:"hewan"`
 }
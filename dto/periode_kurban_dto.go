@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
+)
+
+type CreatePeriodeKurbanRequest struct {
+	TahunHijri int  `json:"tahun_hijri" binding:"required,gte=1400,lte=1600"`
+	OffsetHari int  `json:"offset_hari" binding:"gte=-3,lte=3"`
+	Aktif      bool `json:"aktif"`
+}
+
+// UpdatePeriodeKurbanRequest mengubah periode; field kosong tidak diubah
+type UpdatePeriodeKurbanRequest struct {
+	TahunHijri *int  `json:"tahun_hijri" binding:"omitempty,gte=1400,lte=1600"`
+	OffsetHari *int  `json:"offset_hari" binding:"omitempty,gte=-3,lte=3"`
+	Aktif      *bool `json:"aktif"`
+}
+
+type PeriodeKurbanResponse struct {
+	ID         string    `json:"id"`
+	TahunHijri int       `json:"tahun_hijri"`
+	OffsetHari int       `json:"offset_hari"`
+	Aktif      bool      `json:"aktif"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type HariKurbanResponse struct {
+	Tanggal string `json:"tanggal"`
+	Hijri   string `json:"hijri"`
+	Nama    string `json:"nama"`
+}
+
+// JendelaKurbanResponse adalah rentang tanggal penyembelihan 10-13 Dzulhijjah yang berlaku
+type JendelaKurbanResponse struct {
+	PeriodeID  *string              `json:"periode_id"` // kosong jika tahun tersebut belum disimpan sebagai periode
+	TahunHijri int                  `json:"tahun_hijri"`
+	OffsetHari int                  `json:"offset_hari"`
+	Mulai      string               `json:"mulai"`
+	Selesai    string               `json:"selesai"`
+	Hari       []HariKurbanResponse `json:"hari"`
+}
+
+func ToPeriodeKurbanResponse(p *model.PeriodeKurban) PeriodeKurbanResponse {
+	return PeriodeKurbanResponse{
+		ID:         p.ID.String(),
+		TahunHijri: p.TahunHijri,
+		OffsetHari: p.OffsetHari,
+		Aktif:      p.Aktif,
+		CreatedAt:  p.Created_At,
+		UpdatedAt:  p.Updated_At,
+	}
+}
+
+func ToJendelaKurbanResponse(p *model.PeriodeKurban, tersimpan bool) JendelaKurbanResponse {
+	mulai, selesai := p.Jendela()
+	res := JendelaKurbanResponse{
+		TahunHijri: p.TahunHijri,
+		OffsetHari: p.OffsetHari,
+		Mulai:      mulai.Format("2006-01-02"),
+		Selesai:    selesai.Format("2006-01-02"),
+		Hari:       []HariKurbanResponse{},
+	}
+	if tersimpan {
+		id := p.ID.String()
+		res.PeriodeID = &id
+	}
+	for t := mulai; !t.After(selesai); t = t.AddDate(0, 0, 1) {
+		h := hijri.FromGregorian(t, p.OffsetHari)
+		nama := "Idul Adha"
+		if h.Hari > model.HariIdulAdha {
+			nama = "Hari Tasyrik"
+		}
+		res.Hari = append(res.Hari, HariKurbanResponse{Tanggal: t.Format("2006-01-02"), Hijri: h.String(), Nama: nama})
+	}
+	return res
+}
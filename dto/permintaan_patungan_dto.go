@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type CreatePermintaanPatunganRequest struct {
+	PekurbanID  string   `json:"pekurban_id" binding:"required,uuid"`
+	Jenis       string   `json:"jenis" binding:"required"`
+	JumlahPorsi int      `json:"jumlah_porsi" binding:"required,gt=0"`
+	HargaMin    *float64 `json:"harga_min" binding:"omitempty,gte=0"`
+	HargaMax    *float64 `json:"harga_max" binding:"omitempty,gte=0"`
+}
+
+type PermintaanPatunganResponse struct {
+	ID          string     `json:"id"`
+	PekurbanID  string     `json:"pekurban_id"`
+	Pekurban    string     `json:"pekurban"`
+	Jenis       string     `json:"jenis"`
+	JumlahPorsi int        `json:"jumlah_porsi"`
+	HargaMin    *float64   `json:"harga_min,omitempty"`
+	HargaMax    *float64   `json:"harga_max,omitempty"`
+	Status      string     `json:"status"`
+	HewanID     *string    `json:"hewan_id,omitempty"`
+	MatchedAt   *time.Time `json:"matched_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type MatchPatunganResponse struct {
+	TotalTerpenuhi int                          `json:"total_terpenuhi"`
+	Terpenuhi      []PermintaanPatunganResponse `json:"terpenuhi"`
+}
+
+func ToPermintaanPatunganResponse(p *model.PermintaanPatungan) PermintaanPatunganResponse {
+	var hewanID *string
+	if p.HewanID != nil {
+		id := p.HewanID.String()
+		hewanID = &id
+	}
+
+	return PermintaanPatunganResponse{
+		ID:          p.ID.String(),
+		PekurbanID:  p.PekurbanID.String(),
+		Pekurban:    p.PekurbanName,
+		Jenis:       string(p.Jenis),
+		JumlahPorsi: p.JumlahPorsi,
+		HargaMin:    p.HargaMin,
+		HargaMax:    p.HargaMax,
+		Status:      p.Status,
+		HewanID:     hewanID,
+		MatchedAt:   p.MatchedAt,
+		CreatedAt:   p.Created_At,
+	}
+}
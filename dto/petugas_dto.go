@@ -0,0 +1,54 @@
+package dto
+
+import "github.com/wahyujatirestu/sahabat-kurban/model"
+
+type CreatePetugasRequest struct {
+	Nama    string   `json:"nama" binding:"required,max=100"`
+	Phone   *string  `json:"phone" binding:"omitempty,max=20"`
+	UserID  *string  `json:"user_id" binding:"omitempty,uuid"`
+	Peran   []string `json:"peran" binding:"required,min=1,dive,oneof=jagal pencacah pengemas kurir"`
+	Catatan *string  `json:"catatan"`
+}
+
+// UpdatePetugasRequest mengganti data petugas; field kosong tidak diubah. user_id "" melepas tautan akun.
+type UpdatePetugasRequest struct {
+	Nama    string   `json:"nama" binding:"omitempty,max=100"`
+	Phone   *string  `json:"phone" binding:"omitempty,max=20"`
+	UserID  *string  `json:"user_id" binding:"omitempty,uuid|len=0"`
+	Peran   []string `json:"peran" binding:"omitempty,min=1,dive,oneof=jagal pencacah pengemas kurir"`
+	Catatan *string  `json:"catatan"`
+}
+
+type PetugasResponse struct {
+	ID      string   `json:"id"`
+	Nama    string   `json:"nama"`
+	Phone   *string  `json:"phone,omitempty"`
+	UserID  *string  `json:"user_id,omitempty"`
+	Peran   []string `json:"peran"`
+	Catatan *string  `json:"catatan,omitempty"`
+}
+
+// JadwalPetugasResponse adalah jadwal seorang petugas: shift yang belum berakhir dan penyembelihan yang
+// ditugaskan kepadanya sebagai jagal
+type JadwalPetugasResponse struct {
+	Petugas       PetugasResponse         `json:"petugas"`
+	Shift         []ShiftResponse         `json:"shift"`
+	Penyembelihan []PenyembelihanResponse `json:"penyembelihan"`
+}
+
+func ToPetugasResponse(p *model.Petugas) PetugasResponse {
+	var userID *string
+	if p.UserID != nil {
+		id := p.UserID.String()
+		userID = &id
+	}
+
+	return PetugasResponse{
+		ID:      p.ID.String(),
+		Nama:    p.Nama,
+		Phone:   p.Phone,
+		UserID:  userID,
+		Peran:   p.Peran,
+		Catatan: p.Catatan,
+	}
+}
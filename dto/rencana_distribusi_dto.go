@@ -0,0 +1,69 @@
+package dto
+
+// RencanaDistribusiRequest menyusun usulan jumlah paket per penerima. Pekurban mendapat bagian dari porsi
+// hewannya (default 1/3, maksimal 1/3); sisanya dibagi ke penerima lain sebanding bobot kategorinya.
+// Tanpa penerima_ids, semua penerima yang belum menerima distribusi ikut dihitung.
+type RencanaDistribusiRequest struct {
+	TotalPaket              int            `json:"total_paket" binding:"omitempty,gt=0"` // default seluruh sisa paket hewan yang hasilnya sudah dicatat
+	BagianPekurbanPembilang int64          `json:"bagian_pekurban_pembilang" binding:"omitempty,gte=0"`
+	BagianPekurbanPenyebut  int64          `json:"bagian_pekurban_penyebut" binding:"omitempty,gt=0,lte=1000"`
+	Bobot                   map[string]int `json:"bobot" binding:"omitempty,dive,keys,oneof=warga dhuafa panitia pekurban,endkeys,gte=0,lte=100"`
+	PenerimaIDs             []string       `json:"penerima_ids" binding:"omitempty,dive,uuid"`
+	TanggalDistribusi       string         `json:"tanggal_distribusi"` // wajib jika simpan
+	LokasiID                *string        `json:"lokasi_id" binding:"omitempty,uuid"`
+	Simpan                  bool           `json:"simpan"` // false: hanya pratinjau
+}
+
+// AlokasiPenerimaResponse adalah usulan untuk satu penerima; penerima_id, jumlah_paket, dan hewan
+// bisa langsung dikirim ulang (setelah disunting) ke POST /distribusi/bulk
+type AlokasiPenerimaResponse struct {
+	PenerimaID  string                   `json:"penerima_id"`
+	Name        string                   `json:"name"`
+	Status      string                   `json:"status"`
+	Dasar       string                   `json:"dasar"` // pekurban: bagian porsi sendiri, bobot: pembagian per kategori
+	Bobot       int                      `json:"bobot"`
+	JumlahPaket int                      `json:"jumlah_paket"`
+	Hewan       []DistribusiHewanRequest `json:"hewan"`
+}
+
+type AlokasiKategoriResponse struct {
+	Status   string `json:"status"`
+	Bobot    int    `json:"bobot"`
+	Penerima int    `json:"penerima"`
+	Paket    int    `json:"paket"`
+}
+
+type AlokasiDilewatiResponse struct {
+	PenerimaID string `json:"penerima_id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Alasan     string `json:"alasan"`
+}
+
+type RencanaDistribusiResponse struct {
+	Disimpan        bool                      `json:"disimpan"`
+	StokPaket       int                       `json:"stok_paket"`
+	TotalPaket      int                       `json:"total_paket"`
+	BagianPekurban  string                    `json:"bagian_pekurban"`
+	PaketPekurban   int                       `json:"paket_pekurban"`
+	PaketUmum       int                       `json:"paket_umum"`
+	SisaPaket       int                       `json:"sisa_paket"` // tidak teralokasi karena tidak ada penerima
+	Kategori        []AlokasiKategoriResponse `json:"kategori"`
+	Alokasi         []AlokasiPenerimaResponse `json:"alokasi"`
+	Dilewati        []AlokasiDilewatiResponse `json:"dilewati"`
+	HewanTanpaHasil []string                  `json:"hewan_tanpa_hasil"` // hewan disembelih yang jumlah paketnya belum dicatat
+	Distribusi      []DistribusiResponse      `json:"distribusi,omitempty"`
+}
+
+type BulkDistribusiItem struct {
+	PenerimaID  string                   `json:"penerima_id" binding:"required,uuid"`
+	JumlahPaket int                      `json:"jumlah_paket" binding:"required,min=1"`
+	Hewan       []DistribusiHewanRequest `json:"hewan" binding:"required,min=1,dive"`
+}
+
+// BulkDistribusiRequest menyimpan banyak distribusi sekaligus dalam satu transaksi; satu baris gagal membatalkan semuanya
+type BulkDistribusiRequest struct {
+	TanggalDistribusi string               `json:"tanggal_distribusi" binding:"required"`
+	LokasiID          *string              `json:"lokasi_id" binding:"omitempty,uuid"`
+	Distribusi        []BulkDistribusiItem `json:"distribusi" binding:"required,min=1,dive"`
+}
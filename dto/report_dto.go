@@ -16,6 +16,8 @@ type LaporanResponse struct {
 	RekapHewan      []HewanDTO      `json:"rekap_hewan"`
 	RekapDistribusi []DistribusiDTO `json:"distribusi"`
 	RekapPembayaran []PembayaranDTO `json:"rekap_pembayaran"`
+	RekapRendemen   []RendemenDTO   `json:"rekap_rendemen"`
+	RekapLokasi     []LokasiRekapDTO `json:"rekap_lokasi"`
 }
 
 type RingkasanDTO struct {
@@ -44,6 +46,7 @@ type PekurbanHewanDTO struct {
 	Berat   float64 `json:"berat"`
 	Harga   float64 `json:"harga"`
 	Porsi   float64 `json:"porsi"`
+	AtasNama []AtasNamaResponse `json:"atas_nama"`
 }
 
 type HewanDTO struct {
@@ -64,3 +67,30 @@ type PembayaranDTO struct {
 	Total  int     `json:"total"`
 	Jumlah float64 `json:"jumlah"`
 }
+
+type RendemenDTO struct {
+	Jenis                   string   `json:"jenis"`
+	TotalHewan              int      `json:"total_hewan"`
+	BeratTerdaftar          float64  `json:"berat_terdaftar"`
+	BeratHidup              float64  `json:"berat_hidup"`
+	BeratKarkas             float64  `json:"berat_karkas"`
+	BeratDaging             float64  `json:"berat_daging"`
+	BeratTulang             float64  `json:"berat_tulang"`
+	BeratJeroan             float64  `json:"berat_jeroan"`
+	JumlahPaket             int      `json:"jumlah_paket"`
+	RataDagingPerHewan      float64  `json:"rata_daging_per_hewan"`
+	RendemenKarkas          *float64 `json:"rendemen_karkas"`
+	KarkasTerhadapTerdaftar *float64 `json:"karkas_terhadap_berat_terdaftar"`
+	DagingTerhadapTerdaftar *float64 `json:"daging_terhadap_berat_terdaftar"`
+}
+
+type LokasiRekapDTO struct {
+	Nama             string  `json:"nama"`
+	Tipe             string  `json:"tipe"`
+	HewanDijadwalkan int     `json:"hewan_dijadwalkan"`
+	HewanDisembelih  int     `json:"hewan_disembelih"`
+	BeratDaging      float64 `json:"berat_daging"`
+	PaketDihasilkan  int     `json:"paket_dihasilkan"`
+	TotalDistribusi  int     `json:"total_distribusi"`
+	PaketDistribusi  int     `json:"paket_distribusi"`
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// CreateShiftRequest membuat slot kerja; jam_selesai lebih awal dari jam_mulai berarti shift melewati tengah malam
+type CreateShiftRequest struct {
+	LokasiID   string  `json:"lokasi_id" binding:"required,uuid"`
+	Tanggal    string  `json:"tanggal" binding:"required"`
+	JamMulai   string  `json:"jam_mulai" binding:"required"`
+	JamSelesai string  `json:"jam_selesai" binding:"required"`
+	Peran      string  `json:"peran" binding:"required,oneof=jagal pencacah pengemas kurir"`
+	Kebutuhan  int     `json:"kebutuhan" binding:"omitempty,gt=0,lte=100"`
+	Catatan    *string `json:"catatan"`
+}
+
+// UpdateShiftRequest mengubah shift; field kosong tidak diubah
+type UpdateShiftRequest struct {
+	LokasiID   string  `json:"lokasi_id" binding:"omitempty,uuid"`
+	Tanggal    string  `json:"tanggal"`
+	JamMulai   string  `json:"jam_mulai"`
+	JamSelesai string  `json:"jam_selesai"`
+	Peran      string  `json:"peran" binding:"omitempty,oneof=jagal pencacah pengemas kurir"`
+	Kebutuhan  int     `json:"kebutuhan" binding:"omitempty,gt=0,lte=100"`
+	Catatan    *string `json:"catatan"`
+}
+
+type AssignShiftRequest struct {
+	PetugasID string `json:"petugas_id" binding:"required,uuid"`
+}
+
+type ShiftPetugasResponse struct {
+	PetugasID string `json:"petugas_id"`
+	Nama      string `json:"nama"`
+}
+
+type ShiftResponse struct {
+	ID        string                 `json:"id"`
+	LokasiID  string                 `json:"lokasi_id"`
+	Lokasi    string                 `json:"lokasi"`
+	Peran     string                 `json:"peran"`
+	Mulai     time.Time              `json:"mulai"`
+	Selesai   time.Time              `json:"selesai"`
+	Kebutuhan int                    `json:"kebutuhan"`
+	Terisi    int                    `json:"terisi"`
+	Catatan   *string                `json:"catatan,omitempty"`
+	Petugas   []ShiftPetugasResponse `json:"petugas"`
+}
+
+func ToShiftResponse(s *model.Shift) ShiftResponse {
+	res := ShiftResponse{
+		ID:        s.ID.String(),
+		LokasiID:  s.LokasiID.String(),
+		Lokasi:    s.Lokasi,
+		Peran:     s.Peran,
+		Mulai:     s.Mulai,
+		Selesai:   s.Selesai,
+		Kebutuhan: s.Kebutuhan,
+		Terisi:    len(s.Petugas),
+		Catatan:   s.Catatan,
+		Petugas:   []ShiftPetugasResponse{},
+	}
+	for _, p := range s.Petugas {
+		res.Petugas = append(res.Petugas, ShiftPetugasResponse{PetugasID: p.PetugasID.String(), Nama: p.PetugasNama})
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type TransferPorsiRequest struct {
+	KePekurbanID	string	`json:"ke_pekurban_id" binding:"required,uuid"`
+	Catatan			*string	`json:"catatan"`
+}
+
+type TransferPorsiResponse struct {
+	ID				string		`json:"id"`
+	HewanID			string		`json:"hewan_id"`
+	DariPekurbanID	string		`json:"dari_pekurban_id"`
+	DariPekurban	string		`json:"dari_pekurban,omitempty"`
+	KePekurbanID	string		`json:"ke_pekurban_id"`
+	KePekurban		string		`json:"ke_pekurban,omitempty"`
+	Porsi			float64		`json:"porsi"`
+	PorsiPecahan	string		`json:"porsi_pecahan"`
+	JumlahDibayar	float64		`json:"jumlah_dibayar"`
+	DisetujuiOleh	string		`json:"disetujui_oleh"`
+	Penyetuju		string		`json:"penyetuju,omitempty"`
+	Catatan			*string		`json:"catatan,omitempty"`
+	CreatedAt		time.Time	`json:"created_at"`
+}
+
+func ToTransferPorsiResponse(t *model.TransferPorsi) TransferPorsiResponse {
+	return TransferPorsiResponse{
+		ID:             t.ID.String(),
+		HewanID:        t.HewanID.String(),
+		DariPekurbanID: t.DariPekurbanID.String(),
+		DariPekurban:   t.DariPekurban,
+		KePekurbanID:   t.KePekurbanID.String(),
+		KePekurban:     t.KePekurban,
+		Porsi:          t.Porsi.Float64(),
+		PorsiPecahan:   t.Porsi.String(),
+		JumlahDibayar:  t.JumlahDibayar,
+		DisetujuiOleh:  t.DisetujuiOleh.String(),
+		Penyetuju:      t.Penyetuju,
+		Catatan:        t.Catatan,
+		CreatedAt:      t.Created_At,
+	}
+}
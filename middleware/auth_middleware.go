@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/utils"
 	"github.com/wahyujatirestu/sahabat-kurban/utils/service"
 )
 
@@ -55,6 +56,7 @@ func (a *auhtMiddleware) RequireToken(roles ...string) gin.HandlerFunc {
 			ID: userId,
 			Role: tokenClaim.Role,
 		})
+		ctx.Request = ctx.Request.WithContext(utils.WithActor(ctx.Request.Context(), userId))
 
 		validRole := false
 		if len(roles) == 0 {
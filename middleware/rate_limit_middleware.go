@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitMiddleware interface {
+	Limit() gin.HandlerFunc
+}
+
+type rateLimitMiddleware struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateLimitWindow
+	nextSweep time.Time
+}
+
+type rateLimitWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewRateLimitMiddleware membatasi jumlah request per IP dalam satu window (in-memory, per instance)
+func NewRateLimitMiddleware(limit int, window time.Duration) RateLimitMiddleware {
+	return &rateLimitMiddleware{
+		limit:   limit,
+		window:  window,
+		clients: map[string]*rateLimitWindow{},
+	}
+}
+
+func (m *rateLimitMiddleware) Limit() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		now := time.Now()
+		ip := ctx.ClientIP()
+
+		m.mu.Lock()
+		if now.After(m.nextSweep) {
+			for key, w := range m.clients {
+				if now.After(w.resetAt) {
+					delete(m.clients, key)
+				}
+			}
+			m.nextSweep = now.Add(m.window)
+		}
+
+		w, ok := m.clients[ip]
+		if !ok || now.After(w.resetAt) {
+			w = &rateLimitWindow{resetAt: now.Add(m.window)}
+			m.clients[ip] = w
+		}
+		w.count++
+		count, resetAt := w.count, w.resetAt
+		m.mu.Unlock()
+
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(m.limit))
+		if count > m.limit {
+			ctx.Header("Retry-After", strconv.Itoa(int(resetAt.Sub(now).Seconds())+1))
+			ctx.AbortWithStatusJSON(429, gin.H{
+				"status": 429,
+				"error": "Too many requests, please try again later"})
+			return
+		}
+
+		ctx.Header("X-RateLimit-Remaining", strconv.Itoa(m.limit-count))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AntreanPenyembelihan adalah satu hewan pada antrean penyembelihan harian
+type AntreanPenyembelihan struct {
+	HewanID       uuid.UUID
+	Jenis         string
+	Status        string
+	LokasiID      uuid.UUID
+	Lokasi        string
+	UrutanRencana int
+	UrutanAktual  *int
+	MulaiAt       *time.Time // check-in mulai, terisi saat hewan sedang disembelih
+	DisembelihAt  *time.Time // check-in selesai, atau waktu status hewan berubah menjadi disembelih
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AtasNama adalah nama yang diniatkan pada satu porsi kurban (orang tua, almarhum, dsb)
+type AtasNama struct {
+	ID			uuid.UUID	`db:"id"`
+	PekurbanID	uuid.UUID	`db:"pekurban_id"`
+	HewanID		uuid.UUID	`db:"hewan_id"`
+	Nama		string		`db:"nama"`
+	Hubungan	*string		`db:"hubungan"`
+	MasihHidup	bool		`db:"masih_hidup"`
+	Created_At	time.Time	`db:"created_at"`
+	Updated_At	time.Time	`db:"updated_at"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// status setiap alamat (stop) dalam batch antar
+const (
+	StopMenunggu     = "menunggu"
+	StopTerkirim     = "terkirim"
+	StopTidakDiRumah = "tidak_di_rumah" // boleh dicoba lagi
+	StopDitolak      = "ditolak"
+)
+
+// BatchAntar adalah satu rombongan pengantaran daging dari rumah ke rumah untuk penerima lansia/difabel,
+// dikelompokkan per wilayah (RT/RW atau lokasi) dan dibawa oleh satu kurir
+type BatchAntar struct {
+	ID          uuid.UUID  `db:"id"`
+	Nama        string     `db:"nama"`
+	Tanggal     time.Time  `db:"tanggal"`
+	LokasiID    *uuid.UUID `db:"lokasi_id"` // titik berangkat / wilayah
+	LokasiNama  *string    // hasil join
+	RT          *string    `db:"rt"`
+	RW          *string    `db:"rw"`
+	KurirID     *uuid.UUID `db:"kurir_id"` // petugas berperan kurir
+	KurirNama   *string    // hasil join
+	KurirPhone  *string    // hasil join
+	Catatan     *string    `db:"catatan"`
+	JumlahStop  int        // hasil agregat
+	StopSelesai int        // hasil agregat: terkirim atau ditolak
+	Stop        []BatchAntarStop
+	Created_At  time.Time `db:"created_at"`
+	Updated_At  time.Time `db:"updated_at"`
+}
+
+// BatchAntarStop adalah satu alamat penerima dalam batch, berurutan sesuai rute kurir
+type BatchAntarStop struct {
+	ID             uuid.UUID  `db:"id"`
+	BatchID        uuid.UUID  `db:"batch_id"`
+	PenerimaID     uuid.UUID  `db:"penerima_id"`
+	PenerimaNama   string     // hasil join
+	PenerimaAlamat *string    // hasil join
+	PenerimaPhone  *string    // hasil join
+	PenerimaRT     *string    // hasil join
+	PenerimaRW     *string    // hasil join
+	Urutan         int        `db:"urutan"`
+	JumlahPaket    int        `db:"jumlah_paket"`
+	Status         string     `db:"status"`
+	Catatan        *string    `db:"catatan"`
+	DistribusiID   *uuid.UUID `db:"distribusi_id"` // terisi saat terkirim
+	DiperbaruiOleh *uuid.UUID `db:"diperbarui_oleh"`
+	DiperbaruiAt   *time.Time `db:"diperbarui_at"`
+}
+
+// Terbuka bernilai true selama stop masih bisa diperbarui kurir
+func (s *BatchAntarStop) Terbuka() bool {
+	return s.Status == StopMenunggu || s.Status == StopTidakDiRumah
+}
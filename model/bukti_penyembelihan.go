@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BuktiFoto  = "foto"
+	BuktiVideo = "video"
+)
+
+// BuktiPenyembelihan adalah foto atau video penyembelihan yang disimpan di penyimpanan file lokal
+type BuktiPenyembelihan struct {
+	ID              uuid.UUID  `db:"id"`
+	PenyembelihanID uuid.UUID  `db:"penyembelihan_id"`
+	Tipe            string     `db:"tipe"`
+	Path            string     `db:"path"` // path relatif terhadap direktori upload
+	NamaAsli        string     `db:"nama_asli"`
+	ContentType     string     `db:"content_type"`
+	Ukuran          int64      `db:"ukuran"`
+	UploadedBy      *uuid.UUID `db:"uploaded_by"`
+	Created_At      time.Time  `db:"created_at"`
+}
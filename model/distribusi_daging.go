@@ -4,14 +4,78 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
 )
 
 type DistribusiDaging struct {
 	ID                	uuid.UUID		`db:"id"`
 	PenerimaID        	uuid.UUID		`db:"penerima_id"`
 	PenerimaName      	string			
+	LokasiID			*uuid.UUID		`db:"lokasi_id"`
+	LokasiNama			*string
 	JumlahPaket       	int				`db:"jumlah_paket"`
 	TanggalDistribusi 	time.Time		`db:"tanggal_distribusi"`
+	DicatatOleh			*uuid.UUID		`db:"dicatat_oleh"` // user yang mencatat atau memindai kupon
 	Created_At         	time.Time		`db:"created_at"`
 	Updated_At         	time.Time		`db:"updated_at"`
+	Hewan				[]DistribusiHewan	// asal daging per hewan
+}
+
+// DistribusiHewan mencatat berapa paket dari satu distribusi yang berasal dari hewan tertentu
+type DistribusiHewan struct {
+	DistribusiID	uuid.UUID	`db:"distribusi_id"`
+	HewanID			uuid.UUID	`db:"hewan_id"`
+	Jenis			JenisHewan	// hasil join
+	JumlahPaket		int			`db:"jumlah_paket"`
+}
+
+// RekapDistribusiHewan membandingkan paket hasil penyembelihan satu hewan dengan paket yang sudah dibagikan
+type RekapDistribusiHewan struct {
+	HewanID					uuid.UUID
+	Jenis					JenisHewan
+	Status					string
+	TanggalPenyembelihan	*time.Time
+	PaketDihasilkan			*int	// nil jika hasil penyembelihan belum dicatat
+	PaketDidistribusikan	int
+	TotalDistribusi			int
+}
+
+// TahunDistribusi adalah periode sebuah distribusi: tahun Hijriah tanggal distribusinya. Dzulhijjah bulan
+// terakhir tahun Hijriah, sehingga semua distribusi satu musim kurban masuk ke tahun yang sama.
+// offset adalah offset hari periode kurban aktif.
+func TahunDistribusi(t time.Time, offset int) int {
+	return hijri.FromGregorian(t, offset).Tahun
+}
+
+// RentangTahunHijri mengembalikan tanggal Masehi 1 Muharram dan 29/30 Dzulhijjah tahun Hijriah tersebut
+func RentangTahunHijri(tahun, offset int) (mulai, selesai time.Time) {
+	mulai = hijri.ToGregorian(hijri.Tanggal{Tahun: tahun, Bulan: 1, Hari: 1}, offset, time.Local)
+	selesai = hijri.ToGregorian(hijri.Tanggal{Tahun: tahun + 1, Bulan: 1, Hari: 1}, offset, time.Local).AddDate(0, 0, -1)
+	return mulai, selesai
+}
+
+// AlokasiDistribusi adalah rencana jumlah paket seorang penerima dalam satu periode; boleh dibagikan beberapa gelombang
+type AlokasiDistribusi struct {
+	PenerimaID	uuid.UUID	`db:"penerima_id"`
+	TahunHijri	int			`db:"tahun_hijri"`
+	JumlahPaket	int			`db:"jumlah_paket"`
+	Created_At	time.Time	`db:"created_at"`
+	Updated_At	time.Time	`db:"updated_at"`
+}
+
+// RekapDistribusiPenerima membandingkan alokasi seorang penerima dengan paket yang sudah diterimanya dalam satu periode
+type RekapDistribusiPenerima struct {
+	Penerima		PenerimaDaging
+	TahunHijri		int
+	AlokasiPaket	*int	// nil jika penerima belum dialokasikan
+	PaketDiterima	int
+	TotalDistribusi	int
+}
+
+// BelumTerdistribusi: penerima beralokasi belum menerima seluruh alokasinya, penerima tanpa alokasi belum menerima apa pun
+func (r *RekapDistribusiPenerima) BelumTerdistribusi() bool {
+	if r.AlokasiPaket != nil {
+		return r.PaketDiterima < *r.AlokasiPaket
+	}
+	return r.TotalDistribusi == 0
 }
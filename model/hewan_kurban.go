@@ -6,21 +6,59 @@ import (
 	"github.com/google/uuid"
 )
 
+// nama jenis hewan, merujuk ke tabel master jenis_hewan
 type JenisHewan string
 
+// status siklus hidup hewan kurban
 const (
-	Sapi    JenisHewan = "sapi"
-	Kambing JenisHewan = "kambing"
-	Domba   JenisHewan = "domba"
+	HewanTerdaftar       = "terdaftar"
+	HewanTerisi          = "terisi"
+	HewanLunas           = "lunas"
+	HewanDijadwalkan     = "dijadwalkan"
+	HewanDisembelih      = "disembelih"
+	HewanDicacah         = "dicacah"
+	HewanDidistribusikan = "didistribusikan"
 )
 
 type HewanKurban struct {
 	ID                 	uuid.UUID   `db:"id"`
 	Jenis              	JenisHewan  `db:"jenis"`
 	Berat              	float64     `db:"berat"`
+	UmurBulan          	*int        `db:"umur_bulan"`
 	Harga              	float64     `db:"harga"`
 	IsPrivate          	bool        `db:"is_private"`
+	FotoURL            	*string     `db:"foto_url"`
 	TanggalPendaftaran 	time.Time   `db:"tanggal_pendaftaran"`
+	Status				string		`db:"status"`
 	Created_At          time.Time   `db:"created_at"`
 	Updated_At          time.Time   `db:"updated_at"`
 }
+
+// HewanSiapJadwal adalah hewan berstatus lunas beserta data yang dipakai untuk prioritas penjadwalan
+type HewanSiapJadwal struct {
+	ID                 uuid.UUID
+	Jenis              JenisHewan
+	IsPrivate          bool
+	TanggalPendaftaran time.Time
+	LunasAt            *time.Time // waktu terakhir hewan berstatus lunas
+	InginHadir         bool       // ada pekurban yang ingin menyaksikan penyembelihan
+}
+
+type PublicHewanFilter struct {
+	Jenis    string
+	MinHarga *float64 // harga per porsi
+	MaxHarga *float64 // harga per porsi
+}
+
+// Hewan publik beserta slot porsi yang sudah terisi, tanpa identitas pekurban
+type PublicHewan struct {
+	ID                   uuid.UUID
+	Jenis                JenisHewan
+	Berat                float64
+	Harga                float64
+	KapasitasPorsi       int
+	PorsiTerisi          int
+	FotoURL              *string
+	TanggalPenyembelihan *time.Time
+	Lokasi               *string
+}
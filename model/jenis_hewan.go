@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// JenisHewanMaster menentukan kapasitas porsi dan syarat untuk satu jenis hewan kurban
+type JenisHewanMaster struct {
+	Nama			JenisHewan	`db:"nama"`
+	MaxPorsi		int			`db:"max_porsi"`
+	MinUmurBulan	int			`db:"min_umur_bulan"`
+	HargaDefault	*float64	`db:"harga_default"`
+	Created_At		time.Time	`db:"created_at"`
+	Updated_At		time.Time	`db:"updated_at"`
+}
+
+// Porsi mengubah jumlah orang menjadi pecahan porsi hewan (jumlah_orang/max_porsi)
+func (j JenisHewanMaster) Porsi(jumlahOrang int) (Pecahan, error) {
+	return NewPecahan(int64(jumlahOrang), int64(j.MaxPorsi))
+}
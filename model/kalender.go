@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JadwalPenyembelihanKalender adalah satu penyembelihan terjadwal untuk ekspor kalender
+type JadwalPenyembelihanKalender struct {
+	ID            uuid.UUID
+	HewanID       uuid.UUID
+	Jenis         JenisHewan
+	StatusHewan   string
+	Tanggal       time.Time
+	RencanaMulai  *time.Time
+	MulaiAt       *time.Time
+	SelesaiAt     *time.Time
+	UrutanRencana int
+	Jagal         *string
+	Lokasi        string
+	Alamat        *string
+	Latitude      *float64
+	Longitude     *float64
+	Updated_At    time.Time
+}
+
+// JadwalDistribusiKalender adalah satu jadwal pengambilan daging untuk ekspor kalender
+type JadwalDistribusiKalender struct {
+	ID           uuid.UUID
+	PenerimaNama string
+	JumlahPaket  int
+	Tanggal      time.Time
+	Lokasi       *string
+	Alamat       *string
+	Latitude     *float64
+	Longitude    *float64
+	Updated_At   time.Time
+}
+
+// KalenderToken memberi akses feed kalender lewat URL rahasia; milik satu user panitia/admin atau satu pekurban
+type KalenderToken struct {
+	TokenHash  string
+	UserID     *uuid.UUID
+	PekurbanID *uuid.UUID
+	Created_At time.Time
+}
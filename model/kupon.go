@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KuponDistribusi adalah kupon pengambilan daging milik satu penerima; ditukar sekali lewat pindai QR.
+// Setelah ditukar, penerima boleh dibuatkan kupon baru untuk gelombang berikutnya.
+type KuponDistribusi struct {
+	ID                uuid.UUID  `db:"id"`
+	PenerimaID        uuid.UUID  `db:"penerima_id"`
+	PenerimaNama      string     // hasil join
+	PenerimaAlamat    *string    // hasil join
+	PenerimaStatus    string     // hasil join
+	JumlahPaket       int        `db:"jumlah_paket"`
+	TanggalDistribusi *time.Time `db:"tanggal_distribusi"` // nil: berlaku di hari penukaran
+	LokasiID          *uuid.UUID `db:"lokasi_id"`
+	LokasiNama        *string    // hasil join
+	DistribusiID      *uuid.UUID `db:"distribusi_id"` // distribusi yang dibuat saat kupon ditukar
+	DitukarAt         *time.Time `db:"ditukar_at"`
+	DitukarOleh       *uuid.UUID `db:"ditukar_oleh"`
+	Created_At        time.Time  `db:"created_at"`
+	Updated_At        time.Time  `db:"updated_at"`
+}
+
+// SudahDitukar menandakan kupon tidak bisa dipakai lagi
+func (k *KuponDistribusi) SudahDitukar() bool {
+	return k.DitukarAt != nil
+}
@@ -48,6 +48,32 @@ type PembayaranAggregate struct {
 	Jumlah float64
 }
 
+type RendemenAggregate struct {
+	Jenis                   string
+	TotalHewan              int     // hewan yang hasil penyembelihannya sudah dicatat
+	BeratTerdaftar          float64 // total hewan_kurban.berat
+	BeratHidup              float64
+	BeratKarkas             float64
+	BeratDaging             float64
+	BeratTulang             float64
+	BeratJeroan             float64
+	JumlahPaket             int
+	RendemenKarkas          *float64 // rata-rata karkas / berat hidup (%)
+	KarkasTerhadapTerdaftar *float64 // total karkas / total berat terdaftar (%)
+	DagingTerhadapTerdaftar *float64 // total daging / total berat terdaftar (%)
+}
+
+type LokasiAggregate struct {
+	Nama             string
+	Tipe             string
+	HewanDijadwalkan int
+	HewanDisembelih  int
+	BeratDaging      float64
+	PaketDihasilkan  int // jumlah paket dari hasil penyembelihan
+	TotalDistribusi  int
+	PaketDistribusi  int
+}
+
 // Ringkasan angka-angka besar
 type ReportSummary struct {
 	TotalPekurban           int
@@ -66,6 +92,8 @@ type ConsolidatedReport struct {
 	RekapHewan      []HewanAggregate
 	RekapDistribusi []DistribusiAggregate
 	RekapPembayaran []PembayaranAggregate
+	RekapRendemen   []RendemenAggregate
+	RekapLokasi     []LokasiAggregate
 }
 
 type PekurbanDetailView struct {
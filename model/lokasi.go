@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tipe lokasi: tempat penyembelihan, titik distribusi, atau keduanya
+const (
+	LokasiPenyembelihan = "penyembelihan"
+	LokasiDistribusi    = "distribusi"
+	LokasiKeduanya      = "keduanya"
+)
+
+type Lokasi struct {
+	ID          uuid.UUID `db:"id"`
+	Nama        string    `db:"nama"`
+	Alamat      *string   `db:"alamat"`
+	Latitude    *float64  `db:"latitude"`
+	Longitude   *float64  `db:"longitude"`
+	Kapasitas   int       `db:"kapasitas"` // titik pemotongan paralel atau antrean distribusi
+	KontakNama  *string   `db:"kontak_nama"`
+	KontakPhone *string   `db:"kontak_phone"`
+	Tipe        string    `db:"tipe"`
+	Created_At  time.Time `db:"created_at"`
+	Updated_At  time.Time `db:"updated_at"`
+}
+
+// Melayani bernilai true jika lokasi bisa dipakai untuk kegiatan bertipe tersebut
+func (l *Lokasi) Melayani(tipe string) bool {
+	return l.Tipe == LokasiKeduanya || l.Tipe == tipe
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Pecahan menyimpan porsi kepemilikan hewan secara eksak sebagai pembilang/penyebut,
+// sehingga pembagian tidak rata (mis. 3.5/7 = 1/2) tidak kehilangan presisi
+type Pecahan struct {
+	Pembilang int64 `db:"porsi_pembilang"`
+	Penyebut  int64 `db:"porsi_penyebut"`
+}
+
+var ErrInvalidPecahan = errors.New("Porsi must be a fraction greater than 0 and at most 1")
+
+// PecahanUtuh adalah satu hewan penuh (1/1)
+var PecahanUtuh = Pecahan{Pembilang: 1, Penyebut: 1}
+
+// NewPecahan membuat pecahan yang sudah disederhanakan; porsi harus 0 < p <= 1
+func NewPecahan(pembilang, penyebut int64) (Pecahan, error) {
+	if pembilang <= 0 || penyebut <= 0 || pembilang > penyebut {
+		return Pecahan{}, ErrInvalidPecahan
+	}
+	r := big.NewRat(pembilang, penyebut)
+	return Pecahan{Pembilang: r.Num().Int64(), Penyebut: r.Denom().Int64()}, nil
+}
+
+func (p Pecahan) Rat() *big.Rat {
+	if p.Penyebut == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(p.Pembilang, p.Penyebut)
+}
+
+// Float64 hanya untuk tampilan; perhitungan memakai Rat
+func (p Pecahan) Float64() float64 {
+	f, _ := p.Rat().Float64()
+	return f
+}
+
+func (p Pecahan) String() string {
+	return fmt.Sprintf("%d/%d", p.Pembilang, p.Penyebut)
+}
+
+func (p Pecahan) IsUtuh() bool {
+	return p.Pembilang > 0 && p.Pembilang == p.Penyebut
+}
+
+// JumlahOrang menghitung berapa orang yang tercakup penuh oleh porsi ini pada hewan berkapasitas tertentu
+func (p Pecahan) JumlahOrang(kapasitas int) int {
+	if p.Penyebut == 0 {
+		return 0
+	}
+	return int(p.Pembilang * int64(kapasitas) / p.Penyebut)
+}
+
+// TagihanSen menghitung tagihan porsi dalam sen (dibulatkan setengah ke atas) dari harga hewan
+func (p Pecahan) TagihanSen(harga float64) int64 {
+	h, ok := new(big.Rat).SetString(strconv.FormatFloat(harga, 'f', -1, 64))
+	if !ok || p.Penyebut == 0 {
+		return 0
+	}
+	sen := h.Mul(h, p.Rat())
+	sen.Mul(sen, big.NewRat(100, 1))
+
+	num := new(big.Int).Mul(sen.Num(), big.NewInt(2))
+	num.Add(num, sen.Denom())
+	den := new(big.Int).Mul(sen.Denom(), big.NewInt(2))
+	return new(big.Int).Quo(num, den).Int64()
+}
+
+// TotalPecahan menjumlahkan porsi secara eksak
+func TotalPecahan(list ...Pecahan) *big.Rat {
+	total := new(big.Rat)
+	for _, p := range list {
+		total.Add(total, p.Rat())
+	}
+	return total
+}
+
+// MelebihiSatuHewan bernilai true jika total porsi lebih dari satu hewan penuh
+func MelebihiSatuHewan(total *big.Rat) bool {
+	return total.Cmp(big.NewRat(1, 1)) > 0
+}
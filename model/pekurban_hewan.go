@@ -1,11 +1,22 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PorsiDitahan       = "ditahan"
+	PorsiTerkonfirmasi = "terkonfirmasi"
+)
 
 type PekurbanHewan struct {
 	PekurbanID	uuid.UUID	`db:"pekurban_id"`
 	HewanID 	uuid.UUID	`db:"hewan_id"`
-	Porsi		float64		`db:"porsi"`
+	Porsi		Pecahan
+	Status		string		`db:"status"`
+	ExpiresAt	*time.Time	`db:"expires_at"`
 }
 
 type PekurbanHewanJoin struct {
@@ -13,5 +24,8 @@ type PekurbanHewanJoin struct {
 	Pekurban   string
 	HewanID    string
 	Hewan      string
-	Porsi      float64
+	Kapasitas  int // max porsi jenis hewan
+	Porsi      Pecahan
+	Status     string
+	ExpiresAt  *time.Time
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PembatalanMenunggu  = "menunggu"
+	PembatalanDisetujui = "disetujui"
+	PembatalanDitolak   = "ditolak"
+)
+
+// kebijakan refund yang berlaku untuk sebuah pembatalan
+const (
+	RefundPenuh    = "penuh"
+	RefundSebagian = "sebagian"
+	RefundTidakAda = "tidak_ada"
+)
+
+type PembatalanPatungan struct {
+	ID					uuid.UUID	`db:"id"`
+	PekurbanID			uuid.UUID	`db:"pekurban_id"`
+	HewanID				uuid.UUID	`db:"hewan_id"`
+	Porsi				Pecahan
+	Alasan				string		`db:"alasan"`
+	Status				string		`db:"status"`
+	Kebijakan			string		`db:"kebijakan"`
+	PersentaseRefund	int			`db:"persentase_refund"`
+	JumlahDibayar		float64		`db:"jumlah_dibayar"`
+	JumlahRefund		float64		`db:"jumlah_refund"`
+	DiprosesOleh		*uuid.UUID	`db:"diproses_oleh"`
+	CatatanAdmin		*string		`db:"catatan_admin"`
+	DiprosesAt			*time.Time	`db:"diproses_at"`
+	Created_At			time.Time	`db:"created_at"`
+	Updated_At			time.Time	`db:"updated_at"`
+
+	// hasil join
+	Pekurban			string
+	Hewan				string
+}
@@ -20,7 +20,29 @@ type PembayaranKurban struct {
 	TransactionTime   	*time.Time	`db:"transaction_time"`
 	TanggalPembayaran 	time.Time	`db:"tanggal_pembayaran"`
 	Jumlah            	float64		`db:"jumlah"`
+	AsalPembayaranID	*uuid.UUID	`db:"asal_pembayaran_id"` // terisi pada pecahan pembayaran hasil transfer porsi
+	PerluRefund			bool		`db:"perlu_refund"` // settle tetapi ada porsi yang tidak bisa dikonfirmasi
 	Created_At         	time.Time	`db:"created_at"`
 	Updated_At         	time.Time	`db:"updated_at"`
 }
 
+// status porsi yang dibayar oleh satu pembayaran; dibatalkan untuk porsi yang dibatalkan sebelum pembayarannya
+// settle, ditutup untuk porsi yang sudah dibayar lalu dibatalkan sehingga dananya diselesaikan lewat pembatalan
+const (
+	PembayaranPorsiMenunggu      = "menunggu"
+	PembayaranPorsiTerkonfirmasi = "terkonfirmasi"
+	PembayaranPorsiPerluRefund   = "perlu_refund"
+	PembayaranPorsiDibatalkan    = "dibatalkan"
+	PembayaranPorsiDitutup       = "ditutup"
+)
+
+// PembayaranPorsi mencatat porsi yang ditagihkan pada satu pembayaran, sehingga saat settle hanya porsi
+// tersebut yang dikonfirmasi. PekurbanID adalah pemilik porsi saat ini dan ikut berpindah saat transfer.
+type PembayaranPorsi struct {
+	PembayaranID	uuid.UUID	`db:"pembayaran_id"`
+	HewanID			uuid.UUID	`db:"hewan_id"`
+	PekurbanID		uuid.UUID	`db:"pekurban_id"`
+	Porsi			Pecahan
+	Tagihan			float64		`db:"tagihan"`
+	Status			string		`db:"status"`
+}
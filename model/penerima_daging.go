@@ -11,6 +11,9 @@ type PenerimaDaging struct {
 	Name      		string			`db:"nama"`
 	Alamat    		*string			`db:"alamat"`
 	Phone     		*string			`db:"phone"`
+	RT				*string			`db:"rt"`
+	RW				*string			`db:"rw"`
+	AntarRumah		bool			`db:"antar_rumah"` // lansia/difabel, daging diantar ke rumah
 	Status    		string			`db:"status"`
 	PekurbanID 		*uuid.UUID		`db:"pekurban_id"`
 	Created_At 		time.Time		`db:"created_at"`
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,9 +12,50 @@ type Penyembelihan struct {
 	HewanID				uuid.UUID		`db:"hewan_id"`
 	JenisHewan			JenisHewan		`db:"jenis_hewan"`
 	TglPenyembelihan 	time.Time		`db:"tanggal_penyembelihan"`
-	Lokasi				string			`db:"lokasi"`
+	LokasiID			uuid.UUID		`db:"lokasi_id"`
+	Lokasi				string			// nama lokasi, hasil join
 	UrutanRencana		int				`db:"urutan_rencana"`
 	UrutanAktual		*int 			`db:"Urutan_aktual"`
+	RencanaMulai		*time.Time		`db:"rencana_mulai"`
+	MulaiAt				*time.Time		`db:"mulai_at"`
+	SelesaiAt			*time.Time		`db:"selesai_at"`
+	JagalID				*uuid.UUID		`db:"jagal_id"` // petugas jagal yang ditugaskan
+	Jagal				*string			`db:"jagal"`
+	Saksi				*string			`db:"saksi"`
+	BuktiLengkapAt		*time.Time		`db:"bukti_lengkap_at"` // bukti foto/video ditandai lengkap dan pekurban sudah dikabari
+	BeratHidup			*float64		`db:"berat_hidup"`
+	BeratKarkas			*float64		`db:"berat_karkas"`
+	BeratDaging			*float64		`db:"berat_daging"`
+	BeratTulang			*float64		`db:"berat_tulang"`
+	BeratJeroan			*float64		`db:"berat_jeroan"`
+	JumlahPaket			*int			`db:"jumlah_paket"`
 	Created_At			time.Time		`db:"created_at"`
 	Updated_At			time.Time		`db:"updated_at"`
-}
\ No newline at end of file
+}
+
+// PersentaseKarkas adalah rendemen karkas terhadap berat hidup saat disembelih (dalam persen)
+func (p *Penyembelihan) PersentaseKarkas() *float64 {
+	if p.BeratHidup == nil || p.BeratKarkas == nil || *p.BeratHidup <= 0 {
+		return nil
+	}
+	persen := math.Round(*p.BeratKarkas / *p.BeratHidup * 10000) / 100
+	return &persen
+}
+
+// DurasiMenit adalah lama penyembelihan dari check-in mulai sampai selesai
+func (p *Penyembelihan) DurasiMenit() *float64 {
+	if p.MulaiAt == nil || p.SelesaiAt == nil {
+		return nil
+	}
+	menit := math.Round(p.SelesaiAt.Sub(*p.MulaiAt).Minutes()*10) / 10
+	return &menit
+}
+
+// KeterlambatanMenit adalah selisih waktu mulai aktual terhadap rencana; negatif berarti lebih awal
+func (p *Penyembelihan) KeterlambatanMenit() *float64 {
+	if p.MulaiAt == nil || p.RencanaMulai == nil {
+		return nil
+	}
+	menit := math.Round(p.MulaiAt.Sub(*p.RencanaMulai).Minutes()*10) / 10
+	return &menit
+}
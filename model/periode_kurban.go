@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
+)
+
+// Hari kurban adalah 10 Dzulhijjah (Idul Adha) sampai 13 Dzulhijjah (akhir hari tasyrik)
+const (
+	HariIdulAdha     = 10
+	HariTasyrikAkhir = 13
+)
+
+// PeriodeKurban adalah musim kurban per tahun Hijriah. OffsetHari menyesuaikan kalender tabular
+// dengan tanggal resmi hasil sidang isbat; hanya satu periode yang aktif.
+type PeriodeKurban struct {
+	ID         uuid.UUID `db:"id"`
+	TahunHijri int       `db:"tahun_hijri"`
+	OffsetHari int       `db:"offset_hari"`
+	Aktif      bool      `db:"aktif"`
+	Created_At time.Time `db:"created_at"`
+	Updated_At time.Time `db:"updated_at"`
+}
+
+// Jendela mengembalikan tanggal Masehi 10 dan 13 Dzulhijjah periode ini (tengah malam waktu lokal)
+func (p *PeriodeKurban) Jendela() (mulai, selesai time.Time) {
+	mulai = hijri.ToGregorian(hijri.Tanggal{Tahun: p.TahunHijri, Bulan: hijri.Dzulhijjah, Hari: HariIdulAdha}, p.OffsetHari, time.Local)
+	selesai = hijri.ToGregorian(hijri.Tanggal{Tahun: p.TahunHijri, Bulan: hijri.Dzulhijjah, Hari: HariTasyrikAkhir}, p.OffsetHari, time.Local)
+	return mulai, selesai
+}
+
+// Meliputi memeriksa apakah tanggal kalender t (jam diabaikan) berada di hari kurban periode ini
+func (p *PeriodeKurban) Meliputi(t time.Time) bool {
+	mulai, selesai := p.Jendela()
+	tgl := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return !tgl.Before(mulai) && !tgl.After(selesai)
+}
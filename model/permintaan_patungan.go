@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PermintaanMenunggu    = "menunggu"
+	PermintaanTerpenuhi   = "terpenuhi"
+	PermintaanDibatalkan  = "dibatalkan"
+	PermintaanKedaluwarsa = "kedaluwarsa"
+)
+
+type PermintaanPatungan struct {
+	ID            uuid.UUID `db:"id"`
+	PekurbanID    uuid.UUID `db:"pekurban_id"`
+	PekurbanName  string
+	PekurbanEmail *string
+	Jenis         JenisHewan `db:"jenis"`
+	JumlahPorsi   int        `db:"jumlah_porsi"`
+	HargaMin      *float64   `db:"harga_min"` // per porsi
+	HargaMax      *float64   `db:"harga_max"` // per porsi
+	Status        string     `db:"status"`
+	HewanID       *uuid.UUID `db:"hewan_id"`
+	MatchedAt     *time.Time `db:"matched_at"`
+	Created_At    time.Time  `db:"created_at"`
+	Updated_At    time.Time  `db:"updated_at"`
+}
+
+// Slot porsi sebuah hewan publik yang belum penuh
+type HewanSlot struct {
+	HewanID     uuid.UUID
+	Jenis       JenisHewan
+	Harga       float64
+	Kapasitas   int
+	Terisi      int
+	PekurbanIDs []string
+}
+
+func (h HewanSlot) HargaPerPorsi() float64 {
+	return h.Harga / float64(h.Kapasitas)
+}
+
+func (h HewanSlot) Sisa() int {
+	return h.Kapasitas - h.Terisi
+}
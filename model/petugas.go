@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// peran petugas lapangan
+const (
+	PeranJagal    = "jagal"
+	PeranPencacah = "pencacah"
+	PeranPengemas = "pengemas"
+	PeranKurir    = "kurir"
+)
+
+// Petugas adalah jagal atau relawan; boleh ditautkan ke akun users supaya bisa melihat jadwalnya sendiri
+type Petugas struct {
+	ID         uuid.UUID  `db:"id"`
+	Nama       string     `db:"nama"`
+	Phone      *string    `db:"phone"`
+	UserID     *uuid.UUID `db:"user_id"`
+	Peran      []string   `db:"peran"`
+	Catatan    *string    `db:"catatan"`
+	Created_At time.Time  `db:"created_at"`
+	Updated_At time.Time  `db:"updated_at"`
+}
+
+// MemilikiPeran bernilai true jika petugas bisa mengisi peran tersebut
+func (p *Petugas) MemilikiPeran(peran string) bool {
+	for _, r := range p.Peran {
+		if r == peran {
+			return true
+		}
+	}
+	return false
+}
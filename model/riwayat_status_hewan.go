@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RiwayatStatusHewan struct {
+	ID         uuid.UUID  `db:"id"`
+	HewanID    uuid.UUID  `db:"hewan_id"`
+	DariStatus *string    `db:"dari_status"`
+	KeStatus   string     `db:"ke_status"`
+	DiubahOleh *uuid.UUID `db:"diubah_oleh"`
+	Keterangan *string    `db:"keterangan"`
+	Created_At time.Time  `db:"created_at"`
+
+	// hasil join
+	NamaPengubah *string
+}
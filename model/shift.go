@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shift adalah slot kerja untuk satu peran di satu lokasi; Kebutuhan adalah jumlah petugas yang diperlukan
+type Shift struct {
+	ID         uuid.UUID `db:"id"`
+	LokasiID   uuid.UUID `db:"lokasi_id"`
+	Lokasi     string    // nama lokasi, hasil join
+	Peran      string    `db:"peran"`
+	Mulai      time.Time `db:"mulai"`
+	Selesai    time.Time `db:"selesai"`
+	Kebutuhan  int       `db:"kebutuhan"`
+	Catatan    *string   `db:"catatan"`
+	Created_At time.Time `db:"created_at"`
+	Updated_At time.Time `db:"updated_at"`
+
+	Petugas []PenugasanShift // hasil join
+}
+
+type PenugasanShift struct {
+	ShiftID     uuid.UUID `db:"shift_id"`
+	PetugasID   uuid.UUID `db:"petugas_id"`
+	PetugasNama string    // hasil join
+	Created_At  time.Time `db:"created_at"`
+}
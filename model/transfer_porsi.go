@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferPorsi struct {
+	ID				uuid.UUID	`db:"id"`
+	HewanID			uuid.UUID	`db:"hewan_id"`
+	DariPekurbanID	uuid.UUID	`db:"dari_pekurban_id"`
+	KePekurbanID	uuid.UUID	`db:"ke_pekurban_id"`
+	Porsi			Pecahan
+	JumlahDibayar	float64		`db:"jumlah_dibayar"`
+	DisetujuiOleh	uuid.UUID	`db:"disetujui_oleh"`
+	Catatan			*string		`db:"catatan"`
+	Created_At		time.Time	`db:"created_at"`
+
+	// hasil join
+	DariPekurban	string
+	KePekurban		string
+	Penyetuju		string
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"crypto/sha512"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"os"
 
@@ -11,6 +13,7 @@ import (
 
 type MidtransService interface {
 	Charge(req *model.MidtransChargeRequest) (*model.MidtransChargeResponse, error)
+	VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool
 }
 
 type midtransService struct {
@@ -59,4 +62,10 @@ func (m *midtransService) Charge(req *model.MidtransChargeRequest) (*model.Midtr
 	}
 
 	return &response, nil
-}
\ No newline at end of file
+}
+
+// signature_key notifikasi Midtrans = SHA512(order_id + status_code + gross_amount + server_key)
+func (m *midtransService) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + m.serverKey))
+	return hex.EncodeToString(sum[:]) == signatureKey
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ChannelAntreanPenyembelihan adalah channel NOTIFY yang dikirim trigger database setiap kali jadwal
+// penyembelihan atau status hewan berubah; payload berisi tanggal penyembelihan (YYYY-MM-DD)
+const ChannelAntreanPenyembelihan = "antrean_penyembelihan"
+
+// AntreanListener meneruskan notifikasi antrean dari Postgres. Setiap instance server memasang listener
+// sendiri sehingga perubahan dari instance mana pun sampai ke semua klien.
+type AntreanListener interface {
+	// Notifications mengirim tanggal yang berubah; string kosong berarti koneksi baru tersambung ulang
+	// dan semua antrean perlu dimuat ulang
+	Notifications() <-chan string
+	Close() error
+}
+
+type antreanListener struct {
+	listener *pq.Listener
+	out      chan string
+	done     chan struct{}
+}
+
+func NewAntreanListener(dsn string) (AntreanListener, error) {
+	l := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("antrean listener: %v", err)
+		}
+	})
+	if err := l.Listen(ChannelAntreanPenyembelihan); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	a := &antreanListener{listener: l, out: make(chan string, 64), done: make(chan struct{})}
+	go a.run()
+	return a, nil
+}
+
+func (a *antreanListener) run() {
+	defer close(a.out)
+	// ping berkala supaya koneksi yang putus tanpa kabar tetap terdeteksi dan disambung ulang
+	ticker := time.NewTicker(90 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case n, ok := <-a.listener.Notify:
+			if !ok {
+				return
+			}
+			payload := ""
+			if n != nil {
+				payload = n.Extra
+			}
+			select {
+			case a.out <- payload:
+			case <-a.done:
+				return
+			}
+		case <-ticker.C:
+			go a.listener.Ping()
+		}
+	}
+}
+
+func (a *antreanListener) Notifications() <-chan string {
+	return a.out
+}
+
+func (a *antreanListener) Close() error {
+	close(a.done)
+	return a.listener.Close()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type AtasNamaRepository interface {
+	GetAll(ctx context.Context) ([]*model.AtasNama, error)
+	GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.AtasNama, error)
+	GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.AtasNama, error)
+	GetByShare(ctx context.Context, pekurbanID, hewanID uuid.UUID) ([]*model.AtasNama, error)
+	ReplaceForShare(ctx context.Context, pekurbanID, hewanID uuid.UUID, list []*model.AtasNama) error
+}
+
+type atasNamaRepository struct {
+	db *sql.DB
+}
+
+func NewAtasNamaRepository(db *sql.DB) AtasNamaRepository {
+	return &atasNamaRepository{db: db}
+}
+
+const selectAtasNama = `SELECT id, pekurban_id, hewan_id, nama, hubungan, masih_hidup, created_at, updated_at FROM atas_nama_kurban`
+
+func (r *atasNamaRepository) GetAll(ctx context.Context) ([]*model.AtasNama, error) {
+	return r.query(ctx, selectAtasNama+` ORDER BY created_at`)
+}
+
+func (r *atasNamaRepository) GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.AtasNama, error) {
+	return r.query(ctx, selectAtasNama+` WHERE hewan_id = $1 ORDER BY created_at`, hewanID)
+}
+
+func (r *atasNamaRepository) GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.AtasNama, error) {
+	return r.query(ctx, selectAtasNama+` WHERE pekurban_id = $1 ORDER BY created_at`, pekurbanID)
+}
+
+func (r *atasNamaRepository) GetByShare(ctx context.Context, pekurbanID, hewanID uuid.UUID) ([]*model.AtasNama, error) {
+	return r.query(ctx, selectAtasNama+` WHERE pekurban_id = $1 AND hewan_id = $2 ORDER BY created_at`, pekurbanID, hewanID)
+}
+
+// ReplaceForShare mengganti seluruh daftar atas nama milik satu porsi; dipanggil di dalam TxManager.WithinTx
+func (r *atasNamaRepository) ReplaceForShare(ctx context.Context, pekurbanID, hewanID uuid.UUID, list []*model.AtasNama) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `DELETE FROM atas_nama_kurban WHERE pekurban_id = $1 AND hewan_id = $2`, pekurbanID, hewanID); err != nil {
+		return err
+	}
+
+	for _, a := range list {
+		_, err := db.ExecContext(ctx, `INSERT INTO atas_nama_kurban (id, pekurban_id, hewan_id, nama, hubungan, masih_hidup, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			a.ID, pekurbanID, hewanID, a.Nama, a.Hubungan, a.MasihHidup, a.Created_At, a.Updated_At)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *atasNamaRepository) query(ctx context.Context, q string, args ...interface{}) ([]*model.AtasNama, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.AtasNama
+	for rows.Next() {
+		var a model.AtasNama
+		if err := rows.Scan(&a.ID, &a.PekurbanID, &a.HewanID, &a.Nama, &a.Hubungan, &a.MasihHidup, &a.Created_At, &a.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type BatchAntarRepository interface {
+	// Create menyimpan batch beserta stop-nya; panggil di dalam transaksi agar keduanya tersimpan bersama
+	Create(ctx context.Context, b *model.BatchAntar) error
+	// GetAll mengambil batch tanpa stop, urut tanggal; tanggal dan kurirID opsional sebagai filter
+	GetAll(ctx context.Context, tanggal *time.Time, kurirID *uuid.UUID) ([]*model.BatchAntar, error)
+	// GetById mengambil batch lengkap dengan stop-nya sesuai urutan rute
+	GetById(ctx context.Context, id uuid.UUID) (*model.BatchAntar, error)
+	SetKurir(ctx context.Context, id uuid.UUID, kurirID *uuid.UUID) error
+	// LockStop mengambil satu stop milik batch dan menguncinya sampai transaksi selesai
+	LockStop(ctx context.Context, batchID, stopID uuid.UUID) (*model.BatchAntarStop, error)
+	UpdateStop(ctx context.Context, s *model.BatchAntarStop) error
+	// GetPenerimaTerjadwal mengambil penerima yang masih punya stop terbuka di batch mana pun
+	GetPenerimaTerjadwal(ctx context.Context) (map[uuid.UUID]bool, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type batchAntarRepository struct {
+	db *sql.DB
+}
+
+func NewBatchAntarRepository(db *sql.DB) BatchAntarRepository {
+	return &batchAntarRepository{db: db}
+}
+
+const batchAntarSelect = `SELECT b.id, b.nama, b.tanggal, b.lokasi_id, l.nama, b.rt, b.rw, b.kurir_id, pt.nama, pt.phone, b.catatan,
+		(SELECT COUNT(*) FROM batch_antar_stop s WHERE s.batch_id = b.id),
+		(SELECT COUNT(*) FROM batch_antar_stop s WHERE s.batch_id = b.id AND s.status IN ('terkirim', 'ditolak')),
+		b.created_at, b.updated_at
+	FROM batch_antar b
+	LEFT JOIN lokasi l ON l.id = b.lokasi_id
+	LEFT JOIN petugas pt ON pt.id = b.kurir_id`
+
+const batchAntarStopSelect = `SELECT s.id, s.batch_id, s.penerima_id, p.name, p.alamat, p.phone, p.rt, p.rw, s.urutan, s.jumlah_paket,
+		s.status, s.catatan, s.distribusi_id, s.diperbarui_oleh, s.diperbarui_at
+	FROM batch_antar_stop s
+	JOIN penerima_daging p ON p.id = s.penerima_id`
+
+var errPenerimaTerjadwal = errors.New("Penerima is already on an open delivery stop")
+
+func scanBatchAntar(row interface{ Scan(...interface{}) error }) (*model.BatchAntar, error) {
+	var b model.BatchAntar
+	if err := row.Scan(&b.ID, &b.Nama, &b.Tanggal, &b.LokasiID, &b.LokasiNama, &b.RT, &b.RW, &b.KurirID, &b.KurirNama, &b.KurirPhone, &b.Catatan,
+		&b.JumlahStop, &b.StopSelesai, &b.Created_At, &b.Updated_At); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func scanBatchAntarStop(row interface{ Scan(...interface{}) error }) (*model.BatchAntarStop, error) {
+	var s model.BatchAntarStop
+	if err := row.Scan(&s.ID, &s.BatchID, &s.PenerimaID, &s.PenerimaNama, &s.PenerimaAlamat, &s.PenerimaPhone, &s.PenerimaRT, &s.PenerimaRW,
+		&s.Urutan, &s.JumlahPaket, &s.Status, &s.Catatan, &s.DistribusiID, &s.DiperbaruiOleh, &s.DiperbaruiAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *batchAntarRepository) Create(ctx context.Context, b *model.BatchAntar) error {
+	db := conn(ctx, r.db)
+	_, err := db.ExecContext(ctx, `INSERT INTO batch_antar (id, nama, tanggal, lokasi_id, rt, rw, kurir_id, catatan, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		b.ID, b.Nama, b.Tanggal, b.LokasiID, b.RT, b.RW, b.KurirID, b.Catatan, b.Created_At, b.Updated_At)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range b.Stop {
+		_, err := db.ExecContext(ctx, `INSERT INTO batch_antar_stop (id, batch_id, penerima_id, urutan, jumlah_paket, status)
+			VALUES ($1, $2, $3, $4, $5, $6)`, s.ID, b.ID, s.PenerimaID, s.Urutan, s.JumlahPaket, s.Status)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return errPenerimaTerjadwal
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *batchAntarRepository) GetAll(ctx context.Context, tanggal *time.Time, kurirID *uuid.UUID) ([]*model.BatchAntar, error) {
+	q := batchAntarSelect + ` WHERE ($1::date IS NULL OR b.tanggal = $1) AND ($2::uuid IS NULL OR b.kurir_id = $2)
+		ORDER BY b.tanggal, b.nama`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, tanggal, kurirID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.BatchAntar
+	for rows.Next() {
+		b, err := scanBatchAntar(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
+func (r *batchAntarRepository) GetById(ctx context.Context, id uuid.UUID) (*model.BatchAntar, error) {
+	db := conn(ctx, r.db)
+	b, err := scanBatchAntar(db.QueryRowContext(ctx, batchAntarSelect+` WHERE b.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, batchAntarStopSelect+` WHERE s.batch_id = $1 ORDER BY s.urutan`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanBatchAntarStop(rows)
+		if err != nil {
+			return nil, err
+		}
+		b.Stop = append(b.Stop, *s)
+	}
+	return b, rows.Err()
+}
+
+func (r *batchAntarRepository) SetKurir(ctx context.Context, id uuid.UUID, kurirID *uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE batch_antar SET kurir_id = $2 WHERE id = $1`, id, kurirID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Batch antar not found")
+	}
+	return nil
+}
+
+func (r *batchAntarRepository) LockStop(ctx context.Context, batchID, stopID uuid.UUID) (*model.BatchAntarStop, error) {
+	s, err := scanBatchAntarStop(conn(ctx, r.db).QueryRowContext(ctx, batchAntarStopSelect+` WHERE s.batch_id = $1 AND s.id = $2 FOR UPDATE OF s`, batchID, stopID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *batchAntarRepository) UpdateStop(ctx context.Context, s *model.BatchAntarStop) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE batch_antar_stop SET status = $2, catatan = $3, distribusi_id = $4, diperbarui_oleh = $5, diperbarui_at = $6
+		WHERE id = $1`, s.ID, s.Status, s.Catatan, s.DistribusiID, s.DiperbaruiOleh, s.DiperbaruiAt)
+	return err
+}
+
+func (r *batchAntarRepository) GetPenerimaTerjadwal(ctx context.Context) (map[uuid.UUID]bool, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT penerima_id FROM batch_antar_stop WHERE status IN ('menunggu', 'tidak_di_rumah')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[uuid.UUID]bool{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result[id] = true
+	}
+	return result, rows.Err()
+}
+
+// Delete hanya menghapus batch yang belum punya stop terkirim; batch yang sudah berjalan menjadi bukti pengantaran
+func (r *batchAntarRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM batch_antar b WHERE b.id = $1
+		AND NOT EXISTS (SELECT 1 FROM batch_antar_stop s WHERE s.batch_id = b.id AND s.status = 'terkirim')`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Batch antar not found or already has delivered stops")
+	}
+	return nil
+}
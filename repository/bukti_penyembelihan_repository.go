@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type BuktiPenyembelihanRepository interface {
+	Create(ctx context.Context, b *model.BuktiPenyembelihan) error
+	GetByPenyembelihan(ctx context.Context, penyembelihanID uuid.UUID) ([]*model.BuktiPenyembelihan, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.BuktiPenyembelihan, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+const buktiPenyembelihanColumns = `id, penyembelihan_id, tipe, path, nama_asli, content_type, ukuran, uploaded_by, created_at`
+
+type buktiPenyembelihanRepository struct {
+	db *sql.DB
+}
+
+func NewBuktiPenyembelihanRepository(db *sql.DB) BuktiPenyembelihanRepository {
+	return &buktiPenyembelihanRepository{db: db}
+}
+
+func scanBuktiPenyembelihan(row interface{ Scan(...interface{}) error }) (*model.BuktiPenyembelihan, error) {
+	var b model.BuktiPenyembelihan
+	err := row.Scan(&b.ID, &b.PenyembelihanID, &b.Tipe, &b.Path, &b.NamaAsli, &b.ContentType, &b.Ukuran, &b.UploadedBy, &b.Created_At)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *buktiPenyembelihanRepository) Create(ctx context.Context, b *model.BuktiPenyembelihan) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO bukti_penyembelihan (`+buktiPenyembelihanColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		b.ID, b.PenyembelihanID, b.Tipe, b.Path, b.NamaAsli, b.ContentType, b.Ukuran, b.UploadedBy, b.Created_At)
+	return err
+}
+
+func (r *buktiPenyembelihanRepository) GetByPenyembelihan(ctx context.Context, penyembelihanID uuid.UUID) ([]*model.BuktiPenyembelihan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+buktiPenyembelihanColumns+` FROM bukti_penyembelihan WHERE penyembelihan_id = $1 ORDER BY created_at`, penyembelihanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.BuktiPenyembelihan
+	for rows.Next() {
+		b, err := scanBuktiPenyembelihan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
+func (r *buktiPenyembelihanRepository) GetById(ctx context.Context, id uuid.UUID) (*model.BuktiPenyembelihan, error) {
+	b, err := scanBuktiPenyembelihan(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+buktiPenyembelihanColumns+` FROM bukti_penyembelihan WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return b, err
+}
+
+func (r *buktiPenyembelihanRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM bukti_penyembelihan WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("Bukti penyembelihan not found")
+	}
+	return nil
+}
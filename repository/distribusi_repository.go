@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 )
 
@@ -13,8 +15,20 @@ type DistribusiDagingRepository interface {
 	Create(ctx context.Context, d *model.DistribusiDaging) error
 	GetAll(ctx context.Context) ([]*model.DistribusiDaging, error)	
 	GetByID(ctx context.Context, id uuid.UUID) (*model.DistribusiDaging, error)
-	FindByPenerimaID(ctx context.Context, penerimaID uuid.UUID) (*model.DistribusiDaging, error)
+	// GetByPenerimaID mengambil riwayat distribusi seorang penerima, terbaru lebih dulu
+	GetByPenerimaID(ctx context.Context, penerimaID uuid.UUID) ([]*model.DistribusiDaging, error)
+	// CountByPenerima menghitung distribusi seorang penerima dengan tanggal distribusi di antara mulai dan selesai
+	CountByPenerima(ctx context.Context, penerimaID uuid.UUID, mulai, selesai time.Time) (int, error)
+	// GetRekapPenerima merekap alokasi dan paket diterima setiap penerima dalam satu tahun Hijriah
+	GetRekapPenerima(ctx context.Context, tahunHijri, offsetHari int) ([]*model.RekapDistribusiPenerima, error)
+	// SaveAlokasi menyimpan atau mengganti alokasi penerima pada tahun yang sama
+	SaveAlokasi(ctx context.Context, a *model.AlokasiDistribusi) error
+	DeleteAlokasi(ctx context.Context, penerimaID uuid.UUID, tahunHijri int) error
+	GetAlokasiByPenerima(ctx context.Context, penerimaID uuid.UUID) ([]*model.AlokasiDistribusi, error)
 	CountTotalPaket(ctx context.Context) (int, error)
+	// SumPaketByHewan menjumlahkan paket yang sudah dibagikan dari satu hewan
+	SumPaketByHewan(ctx context.Context, hewanID uuid.UUID) (int, error)
+	GetRekapHewan(ctx context.Context) ([]*model.RekapDistribusiHewan, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -26,16 +40,28 @@ func NewDistribusiDagingRepository(db *sql.DB) DistribusiDagingRepository {
 	return &distribusiDagingRepository{db: db}
 }
 
+// Create menyimpan distribusi beserta asal hewannya; panggil di dalam transaksi agar keduanya tersimpan bersama
 func (r *distribusiDagingRepository) Create(ctx context.Context, d *model.DistribusiDaging) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO distribusi_daging (id, penerima_id, jumlah_paket, tanggal_distribusi, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`, d.ID, d.PenerimaID, d.JumlahPaket, d.TanggalDistribusi, d.Created_At, d.Updated_At)
-	return err
+	db := conn(ctx, r.db)
+	_, err := db.ExecContext(ctx, `INSERT INTO distribusi_daging (id, penerima_id, lokasi_id, jumlah_paket, tanggal_distribusi, dicatat_oleh, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, d.ID, d.PenerimaID, d.LokasiID, d.JumlahPaket, d.TanggalDistribusi, d.DicatatOleh, d.Created_At, d.Updated_At)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range d.Hewan {
+		if _, err := db.ExecContext(ctx, `INSERT INTO distribusi_hewan (distribusi_id, hewan_id, jumlah_paket) VALUES ($1, $2, $3)`, d.ID, h.HewanID, h.JumlahPaket); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *distribusiDagingRepository) GetAll(ctx context.Context) ([]*model.DistribusiDaging, error) {
     rows, err := r.db.QueryContext(ctx, `
-        SELECT d.id, d.penerima_id, p.name, d.jumlah_paket, d.tanggal_distribusi, d.created_at, d.updated_at
+        SELECT d.id, d.penerima_id, p.name, d.lokasi_id, l.nama, d.jumlah_paket, d.tanggal_distribusi, d.dicatat_oleh, d.created_at, d.updated_at
         FROM distribusi_daging d
         JOIN penerima_daging p ON d.penerima_id = p.id
+        LEFT JOIN lokasi l ON l.id = d.lokasi_id
     `)
     if err != nil {
         return nil, err
@@ -47,57 +73,169 @@ func (r *distribusiDagingRepository) GetAll(ctx context.Context) ([]*model.Distr
         var d model.DistribusiDaging
         var penerimaName string
 
-        if err := rows.Scan(&d.ID, &d.PenerimaID, &penerimaName, &d.JumlahPaket, &d.TanggalDistribusi, &d.Created_At, &d.Updated_At); err != nil {
+        if err := rows.Scan(&d.ID, &d.PenerimaID, &penerimaName, &d.LokasiID, &d.LokasiNama, &d.JumlahPaket, &d.TanggalDistribusi, &d.DicatatOleh, &d.Created_At, &d.Updated_At); err != nil {
             return nil, err
         }
 
         d.PenerimaName = penerimaName 
         result = append(result, &d)
     }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
 
-    return result, nil
+    return result, r.loadHewan(ctx, result)
 }
 
 
 func (r *distribusiDagingRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.DistribusiDaging, error) {
 	rows := r.db.QueryRowContext(ctx, `
-		SELECT d.id, d.penerima_id, p.name, d.jumlah_paket, d.tanggal_distribusi, d.created_at, d.updated_at 
+		SELECT d.id, d.penerima_id, p.name, d.lokasi_id, l.nama, d.jumlah_paket, d.tanggal_distribusi, d.dicatat_oleh, d.created_at, d.updated_at 
 		FROM distribusi_daging d
-		JOIN penerima_daging p ON d.penerima_id = p.id 
+		JOIN penerima_daging p ON d.penerima_id = p.id
+		LEFT JOIN lokasi l ON l.id = d.lokasi_id
 		WHERE d.id = $1`, id)
 
 	var d model.DistribusiDaging
-	if err := rows.Scan(&d.ID, &d.PenerimaID, &d.PenerimaName, &d.JumlahPaket, &d.TanggalDistribusi, &d.Created_At, &d.Updated_At); err != nil {
+	if err := rows.Scan(&d.ID, &d.PenerimaID, &d.PenerimaName, &d.LokasiID, &d.LokasiNama, &d.JumlahPaket, &d.TanggalDistribusi, &d.DicatatOleh, &d.Created_At, &d.Updated_At); err != nil {
 		return nil, err
 	}
 
-	return &d, nil
+	return &d, r.loadHewan(ctx, []*model.DistribusiDaging{&d})
 }
 
-func (r *distribusiDagingRepository) FindByPenerimaID(ctx context.Context, penerimaID uuid.UUID) (*model.DistribusiDaging, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT d.id, d.penerima_id, p.name, d.jumlah_paket, d.tanggal_distribusi, d.created_at, d.updated_at 
+func (r *distribusiDagingRepository) GetByPenerimaID(ctx context.Context, penerimaID uuid.UUID) ([]*model.DistribusiDaging, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT d.id, d.penerima_id, p.name, d.lokasi_id, l.nama, d.jumlah_paket, d.tanggal_distribusi, d.dicatat_oleh, d.created_at, d.updated_at
 		FROM distribusi_daging d
-		JOIN penerima_daging p ON d.penerima_id = p.id 
-		WHERE d.penerima_id = $1`, penerimaID)
+		JOIN penerima_daging p ON d.penerima_id = p.id
+		LEFT JOIN lokasi l ON l.id = d.lokasi_id
+		WHERE d.penerima_id = $1
+		ORDER BY d.tanggal_distribusi DESC, d.created_at DESC`, penerimaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	var d model.DistribusiDaging
-	if err := row.Scan(
-		&d.ID,
-		&d.PenerimaID,
-		&d.PenerimaName,
-		&d.JumlahPaket,
-		&d.TanggalDistribusi,
-		&d.Created_At,
-		&d.Updated_At,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+	var result []*model.DistribusiDaging
+	for rows.Next() {
+		var d model.DistribusiDaging
+		if err := rows.Scan(&d.ID, &d.PenerimaID, &d.PenerimaName, &d.LokasiID, &d.LokasiNama, &d.JumlahPaket, &d.TanggalDistribusi, &d.DicatatOleh, &d.Created_At, &d.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, r.loadHewan(ctx, result)
+}
+
+func (r *distribusiDagingRepository) CountByPenerima(ctx context.Context, penerimaID uuid.UUID, mulai, selesai time.Time) (int, error) {
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM distribusi_daging WHERE penerima_id = $1 AND tanggal_distribusi BETWEEN $2 AND $3`,
+		penerimaID, mulai.Format("2006-01-02"), selesai.Format("2006-01-02")).Scan(&total)
+	return total, err
+}
+
+func (r *distribusiDagingRepository) GetRekapPenerima(ctx context.Context, tahunHijri, offsetHari int) ([]*model.RekapDistribusiPenerima, error) {
+	mulai, selesai := model.RentangTahunHijri(tahunHijri, offsetHari)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT p.id, p.name, p.alamat, p.phone, p.rt, p.rw, p.antar_rumah, p.status, p.pekurban_id, p.created_at, p.updated_at,
+		       a.jumlah_paket, COALESCE(d.paket, 0), COALESCE(d.total, 0)
+		FROM penerima_daging p
+		LEFT JOIN alokasi_distribusi a ON a.penerima_id = p.id AND a.tahun_hijri = $1
+		LEFT JOIN (
+			SELECT penerima_id, SUM(jumlah_paket) AS paket, COUNT(*) AS total
+			FROM distribusi_daging
+			WHERE tanggal_distribusi BETWEEN $2 AND $3
+			GROUP BY penerima_id
+		) d ON d.penerima_id = p.id
+		ORDER BY p.name`, tahunHijri, mulai.Format("2006-01-02"), selesai.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.RekapDistribusiPenerima
+	for rows.Next() {
+		rk := model.RekapDistribusiPenerima{TahunHijri: tahunHijri}
+		p := &rk.Penerima
+		if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.RT, &p.RW, &p.AntarRumah, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At,
+			&rk.AlokasiPaket, &rk.PaketDiterima, &rk.TotalDistribusi); err != nil {
+			return nil, err
 		}
+		result = append(result, &rk)
+	}
+	return result, rows.Err()
+}
+
+func (r *distribusiDagingRepository) SaveAlokasi(ctx context.Context, a *model.AlokasiDistribusi) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO alokasi_distribusi (penerima_id, tahun_hijri, jumlah_paket, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (penerima_id, tahun_hijri) DO UPDATE SET jumlah_paket = EXCLUDED.jumlah_paket`,
+		a.PenerimaID, a.TahunHijri, a.JumlahPaket, a.Created_At, a.Updated_At)
+	return err
+}
+
+func (r *distribusiDagingRepository) DeleteAlokasi(ctx context.Context, penerimaID uuid.UUID, tahunHijri int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM alokasi_distribusi WHERE penerima_id = $1 AND tahun_hijri = $2`, penerimaID, tahunHijri)
+	return err
+}
+
+func (r *distribusiDagingRepository) GetAlokasiByPenerima(ctx context.Context, penerimaID uuid.UUID) ([]*model.AlokasiDistribusi, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT penerima_id, tahun_hijri, jumlah_paket, created_at, updated_at
+		FROM alokasi_distribusi WHERE penerima_id = $1 ORDER BY tahun_hijri DESC`, penerimaID)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var result []*model.AlokasiDistribusi
+	for rows.Next() {
+		var a model.AlokasiDistribusi
+		if err := rows.Scan(&a.PenerimaID, &a.TahunHijri, &a.JumlahPaket, &a.Created_At, &a.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
+
+// loadHewan mengisi asal hewan setiap distribusi
+func (r *distribusiDagingRepository) loadHewan(ctx context.Context, list []*model.DistribusiDaging) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(list))
+	byID := map[uuid.UUID]*model.DistribusiDaging{}
+	for _, d := range list {
+		d.Hewan = []model.DistribusiHewan{}
+		ids = append(ids, d.ID)
+		byID[d.ID] = d
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT dh.distribusi_id, dh.hewan_id, h.jenis, dh.jumlah_paket
+		FROM distribusi_hewan dh
+		JOIN hewan_kurban h ON h.id = dh.hewan_id
+		WHERE dh.distribusi_id = ANY($1)
+		ORDER BY h.jenis, dh.hewan_id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-	return &d, nil
+	for rows.Next() {
+		var h model.DistribusiHewan
+		if err := rows.Scan(&h.DistribusiID, &h.HewanID, &h.Jenis, &h.JumlahPaket); err != nil {
+			return err
+		}
+		byID[h.DistribusiID].Hewan = append(byID[h.DistribusiID].Hewan, h)
+	}
+	return rows.Err()
 }
 
 
@@ -109,6 +247,40 @@ func (r *distribusiDagingRepository) CountTotalPaket(ctx context.Context) (int,
 }
 
 
+func (r *distribusiDagingRepository) SumPaketByHewan(ctx context.Context, hewanID uuid.UUID) (int, error) {
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(SUM(jumlah_paket), 0) FROM distribusi_hewan WHERE hewan_id = $1`, hewanID).Scan(&total)
+	return total, err
+}
+
+// GetRekapHewan merekap paket per hewan yang sudah disembelih atau sudah pernah dibagikan
+func (r *distribusiDagingRepository) GetRekapHewan(ctx context.Context) ([]*model.RekapDistribusiHewan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT h.id, h.jenis, h.status, ps.tanggal_penyembelihan, ps.jumlah_paket,
+		       COALESCE(SUM(dh.jumlah_paket), 0), COUNT(dh.distribusi_id)
+		FROM hewan_kurban h
+		LEFT JOIN penyembelihan ps ON ps.hewan_id = h.id
+		LEFT JOIN distribusi_hewan dh ON dh.hewan_id = h.id
+		WHERE h.status IN ('disembelih', 'dicacah', 'didistribusikan') OR dh.hewan_id IS NOT NULL
+		GROUP BY h.id, h.jenis, h.status, ps.tanggal_penyembelihan, ps.jumlah_paket
+		ORDER BY ps.tanggal_penyembelihan NULLS LAST, h.jenis, h.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.RekapDistribusiHewan
+	for rows.Next() {
+		var rk model.RekapDistribusiHewan
+		if err := rows.Scan(&rk.HewanID, &rk.Jenis, &rk.Status, &rk.TanggalPenyembelihan, &rk.PaketDihasilkan,
+			&rk.PaketDidistribusikan, &rk.TotalDistribusi); err != nil {
+			return nil, err
+		}
+		result = append(result, &rk)
+	}
+	return result, rows.Err()
+}
+
 func (r *distribusiDagingRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	res, err := r.db.ExecContext(ctx, `DELETE FROM distribusi_daging WHERE id = $1`, id)
 	if err != nil {
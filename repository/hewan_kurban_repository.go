@@ -4,17 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 )
 
 type HewanKurbanRepository interface {
 	Create(ctx context.Context, h *model.HewanKurban) error
 	GetAll(ctx context.Context) ([]*model.HewanKurban, error)
+	GetByStatus(ctx context.Context, status string) ([]*model.HewanKurban, error)
 	GetById(ctx context.Context, id uuid.UUID) (*model.HewanKurban, error)
+	LockById(ctx context.Context, id uuid.UUID) (*model.HewanKurban, error)
 	Update(ctx context.Context, h *model.HewanKurban) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetPublicCatalog(ctx context.Context, f model.PublicHewanFilter) ([]model.PublicHewan, error)
+	GetSiapJadwal(ctx context.Context) ([]model.HewanSiapJadwal, error)
 }
 
 type hewanKurbanRepository struct {
@@ -26,12 +34,20 @@ func NewHewanKurbanRepository(db *sql.DB) HewanKurbanRepository {
 }
 
 func (r *hewanKurbanRepository) Create(ctx context.Context, h *model.HewanKurban) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO hewan_kurban (id, jenis, berat, harga, is_private, tanggal_pendaftaran, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, h.ID, h.Jenis, h.Berat, h.Harga, h.IsPrivate, h.TanggalPendaftaran, h.Created_At, h.Updated_At)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO hewan_kurban (id, jenis, berat, umur_bulan, harga, is_private, foto_url, tanggal_pendaftaran, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, h.ID, h.Jenis, h.Berat, h.UmurBulan, h.Harga, h.IsPrivate, h.FotoURL, h.TanggalPendaftaran, h.Status, h.Created_At, h.Updated_At)
 	return  err
 }
 
 func (r *hewanKurbanRepository) GetAll(ctx context.Context) ([]*model.HewanKurban, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, jenis, berat, harga, is_private, tanggal_pendaftaran, created_at, updated_at FROM hewan_kurban`)
+	return r.query(ctx, `SELECT id, jenis, berat, umur_bulan, harga, is_private, foto_url, tanggal_pendaftaran, status, created_at, updated_at FROM hewan_kurban`)
+}
+
+func (r *hewanKurbanRepository) GetByStatus(ctx context.Context, status string) ([]*model.HewanKurban, error) {
+	return r.query(ctx, `SELECT id, jenis, berat, umur_bulan, harga, is_private, foto_url, tanggal_pendaftaran, status, created_at, updated_at FROM hewan_kurban WHERE status = $1`, status)
+}
+
+func (r *hewanKurbanRepository) query(ctx context.Context, q string, args ...interface{}) ([]*model.HewanKurban, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +57,7 @@ func (r *hewanKurbanRepository) GetAll(ctx context.Context) ([]*model.HewanKurba
 	var result []*model.HewanKurban
 	for rows.Next() {
 		var r model.HewanKurban
-		err := rows.Scan(&r.ID, &r.Jenis, &r.Berat, &r.Harga, &r.IsPrivate, &r.TanggalPendaftaran, &r.Created_At, &r.Updated_At)
+		err := rows.Scan(&r.ID, &r.Jenis, &r.Berat, &r.UmurBulan, &r.Harga, &r.IsPrivate, &r.FotoURL, &r.TanggalPendaftaran, &r.Status, &r.Created_At, &r.Updated_At)
 		if err != nil {
 			return nil, err
 		}
@@ -53,10 +69,28 @@ func (r *hewanKurbanRepository) GetAll(ctx context.Context) ([]*model.HewanKurba
 }
 
 func (r *hewanKurbanRepository) GetById(ctx context.Context, id uuid.UUID) (*model.HewanKurban, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, jenis, berat, harga, is_private, tanggal_pendaftaran, created_at, updated_at FROM hewan_kurban WHERE id=$1`, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT id, jenis, berat, umur_bulan, harga, is_private, foto_url, tanggal_pendaftaran, status, created_at, updated_at FROM hewan_kurban WHERE id=$1`, id)
+
+	var h model.HewanKurban
+	err := row.Scan(&h.ID, &h.Jenis, &h.Berat, &h.UmurBulan, &h.Harga, &h.IsPrivate, &h.FotoURL, &h.TanggalPendaftaran, &h.Status, &h.Created_At, &h.Updated_At)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+// LockById sama dengan GetById namun mengunci baris hewan (FOR UPDATE) sampai transaksi selesai,
+// dipakai untuk menyerialkan perubahan porsi pada hewan yang sama
+func (r *hewanKurbanRepository) LockById(ctx context.Context, id uuid.UUID) (*model.HewanKurban, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT id, jenis, berat, umur_bulan, harga, is_private, foto_url, tanggal_pendaftaran, status, created_at, updated_at FROM hewan_kurban WHERE id=$1 FOR UPDATE`, id)
 
 	var h model.HewanKurban
-	err := row.Scan(&h.ID, &h.Jenis, &h.Berat, &h.Harga, &h.IsPrivate, &h.TanggalPendaftaran, &h.Created_At, &h.Updated_At)
+	err := row.Scan(&h.ID, &h.Jenis, &h.Berat, &h.UmurBulan, &h.Harga, &h.IsPrivate, &h.FotoURL, &h.TanggalPendaftaran, &h.Status, &h.Created_At, &h.Updated_At)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -69,13 +103,23 @@ func (r *hewanKurbanRepository) GetById(ctx context.Context, id uuid.UUID) (*mod
 }
 
 func (r *hewanKurbanRepository) Update(ctx context.Context, h *model.HewanKurban) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE hewan_kurban SET jenis=$2, berat=$3, harga=$4, is_private=$5, tanggal_pendaftaran=$6 WHERE id=$1`, h.ID, h.Jenis, h.Berat, h.Harga, h.IsPrivate, h.TanggalPendaftaran)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE hewan_kurban SET jenis=$2, berat=$3, umur_bulan=$4, harga=$5, is_private=$6, foto_url=$7, tanggal_pendaftaran=$8 WHERE id=$1`, h.ID, h.Jenis, h.Berat, h.UmurBulan, h.Harga, h.IsPrivate, h.FotoURL, h.TanggalPendaftaran)
+	return err
+}
+
+// UpdateStatus hanya mengubah status siklus hidup; validasi transisi dilakukan di service
+func (r *hewanKurbanRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE hewan_kurban SET status=$2 WHERE id=$1`, id, status)
 	return err
 }
 
 func (r *hewanKurbanRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM hewan_kurban WHERE id = $1`, id)
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM hewan_kurban WHERE id = $1`, id)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return errors.New("Hewan kurban already has distribusi daging")
+		}
 		return err
 	}
 
@@ -88,4 +132,77 @@ func (r *hewanKurbanRepository) Delete(ctx context.Context, id uuid.UUID) error
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// GetSiapJadwal mengambil hewan berstatus lunas yang belum punya jadwal penyembelihan
+func (r *hewanKurbanRepository) GetSiapJadwal(ctx context.Context) ([]model.HewanSiapJadwal, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT h.id, h.jenis, h.is_private, h.tanggal_pendaftaran,
+		       (SELECT MAX(rs.created_at) FROM riwayat_status_hewan rs WHERE rs.hewan_id = h.id AND rs.ke_status = 'lunas'),
+		       EXISTS (SELECT 1 FROM pekurban_hewan ph WHERE ph.hewan_id = h.id AND ph.ingin_hadir AND `+activeShareCondition+`)
+		FROM hewan_kurban h
+		WHERE h.status = 'lunas' AND NOT EXISTS (SELECT 1 FROM penyembelihan ps WHERE ps.hewan_id = h.id)
+		ORDER BY h.tanggal_pendaftaran, h.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.HewanSiapJadwal
+	for rows.Next() {
+		var h model.HewanSiapJadwal
+		if err := rows.Scan(&h.ID, &h.Jenis, &h.IsPrivate, &h.TanggalPendaftaran, &h.LunasAt, &h.InginHadir); err != nil {
+			return nil, err
+		}
+		result = append(result, h)
+	}
+	return result, rows.Err()
+}
+
+func (r *hewanKurbanRepository) GetPublicCatalog(ctx context.Context, f model.PublicHewanFilter) ([]model.PublicHewan, error) {
+	args := []any{}
+	clauses := []string{"h.is_private = FALSE"}
+	if f.Jenis != "" {
+		args = append(args, f.Jenis)
+		clauses = append(clauses, fmt.Sprintf("h.jenis = $%d", len(args)))
+	}
+	if f.MinHarga != nil {
+		args = append(args, *f.MinHarga)
+		clauses = append(clauses, fmt.Sprintf("h.harga / j.max_porsi >= $%d", len(args)))
+	}
+	if f.MaxHarga != nil {
+		args = append(args, *f.MaxHarga)
+		clauses = append(clauses, fmt.Sprintf("h.harga / j.max_porsi <= $%d", len(args)))
+	}
+
+	q := fmt.Sprintf(`
+	SELECT h.id, h.jenis, h.berat, h.harga,
+	       j.max_porsi AS kapasitas,
+	       COALESCE(CEIL(SUM(ph.porsi) * j.max_porsi), 0)::int AS terisi,
+	       h.foto_url, p.tanggal_penyembelihan, l.nama
+	FROM hewan_kurban h
+	JOIN jenis_hewan j ON j.nama = h.jenis
+	LEFT JOIN pekurban_hewan ph ON ph.hewan_id = h.id AND `+activeShareCondition+`
+	LEFT JOIN penyembelihan p ON p.hewan_id = h.id
+	LEFT JOIN lokasi l ON l.id = p.lokasi_id
+	WHERE %s
+	GROUP BY h.id, j.max_porsi, p.tanggal_penyembelihan, l.nama
+	ORDER BY h.tanggal_pendaftaran, h.created_at
+	`, strings.Join(clauses, " AND "))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.PublicHewan{}
+	for rows.Next() {
+		var h model.PublicHewan
+		if err := rows.Scan(&h.ID, &h.Jenis, &h.Berat, &h.Harga, &h.KapasitasPorsi, &h.PorsiTerisi, &h.FotoURL, &h.TanggalPenyembelihan, &h.Lokasi); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type JenisHewanRepository interface {
+	Create(ctx context.Context, j *model.JenisHewanMaster) error
+	GetAll(ctx context.Context) ([]*model.JenisHewanMaster, error)
+	GetByNama(ctx context.Context, nama string) (*model.JenisHewanMaster, error)
+	Update(ctx context.Context, j *model.JenisHewanMaster) error
+	Delete(ctx context.Context, nama string) error
+}
+
+type jenisHewanRepository struct {
+	db *sql.DB
+}
+
+func NewJenisHewanRepository(db *sql.DB) JenisHewanRepository {
+	return &jenisHewanRepository{db: db}
+}
+
+func (r *jenisHewanRepository) Create(ctx context.Context, j *model.JenisHewanMaster) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO jenis_hewan (nama, max_porsi, min_umur_bulan, harga_default, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		j.Nama, j.MaxPorsi, j.MinUmurBulan, j.HargaDefault, j.Created_At, j.Updated_At)
+	return err
+}
+
+func (r *jenisHewanRepository) GetAll(ctx context.Context) ([]*model.JenisHewanMaster, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT nama, max_porsi, min_umur_bulan, harga_default, created_at, updated_at FROM jenis_hewan ORDER BY max_porsi DESC, nama`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.JenisHewanMaster
+	for rows.Next() {
+		var j model.JenisHewanMaster
+		if err := rows.Scan(&j.Nama, &j.MaxPorsi, &j.MinUmurBulan, &j.HargaDefault, &j.Created_At, &j.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &j)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *jenisHewanRepository) GetByNama(ctx context.Context, nama string) (*model.JenisHewanMaster, error) {
+	var j model.JenisHewanMaster
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT nama, max_porsi, min_umur_bulan, harga_default, created_at, updated_at FROM jenis_hewan WHERE nama = $1`, nama).
+		Scan(&j.Nama, &j.MaxPorsi, &j.MinUmurBulan, &j.HargaDefault, &j.Created_At, &j.Updated_At)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *jenisHewanRepository) Update(ctx context.Context, j *model.JenisHewanMaster) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE jenis_hewan SET max_porsi=$2, min_umur_bulan=$3, harga_default=$4 WHERE nama=$1`,
+		j.Nama, j.MaxPorsi, j.MinUmurBulan, j.HargaDefault)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Jenis hewan not found")
+	}
+	return nil
+}
+
+func (r *jenisHewanRepository) Delete(ctx context.Context, nama string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM jenis_hewan WHERE nama=$1`, nama)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return errors.New("Jenis hewan is still used by hewan kurban or permintaan patungan")
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Jenis hewan not found")
+	}
+	return nil
+}
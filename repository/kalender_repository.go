@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type KalenderRepository interface {
+	// GetPenyembelihan mengambil semua jadwal penyembelihan, atau hanya hewan yang porsinya terkonfirmasi milik pekurban
+	GetPenyembelihan(ctx context.Context, pekurbanID *uuid.UUID) ([]*model.JadwalPenyembelihanKalender, error)
+	// GetDistribusi mengambil semua jadwal distribusi, atau hanya yang penerimanya adalah pekurban tersebut
+	GetDistribusi(ctx context.Context, pekurbanID *uuid.UUID) ([]*model.JadwalDistribusiKalender, error)
+	SaveToken(ctx context.Context, t *model.KalenderToken) error
+	GetToken(ctx context.Context, tokenHash string) (*model.KalenderToken, error)
+}
+
+type kalenderRepository struct {
+	db *sql.DB
+}
+
+func NewKalenderRepository(db *sql.DB) KalenderRepository {
+	return &kalenderRepository{db: db}
+}
+
+func (r *kalenderRepository) GetPenyembelihan(ctx context.Context, pekurbanID *uuid.UUID) ([]*model.JadwalPenyembelihanKalender, error) {
+	q := `SELECT ps.id, ps.hewan_id, h.jenis, h.status, ps.tanggal_penyembelihan, ps.rencana_mulai, ps.mulai_at, ps.selesai_at,
+			ps.urutan_rencana, ps.jagal, l.nama, l.alamat, l.latitude, l.longitude, ps.updated_at
+		FROM penyembelihan ps
+		JOIN hewan_kurban h ON h.id = ps.hewan_id
+		JOIN lokasi l ON l.id = ps.lokasi_id`
+	args := []interface{}{}
+	if pekurbanID != nil {
+		q += ` WHERE EXISTS (SELECT 1 FROM pekurban_hewan ph WHERE ph.hewan_id = ps.hewan_id AND ph.pekurban_id = $1 AND ph.status = 'terkonfirmasi')`
+		args = append(args, *pekurbanID)
+	}
+	q += ` ORDER BY ps.tanggal_penyembelihan, l.nama, ps.urutan_rencana`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.JadwalPenyembelihanKalender
+	for rows.Next() {
+		var j model.JadwalPenyembelihanKalender
+		if err := rows.Scan(&j.ID, &j.HewanID, &j.Jenis, &j.StatusHewan, &j.Tanggal, &j.RencanaMulai, &j.MulaiAt, &j.SelesaiAt,
+			&j.UrutanRencana, &j.Jagal, &j.Lokasi, &j.Alamat, &j.Latitude, &j.Longitude, &j.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &j)
+	}
+	return result, rows.Err()
+}
+
+func (r *kalenderRepository) GetDistribusi(ctx context.Context, pekurbanID *uuid.UUID) ([]*model.JadwalDistribusiKalender, error) {
+	q := `SELECT d.id, p.name, d.jumlah_paket, d.tanggal_distribusi, l.nama, l.alamat, l.latitude, l.longitude, d.updated_at
+		FROM distribusi_daging d
+		JOIN penerima_daging p ON p.id = d.penerima_id
+		LEFT JOIN lokasi l ON l.id = d.lokasi_id`
+	args := []interface{}{}
+	if pekurbanID != nil {
+		q += ` WHERE p.pekurban_id = $1`
+		args = append(args, *pekurbanID)
+	}
+	q += ` ORDER BY d.tanggal_distribusi, p.name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.JadwalDistribusiKalender
+	for rows.Next() {
+		var j model.JadwalDistribusiKalender
+		if err := rows.Scan(&j.ID, &j.PenerimaNama, &j.JumlahPaket, &j.Tanggal, &j.Lokasi, &j.Alamat, &j.Latitude, &j.Longitude, &j.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &j)
+	}
+	return result, rows.Err()
+}
+
+// SaveToken menyimpan token baru dan menggantikan token lama milik user atau pekurban yang sama
+func (r *kalenderRepository) SaveToken(ctx context.Context, t *model.KalenderToken) error {
+	db := conn(ctx, r.db)
+	var err error
+	if t.UserID != nil {
+		_, err = db.ExecContext(ctx, `DELETE FROM kalender_token WHERE user_id = $1`, *t.UserID)
+	} else {
+		_, err = db.ExecContext(ctx, `DELETE FROM kalender_token WHERE pekurban_id = $1`, *t.PekurbanID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO kalender_token (token_hash, user_id, pekurban_id, created_at) VALUES ($1, $2, $3, $4)`,
+		t.TokenHash, t.UserID, t.PekurbanID, t.Created_At)
+	return err
+}
+
+func (r *kalenderRepository) GetToken(ctx context.Context, tokenHash string) (*model.KalenderToken, error) {
+	var t model.KalenderToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT token_hash, user_id, pekurban_id, created_at FROM kalender_token WHERE token_hash = $1`, tokenHash).
+		Scan(&t.TokenHash, &t.UserID, &t.PekurbanID, &t.Created_At)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
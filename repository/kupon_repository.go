@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type KuponRepository interface {
+	Create(ctx context.Context, k *model.KuponDistribusi) error
+	// GetAll mengambil semua kupon urut nama penerima; belumDitukar=true hanya kupon yang belum dipakai
+	GetAll(ctx context.Context, belumDitukar bool) ([]*model.KuponDistribusi, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.KuponDistribusi, error)
+	// LockById sama dengan GetById namun mengunci baris kupon sampai transaksi selesai agar tidak ditukar dua kali
+	LockById(ctx context.Context, id uuid.UUID) (*model.KuponDistribusi, error)
+	// GetAktifByPenerimaID mengambil kupon penerima yang belum ditukar; setiap penerima paling banyak punya satu
+	GetAktifByPenerimaID(ctx context.Context, penerimaID uuid.UUID) (*model.KuponDistribusi, error)
+	Tukar(ctx context.Context, id, distribusiID uuid.UUID, oleh *uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type kuponRepository struct {
+	db *sql.DB
+}
+
+func NewKuponRepository(db *sql.DB) KuponRepository {
+	return &kuponRepository{db: db}
+}
+
+const kuponSelect = `SELECT k.id, k.penerima_id, p.name, p.alamat, p.status, k.jumlah_paket, k.tanggal_distribusi, k.lokasi_id, l.nama,
+		k.distribusi_id, k.ditukar_at, k.ditukar_oleh, k.created_at, k.updated_at
+	FROM kupon_distribusi k
+	JOIN penerima_daging p ON p.id = k.penerima_id
+	LEFT JOIN lokasi l ON l.id = k.lokasi_id`
+
+var errKuponExists = errors.New("Penerima already has an unredeemed kupon")
+
+func scanKupon(row interface{ Scan(...interface{}) error }) (*model.KuponDistribusi, error) {
+	var k model.KuponDistribusi
+	if err := row.Scan(&k.ID, &k.PenerimaID, &k.PenerimaNama, &k.PenerimaAlamat, &k.PenerimaStatus, &k.JumlahPaket, &k.TanggalDistribusi, &k.LokasiID, &k.LokasiNama,
+		&k.DistribusiID, &k.DitukarAt, &k.DitukarOleh, &k.Created_At, &k.Updated_At); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *kuponRepository) Create(ctx context.Context, k *model.KuponDistribusi) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO kupon_distribusi (id, penerima_id, jumlah_paket, tanggal_distribusi, lokasi_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		k.ID, k.PenerimaID, k.JumlahPaket, k.TanggalDistribusi, k.LokasiID, k.Created_At, k.Updated_At)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errKuponExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *kuponRepository) GetAll(ctx context.Context, belumDitukar bool) ([]*model.KuponDistribusi, error) {
+	q := kuponSelect
+	if belumDitukar {
+		q += ` WHERE k.ditukar_at IS NULL`
+	}
+	q += ` ORDER BY p.name, k.created_at`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.KuponDistribusi
+	for rows.Next() {
+		k, err := scanKupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, k)
+	}
+	return result, rows.Err()
+}
+
+func (r *kuponRepository) GetById(ctx context.Context, id uuid.UUID) (*model.KuponDistribusi, error) {
+	return r.getOne(ctx, kuponSelect+` WHERE k.id = $1`, id)
+}
+
+func (r *kuponRepository) LockById(ctx context.Context, id uuid.UUID) (*model.KuponDistribusi, error) {
+	// hanya baris kupon yang dikunci; lokasi berada di sisi LEFT JOIN sehingga tidak bisa ikut dikunci
+	return r.getOne(ctx, kuponSelect+` WHERE k.id = $1 FOR UPDATE OF k`, id)
+}
+
+func (r *kuponRepository) GetAktifByPenerimaID(ctx context.Context, penerimaID uuid.UUID) (*model.KuponDistribusi, error) {
+	return r.getOne(ctx, kuponSelect+` WHERE k.penerima_id = $1 AND k.ditukar_at IS NULL`, penerimaID)
+}
+
+func (r *kuponRepository) getOne(ctx context.Context, q string, arg interface{}) (*model.KuponDistribusi, error) {
+	k, err := scanKupon(conn(ctx, r.db).QueryRowContext(ctx, q, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *kuponRepository) Tukar(ctx context.Context, id, distribusiID uuid.UUID, oleh *uuid.UUID, at time.Time) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE kupon_distribusi SET distribusi_id = $2, ditukar_at = $3, ditukar_oleh = $4
+		WHERE id = $1 AND ditukar_at IS NULL`, id, distribusiID, at, oleh)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Kupon not found or already redeemed")
+	}
+	return nil
+}
+
+// Delete hanya menghapus kupon yang belum ditukar; kupon yang sudah dipakai menjadi bukti penukaran
+func (r *kuponRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM kupon_distribusi WHERE id = $1 AND ditukar_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Kupon not found or already redeemed")
+	}
+	return nil
+}
@@ -14,11 +14,14 @@ type ReportRepository interface {
 	// blok pekurban
 	GetPekurbanAggregates(ctx context.Context, f model.ReportFilter) ([]model.PekurbanAggregate, error)
 	GetPekurbanHewanDetails(ctx context.Context, f model.ReportFilter) ([]model.PekurbanHewanDetail, error)
+	GetAtasNamaDetails(ctx context.Context, f model.ReportFilter) ([]model.AtasNama, error)
 
 	// rekap
 	GetHewanAggregate(ctx context.Context, f model.ReportFilter) ([]model.HewanAggregate, error)
 	GetDistribusiAggregate(ctx context.Context, f model.ReportFilter) ([]model.DistribusiAggregate, error)
 	GetPembayaranAggregate(ctx context.Context, f model.ReportFilter) ([]model.PembayaranAggregate, error)
+	GetRendemenAggregate(ctx context.Context, f model.ReportFilter) ([]model.RendemenAggregate, error)
+	GetLokasiAggregate(ctx context.Context, f model.ReportFilter) ([]model.LokasiAggregate, error)
 
 	// summary counts
 	CountPekurban(ctx context.Context) (int, error)
@@ -87,7 +90,7 @@ func (r *reportRepository) GetPekurbanAggregates(ctx context.Context, f model.Re
 		GROUP BY pk.pekurban_id
 	), kewajiban AS (
 		SELECT ph.pekurban_id,
-		       COALESCE(SUM( CASE WHEN hk.is_private = FALSE THEN (ph.porsi * hk.harga) ELSE 0 END ),0) AS total_kewajiban,
+		       COALESCE(SUM( CASE WHEN hk.is_private = FALSE THEN ROUND(hk.harga * ph.porsi_pembilang / ph.porsi_penyebut, 2) ELSE 0 END ),0) AS total_kewajiban,
 		       COUNT(DISTINCT ph.hewan_id) AS total_hewan
 		FROM pekurban_hewan ph
 		JOIN hewan_kurban hk ON hk.id = ph.hewan_id
@@ -153,6 +156,35 @@ func (r *reportRepository) GetPekurbanHewanDetails(ctx context.Context, f model.
 	return out, rows.Err()
 }
 
+func (r *reportRepository) GetAtasNamaDetails(ctx context.Context, f model.ReportFilter) ([]model.AtasNama, error) {
+	args := []any{}
+	where := betweenClause("hk.tanggal_pendaftaran", f, &args)
+
+	q := fmt.Sprintf(`
+	SELECT an.id, an.pekurban_id, an.hewan_id, an.nama, an.hubungan, an.masih_hidup, an.created_at, an.updated_at
+	FROM atas_nama_kurban an
+	JOIN hewan_kurban hk ON hk.id = an.hewan_id
+	%s
+	ORDER BY an.pekurban_id, an.created_at
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.AtasNama{}
+	for rows.Next() {
+		var a model.AtasNama
+		if err := rows.Scan(&a.ID, &a.PekurbanID, &a.HewanID, &a.Nama, &a.Hubungan, &a.MasihHidup, &a.Created_At, &a.Updated_At); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
 // ================= Rekap =================
 
 func (r *reportRepository) GetHewanAggregate(ctx context.Context, f model.ReportFilter) ([]model.HewanAggregate, error) {
@@ -247,6 +279,93 @@ func (r *reportRepository) GetPembayaranAggregate(ctx context.Context, f model.R
 	return out, rows.Err()
 }
 
+// GetRendemenAggregate merekap hasil penyembelihan per jenis; persentase terhadap berat terdaftar
+// hanya menghitung hewan yang bobot bagian tersebut sudah dicatat
+func (r *reportRepository) GetRendemenAggregate(ctx context.Context, f model.ReportFilter) ([]model.RendemenAggregate, error) {
+	args := []any{}
+	where := betweenClause("ps.tanggal_penyembelihan", f, &args)
+	where = appendCondition(where, "(ps.berat_hidup IS NOT NULL OR ps.berat_karkas IS NOT NULL OR ps.berat_daging IS NOT NULL)")
+
+	q := fmt.Sprintf(`
+	SELECT hk.jenis::text, COUNT(*) AS total,
+	       COALESCE(SUM(hk.berat),0), COALESCE(SUM(ps.berat_hidup),0), COALESCE(SUM(ps.berat_karkas),0),
+	       COALESCE(SUM(ps.berat_daging),0), COALESCE(SUM(ps.berat_tulang),0), COALESCE(SUM(ps.berat_jeroan),0),
+	       COALESCE(SUM(ps.jumlah_paket),0),
+	       ROUND(AVG(ps.berat_karkas / NULLIF(ps.berat_hidup,0) * 100), 2),
+	       ROUND(SUM(ps.berat_karkas) / NULLIF(SUM(hk.berat) FILTER (WHERE ps.berat_karkas IS NOT NULL),0) * 100, 2),
+	       ROUND(SUM(ps.berat_daging) / NULLIF(SUM(hk.berat) FILTER (WHERE ps.berat_daging IS NOT NULL),0) * 100, 2)
+	FROM penyembelihan ps
+	JOIN hewan_kurban hk ON hk.id = ps.hewan_id
+	%s
+	GROUP BY hk.jenis
+	ORDER BY hk.jenis
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.RendemenAggregate{}
+	for rows.Next() {
+		var a model.RendemenAggregate
+		if err := rows.Scan(&a.Jenis, &a.TotalHewan, &a.BeratTerdaftar, &a.BeratHidup, &a.BeratKarkas,
+			&a.BeratDaging, &a.BeratTulang, &a.BeratJeroan, &a.JumlahPaket,
+			&a.RendemenKarkas, &a.KarkasTerhadapTerdaftar, &a.DagingTerhadapTerdaftar); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetLokasiAggregate merekap penyembelihan dan distribusi per lokasi; lokasi tanpa kegiatan tetap tampil
+// dengan angka nol supaya semua titik terlihat di laporan
+func (r *reportRepository) GetLokasiAggregate(ctx context.Context, f model.ReportFilter) ([]model.LokasiAggregate, error) {
+	args := []any{}
+	psWhere := betweenClause("ps.tanggal_penyembelihan", f, &args)
+	ddWhere := betweenClause("dd.tanggal_distribusi", f, &args)
+
+	q := fmt.Sprintf(`
+	SELECT l.nama, l.tipe,
+	       COALESCE(p.dijadwalkan,0), COALESCE(p.disembelih,0), COALESCE(p.berat_daging,0), COALESCE(p.jumlah_paket,0),
+	       COALESCE(d.total,0), COALESCE(d.jumlah_paket,0)
+	FROM lokasi l
+	LEFT JOIN (
+		SELECT ps.lokasi_id, COUNT(*) AS dijadwalkan, COUNT(ps.urutan_aktual) AS disembelih,
+		       SUM(ps.berat_daging) AS berat_daging, SUM(ps.jumlah_paket) AS jumlah_paket
+		FROM penyembelihan ps
+		%s
+		GROUP BY ps.lokasi_id
+	) p ON p.lokasi_id = l.id
+	LEFT JOIN (
+		SELECT dd.lokasi_id, COUNT(*) AS total, SUM(dd.jumlah_paket) AS jumlah_paket
+		FROM distribusi_daging dd
+		%s
+		GROUP BY dd.lokasi_id
+	) d ON d.lokasi_id = l.id
+	ORDER BY l.nama
+	`, psWhere, ddWhere)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.LokasiAggregate{}
+	for rows.Next() {
+		var a model.LokasiAggregate
+		if err := rows.Scan(&a.Nama, &a.Tipe, &a.HewanDijadwalkan, &a.HewanDisembelih, &a.BeratDaging,
+			&a.PaketDihasilkan, &a.TotalDistribusi, &a.PaketDistribusi); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
 // ================= Summary counts =================
 
 func (r *reportRepository) CountPekurban(ctx context.Context) (int, error) {
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type LokasiRepository interface {
+	Create(ctx context.Context, l *model.Lokasi) error
+	GetAll(ctx context.Context, tipe string) ([]*model.Lokasi, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.Lokasi, error)
+	Update(ctx context.Context, l *model.Lokasi) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Merge(ctx context.Context, fromID, toID uuid.UUID) error
+}
+
+type lokasiRepository struct {
+	db *sql.DB
+}
+
+func NewLokasiRepository(db *sql.DB) LokasiRepository {
+	return &lokasiRepository{db: db}
+}
+
+const lokasiColumns = `id, nama, alamat, latitude, longitude, kapasitas, kontak_nama, kontak_phone, tipe, created_at, updated_at`
+
+var errLokasiExists = errors.New("Lokasi with this nama already exists")
+
+func scanLokasi(row interface{ Scan(...interface{}) error }) (*model.Lokasi, error) {
+	var l model.Lokasi
+	if err := row.Scan(&l.ID, &l.Nama, &l.Alamat, &l.Latitude, &l.Longitude, &l.Kapasitas, &l.KontakNama, &l.KontakPhone, &l.Tipe, &l.Created_At, &l.Updated_At); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *lokasiRepository) Create(ctx context.Context, l *model.Lokasi) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO lokasi (`+lokasiColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		l.ID, l.Nama, l.Alamat, l.Latitude, l.Longitude, l.Kapasitas, l.KontakNama, l.KontakPhone, l.Tipe, l.Created_At, l.Updated_At)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errLokasiExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAll mengambil semua lokasi; tipe penyembelihan/distribusi ikut menyertakan lokasi bertipe keduanya
+func (r *lokasiRepository) GetAll(ctx context.Context, tipe string) ([]*model.Lokasi, error) {
+	q := `SELECT ` + lokasiColumns + ` FROM lokasi`
+	args := []interface{}{}
+	if tipe != "" {
+		q += ` WHERE tipe = $1 OR tipe = 'keduanya'`
+		args = append(args, tipe)
+	}
+	q += ` ORDER BY nama`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Lokasi
+	for rows.Next() {
+		l, err := scanLokasi(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, l)
+	}
+	return result, rows.Err()
+}
+
+func (r *lokasiRepository) GetById(ctx context.Context, id uuid.UUID) (*model.Lokasi, error) {
+	l, err := scanLokasi(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+lokasiColumns+` FROM lokasi WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return l, nil
+}
+
+func (r *lokasiRepository) Update(ctx context.Context, l *model.Lokasi) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE lokasi SET nama=$2, alamat=$3, latitude=$4, longitude=$5, kapasitas=$6, kontak_nama=$7, kontak_phone=$8, tipe=$9 WHERE id=$1`,
+		l.ID, l.Nama, l.Alamat, l.Latitude, l.Longitude, l.Kapasitas, l.KontakNama, l.KontakPhone, l.Tipe)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errLokasiExists
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Lokasi not found")
+	}
+	return nil
+}
+
+func (r *lokasiRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM lokasi WHERE id=$1`, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return errors.New("Lokasi is still used by penyembelihan or distribusi")
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Lokasi not found")
+	}
+	return nil
+}
+
+// Merge memindahkan semua penyembelihan, shift petugas, distribusi, dan kupon dari lokasi asal ke lokasi tujuan lalu menghapus
+// lokasi asal; dipakai untuk menyatukan lokasi ganda akibat salah ketik. Harus dijalankan dalam transaksi.
+func (r *lokasiRepository) Merge(ctx context.Context, fromID, toID uuid.UUID) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `UPDATE penyembelihan SET lokasi_id=$2 WHERE lokasi_id=$1`, fromID, toID); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE shift SET lokasi_id=$2 WHERE lokasi_id=$1`, fromID, toID); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE distribusi_daging SET lokasi_id=$2 WHERE lokasi_id=$1`, fromID, toID); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE kupon_distribusi SET lokasi_id=$2 WHERE lokasi_id=$1`, fromID, toID); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE batch_antar SET lokasi_id=$2 WHERE lokasi_id=$1`, fromID, toID); err != nil {
+		return err
+	}
+	return r.Delete(ctx, fromID)
+}
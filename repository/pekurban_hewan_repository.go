@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 )
 
@@ -16,8 +17,27 @@ type PekurbanHewanRepository interface {
 	GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PekurbanHewanJoin, error)
 	Update(ctx context.Context, ph *model.PekurbanHewan) error
 	Delete(ctx context.Context, pekurbanID, hewanID uuid.UUID) error
+	ConfirmByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) (int64, error)
+	Confirm(ctx context.Context, pekurbanID, hewanID uuid.UUID, porsi model.Pecahan) error
+	DeleteExpiredHolds(ctx context.Context) ([]uuid.UUID, error)
+	Transfer(ctx context.Context, fromPekurbanID, toPekurbanID, hewanID uuid.UUID) error
+	SetKehadiran(ctx context.Context, pekurbanID, hewanID uuid.UUID, inginHadir bool) error
 }
 
+// porsi dihitung aktif jika sudah terkonfirmasi atau masih dalam masa tahan
+const activeShareCondition = `(ph.status = 'terkonfirmasi' OR ph.expires_at > now())`
+
+// insert porsi baru; baris lama milik pasangan yang sama boleh ditimpa hanya jika porsi ditahannya sudah kedaluwarsa
+const insertPekurbanHewanQuery = `
+	INSERT INTO pekurban_hewan (pekurban_id, hewan_id, porsi_pembilang, porsi_penyebut, status, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (pekurban_id, hewan_id) DO UPDATE SET porsi_pembilang = EXCLUDED.porsi_pembilang, porsi_penyebut = EXCLUDED.porsi_penyebut, status = EXCLUDED.status, expires_at = EXCLUDED.expires_at
+	WHERE pekurban_hewan.status = 'ditahan' AND pekurban_hewan.expires_at <= now()`
+
+var errShareExists = errors.New("Pekurban already has a share in this hewan")
+
+// ErrPorsiExceeded dikembalikan ketika trigger database menolak total porsi aktif hewan melebihi 1
+var ErrPorsiExceeded = errors.New("Total portion exceeds the maximum limit")
+
 type pekurbanHewanRepository struct {
 	db *sql.DB
 }
@@ -27,16 +47,24 @@ func NewPekurbanHewanRepository(db *sql.DB) PekurbanHewanRepository {
 }
 
 func (r *pekurbanHewanRepository) Create(ctx context.Context, ph *model.PekurbanHewan) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO pekurban_hewan (pekurban_id, hewan_id, porsi) VALUES ($1, $2, $3)`, ph.PekurbanID, ph.HewanID, ph.Porsi)
-	return err
+	res, err := conn(ctx, r.db).ExecContext(ctx, insertPekurbanHewanQuery, ph.PekurbanID, ph.HewanID, ph.Porsi.Pembilang, ph.Porsi.Penyebut, ph.Status, ph.ExpiresAt)
+	if err != nil {
+		return porsiError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errShareExists
+	}
+	return nil
 }
 
 func (r *pekurbanHewanRepository) FindAll(ctx context.Context) ([]*model.PekurbanHewanJoin, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, ph.porsi
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, j.max_porsi, ph.porsi_pembilang, ph.porsi_penyebut, ph.status, ph.expires_at
         FROM pekurban_hewan ph
         JOIN pekurban p ON ph.pekurban_id = p.id
-        JOIN hewan_kurban h ON ph.hewan_id = h.id`,
+        JOIN hewan_kurban h ON ph.hewan_id = h.id
+        JOIN jenis_hewan j ON j.nama = h.jenis
+		WHERE `+activeShareCondition,
 	)
 	if err != nil {
 		return nil, err
@@ -60,12 +88,13 @@ func (r *pekurbanHewanRepository) FindAll(ctx context.Context) ([]*model.Pekurba
 }
 
 func (r *pekurbanHewanRepository) GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.PekurbanHewanJoin, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, ph.porsi
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, j.max_porsi, ph.porsi_pembilang, ph.porsi_penyebut, ph.status, ph.expires_at
         FROM pekurban_hewan ph
         JOIN pekurban p ON ph.pekurban_id = p.id
         JOIN hewan_kurban h ON ph.hewan_id = h.id 
-		WHERE hewan_id = $1`, hewanID)
+        JOIN jenis_hewan j ON j.nama = h.jenis
+		WHERE hewan_id = $1 AND `+activeShareCondition, hewanID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,12 +116,13 @@ func (r *pekurbanHewanRepository) GetByHewanId(ctx context.Context, hewanID uuid
 }
 
 func (r *pekurbanHewanRepository) GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PekurbanHewanJoin, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, ph.porsi
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ph.pekurban_id, p.name, ph.hewan_id, h.jenis, j.max_porsi, ph.porsi_pembilang, ph.porsi_penyebut, ph.status, ph.expires_at
         FROM pekurban_hewan ph
         JOIN pekurban p ON ph.pekurban_id = p.id
         JOIN hewan_kurban h ON ph.hewan_id = h.id 
-		WHERE pekurban_id = $1`, pekurbanID)
+        JOIN jenis_hewan j ON j.nama = h.jenis
+		WHERE pekurban_id = $1 AND `+activeShareCondition, pekurbanID)
 	if err != nil {
 		return nil, err
 	}
@@ -114,12 +144,12 @@ func (r *pekurbanHewanRepository) GetByPekurbanId(ctx context.Context, pekurbanI
 }
 
 func (r *pekurbanHewanRepository) Update(ctx context.Context, ph *model.PekurbanHewan) error {
-	result, err := r.db.ExecContext(ctx,
-		`UPDATE pekurban_hewan SET porsi=$3 WHERE pekurban_id=$1 AND hewan_id=$2`,
-		ph.PekurbanID, ph.HewanID, ph.Porsi)
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE pekurban_hewan SET porsi_pembilang=$3, porsi_penyebut=$4 WHERE pekurban_id=$1 AND hewan_id=$2`,
+		ph.PekurbanID, ph.HewanID, ph.Porsi.Pembilang, ph.Porsi.Penyebut)
 
 	if err != nil {
-		return err
+		return porsiError(err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -134,7 +164,7 @@ func (r *pekurbanHewanRepository) Update(ctx context.Context, ph *model.Pekurban
 }
 
 func (r *pekurbanHewanRepository) Delete(ctx context.Context, pekurbanID, hewanID uuid.UUID) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM pekurban_hewan WHERE pekurban_id=$1 AND hewan_id=$2`, pekurbanID, hewanID)
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM pekurban_hewan WHERE pekurban_id=$1 AND hewan_id=$2`, pekurbanID, hewanID)
 	if err != nil {
 		return err
 	}
@@ -150,6 +180,104 @@ func (r *pekurbanHewanRepository) Delete(ctx context.Context, pekurbanID, hewanI
 	return nil
 }
 
+func (r *pekurbanHewanRepository) SetKehadiran(ctx context.Context, pekurbanID, hewanID uuid.UUID, inginHadir bool) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE pekurban_hewan ph SET ingin_hadir=$3 WHERE ph.pekurban_id=$1 AND ph.hewan_id=$2 AND `+activeShareCondition, pekurbanID, hewanID, inginHadir)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Patungan not found")
+	}
+
+	return nil
+}
+
+// ConfirmByPekurbanId mengubah semua porsi ditahan milik pekurban yang belum kedaluwarsa menjadi terkonfirmasi
+func (r *pekurbanHewanRepository) ConfirmByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE pekurban_hewan SET status = 'terkonfirmasi', expires_at = NULL
+		WHERE pekurban_id = $1 AND status = 'ditahan' AND expires_at > now()`, pekurbanID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Confirm mengonfirmasi satu porsi yang sudah dibayar sebesar porsi yang ditagihkan. Porsi ditahan, termasuk yang
+// sudah kedaluwarsa tetapi belum dilepas sweeper, diubah menjadi terkonfirmasi; porsi yang sudah dilepas dibuat ulang.
+// Pemanggil wajib mengunci hewan dan memeriksa kapasitas lebih dulu.
+func (r *pekurbanHewanRepository) Confirm(ctx context.Context, pekurbanID, hewanID uuid.UUID, porsi model.Pecahan) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO pekurban_hewan (pekurban_id, hewan_id, porsi_pembilang, porsi_penyebut, status) VALUES ($1, $2, $3, $4, 'terkonfirmasi')
+		ON CONFLICT (pekurban_id, hewan_id) DO UPDATE SET porsi_pembilang = EXCLUDED.porsi_pembilang, porsi_penyebut = EXCLUDED.porsi_penyebut,
+			status = 'terkonfirmasi', expires_at = NULL
+		WHERE pekurban_hewan.status = 'ditahan'`, pekurbanID, hewanID, porsi.Pembilang, porsi.Penyebut)
+	return porsiError(err)
+}
+
+// DeleteExpiredHolds melepas porsi ditahan yang sudah lewat batas waktu; permintaan antrean yang
+// terpasang ke porsi tersebut ikut ditandai kedaluwarsa. Mengembalikan hewan yang porsinya dilepas.
+func (r *pekurbanHewanRepository) DeleteExpiredHolds(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		WITH released AS (
+			DELETE FROM pekurban_hewan
+			WHERE status = 'ditahan' AND expires_at <= now()
+			RETURNING pekurban_id, hewan_id
+		), expired AS (
+			UPDATE permintaan_patungan pp SET status = 'kedaluwarsa'
+			FROM released r
+			WHERE pp.pekurban_id = r.pekurban_id AND pp.hewan_id = r.hewan_id AND pp.status = 'terpenuhi'
+		)
+		SELECT hewan_id FROM released`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hewanIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		hewanIDs = append(hewanIDs, id)
+	}
+	return hewanIDs, rows.Err()
+}
+
+// Transfer memindahkan kepemilikan porsi ke pekurban lain; atas nama ikut berpindah lewat ON UPDATE CASCADE
+func (r *pekurbanHewanRepository) Transfer(ctx context.Context, fromPekurbanID, toPekurbanID, hewanID uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE pekurban_hewan ph SET pekurban_id = $2
+		WHERE ph.pekurban_id = $1 AND ph.hewan_id = $3 AND `+activeShareCondition, fromPekurbanID, toPekurbanID, hewanID)
+	if err != nil {
+		return porsiError(err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("data not found")
+	}
+	return nil
+}
+
+// porsiError menerjemahkan penolakan trigger cek_total_porsi_hewan menjadi ErrPorsiExceeded
+func porsiError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "pekurban_hewan_total_porsi_check" {
+		return ErrPorsiExceeded
+	}
+	return err
+}
+
 func scanPekurbanHewan(rows *sql.Rows) (*model.PekurbanHewanJoin, error) {
 	var ph model.PekurbanHewanJoin
 	err := rows.Scan(
@@ -157,7 +285,11 @@ func scanPekurbanHewan(rows *sql.Rows) (*model.PekurbanHewanJoin, error) {
 		&ph.Pekurban,
 		&ph.HewanID,
 		&ph.Hewan,
-		&ph.Porsi,
+		&ph.Kapasitas,
+		&ph.Porsi.Pembilang,
+		&ph.Porsi.Penyebut,
+		&ph.Status,
+		&ph.ExpiresAt,
 	)
 	if err != nil {
 		return nil, err
@@ -14,6 +14,7 @@ type PekurbanRepository interface {
 	FindAll(ctx context.Context) ([]*model.Pekurban, error)
 	FindById(ctx context.Context, id uuid.UUID) (*model.Pekurban, error)
 	FindByUserId(ctx context.Context, userID uuid.UUID) (*model.Pekurban, error)
+	FindByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.Pekurban, error)
 	Update(ctx context.Context, p *model.Pekurban) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -83,6 +84,31 @@ func (r *pekurbanRepository) FindByUserId(ctx context.Context, userID uuid.UUID)
 	return &p, nil
 }
 
+// FindByHewanId mengambil pekurban yang porsinya di hewan sudah terkonfirmasi; email kosong diisi email akun yang ditautkan
+func (r *pekurbanRepository) FindByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.Pekurban, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.user_id, p.name, p.phone, COALESCE(NULLIF(p.email, ''), u.email), p.alamat, p.created_at, p.updated_at
+		FROM pekurban p
+		JOIN pekurban_hewan ph ON ph.pekurban_id = p.id
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE ph.hewan_id = $1 AND ph.status = 'terkonfirmasi'
+		ORDER BY p.name`, hewanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Pekurban
+	for rows.Next() {
+		var p model.Pekurban
+		if err := rows.Scan(&p.ID, &p.UserId, &p.Name, &p.Phone, &p.Email, &p.Alamat, &p.Created_At, &p.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
 func (r *pekurbanRepository) Update(ctx context.Context, p *model.Pekurban) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE pekurban SET name=$1, phone=$2, email=$3, alamat=$4 WHERE id=$5`, p.Name, p.Phone, p.Email, p.Alamat, p.ID)
 	return err
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type PembatalanPatunganRepository interface {
+	Create(ctx context.Context, p *model.PembatalanPatungan) error
+	GetAll(ctx context.Context) ([]*model.PembatalanPatungan, error)
+	GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PembatalanPatungan, error)
+	LockById(ctx context.Context, id uuid.UUID) (*model.PembatalanPatungan, error)
+	Process(ctx context.Context, p *model.PembatalanPatungan) error
+	SumRefundByPekurban(ctx context.Context, pekurbanID uuid.UUID) (float64, error)
+}
+
+type pembatalanPatunganRepository struct {
+	db *sql.DB
+}
+
+func NewPembatalanPatunganRepository(db *sql.DB) PembatalanPatunganRepository {
+	return &pembatalanPatunganRepository{db: db}
+}
+
+const selectPembatalanPatungan = `
+	SELECT b.id, b.pekurban_id, b.hewan_id, b.porsi_pembilang, b.porsi_penyebut, b.alasan, b.status, b.kebijakan, b.persentase_refund,
+	       b.jumlah_dibayar, b.jumlah_refund, b.diproses_oleh, b.catatan_admin, b.diproses_at, b.created_at, b.updated_at,
+	       COALESCE(p.name, ''), h.jenis
+	FROM pembatalan_patungan b
+	JOIN pekurban p ON p.id = b.pekurban_id
+	JOIN hewan_kurban h ON h.id = b.hewan_id`
+
+func (r *pembatalanPatunganRepository) Create(ctx context.Context, p *model.PembatalanPatungan) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO pembatalan_patungan (id, pekurban_id, hewan_id, porsi_pembilang, porsi_penyebut, alasan, status, kebijakan, persentase_refund, jumlah_dibayar, jumlah_refund, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		p.ID, p.PekurbanID, p.HewanID, p.Porsi.Pembilang, p.Porsi.Penyebut, p.Alasan, p.Status, p.Kebijakan, p.PersentaseRefund, p.JumlahDibayar, p.JumlahRefund, p.Created_At, p.Updated_At)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errors.New("A cancellation request for this share is already pending")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *pembatalanPatunganRepository) GetAll(ctx context.Context) ([]*model.PembatalanPatungan, error) {
+	return r.query(ctx, selectPembatalanPatungan+` ORDER BY b.created_at DESC`)
+}
+
+func (r *pembatalanPatunganRepository) GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PembatalanPatungan, error) {
+	return r.query(ctx, selectPembatalanPatungan+` WHERE b.pekurban_id = $1 ORDER BY b.created_at DESC`, pekurbanID)
+}
+
+// LockById mengambil pengajuan dengan SELECT ... FOR UPDATE agar tidak diproses dua kali; dipanggil di dalam TxManager.WithinTx
+func (r *pembatalanPatunganRepository) LockById(ctx context.Context, id uuid.UUID) (*model.PembatalanPatungan, error) {
+	list, err := r.query(ctx, selectPembatalanPatungan+` WHERE b.id = $1 FOR UPDATE OF b`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+// Process menyimpan keputusan admin; hanya pengajuan yang masih menunggu yang bisa diproses
+func (r *pembatalanPatunganRepository) Process(ctx context.Context, p *model.PembatalanPatungan) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE pembatalan_patungan
+		SET status=$2, kebijakan=$3, persentase_refund=$4, jumlah_dibayar=$5, jumlah_refund=$6, diproses_oleh=$7, catatan_admin=$8, diproses_at=$9
+		WHERE id=$1 AND status='menunggu'`,
+		p.ID, p.Status, p.Kebijakan, p.PersentaseRefund, p.JumlahDibayar, p.JumlahRefund, p.DiprosesOleh, p.CatatanAdmin, p.DiprosesAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Cancellation request has already been processed")
+	}
+	return nil
+}
+
+// SumRefundByPekurban menjumlahkan refund dari pembatalan yang sudah disetujui
+func (r *pembatalanPatunganRepository) SumRefundByPekurban(ctx context.Context, pekurbanID uuid.UUID) (float64, error) {
+	var total float64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(SUM(jumlah_refund), 0) FROM pembatalan_patungan WHERE pekurban_id = $1 AND status = 'disetujui'`, pekurbanID).Scan(&total)
+	return total, err
+}
+
+func (r *pembatalanPatunganRepository) query(ctx context.Context, q string, args ...interface{}) ([]*model.PembatalanPatungan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.PembatalanPatungan
+	for rows.Next() {
+		var p model.PembatalanPatungan
+		if err := rows.Scan(&p.ID, &p.PekurbanID, &p.HewanID, &p.Porsi.Pembilang, &p.Porsi.Penyebut, &p.Alasan, &p.Status, &p.Kebijakan, &p.PersentaseRefund,
+			&p.JumlahDibayar, &p.JumlahRefund, &p.DiprosesOleh, &p.CatatanAdmin, &p.DiprosesAt, &p.Created_At, &p.Updated_At,
+			&p.Pekurban, &p.Hewan); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
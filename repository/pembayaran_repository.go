@@ -5,6 +5,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
@@ -18,6 +20,17 @@ type PembayaranKurbanRepository interface {
 	GetTotalPembayaranPerHewan(ctx context.Context) ([]model.TotalPembayaranPerHewan, error)
 	IsHewanLunas(ctx context.Context, hewanID uuid.UUID) (bool, error)
 	GetProgressPembayaranPekurban(ctx context.Context) ([]model.ProgressPembayaran, error)
+	UpdateStatus(ctx context.Context, orderID, status string, fraudStatus *string) error
+	SumSettlementByPekurban(ctx context.Context, pekurbanID uuid.UUID) (float64, error)
+	SplitSettlement(ctx context.Context, fromPekurbanID, toPekurbanID uuid.UUID, jumlah float64) (float64, error)
+	CreatePorsi(ctx context.Context, list []*model.PembayaranPorsi) error
+	GetPorsi(ctx context.Context, pembayaranID uuid.UUID) ([]*model.PembayaranPorsi, error)
+	UpdatePorsiStatus(ctx context.Context, pembayaranID, hewanID uuid.UUID, status string) error
+	BatalkanPorsi(ctx context.Context, pekurbanID, hewanID uuid.UUID) error
+	PindahkanPorsi(ctx context.Context, fromPekurbanID, toPekurbanID, hewanID uuid.UUID) error
+	SumPorsiDibayar(ctx context.Context, pekurbanID, hewanID uuid.UUID) (float64, error)
+	HasPorsi(ctx context.Context, pekurbanID uuid.UUID) (bool, error)
+	SetPerluRefund(ctx context.Context, id uuid.UUID) error
 }
 
 type pembayaranRepo struct {
@@ -29,34 +42,34 @@ func NewPembayaranKurbanRepository(db *sql.DB) PembayaranKurbanRepository {
 }
 
 func (r *pembayaranRepo) Create(ctx context.Context, p *model.PembayaranKurban) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO pembayaran_kurban (
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO pembayaran_kurban (
 		id, order_id, transaction_id, pekurban_id, metode, payment_type, va_number, status, fraud_status,
-		approval_code, transaction_time, tanggal_pembayaran, jumlah, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`,
+		approval_code, transaction_time, tanggal_pembayaran, jumlah, asal_pembayaran_id, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
 		p.ID, p.OrderID, p.TransactionID, p.PekurbanID, p.Metode, p.PaymentType, p.VANumber,
 		p.Status, p.FraudStatus, p.ApprovalCode, p.TransactionTime, p.TanggalPembayaran, p.Jumlah,
-		p.Created_At, p.Updated_At,
+		p.AsalPembayaranID, p.Created_At, p.Updated_At,
 	)
 	return err
 }
 
 func (r *pembayaranRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.PembayaranKurban, error) {
 	row := r.db.QueryRowContext(ctx, `SELECT id, order_id, transaction_id, pekurban_id, metode, payment_type, va_number,
-		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, created_at, updated_at
+		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, asal_pembayaran_id, perlu_refund, created_at, updated_at
 		FROM pembayaran_kurban WHERE id = $1`, id)
 	return scanPembayaran(row)
 }
 
 func (r *pembayaranRepo) FindByOrderID(ctx context.Context, orderID string) (*model.PembayaranKurban, error) {
 	row := r.db.QueryRowContext(ctx, `SELECT id, order_id, transaction_id, pekurban_id, metode, payment_type, va_number,
-		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, created_at, updated_at
+		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, asal_pembayaran_id, perlu_refund, created_at, updated_at
 		FROM pembayaran_kurban WHERE order_id = $1`, orderID)
 	return scanPembayaran(row)
 }
 
 func (r *pembayaranRepo) GetAll(ctx context.Context) ([]*model.PembayaranKurban, error) {
 	rows, err := r.db.QueryContext(ctx, `SELECT id, order_id, transaction_id, pekurban_id, metode, payment_type, va_number,
-		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, created_at, updated_at
+		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, asal_pembayaran_id, perlu_refund, created_at, updated_at
 		FROM pembayaran_kurban`)
 	if err != nil {
 		return nil, err
@@ -68,7 +81,7 @@ func (r *pembayaranRepo) GetAll(ctx context.Context) ([]*model.PembayaranKurban,
 		p := new(model.PembayaranKurban)
 		err := rows.Scan(
 			&p.ID, &p.OrderID, &p.TransactionID, &p.PekurbanID, &p.Metode, &p.PaymentType, &p.VANumber,
-			&p.Status, &p.FraudStatus, &p.ApprovalCode, &p.TransactionTime, &p.TanggalPembayaran, &p.Jumlah,
+			&p.Status, &p.FraudStatus, &p.ApprovalCode, &p.TransactionTime, &p.TanggalPembayaran, &p.Jumlah, &p.AsalPembayaranID, &p.PerluRefund,
 			&p.Created_At, &p.Updated_At,
 		)
 		if err != nil {
@@ -85,7 +98,7 @@ func (r *pembayaranRepo) GetTotalPembayaranPerHewan(ctx context.Context) ([]mode
 		h.id AS hewan_id,
 		h.jenis,
 		h.harga AS harga_target,
-		COALESCE(SUM(ph.porsi * h.harga), 0) AS total_masuk,
+		COALESCE(ROUND(SUM(h.harga * ph.porsi_pembilang / ph.porsi_penyebut), 2), 0) AS total_masuk,
 		h.is_private
 	FROM hewan_kurban h
 	LEFT JOIN pekurban_hewan ph ON h.id = ph.hewan_id
@@ -116,7 +129,7 @@ func (r *pembayaranRepo) IsHewanLunas(ctx context.Context, hewanID uuid.UUID) (b
 	query := `
 	SELECT 
 		(h.is_private = true) OR 
-		(COALESCE(SUM(ph.porsi * h.harga), 0) >= h.harga) AS is_lunas
+		(COALESCE(ROUND(SUM(h.harga * ph.porsi_pembilang / ph.porsi_penyebut), 2), 0) >= h.harga) AS is_lunas
 	FROM hewan_kurban h
 	LEFT JOIN pekurban_hewan ph ON h.id = ph.hewan_id
 	LEFT JOIN pembayaran_kurban pk ON ph.pekurban_id = pk.pekurban_id
@@ -139,9 +152,9 @@ func (r *pembayaranRepo) GetProgressPembayaranPekurban(ctx context.Context) ([]m
 		p.id AS pekurban_id,
 		COALESCE(p.name, 'Tanpa Nama') AS nama_pekurban,
 		COALESCE(SUM(ph.porsi), 0) AS total_porsi,
-		COALESCE(SUM(ph.porsi * h.harga), 0) AS total_tagihan,
+		COALESCE(SUM(ROUND(h.harga * ph.porsi_pembilang / ph.porsi_penyebut, 2)), 0) AS total_tagihan,
 		COALESCE((
-			SELECT SUM(h.harga * ph2.porsi)
+			SELECT SUM(ROUND(h.harga * ph2.porsi_pembilang / ph2.porsi_penyebut, 2))
 			FROM pembayaran_kurban pk2
 			JOIN pekurban_hewan ph2 ON ph2.pekurban_id = pk2.pekurban_id
 			JOIN hewan_kurban h ON h.id = ph2.hewan_id
@@ -171,12 +184,184 @@ func (r *pembayaranRepo) GetProgressPembayaranPekurban(ctx context.Context) ([]m
 	return result, nil
 }
 
+func (r *pembayaranRepo) UpdateStatus(ctx context.Context, orderID, status string, fraudStatus *string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE pembayaran_kurban SET status = $2, fraud_status = COALESCE($3, fraud_status) WHERE order_id = $1`,
+		orderID, status, fraudStatus)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("order ID not found")
+	}
+	return nil
+}
+
+func (r *pembayaranRepo) SumSettlementByPekurban(ctx context.Context, pekurbanID uuid.UUID) (float64, error) {
+	var total float64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(SUM(jumlah), 0) FROM pembayaran_kurban WHERE pekurban_id = $1 AND status = 'settlement'`, pekurbanID).Scan(&total)
+	return total, err
+}
+
+// SplitSettlement memindahkan pembayaran settlement senilai jumlah ke pekurban lain, dimulai dari pembayaran terbaru.
+// Pembayaran yang hanya terpakai sebagian dipecah: jumlahnya dikurangi dan selisihnya dicatat sebagai baris baru
+// milik pekurban tujuan yang menunjuk pembayaran asal. Pembayaran lain tetap milik pengirim. Panggil di dalam transaksi.
+func (r *pembayaranRepo) SplitSettlement(ctx context.Context, fromPekurbanID, toPekurbanID uuid.UUID, jumlah float64) (float64, error) {
+	db := conn(ctx, r.db)
+	rows, err := db.QueryContext(ctx, `SELECT id, order_id, transaction_id, pekurban_id, metode, payment_type, va_number,
+		status, fraud_status, approval_code, transaction_time, tanggal_pembayaran, jumlah, asal_pembayaran_id, perlu_refund, created_at, updated_at
+		FROM pembayaran_kurban WHERE pekurban_id = $1 AND status = 'settlement'
+		ORDER BY tanggal_pembayaran DESC, created_at DESC FOR UPDATE`, fromPekurbanID)
+	if err != nil {
+		return 0, err
+	}
+	var list []*model.PembayaranKurban
+	for rows.Next() {
+		p := new(model.PembayaranKurban)
+		if err := rows.Scan(
+			&p.ID, &p.OrderID, &p.TransactionID, &p.PekurbanID, &p.Metode, &p.PaymentType, &p.VANumber,
+			&p.Status, &p.FraudStatus, &p.ApprovalCode, &p.TransactionTime, &p.TanggalPembayaran, &p.Jumlah, &p.AsalPembayaranID, &p.PerluRefund,
+			&p.Created_At, &p.Updated_At,
+		); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// dihitung dalam sen agar pecahan tidak menyisakan selisih pembulatan
+	sisa := int64(math.Round(jumlah * 100))
+	var dipindah int64
+	for _, p := range list {
+		if sisa <= 0 {
+			break
+		}
+		sen := int64(math.Round(p.Jumlah * 100))
+		if sen <= sisa {
+			if _, err := db.ExecContext(ctx, `UPDATE pembayaran_kurban SET pekurban_id = $2 WHERE id = $1`, p.ID, toPekurbanID); err != nil {
+				return 0, err
+			}
+			sisa -= sen
+			dipindah += sen
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, `UPDATE pembayaran_kurban SET jumlah = $2 WHERE id = $1`, p.ID, float64(sen-sisa)/100); err != nil {
+			return 0, err
+		}
+		now := time.Now()
+		pecahan := *p
+		pecahan.ID = uuid.New()
+		suffix := "-" + pecahan.ID.String()[:8]
+		pecahan.OrderID = p.OrderID + suffix
+		pecahan.TransactionID = p.TransactionID + suffix
+		pecahan.PekurbanID = toPekurbanID
+		pecahan.Jumlah = float64(sisa) / 100
+		pecahan.AsalPembayaranID = &p.ID
+		pecahan.Created_At = now
+		pecahan.Updated_At = now
+		if err := r.Create(ctx, &pecahan); err != nil {
+			return 0, err
+		}
+		dipindah += sisa
+		sisa = 0
+	}
+	return float64(dipindah) / 100, nil
+}
+
+// CreatePorsi mencatat porsi yang ditagihkan pada pembayaran; panggil bersama Create di dalam transaksi
+func (r *pembayaranRepo) CreatePorsi(ctx context.Context, list []*model.PembayaranPorsi) error {
+	db := conn(ctx, r.db)
+	for _, p := range list {
+		_, err := db.ExecContext(ctx, `INSERT INTO pembayaran_porsi (pembayaran_id, hewan_id, pekurban_id, porsi_pembilang, porsi_penyebut, tagihan, status) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			p.PembayaranID, p.HewanID, p.PekurbanID, p.Porsi.Pembilang, p.Porsi.Penyebut, p.Tagihan, p.Status)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pembayaranRepo) GetPorsi(ctx context.Context, pembayaranID uuid.UUID) ([]*model.PembayaranPorsi, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT pembayaran_id, hewan_id, pekurban_id, porsi_pembilang, porsi_penyebut, tagihan, status
+		FROM pembayaran_porsi WHERE pembayaran_id = $1`, pembayaranID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.PembayaranPorsi
+	for rows.Next() {
+		var p model.PembayaranPorsi
+		if err := rows.Scan(&p.PembayaranID, &p.HewanID, &p.PekurbanID, &p.Porsi.Pembilang, &p.Porsi.Penyebut, &p.Tagihan, &p.Status); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
+func (r *pembayaranRepo) UpdatePorsiStatus(ctx context.Context, pembayaranID, hewanID uuid.UUID, status string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE pembayaran_porsi SET status = $3 WHERE pembayaran_id = $1 AND hewan_id = $2`, pembayaranID, hewanID, status)
+	return err
+}
+
+// BatalkanPorsi menutup catatan porsi milik pekurban pada hewan: yang belum settle menjadi dibatalkan sehingga
+// settlement yang datang belakangan tidak menghidupkan kembali porsinya, yang sudah settle menjadi ditutup
+// sehingga tidak dialokasikan lagi ke porsi lain
+func (r *pembayaranRepo) BatalkanPorsi(ctx context.Context, pekurbanID, hewanID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE pembayaran_porsi SET status = CASE status WHEN 'menunggu' THEN 'dibatalkan' ELSE 'ditutup' END
+		WHERE pekurban_id = $1 AND hewan_id = $2 AND status IN ('menunggu', 'terkonfirmasi')`, pekurbanID, hewanID)
+	return err
+}
+
+// PindahkanPorsi memindahkan kepemilikan catatan porsi pada hewan ke pekurban penerima transfer
+func (r *pembayaranRepo) PindahkanPorsi(ctx context.Context, fromPekurbanID, toPekurbanID, hewanID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE pembayaran_porsi SET pekurban_id = $2
+		WHERE pekurban_id = $1 AND hewan_id = $3 AND status IN ('menunggu', 'terkonfirmasi')`, fromPekurbanID, toPekurbanID, hewanID)
+	return err
+}
+
+// SumPorsiDibayar menjumlahkan tagihan porsi pekurban pada hewan yang sudah dibayar lewat pembayaran settlement
+func (r *pembayaranRepo) SumPorsiDibayar(ctx context.Context, pekurbanID, hewanID uuid.UUID) (float64, error) {
+	var total float64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(pp.tagihan), 0)
+		FROM pembayaran_porsi pp
+		JOIN pembayaran_kurban pk ON pk.id = pp.pembayaran_id
+		WHERE pp.pekurban_id = $1 AND pp.hewan_id = $2 AND pp.status = 'terkonfirmasi' AND pk.status = 'settlement'`, pekurbanID, hewanID).Scan(&total)
+	return total, err
+}
+
+// HasPorsi memeriksa apakah pekurban memiliki catatan porsi pembayaran; pembayaran lama belum mencatatnya
+func (r *pembayaranRepo) HasPorsi(ctx context.Context, pekurbanID uuid.UUID) (bool, error) {
+	var ada bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pembayaran_porsi WHERE pekurban_id = $1)`, pekurbanID).Scan(&ada)
+	return ada, err
+}
+
+// SetPerluRefund menandai pembayaran yang sudah settle tetapi porsinya tidak bisa dikonfirmasi
+func (r *pembayaranRepo) SetPerluRefund(ctx context.Context, id uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE pembayaran_kurban SET perlu_refund = TRUE WHERE id = $1`, id)
+	return err
+}
 
 func scanPembayaran(row *sql.Row) (*model.PembayaranKurban, error) {
 	var p model.PembayaranKurban
 	err := row.Scan(
 		&p.ID, &p.OrderID, &p.TransactionID, &p.PekurbanID, &p.Metode, &p.PaymentType, &p.VANumber,
-		&p.Status, &p.FraudStatus, &p.ApprovalCode, &p.TransactionTime, &p.TanggalPembayaran, &p.Jumlah,
+		&p.Status, &p.FraudStatus, &p.ApprovalCode, &p.TransactionTime, &p.TanggalPembayaran, &p.Jumlah, &p.AsalPembayaranID, &p.PerluRefund,
 		&p.Created_At, &p.Updated_At,
 	)
 	if err != nil {
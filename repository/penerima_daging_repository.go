@@ -12,6 +12,9 @@ import (
 type PenerimaDagingRepository interface {
 	Create(ctx context.Context, p *model.PenerimaDaging) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.PenerimaDaging, error)
+	// LockById sama dengan GetByID namun mengunci baris penerima sampai transaksi selesai,
+	// dipakai untuk menyerialkan pencatatan distribusi penerima yang sama
+	LockById(ctx context.Context, id uuid.UUID) (*model.PenerimaDaging, error)
 	GetAll(ctx context.Context) ([]*model.PenerimaDaging, error)
 	Update(ctx context.Context, p *model.PenerimaDaging) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -26,17 +29,17 @@ func NewPenerimaDagingRepository(db *sql.DB) PenerimaDagingRepository {
 }
 
 func (r *penerimaDagingRepository) Create(ctx context.Context, p *model.PenerimaDaging ) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO penerima_daging (id, name, alamat, phone, status, pekurban_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, p.ID, p.Name, p.Alamat, p.Phone, p.Status, p.PekurbanID, p.Created_At, p.Updated_At)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO penerima_daging (id, name, alamat, phone, rt, rw, antar_rumah, status, pekurban_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, p.ID, p.Name, p.Alamat, p.Phone, p.RT, p.RW, p.AntarRumah, p.Status, p.PekurbanID, p.Created_At, p.Updated_At)
 	return err
 }
 
 func (r *penerimaDagingRepository) Update(ctx context.Context, p *model.PenerimaDaging) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE penerima_daging SET name=$2, alamat=$3, phone=$4, status=$5, pekurban_id=$6 WHERE id = $1`, p.ID, p.Name, p.Alamat, p.Phone, p.Status, p.PekurbanID)
+	_, err := r.db.ExecContext(ctx, `UPDATE penerima_daging SET name=$2, alamat=$3, phone=$4, rt=$5, rw=$6, antar_rumah=$7, status=$8, pekurban_id=$9 WHERE id = $1`, p.ID, p.Name, p.Alamat, p.Phone, p.RT, p.RW, p.AntarRumah, p.Status, p.PekurbanID)
 	return err
 }
 
 func (r *penerimaDagingRepository) GetAll(ctx context.Context) ([]*model.PenerimaDaging, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, name, alamat, phone, status, pekurban_id, created_at, updated_at FROM penerima_daging`)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id, name, alamat, phone, rt, rw, antar_rumah, status, pekurban_id, created_at, updated_at FROM penerima_daging`)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +48,7 @@ func (r *penerimaDagingRepository) GetAll(ctx context.Context) ([]*model.Penerim
 	var result []*model.PenerimaDaging
 	for rows.Next() {
 		var p model.PenerimaDaging
-		if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.RT, &p.RW, &p.AntarRumah, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At); err != nil {
 			return nil, err
 		}
 
@@ -56,10 +59,24 @@ func (r *penerimaDagingRepository) GetAll(ctx context.Context) ([]*model.Penerim
 }
 
 func (r *penerimaDagingRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.PenerimaDaging, error) {
-	rows := r.db.QueryRowContext(ctx, "SELECT id, name, alamat, phone, status, pekurban_id, created_at, updated_at FROM penerima_daging WHERE id = $1", id)
+	rows := r.db.QueryRowContext(ctx, "SELECT id, name, alamat, phone, rt, rw, antar_rumah, status, pekurban_id, created_at, updated_at FROM penerima_daging WHERE id = $1", id)
 
 	var p model.PenerimaDaging
-	if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At); err != nil {
+	if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.RT, &p.RW, &p.AntarRumah, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *penerimaDagingRepository) LockById(ctx context.Context, id uuid.UUID) (*model.PenerimaDaging, error) {
+	rows := conn(ctx, r.db).QueryRowContext(ctx, "SELECT id, name, alamat, phone, rt, rw, antar_rumah, status, pekurban_id, created_at, updated_at FROM penerima_daging WHERE id = $1 FOR UPDATE", id)
+
+	var p model.PenerimaDaging
+	if err := rows.Scan(&p.ID, &p.Name, &p.Alamat, &p.Phone, &p.RT, &p.RW, &p.AntarRumah, &p.Status, &p.PekurbanID, &p.Created_At, &p.Updated_At); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
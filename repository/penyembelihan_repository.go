@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
@@ -15,9 +16,31 @@ type PenyembelihanRepository interface {
 	GetById(ctx context.Context, id uuid.UUID) (*model.Penyembelihan, error)
 	GetByHewanID(ctx context.Context, hewanID uuid.UUID) (*model.Penyembelihan, error)
 	Update(ctx context.Context, p *model.Penyembelihan) error
+	UpdateHasil(ctx context.Context, p *model.Penyembelihan) error
+	GetBelumDisembelih(ctx context.Context) ([]*model.Penyembelihan, error)
+	GetAntrean(ctx context.Context, tanggal time.Time) ([]*model.AntreanPenyembelihan, error)
+	GetByTanggal(ctx context.Context, tanggal time.Time) ([]*model.Penyembelihan, error)
+	GetByJagal(ctx context.Context, petugasID uuid.UUID, dari time.Time) ([]*model.Penyembelihan, error)
+	SetJagal(ctx context.Context, id uuid.UUID, jagalID uuid.UUID, nama string) error
+	Mulai(ctx context.Context, p *model.Penyembelihan) error
+	TandaiBuktiLengkap(ctx context.Context, id uuid.UUID, waktu time.Time) error
+	Selesai(ctx context.Context, p *model.Penyembelihan) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+const penyembelihanColumns = `ps.id, ps.hewan_id, ps.tanggal_penyembelihan, ps.lokasi_id, l.nama, ps.urutan_rencana, ps.urutan_aktual,
+	ps.rencana_mulai, ps.mulai_at, ps.selesai_at, ps.jagal_id, ps.jagal, ps.saksi, ps.bukti_lengkap_at,
+	ps.berat_hidup, ps.berat_karkas, ps.berat_daging, ps.berat_tulang, ps.berat_jeroan, ps.jumlah_paket, ps.created_at, ps.updated_at`
+
+const penyembelihanFrom = `penyembelihan ps JOIN lokasi l ON l.id = ps.lokasi_id`
+
+// penyembelihanFields mengembalikan tujuan Scan sesuai urutan penyembelihanColumns
+func penyembelihanFields(p *model.Penyembelihan) []interface{} {
+	return []interface{}{&p.ID, &p.HewanID, &p.TglPenyembelihan, &p.LokasiID, &p.Lokasi, &p.UrutanRencana, &p.UrutanAktual,
+		&p.RencanaMulai, &p.MulaiAt, &p.SelesaiAt, &p.JagalID, &p.Jagal, &p.Saksi, &p.BuktiLengkapAt,
+		&p.BeratHidup, &p.BeratKarkas, &p.BeratDaging, &p.BeratTulang, &p.BeratJeroan, &p.JumlahPaket, &p.Created_At, &p.Updated_At}
+}
+
 type penyembelihanRepository struct{
 	db *sql.DB
 }
@@ -27,13 +50,13 @@ func NewPenyembelihanRepository(db *sql.DB) PenyembelihanRepository {
 }
 
 func (r *penyembelihanRepository) Create(ctx context.Context, p *model.Penyembelihan) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO penyembelihan (id, hewan_id, tanggal_penyembelihan, lokasi, urutan_rencana, urutan_aktual, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, p.ID, p.HewanID, p.TglPenyembelihan, p.Lokasi,
-		p.UrutanRencana, p.UrutanAktual, p.Created_At, p.Updated_At)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO penyembelihan (id, hewan_id, tanggal_penyembelihan, lokasi_id, urutan_rencana, urutan_aktual, rencana_mulai, jagal, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`, p.ID, p.HewanID, p.TglPenyembelihan, p.LokasiID,
+		p.UrutanRencana, p.UrutanAktual, p.RencanaMulai, p.Jagal, p.Created_At, p.Updated_At)
 	return err
 }
 
 func (r *penyembelihanRepository) GetAll(ctx context.Context) ([]*model.Penyembelihan, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, hewan_id, tanggal_penyembelihan, lokasi, urutan_rencana, urutan_aktual, created_at, updated_at FROM penyembelihan`)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+penyembelihanColumns+` FROM `+penyembelihanFrom)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -46,8 +69,7 @@ func (r *penyembelihanRepository) GetAll(ctx context.Context) ([]*model.Penyembe
 	var result []*model.Penyembelihan
 	for rows.Next(){
 		var p model.Penyembelihan
-		if err := rows.Scan(&p.ID, &p.HewanID, &p.TglPenyembelihan, &p.Lokasi,
-		&p.UrutanRencana, &p.UrutanAktual, &p.Created_At, &p.Updated_At); err != nil {
+		if err := rows.Scan(penyembelihanFields(&p)...); err != nil {
 			return nil, err
 		}
 
@@ -57,22 +79,188 @@ func (r *penyembelihanRepository) GetAll(ctx context.Context) ([]*model.Penyembe
 	return result, nil
 }
 
+// GetBelumDisembelih mengambil jadwal yang urutan aktualnya belum diisi beserta jenis hewannya
+func (r *penyembelihanRepository) GetBelumDisembelih(ctx context.Context) ([]*model.Penyembelihan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+penyembelihanColumns+`, h.jenis FROM `+penyembelihanFrom+`
+		JOIN hewan_kurban h ON h.id = ps.hewan_id
+		WHERE ps.urutan_aktual IS NULL
+		ORDER BY ps.tanggal_penyembelihan, l.nama, ps.urutan_rencana`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Penyembelihan
+	for rows.Next() {
+		var p model.Penyembelihan
+		if err := rows.Scan(append(penyembelihanFields(&p), &p.JenisHewan)...); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
+// GetAntrean mengambil seluruh jadwal pada satu tanggal, yang sudah disembelih lebih dulu sesuai urutan aktual
+// lalu sisanya sesuai urutan rencana
+func (r *penyembelihanRepository) GetAntrean(ctx context.Context, tanggal time.Time) ([]*model.AntreanPenyembelihan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ps.hewan_id, h.jenis, h.status, ps.lokasi_id, l.nama, ps.urutan_rencana, ps.urutan_aktual, ps.mulai_at,
+		       COALESCE(ps.selesai_at, (SELECT MAX(r.created_at) FROM riwayat_status_hewan r WHERE r.hewan_id = ps.hewan_id AND r.ke_status = 'disembelih'))
+		FROM `+penyembelihanFrom+`
+		JOIN hewan_kurban h ON h.id = ps.hewan_id
+		WHERE ps.tanggal_penyembelihan = $1
+		ORDER BY l.nama, ps.urutan_aktual NULLS LAST, ps.mulai_at NULLS LAST, ps.urutan_rencana`, tanggal.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.AntreanPenyembelihan
+	for rows.Next() {
+		var a model.AntreanPenyembelihan
+		if err := rows.Scan(&a.HewanID, &a.Jenis, &a.Status, &a.LokasiID, &a.Lokasi, &a.UrutanRencana, &a.UrutanAktual, &a.MulaiAt, &a.DisembelihAt); err != nil {
+			return nil, err
+		}
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
+
+// GetByTanggal mengambil semua jadwal pada satu tanggal beserta jenis hewannya
+func (r *penyembelihanRepository) GetByTanggal(ctx context.Context, tanggal time.Time) ([]*model.Penyembelihan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+penyembelihanColumns+`, h.jenis FROM `+penyembelihanFrom+`
+		JOIN hewan_kurban h ON h.id = ps.hewan_id
+		WHERE ps.tanggal_penyembelihan = $1
+		ORDER BY l.nama, ps.urutan_aktual NULLS LAST, ps.urutan_rencana`, tanggal.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Penyembelihan
+	for rows.Next() {
+		var p model.Penyembelihan
+		if err := rows.Scan(append(penyembelihanFields(&p), &p.JenisHewan)...); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
+// GetByJagal mengambil penyembelihan yang ditugaskan ke petugas jagal mulai tanggal dari
+func (r *penyembelihanRepository) GetByJagal(ctx context.Context, petugasID uuid.UUID, dari time.Time) ([]*model.Penyembelihan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+penyembelihanColumns+`, h.jenis FROM `+penyembelihanFrom+`
+		JOIN hewan_kurban h ON h.id = ps.hewan_id
+		WHERE ps.jagal_id = $1 AND ps.tanggal_penyembelihan >= $2
+		ORDER BY ps.tanggal_penyembelihan, ps.rencana_mulai NULLS LAST, ps.urutan_rencana`, petugasID, dari.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Penyembelihan
+	for rows.Next() {
+		var p model.Penyembelihan
+		if err := rows.Scan(append(penyembelihanFields(&p), &p.JenisHewan)...); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
+// SetJagal menugaskan petugas jagal; nama ikut disimpan supaya riwayat tetap terbaca jika petugas dihapus
+func (r *penyembelihanRepository) SetJagal(ctx context.Context, id uuid.UUID, jagalID uuid.UUID, nama string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE penyembelihan SET jagal_id=$2, jagal=$3 WHERE id = $1`, id, jagalID, nama)
+	return err
+}
+
+// Mulai mencatat check-in awal penyembelihan; ditolak jika sudah pernah dimulai
+func (r *penyembelihanRepository) Mulai(ctx context.Context, p *model.Penyembelihan) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE penyembelihan SET mulai_at=$2, jagal=$3, saksi=$4 WHERE id = $1 AND mulai_at IS NULL`, p.ID, p.MulaiAt, p.Jagal, p.Saksi)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("Penyembelihan has already started")
+	}
+	return nil
+}
+
+// TandaiBuktiLengkap menandai bukti penyembelihan lengkap; ditolak jika sudah ditandai atau belum ada bukti
+func (r *penyembelihanRepository) TandaiBuktiLengkap(ctx context.Context, id uuid.UUID, waktu time.Time) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE penyembelihan SET bukti_lengkap_at=$2
+		WHERE id = $1 AND bukti_lengkap_at IS NULL AND EXISTS (SELECT 1 FROM bukti_penyembelihan b WHERE b.penyembelihan_id = $1)`, id, waktu)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("Bukti penyembelihan is already complete or empty")
+	}
+	return nil
+}
+
+// Selesai mencatat check-in akhir lalu menomori ulang urutan_aktual penyembelihan pada tanggal dan lokasi yang
+// sama menurut selesai_at, sehingga check-in yang waktunya diisi mundur tetap mendapat urutan yang benar.
+// Urutan lama tanpa selesai_at (diisi manual) tetap di depan dengan urutan semula. Baris lokasi dikunci supaya
+// dua check-in bersamaan tidak saling menimpa penomoran; harus dijalankan dalam transaksi.
+func (r *penyembelihanRepository) Selesai(ctx context.Context, p *model.Penyembelihan) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `SELECT 1 FROM lokasi WHERE id = $1 FOR UPDATE`, p.LokasiID); err != nil {
+		return err
+	}
+
+	var tanggal time.Time
+	err := db.QueryRowContext(ctx, `
+		UPDATE penyembelihan SET selesai_at=$2, saksi=$3, urutan_aktual=0
+		WHERE id = $1 AND mulai_at IS NOT NULL AND selesai_at IS NULL AND urutan_aktual IS NULL
+		RETURNING tanggal_penyembelihan`, p.ID, p.SelesaiAt, p.Saksi).Scan(&tanggal)
+	if err == sql.ErrNoRows {
+		return errors.New("Penyembelihan has not started or is already finished")
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		UPDATE penyembelihan ps SET urutan_aktual = r.urutan
+		FROM (
+			SELECT x.id, ROW_NUMBER() OVER (ORDER BY x.selesai_at NULLS FIRST, x.urutan_aktual, x.id) AS urutan
+			FROM penyembelihan x
+			WHERE x.tanggal_penyembelihan = $1 AND x.lokasi_id = $2 AND x.urutan_aktual IS NOT NULL
+		) r
+		WHERE ps.id = r.id AND ps.urutan_aktual IS DISTINCT FROM r.urutan`, tanggal, p.LokasiID); err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, `SELECT urutan_aktual FROM penyembelihan WHERE id = $1`, p.ID).Scan(&p.UrutanAktual)
+}
+
 func (r *penyembelihanRepository) GetByHewanID(ctx context.Context, hewanID uuid.UUID) (*model.Penyembelihan, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, hewan_id, tanggal_penyembelihan, lokasi, urutan_rencana, urutan_aktual, created_at, updated_at FROM penyembelihan WHERE hewan_id = $1 LIMIT 1`, hewanID)
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+penyembelihanColumns+` FROM `+penyembelihanFrom+` WHERE ps.hewan_id = $1 LIMIT 1`, hewanID)
 
 	var p model.Penyembelihan
-	if err := row.Scan(&p.ID, &p.HewanID, &p.TglPenyembelihan, &p.Lokasi, &p.UrutanRencana, &p.UrutanAktual, &p.Created_At, &p.Updated_At);err != nil {
+	if err := row.Scan(penyembelihanFields(&p)...);err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
 func (r *penyembelihanRepository) GetById(ctx context.Context, id uuid.UUID) (*model.Penyembelihan, error) {
-	rows := r.db.QueryRowContext(ctx, `SELECT id, hewan_id, tanggal_penyembelihan, lokasi, urutan_rencana, urutan_aktual, created_at, updated_at FROM penyembelihan WHERE id = $1`, id)
+	rows := conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+penyembelihanColumns+` FROM `+penyembelihanFrom+` WHERE ps.id = $1`, id)
 
 	var p model.Penyembelihan
-	if err := rows.Scan(&p.ID, &p.HewanID, &p.TglPenyembelihan, &p.Lokasi,
-		&p.UrutanRencana, &p.UrutanAktual, &p.Created_At, &p.Updated_At); err != nil {
+	if err := rows.Scan(penyembelihanFields(&p)...); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, nil
 			}
@@ -82,12 +270,17 @@ func (r *penyembelihanRepository) GetById(ctx context.Context, id uuid.UUID) (*m
 }
 
 func (r *penyembelihanRepository) Update(ctx context.Context, p *model.Penyembelihan) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE penyembelihan SET tanggal_penyembelihan=$2, lokasi=$3, urutan_rencana=$4, urutan_aktual=$5 WHERE id = $1`, p.ID, p.TglPenyembelihan, p.Lokasi, p.UrutanRencana, p.UrutanAktual)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE penyembelihan SET tanggal_penyembelihan=$2, lokasi_id=$3, urutan_rencana=$4, urutan_aktual=$5, rencana_mulai=$6 WHERE id = $1`, p.ID, p.TglPenyembelihan, p.LokasiID, p.UrutanRencana, p.UrutanAktual, p.RencanaMulai)
+	return err
+}
+
+func (r *penyembelihanRepository) UpdateHasil(ctx context.Context, p *model.Penyembelihan) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE penyembelihan SET berat_hidup=$2, berat_karkas=$3, berat_daging=$4, berat_tulang=$5, berat_jeroan=$6, jumlah_paket=$7 WHERE id = $1`, p.ID, p.BeratHidup, p.BeratKarkas, p.BeratDaging, p.BeratTulang, p.BeratJeroan, p.JumlahPaket)
 	return err
 }
 
 func (r *penyembelihanRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM penyembelihan WHERE id = $1`, id)
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM penyembelihan WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
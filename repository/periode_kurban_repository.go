@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type PeriodeKurbanRepository interface {
+	Create(ctx context.Context, p *model.PeriodeKurban) error
+	GetAll(ctx context.Context) ([]*model.PeriodeKurban, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.PeriodeKurban, error)
+	GetByTahun(ctx context.Context, tahunHijri int) (*model.PeriodeKurban, error)
+	GetAktif(ctx context.Context) (*model.PeriodeKurban, error)
+	Update(ctx context.Context, p *model.PeriodeKurban) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	NonaktifkanSemua(ctx context.Context) error
+}
+
+type periodeKurbanRepository struct {
+	db *sql.DB
+}
+
+func NewPeriodeKurbanRepository(db *sql.DB) PeriodeKurbanRepository {
+	return &periodeKurbanRepository{db: db}
+}
+
+const periodeKurbanColumns = `id, tahun_hijri, offset_hari, aktif, created_at, updated_at`
+
+func scanPeriodeKurban(row interface{ Scan(...interface{}) error }) (*model.PeriodeKurban, error) {
+	var p model.PeriodeKurban
+	if err := row.Scan(&p.ID, &p.TahunHijri, &p.OffsetHari, &p.Aktif, &p.Created_At, &p.Updated_At); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func periodeKurbanError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if pqErr.Constraint == "periode_kurban_aktif_unique" {
+			return errors.New("Another periode kurban is already active")
+		}
+		return errors.New("Periode kurban for this tahun_hijri already exists")
+	}
+	return err
+}
+
+func (r *periodeKurbanRepository) Create(ctx context.Context, p *model.PeriodeKurban) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO periode_kurban (`+periodeKurbanColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		p.ID, p.TahunHijri, p.OffsetHari, p.Aktif, p.Created_At, p.Updated_At)
+	return periodeKurbanError(err)
+}
+
+func (r *periodeKurbanRepository) GetAll(ctx context.Context) ([]*model.PeriodeKurban, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+periodeKurbanColumns+` FROM periode_kurban ORDER BY tahun_hijri DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.PeriodeKurban
+	for rows.Next() {
+		p, err := scanPeriodeKurban(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+func (r *periodeKurbanRepository) getOne(ctx context.Context, where string, args ...interface{}) (*model.PeriodeKurban, error) {
+	p, err := scanPeriodeKurban(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+periodeKurbanColumns+` FROM periode_kurban WHERE `+where, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *periodeKurbanRepository) GetById(ctx context.Context, id uuid.UUID) (*model.PeriodeKurban, error) {
+	return r.getOne(ctx, `id = $1`, id)
+}
+
+func (r *periodeKurbanRepository) GetByTahun(ctx context.Context, tahunHijri int) (*model.PeriodeKurban, error) {
+	return r.getOne(ctx, `tahun_hijri = $1`, tahunHijri)
+}
+
+func (r *periodeKurbanRepository) GetAktif(ctx context.Context) (*model.PeriodeKurban, error) {
+	return r.getOne(ctx, `aktif`)
+}
+
+func (r *periodeKurbanRepository) Update(ctx context.Context, p *model.PeriodeKurban) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE periode_kurban SET tahun_hijri=$2, offset_hari=$3, aktif=$4 WHERE id = $1`,
+		p.ID, p.TahunHijri, p.OffsetHari, p.Aktif)
+	return periodeKurbanError(err)
+}
+
+func (r *periodeKurbanRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM periode_kurban WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("Periode kurban not found")
+	}
+	return nil
+}
+
+// NonaktifkanSemua dipakai sebelum mengaktifkan periode lain dalam transaksi yang sama
+func (r *periodeKurbanRepository) NonaktifkanSemua(ctx context.Context) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE periode_kurban SET aktif = FALSE WHERE aktif`)
+	return err
+}
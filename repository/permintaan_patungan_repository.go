@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type PermintaanPatunganRepository interface {
+	Create(ctx context.Context, p *model.PermintaanPatungan) error
+	GetAll(ctx context.Context) ([]*model.PermintaanPatungan, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.PermintaanPatungan, error)
+	GetByPekurbanID(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PermintaanPatungan, error)
+	GetWaiting(ctx context.Context) ([]*model.PermintaanPatungan, error)
+	GetAvailableSlots(ctx context.Context, jenis model.JenisHewan) ([]*model.HewanSlot, error)
+	Assign(ctx context.Context, hewanID uuid.UUID, reqs []*model.PermintaanPatungan, holdUntil time.Time) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to string) error
+}
+
+type permintaanPatunganRepository struct {
+	db *sql.DB
+}
+
+func NewPermintaanPatunganRepository(db *sql.DB) PermintaanPatunganRepository {
+	return &permintaanPatunganRepository{db: db}
+}
+
+const selectPermintaanPatungan = `
+	SELECT pp.id, pp.pekurban_id, COALESCE(p.name, ''), p.email, pp.jenis, pp.jumlah_porsi, pp.harga_min, pp.harga_max,
+	       pp.status, pp.hewan_id, pp.matched_at, pp.created_at, pp.updated_at
+	FROM permintaan_patungan pp
+	JOIN pekurban p ON p.id = pp.pekurban_id`
+
+func (r *permintaanPatunganRepository) Create(ctx context.Context, p *model.PermintaanPatungan) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO permintaan_patungan (id, pekurban_id, jenis, jumlah_porsi, harga_min, harga_max, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		p.ID, p.PekurbanID, p.Jenis, p.JumlahPorsi, p.HargaMin, p.HargaMax, p.Status, p.Created_At, p.Updated_At)
+	return err
+}
+
+func (r *permintaanPatunganRepository) GetAll(ctx context.Context) ([]*model.PermintaanPatungan, error) {
+	return r.query(ctx, selectPermintaanPatungan+` ORDER BY pp.created_at`)
+}
+
+func (r *permintaanPatunganRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.PermintaanPatungan, error) {
+	list, err := r.query(ctx, selectPermintaanPatungan+` WHERE pp.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (r *permintaanPatunganRepository) GetByPekurbanID(ctx context.Context, pekurbanID uuid.UUID) ([]*model.PermintaanPatungan, error) {
+	return r.query(ctx, selectPermintaanPatungan+` WHERE pp.pekurban_id = $1 ORDER BY pp.created_at`, pekurbanID)
+}
+
+func (r *permintaanPatunganRepository) GetWaiting(ctx context.Context) ([]*model.PermintaanPatungan, error) {
+	return r.query(ctx, selectPermintaanPatungan+` WHERE pp.status = $1 ORDER BY pp.created_at`, model.PermintaanMenunggu)
+}
+
+func (r *permintaanPatunganRepository) GetAvailableSlots(ctx context.Context, jenis model.JenisHewan) ([]*model.HewanSlot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT h.id, h.jenis, h.harga,
+	       j.max_porsi AS kapasitas,
+	       COALESCE(CEIL(SUM(ph.porsi) * j.max_porsi), 0)::int AS terisi,
+	       COALESCE(array_agg(ph.pekurban_id::text) FILTER (WHERE ph.pekurban_id IS NOT NULL), '{}') AS pekurban_ids
+	FROM hewan_kurban h
+	JOIN jenis_hewan j ON j.nama = h.jenis
+	LEFT JOIN pekurban_hewan ph ON ph.hewan_id = h.id AND `+activeShareCondition+`
+	WHERE h.is_private = FALSE AND h.jenis = $1
+	  AND NOT EXISTS (SELECT 1 FROM penyembelihan p WHERE p.hewan_id = h.id)
+	GROUP BY h.id, j.max_porsi
+	HAVING COALESCE(CEIL(SUM(ph.porsi) * j.max_porsi), 0) < j.max_porsi
+	ORDER BY terisi DESC, h.tanggal_pendaftaran, h.created_at`, jenis)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.HewanSlot
+	for rows.Next() {
+		var h model.HewanSlot
+		if err := rows.Scan(&h.HewanID, &h.Jenis, &h.Harga, &h.Kapasitas, &h.Terisi, pq.Array(&h.PekurbanIDs)); err != nil {
+			return nil, err
+		}
+		result = append(result, &h)
+	}
+
+	return result, rows.Err()
+}
+
+// Assign memasukkan semua permintaan ke hewan yang sama sebagai porsi ditahan.
+// Dipanggil di dalam TxManager.WithinTx agar kunci hewan, porsi, dan status permintaan tersimpan bersama.
+func (r *permintaanPatunganRepository) Assign(ctx context.Context, hewanID uuid.UUID, reqs []*model.PermintaanPatungan, holdUntil time.Time) error {
+	tx := conn(ctx, r.db)
+
+	var kapasitas int
+	err := tx.QueryRowContext(ctx, `SELECT j.max_porsi FROM hewan_kurban h JOIN jenis_hewan j ON j.nama = h.jenis WHERE h.id = $1 FOR UPDATE OF h`, hewanID).Scan(&kapasitas)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Hewan kurban not found")
+		}
+		return err
+	}
+
+	// total porsi dijumlahkan eksak sebagai pecahan, bukan dari kolom numerik turunan
+	rows, err := tx.QueryContext(ctx, `SELECT ph.porsi_pembilang, ph.porsi_penyebut FROM pekurban_hewan ph WHERE ph.hewan_id = $1 AND `+activeShareCondition, hewanID)
+	if err != nil {
+		return err
+	}
+	var shares []model.Pecahan
+	for rows.Next() {
+		var p model.Pecahan
+		if err := rows.Scan(&p.Pembilang, &p.Penyebut); err != nil {
+			rows.Close()
+			return err
+		}
+		shares = append(shares, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	porsiBaru := make([]model.Pecahan, len(reqs))
+	for i, req := range reqs {
+		p, err := model.NewPecahan(int64(req.JumlahPorsi), int64(kapasitas))
+		if err != nil {
+			return err
+		}
+		porsiBaru[i] = p
+	}
+	if model.MelebihiSatuHewan(model.TotalPecahan(append(shares, porsiBaru...)...)) {
+		return ErrPorsiExceeded
+	}
+
+	now := time.Now()
+	for i, req := range reqs {
+		ins, err := tx.ExecContext(ctx, insertPekurbanHewanQuery, req.PekurbanID, hewanID, porsiBaru[i].Pembilang, porsiBaru[i].Penyebut, model.PorsiDitahan, holdUntil)
+		if err != nil {
+			return porsiError(err)
+		}
+		if n, _ := ins.RowsAffected(); n == 0 {
+			return errShareExists
+		}
+
+		res, err := tx.ExecContext(ctx, `UPDATE permintaan_patungan SET status=$2, hewan_id=$3, matched_at=$4 WHERE id=$1 AND status=$5`, req.ID, model.PermintaanTerpenuhi, hewanID, now, model.PermintaanMenunggu)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errors.New("Permintaan patungan is no longer waiting")
+		}
+
+		req.Status = model.PermintaanTerpenuhi
+		req.HewanID = &hewanID
+		req.MatchedAt = &now
+	}
+
+	return nil
+}
+
+// UpdateStatus mengubah status hanya jika status saat ini masih from, sehingga pembatalan tidak menimpa
+// permintaan yang baru saja dipasangkan oleh Match
+func (r *permintaanPatunganRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE permintaan_patungan SET status=$3 WHERE id=$1 AND status=$2`, id, from, to)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Permintaan patungan is no longer waiting")
+	}
+
+	return nil
+}
+
+func (r *permintaanPatunganRepository) query(ctx context.Context, q string, args ...any) ([]*model.PermintaanPatungan, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.PermintaanPatungan
+	for rows.Next() {
+		var p model.PermintaanPatungan
+		if err := rows.Scan(&p.ID, &p.PekurbanID, &p.PekurbanName, &p.PekurbanEmail, &p.Jenis, &p.JumlahPorsi, &p.HargaMin, &p.HargaMax,
+			&p.Status, &p.HewanID, &p.MatchedAt, &p.Created_At, &p.Updated_At); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+
+	return result, rows.Err()
+}
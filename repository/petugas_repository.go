@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type PetugasRepository interface {
+	Create(ctx context.Context, p *model.Petugas) error
+	GetAll(ctx context.Context, peran string) ([]*model.Petugas, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.Petugas, error)
+	GetByUserId(ctx context.Context, userID uuid.UUID) (*model.Petugas, error)
+	Update(ctx context.Context, p *model.Petugas) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Lock(ctx context.Context, id uuid.UUID) error
+}
+
+type petugasRepository struct {
+	db *sql.DB
+}
+
+func NewPetugasRepository(db *sql.DB) PetugasRepository {
+	return &petugasRepository{db: db}
+}
+
+const petugasColumns = `id, nama, phone, user_id, peran, catatan, created_at, updated_at`
+
+func scanPetugas(row interface{ Scan(...interface{}) error }) (*model.Petugas, error) {
+	var p model.Petugas
+	if err := row.Scan(&p.ID, &p.Nama, &p.Phone, &p.UserID, pq.Array(&p.Peran), &p.Catatan, &p.Created_At, &p.Updated_At); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// petugasError menerjemahkan pelanggaran constraint user_id menjadi pesan yang jelas
+func petugasError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return errors.New("User is already linked to another petugas")
+		case "23503":
+			return errors.New("User not found")
+		}
+	}
+	return err
+}
+
+func (r *petugasRepository) Create(ctx context.Context, p *model.Petugas) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO petugas (`+petugasColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		p.ID, p.Nama, p.Phone, p.UserID, pq.Array(p.Peran), p.Catatan, p.Created_At, p.Updated_At)
+	return petugasError(err)
+}
+
+// GetAll mengambil semua petugas, opsional hanya yang memiliki peran tertentu
+func (r *petugasRepository) GetAll(ctx context.Context, peran string) ([]*model.Petugas, error) {
+	q := `SELECT ` + petugasColumns + ` FROM petugas`
+	args := []interface{}{}
+	if peran != "" {
+		q += ` WHERE $1 = ANY(peran)`
+		args = append(args, peran)
+	}
+	q += ` ORDER BY nama`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Petugas
+	for rows.Next() {
+		p, err := scanPetugas(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+func (r *petugasRepository) GetById(ctx context.Context, id uuid.UUID) (*model.Petugas, error) {
+	p, err := scanPetugas(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+petugasColumns+` FROM petugas WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *petugasRepository) GetByUserId(ctx context.Context, userID uuid.UUID) (*model.Petugas, error) {
+	p, err := scanPetugas(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+petugasColumns+` FROM petugas WHERE user_id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *petugasRepository) Update(ctx context.Context, p *model.Petugas) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE petugas SET nama=$2, phone=$3, user_id=$4, peran=$5, catatan=$6 WHERE id=$1`,
+		p.ID, p.Nama, p.Phone, p.UserID, pq.Array(p.Peran), p.Catatan)
+	if err != nil {
+		return petugasError(err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Petugas not found")
+	}
+	return nil
+}
+
+// Delete menghapus petugas beserta penugasan shiftnya; penyembelihan yang memakai petugas ini sebagai jagal
+// tetap menyimpan nama jagal
+func (r *petugasRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM petugas WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Petugas not found")
+	}
+	return nil
+}
+
+// Lock mengunci baris petugas sampai transaksi selesai supaya dua penugasan bersamaan tidak lolos cek bentrok
+func (r *petugasRepository) Lock(ctx context.Context, id uuid.UUID) error {
+	var one int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT 1 FROM petugas WHERE id = $1 FOR UPDATE`, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("Petugas not found")
+	}
+	return err
+}
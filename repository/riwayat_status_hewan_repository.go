@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type RiwayatStatusHewanRepository interface {
+	Create(ctx context.Context, r *model.RiwayatStatusHewan) error
+	GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.RiwayatStatusHewan, error)
+}
+
+type riwayatStatusHewanRepository struct {
+	db *sql.DB
+}
+
+func NewRiwayatStatusHewanRepository(db *sql.DB) RiwayatStatusHewanRepository {
+	return &riwayatStatusHewanRepository{db: db}
+}
+
+func (r *riwayatStatusHewanRepository) Create(ctx context.Context, h *model.RiwayatStatusHewan) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO riwayat_status_hewan (id, hewan_id, dari_status, ke_status, diubah_oleh, keterangan, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		h.ID, h.HewanID, h.DariStatus, h.KeStatus, h.DiubahOleh, h.Keterangan, h.Created_At)
+	return err
+}
+
+func (r *riwayatStatusHewanRepository) GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.RiwayatStatusHewan, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT rs.id, rs.hewan_id, rs.dari_status, rs.ke_status, rs.diubah_oleh, rs.keterangan, rs.created_at, u.name
+		FROM riwayat_status_hewan rs
+		LEFT JOIN users u ON u.id = rs.diubah_oleh
+		WHERE rs.hewan_id = $1
+		ORDER BY rs.created_at`, hewanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.RiwayatStatusHewan
+	for rows.Next() {
+		var h model.RiwayatStatusHewan
+		if err := rows.Scan(&h.ID, &h.HewanID, &h.DariStatus, &h.KeStatus, &h.DiubahOleh, &h.Keterangan, &h.Created_At, &h.NamaPengubah); err != nil {
+			return nil, err
+		}
+		result = append(result, &h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type ShiftRepository interface {
+	Create(ctx context.Context, s *model.Shift) error
+	GetAll(ctx context.Context, tanggal *time.Time, lokasiID *uuid.UUID) ([]*model.Shift, error)
+	GetById(ctx context.Context, id uuid.UUID) (*model.Shift, error)
+	Lock(ctx context.Context, id uuid.UUID) error
+	GetByPetugas(ctx context.Context, petugasID uuid.UUID, dari time.Time) ([]*model.Shift, error)
+	// GetBentrok mengambil shift lain milik petugas yang waktunya beririsan dengan [mulai, selesai)
+	GetBentrok(ctx context.Context, petugasID uuid.UUID, mulai, selesai time.Time, kecualiShiftID uuid.UUID) ([]*model.Shift, error)
+	// GetMeliputi mengambil shift petugas dengan peran tertentu di lokasi yang mencakup waktu tersebut
+	GetMeliputi(ctx context.Context, petugasID, lokasiID uuid.UUID, peran string, waktu time.Time) (*model.Shift, error)
+	Update(ctx context.Context, s *model.Shift) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Assign(ctx context.Context, shiftID, petugasID uuid.UUID) error
+	Unassign(ctx context.Context, shiftID, petugasID uuid.UUID) error
+}
+
+type shiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) ShiftRepository {
+	return &shiftRepository{db: db}
+}
+
+const shiftColumns = `s.id, s.lokasi_id, l.nama, s.peran, s.mulai, s.selesai, s.kebutuhan, s.catatan, s.created_at, s.updated_at`
+
+const shiftFrom = `shift s JOIN lokasi l ON l.id = s.lokasi_id`
+
+func scanShift(row interface{ Scan(...interface{}) error }) (*model.Shift, error) {
+	var s model.Shift
+	if err := row.Scan(&s.ID, &s.LokasiID, &s.Lokasi, &s.Peran, &s.Mulai, &s.Selesai, &s.Kebutuhan, &s.Catatan, &s.Created_At, &s.Updated_At); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *shiftRepository) query(ctx context.Context, q string, args ...interface{}) ([]*model.Shift, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Shift
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, r.loadPetugas(ctx, result)
+}
+
+// loadPetugas mengisi daftar petugas yang ditugaskan ke setiap shift
+func (r *shiftRepository) loadPetugas(ctx context.Context, shifts []*model.Shift) error {
+	if len(shifts) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(shifts))
+	byID := map[uuid.UUID]*model.Shift{}
+	for _, s := range shifts {
+		s.Petugas = []model.PenugasanShift{}
+		ids = append(ids, s.ID)
+		byID[s.ID] = s
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ps.shift_id, ps.petugas_id, p.nama, ps.created_at
+		FROM penugasan_shift ps
+		JOIN petugas p ON p.id = ps.petugas_id
+		WHERE ps.shift_id = ANY($1)
+		ORDER BY p.nama`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a model.PenugasanShift
+		if err := rows.Scan(&a.ShiftID, &a.PetugasID, &a.PetugasNama, &a.Created_At); err != nil {
+			return err
+		}
+		byID[a.ShiftID].Petugas = append(byID[a.ShiftID].Petugas, a)
+	}
+	return rows.Err()
+}
+
+func (r *shiftRepository) Create(ctx context.Context, s *model.Shift) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO shift (id, lokasi_id, peran, mulai, selesai, kebutuhan, catatan, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		s.ID, s.LokasiID, s.Peran, s.Mulai, s.Selesai, s.Kebutuhan, s.Catatan, s.Created_At, s.Updated_At)
+	return err
+}
+
+// GetAll mengambil shift, opsional difilter tanggal mulai dan lokasi
+func (r *shiftRepository) GetAll(ctx context.Context, tanggal *time.Time, lokasiID *uuid.UUID) ([]*model.Shift, error) {
+	q := `SELECT ` + shiftColumns + ` FROM ` + shiftFrom + ` WHERE 1=1`
+	args := []interface{}{}
+	if tanggal != nil {
+		args = append(args, tanggal.Format("2006-01-02"))
+		q += fmt.Sprintf(` AND s.mulai::date = $%d`, len(args))
+	}
+	if lokasiID != nil {
+		args = append(args, *lokasiID)
+		q += fmt.Sprintf(` AND s.lokasi_id = $%d`, len(args))
+	}
+	q += ` ORDER BY s.mulai, l.nama, s.peran`
+	return r.query(ctx, q, args...)
+}
+
+// Lock mengunci baris shift sampai transaksi selesai supaya jumlah petugas tidak melewati kebutuhan
+func (r *shiftRepository) Lock(ctx context.Context, id uuid.UUID) error {
+	var one int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT 1 FROM shift WHERE id = $1 FOR UPDATE`, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("Shift not found")
+	}
+	return err
+}
+
+func (r *shiftRepository) GetById(ctx context.Context, id uuid.UUID) (*model.Shift, error) {
+	list, err := r.query(ctx, `SELECT `+shiftColumns+` FROM `+shiftFrom+` WHERE s.id = $1`, id)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// GetByPetugas mengambil shift petugas yang belum berakhir sejak waktu dari
+func (r *shiftRepository) GetByPetugas(ctx context.Context, petugasID uuid.UUID, dari time.Time) ([]*model.Shift, error) {
+	return r.query(ctx, `SELECT `+shiftColumns+` FROM `+shiftFrom+`
+		JOIN penugasan_shift ps ON ps.shift_id = s.id
+		WHERE ps.petugas_id = $1 AND s.selesai > $2
+		ORDER BY s.mulai`, petugasID, dari)
+}
+
+func (r *shiftRepository) GetBentrok(ctx context.Context, petugasID uuid.UUID, mulai, selesai time.Time, kecualiShiftID uuid.UUID) ([]*model.Shift, error) {
+	return r.query(ctx, `SELECT `+shiftColumns+` FROM `+shiftFrom+`
+		JOIN penugasan_shift ps ON ps.shift_id = s.id
+		WHERE ps.petugas_id = $1 AND s.mulai < $3 AND s.selesai > $2 AND s.id <> $4
+		ORDER BY s.mulai`, petugasID, mulai, selesai, kecualiShiftID)
+}
+
+func (r *shiftRepository) GetMeliputi(ctx context.Context, petugasID, lokasiID uuid.UUID, peran string, waktu time.Time) (*model.Shift, error) {
+	list, err := r.query(ctx, `SELECT `+shiftColumns+` FROM `+shiftFrom+`
+		JOIN penugasan_shift ps ON ps.shift_id = s.id
+		WHERE ps.petugas_id = $1 AND s.lokasi_id = $2 AND s.peran = $3 AND s.mulai <= $4 AND s.selesai > $4
+		LIMIT 1`, petugasID, lokasiID, peran, waktu)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+func (r *shiftRepository) Update(ctx context.Context, s *model.Shift) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE shift SET lokasi_id=$2, peran=$3, mulai=$4, selesai=$5, kebutuhan=$6, catatan=$7 WHERE id=$1`,
+		s.ID, s.LokasiID, s.Peran, s.Mulai, s.Selesai, s.Kebutuhan, s.Catatan)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Shift not found")
+	}
+	return nil
+}
+
+func (r *shiftRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shift WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Shift not found")
+	}
+	return nil
+}
+
+func (r *shiftRepository) Assign(ctx context.Context, shiftID, petugasID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO penugasan_shift (shift_id, petugas_id) VALUES ($1, $2)`, shiftID, petugasID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errors.New("Petugas is already assigned to this shift")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *shiftRepository) Unassign(ctx context.Context, shiftID, petugasID uuid.UUID) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM penugasan_shift WHERE shift_id=$1 AND petugas_id=$2`, shiftID, petugasID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("Petugas is not assigned to this shift")
+	}
+	return nil
+}
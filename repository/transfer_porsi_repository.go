@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+type TransferPorsiRepository interface {
+	Create(ctx context.Context, t *model.TransferPorsi) error
+	GetAll(ctx context.Context) ([]*model.TransferPorsi, error)
+	GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.TransferPorsi, error)
+}
+
+type transferPorsiRepository struct {
+	db *sql.DB
+}
+
+func NewTransferPorsiRepository(db *sql.DB) TransferPorsiRepository {
+	return &transferPorsiRepository{db: db}
+}
+
+const selectTransferPorsi = `
+	SELECT t.id, t.hewan_id, t.dari_pekurban_id, t.ke_pekurban_id, t.porsi_pembilang, t.porsi_penyebut, t.jumlah_dibayar, t.disetujui_oleh, t.catatan, t.created_at,
+	       COALESCE(dp.name, ''), COALESCE(kp.name, ''), COALESCE(u.name, '')
+	FROM transfer_porsi t
+	LEFT JOIN pekurban dp ON dp.id = t.dari_pekurban_id
+	LEFT JOIN pekurban kp ON kp.id = t.ke_pekurban_id
+	LEFT JOIN users u ON u.id = t.disetujui_oleh`
+
+func (r *transferPorsiRepository) Create(ctx context.Context, t *model.TransferPorsi) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO transfer_porsi (id, hewan_id, dari_pekurban_id, ke_pekurban_id, porsi_pembilang, porsi_penyebut, jumlah_dibayar, disetujui_oleh, catatan, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		t.ID, t.HewanID, t.DariPekurbanID, t.KePekurbanID, t.Porsi.Pembilang, t.Porsi.Penyebut, t.JumlahDibayar, t.DisetujuiOleh, t.Catatan, t.Created_At)
+	return err
+}
+
+func (r *transferPorsiRepository) GetAll(ctx context.Context) ([]*model.TransferPorsi, error) {
+	return r.query(ctx, selectTransferPorsi+` ORDER BY t.created_at DESC`)
+}
+
+func (r *transferPorsiRepository) GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]*model.TransferPorsi, error) {
+	return r.query(ctx, selectTransferPorsi+` WHERE t.hewan_id = $1 ORDER BY t.created_at DESC`, hewanID)
+}
+
+func (r *transferPorsiRepository) query(ctx context.Context, q string, args ...interface{}) ([]*model.TransferPorsi, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.TransferPorsi
+	for rows.Next() {
+		var t model.TransferPorsi
+		if err := rows.Scan(&t.ID, &t.HewanID, &t.DariPekurbanID, &t.KePekurbanID, &t.Porsi.Pembilang, &t.Porsi.Penyebut, &t.JumlahDibayar, &t.DisetujuiOleh, &t.Catatan, &t.Created_At,
+			&t.DariPekurban, &t.KePekurban, &t.Penyetuju); err != nil {
+			return nil, err
+		}
+		result = append(result, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func BatchAntarRoute(rg *gin.RouterGroup, c *controller.BatchAntarController, auth middleware.AuthMiddleware) {
+	r := rg.Group("/antar")
+	{
+		r.GET("/me", auth.RequireToken(), c.GetMine)
+		r.POST("/", auth.RequireToken("admin", "panitia"), c.Create)
+		r.POST("/kelompokkan", auth.RequireToken("admin", "panitia"), c.Kelompokkan)
+		r.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
+		r.GET("/:id", auth.RequireToken("admin", "panitia"), c.GetById)
+		r.PUT("/:id/kurir", auth.RequireToken("admin", "panitia"), c.AssignKurir)
+		// kurir boleh mencetak manifest dan memperbarui stop batch miliknya; dicek di service
+		r.GET("/:id/manifest", auth.RequireToken(), c.Manifest)
+		r.PUT("/:id/stop/:stop_id", auth.RequireToken(), c.UpdateStop)
+		r.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func BuktiPenyembelihanRoute(rg *gin.RouterGroup, c *controller.BuktiPenyembelihanController, auth middleware.AuthMiddleware) {
+	b := rg.Group("/penyembelihan/:id/bukti")
+	{
+		b.POST("", auth.RequireToken("admin", "panitia"), c.Upload)
+		b.GET("", auth.RequireToken("admin", "panitia", "user"), c.GetAll)
+		b.POST("/lengkap", auth.RequireToken("admin", "panitia"), c.TandaiLengkap)
+		b.DELETE("/:bukti_id", auth.RequireToken("admin", "panitia"), c.Delete)
+	}
+}
@@ -10,10 +10,16 @@ func DistribusiDagingRoute(rg *gin.RouterGroup, c *controller.DistribusiDagingCo
 	r := rg.Group("/distribusi")
 	{
 		r.POST("/", auth.RequireToken("admin", "panitia"), c.Create)
+		r.POST("/rencana", auth.RequireToken("admin", "panitia"), c.Rencanakan)
+		r.POST("/bulk", auth.RequireToken("admin", "panitia"), c.CreateBulk)
 		r.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
 		r.GET("/:id", auth.RequireToken("admin", "panitia"), c.GetByID)
 		r.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
 		r.GET("/total-paket", auth.RequireToken("admin", "panitia"), c.GetTotalPaket)
 		r.GET("/belum-terdistribusi", auth.RequireToken("admin", "panitia"), c.GetPenerimaBelumDistribusi)
+		r.GET("/rekap-hewan", auth.RequireToken("admin", "panitia"), c.GetRekapHewan)
+		r.GET("/alokasi", auth.RequireToken("admin", "panitia"), c.GetAlokasi)
+		r.PUT("/alokasi", auth.RequireToken("admin", "panitia"), c.SaveAlokasi)
+		r.GET("/penerima/:id", auth.RequireToken("admin", "panitia"), c.GetRiwayatPenerima)
 	}
 }
\ No newline at end of file
@@ -12,6 +12,8 @@ func HewanKurbanRoute(rg *gin.RouterGroup, c *controller.HewanKurbanController,
 		hk.POST("/", auth.RequireToken("admin"), c.Create)
 		hk.PUT("/:id", auth.RequireToken("admin"), c.Update)
 		hk.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+		hk.PUT("/:id/status", auth.RequireToken("admin", "panitia"), c.UpdateStatus)
+		hk.GET("/:id/riwayat-status", auth.RequireToken(), c.GetRiwayatStatus)
 		hk.GET("/", auth.RequireToken(), c.GetAll)
 		hk.GET("/:id", auth.RequireToken(), c.GetByID)
 	}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func JenisHewanRoute(rg *gin.RouterGroup, c *controller.JenisHewanController, auth middleware.AuthMiddleware) {
+	j := rg.Group("/jenis-hewan")
+	{
+		j.GET("/", auth.RequireToken(), c.GetAll)
+		j.POST("/", auth.RequireToken("admin"), c.Create)
+		j.PUT("/:nama", auth.RequireToken("admin"), c.Update)
+		j.DELETE("/:nama", auth.RequireToken("admin"), c.Delete)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func KalenderRoute(rg *gin.RouterGroup, c *controller.KalenderController, auth middleware.AuthMiddleware, rl middleware.RateLimitMiddleware) {
+	rg.GET("/penyembelihan/calendar.ics", auth.RequireToken("admin", "panitia"), c.Calendar)
+
+	k := rg.Group("/kalender")
+	{
+		k.POST("/token", auth.RequireToken(), c.CreateToken)
+		k.POST("/pekurban/:id/token", auth.RequireToken("admin", "panitia"), c.CreateTokenPekurban)
+		// feed dibuka aplikasi kalender tanpa header Authorization; token di URL adalah kuncinya
+		k.GET("/feed/:token", rl.Limit(), c.Feed)
+	}
+}
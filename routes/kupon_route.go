@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func KuponRoute(rg *gin.RouterGroup, c *controller.KuponController, auth middleware.AuthMiddleware) {
+	r := rg.Group("/kupon")
+	{
+		r.POST("/", auth.RequireToken("admin", "panitia"), c.Generate)
+		r.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
+		r.GET("/cetak", auth.RequireToken("admin", "panitia"), c.Cetak)
+		r.POST("/scan", auth.RequireToken("admin", "panitia"), c.Scan)
+		r.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func LokasiRoute(rg *gin.RouterGroup, c *controller.LokasiController, auth middleware.AuthMiddleware) {
+	l := rg.Group("/lokasi")
+	{
+		l.GET("/", auth.RequireToken(), c.GetAll)
+		l.GET("/:id", auth.RequireToken(), c.GetById)
+		l.POST("/", auth.RequireToken("admin"), c.Create)
+		l.PUT("/:id", auth.RequireToken("admin"), c.Update)
+		l.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+		l.POST("/:id/gabung", auth.RequireToken("admin"), c.Merge)
+	}
+}
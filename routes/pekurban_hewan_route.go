@@ -13,7 +13,9 @@ func PekurbanHewanRoute(rg *gin.RouterGroup, c *controller.PekurbanHewanControll
 		r.GET("/", authMw.RequireToken("admin", "panitia"), c.GetAll)
 		r.GET("/hewan/:hewan_id", authMw.RequireToken(), c.GetByHewanID)
 		r.GET("/pekurban/:pekurban_id", authMw.RequireToken(), c.GetByPekurbanID)
-		r.PUT("/:id", authMw.RequireToken(), c.Update)
+		r.PUT("/:pekurban_id/:hewan_id", authMw.RequireToken(), c.Update)
+		r.PUT("/:pekurban_id/:hewan_id/atas-nama", authMw.RequireToken(), c.UpdateAtasNama)
+		r.PUT("/:pekurban_id/:hewan_id/kehadiran", authMw.RequireToken(), c.UpdateKehadiran)
 		r.DELETE("/:pekurban_id/:hewan_id", authMw.RequireToken("admin"), c.Delete)
 	}
 }
@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/service"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/testdb"
+)
+
+// adminAuth menggantikan middleware JWT: setiap request dianggap dari admin
+type adminAuth struct{}
+
+func (adminAuth) RequireToken(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set("user", model.User{ID: uuid.New(), Role: "admin"})
+		ctx.Next()
+	}
+}
+
+// TestPekurbanHewanCreateConcurrent mengirim POST /patungan secara paralel lewat router gin, sehingga
+// binding, controller, service, kunci hewan, dan trigger database ikut teruji bersama
+func TestPekurbanHewanCreateConcurrent(t *testing.T) {
+	db := testdb.Open(t)
+	gin.SetMode(gin.TestMode)
+
+	phRepo := repository.NewPekurbanHewanRepository(db)
+	pekurbanRepo := repository.NewPekurbanRepository(db)
+	hRepo := repository.NewHewanKurbanRepository(db)
+	tx := repository.NewTxManager(db)
+	lifecycle := service.NewHewanLifecycleService(hRepo, phRepo, repository.NewRiwayatStatusHewanRepository(db), tx)
+	svc := service.NewPekurbanHewanService(phRepo, pekurbanRepo, hRepo, repository.NewJenisHewanRepository(db),
+		repository.NewAtasNamaRepository(db), repository.NewPenyembelihanRepository(db), repository.NewPembayaranKurbanRepository(db), tx, lifecycle, time.Hour)
+	ctrl := controller.NewPekurbanHewanController(svc, service.NewPekurbanService(pekurbanRepo, repository.NewUserRepository(db)))
+
+	engine := gin.New()
+	PekurbanHewanRoute(engine.Group("/api/v1"), ctrl, adminAuth{})
+
+	hewanID := uuid.New()
+	if _, err := db.Exec(`INSERT INTO hewan_kurban (id, jenis, berat, harga, is_private, tanggal_pendaftaran) VALUES ($1, 'sapi', 300, 21000000, false, CURRENT_DATE)`, hewanID); err != nil {
+		t.Fatal(err)
+	}
+
+	// sapi berkapasitas 7 orang; 12 pekurban berebut satu orang masing-masing
+	const n = 12
+	pekurbanIDs := make([]uuid.UUID, n)
+	for i := range pekurbanIDs {
+		pekurbanIDs[i] = uuid.New()
+		if _, err := db.Exec(`INSERT INTO pekurban (id, name) VALUES ($1, $2)`, pekurbanIDs[i], fmt.Sprintf("Pekurban %d", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	codes := make([]int, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, _ := json.Marshal(gin.H{
+				"pekurban_id":  pekurbanIDs[i].String(),
+				"hewan_id":     hewanID.String(),
+				"jumlah_orang": 1,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/patungan/", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			<-start
+			engine.ServeHTTP(w, req)
+			codes[i] = w.Code
+			if w.Code != http.StatusCreated && w.Code != http.StatusConflict {
+				t.Errorf("request %d: status %d, body %s", i, w.Code, w.Body.String())
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	berhasil := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			berhasil++
+		}
+	}
+	if berhasil != 7 {
+		t.Errorf("201 responses = %d, want 7", berhasil)
+	}
+
+	list, err := phRepo.GetByHewanId(context.Background(), hewanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	porsi := make([]model.Pecahan, 0, len(list))
+	for _, ph := range list {
+		porsi = append(porsi, ph.Porsi)
+	}
+	if total := model.TotalPecahan(porsi...); model.MelebihiSatuHewan(total) {
+		t.Errorf("total porsi = %s, exceeds 1", total.RatString())
+	}
+	if len(list) != berhasil {
+		t.Errorf("stored shares = %d, want %d", len(list), berhasil)
+	}
+}
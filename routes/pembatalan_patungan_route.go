@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func PembatalanPatunganRoute(rg *gin.RouterGroup, c *controller.PembatalanPatunganController, authMw middleware.AuthMiddleware) {
+	r := rg.Group("/patungan")
+	{
+		r.GET("/pembatalan", authMw.RequireToken(), c.GetAll)
+		r.PUT("/pembatalan/:id/approve", authMw.RequireToken("admin"), c.Approve)
+		r.PUT("/pembatalan/:id/reject", authMw.RequireToken("admin"), c.Reject)
+		r.POST("/:pekurban_id/:hewan_id/pembatalan", authMw.RequireToken(), c.Create)
+	}
+}
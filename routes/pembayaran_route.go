@@ -10,6 +10,7 @@ func PembayaranRoute(rg *gin.RouterGroup, c *controller.PembayaranController, au
 	p := rg.Group("/pembayaran")
 	{
 		p.POST("/", auth.RequireToken(), c.Create)
+		p.POST("/notification", c.Notification)
 		p.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
 		p.GET("/:id", auth.RequireToken("admin", "panitia"), c.GetByID)
 		p.GET("/order/:order_id", auth.RequireToken("admin", "panitia"), c.GetByOrderID)
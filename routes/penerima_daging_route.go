@@ -10,6 +10,7 @@ func PenerimaDagingRoute(rg *gin.RouterGroup, c *controller.PenerimaDagingContro
 	r := rg.Group("/penerima")
 	{
 		r.POST("/", auth.RequireToken("admin", "panitia"), c.Create)
+		r.POST("/import", auth.RequireToken("admin", "panitia"), c.Import)
 		r.PUT("/:id", auth.RequireToken("admin", "panitia"), c.Update)
 		r.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
 		r.GET("/", auth.RequireToken(), c.GetAll)
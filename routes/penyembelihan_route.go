@@ -10,9 +10,18 @@ func PenyembelihanRoute(rg *gin.RouterGroup, c *controller.PenyembelihanControll
 	pr := rg.Group("/penyembelihan")
 	{
 		pr.POST("", auth.RequireToken("admin", "panitia"), c.Create)
+		pr.POST("/jadwal", auth.RequireToken("admin", "panitia"), c.GenerateJadwal)
 		pr.PUT("/:id", auth.RequireToken("admin", "panitia"), c.Update)
+		pr.PUT("/:id/hasil", auth.RequireToken("admin", "panitia"), c.UpdateHasil)
+		pr.PUT("/:id/jagal", auth.RequireToken("admin", "panitia"), c.AssignJagal)
+		pr.POST("/:id/mulai", auth.RequireToken("admin", "panitia"), c.Mulai)
+		pr.POST("/:id/selesai", auth.RequireToken("admin", "panitia"), c.Selesai)
+		pr.GET("/rekap-harian", auth.RequireToken("admin", "panitia"), c.RekapHarian)
 		pr.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
 		pr.GET("/", auth.RequireToken("admin", "panitia", "user"), c.GetAll)
+		pr.POST("/live/token", auth.RequireToken("admin", "panitia", "user"), c.LiveToken)
+		// EventSource tidak bisa mengirim header Authorization; stream memakai token dari /live/token
+		pr.GET("/live", c.Live)
 		pr.GET("/:id", auth.RequireToken("admin", "panitia", "user"), c.GetById)
 	}
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func PeriodeKurbanRoute(rg *gin.RouterGroup, c *controller.PeriodeKurbanController, auth middleware.AuthMiddleware) {
+	p := rg.Group("/periode-kurban")
+	{
+		p.GET("/", auth.RequireToken(), c.GetAll)
+		p.GET("/jendela", auth.RequireToken(), c.GetJendela)
+		p.POST("/", auth.RequireToken("admin"), c.Create)
+		p.PUT("/:id", auth.RequireToken("admin"), c.Update)
+		p.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func PermintaanPatunganRoute(rg *gin.RouterGroup, c *controller.PermintaanPatunganController, authMw middleware.AuthMiddleware) {
+	r := rg.Group("/antrean-patungan")
+	{
+		r.POST("/", authMw.RequireToken(), c.Create)
+		r.GET("/", authMw.RequireToken(), c.GetAll)
+		r.POST("/match", authMw.RequireToken("admin", "panitia"), c.Match)
+		r.DELETE("/:id", authMw.RequireToken(), c.Cancel)
+	}
+}
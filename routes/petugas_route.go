@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func PetugasRoute(rg *gin.RouterGroup, c *controller.PetugasController, auth middleware.AuthMiddleware) {
+	p := rg.Group("/petugas")
+	{
+		p.GET("/me/jadwal", auth.RequireToken(), c.GetMyJadwal)
+		p.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
+		p.GET("/:id", auth.RequireToken("admin", "panitia"), c.GetById)
+		p.GET("/:id/jadwal", auth.RequireToken("admin", "panitia"), c.GetJadwal)
+		p.POST("/", auth.RequireToken("admin", "panitia"), c.Create)
+		p.PUT("/:id", auth.RequireToken("admin", "panitia"), c.Update)
+		p.DELETE("/:id", auth.RequireToken("admin"), c.Delete)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func PublicRoute(rg *gin.RouterGroup, hc *controller.HewanKurbanController, rl middleware.RateLimitMiddleware) {
+	r := rg.Group("/public", rl.Limit())
+	{
+		r.GET("/hewan", hc.GetPublicCatalog)
+	}
+}
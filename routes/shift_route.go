@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func ShiftRoute(rg *gin.RouterGroup, c *controller.ShiftController, auth middleware.AuthMiddleware) {
+	s := rg.Group("/shift")
+	{
+		s.GET("/", auth.RequireToken("admin", "panitia"), c.GetAll)
+		s.GET("/:id", auth.RequireToken("admin", "panitia"), c.GetById)
+		s.POST("/", auth.RequireToken("admin", "panitia"), c.Create)
+		s.PUT("/:id", auth.RequireToken("admin", "panitia"), c.Update)
+		s.DELETE("/:id", auth.RequireToken("admin", "panitia"), c.Delete)
+		s.POST("/:id/petugas", auth.RequireToken("admin", "panitia"), c.Assign)
+		s.DELETE("/:id/petugas/:petugas_id", auth.RequireToken("admin", "panitia"), c.Unassign)
+	}
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wahyujatirestu/sahabat-kurban/controller"
+	"github.com/wahyujatirestu/sahabat-kurban/middleware"
+)
+
+func TransferPorsiRoute(rg *gin.RouterGroup, c *controller.TransferPorsiController, authMw middleware.AuthMiddleware) {
+	r := rg.Group("/patungan")
+	{
+		r.GET("/transfer", authMw.RequireToken("admin", "panitia"), c.GetAll)
+		r.POST("/:pekurban_id/:hewan_id/transfer", authMw.RequireToken("admin", "panitia"), c.Transfer)
+	}
+}
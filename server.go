@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "github.com/wahyujatirestu/sahabat-kurban/docs"
@@ -32,6 +34,19 @@ type Server struct {
 	emailRepo				utilsrepo.EmailVerificationRepository
 	resetRepo 				utilsrepo.ResetPasswordRepository
 	laporanRepo 			repository.ReportRepository
+	permintaanRepo			repository.PermintaanPatunganRepository
+	atasNamaRepo			repository.AtasNamaRepository
+	transferRepo			repository.TransferPorsiRepository
+	jenisRepo				repository.JenisHewanRepository
+	pembatalanRepo			repository.PembatalanPatunganRepository
+	lokasiRepo				repository.LokasiRepository
+	petugasRepo				repository.PetugasRepository
+	shiftRepo				repository.ShiftRepository
+	buktiRepo				repository.BuktiPenyembelihanRepository
+	periodeRepo				repository.PeriodeKurbanRepository
+	kalenderRepo			repository.KalenderRepository
+	kuponRepo				repository.KuponRepository
+	batchAntarRepo			repository.BatchAntarRepository
 	userService 			service.UserService
 	authService 			service.AuthService
 	emailService			utilsservice.EmailService
@@ -45,7 +60,26 @@ type Server struct {
 	midtransService			payserv.MidtransService
 	pembayaranService		service.PembayaranKurbanService
 	laporanService			service.ReportService
+	permintaanService		service.PermintaanPatunganService
+	transferService			service.TransferPorsiService
+	jenisService			service.JenisHewanService
+	pembatalanService		service.PembatalanPatunganService
+	lokasiService			service.LokasiService
+	antreanService			service.AntreanPenyembelihanService
+	petugasService			service.PetugasService
+	shiftService			service.ShiftService
+	buktiService			service.BuktiPenyembelihanService
+	periodeService			service.PeriodeKurbanService
+	kalenderService			service.KalenderService
+	kuponService			service.KuponService
+	batchAntarService		service.BatchAntarService
+	fileStorage				utilsservice.FileStorage
 	rtRepo 					utilsrepo.RefreshTokenRepository
+	cfg						*config.Config
+	stopSweeper				context.CancelFunc
+	antreanListener			repository.AntreanListener
+	stopAntrean				context.CancelFunc
+	dsn						string
 	db 						*sql.DB
 	engine 					*gin.Engine
 	host					string
@@ -76,6 +110,21 @@ func NewServer() *Server {
 	distribusiRepo := repository.NewDistribusiDagingRepository(db)
 	pembayaranRepo := repository.NewPembayaranKurbanRepository(db)
 	laporanRepo := repository.NewReportRepository(db)
+	permintaanRepo := repository.NewPermintaanPatunganRepository(db)
+	atasNamaRepo := repository.NewAtasNamaRepository(db)
+	transferRepo := repository.NewTransferPorsiRepository(db)
+	jenisRepo := repository.NewJenisHewanRepository(db)
+	pembatalanRepo := repository.NewPembatalanPatunganRepository(db)
+	riwayatStatusRepo := repository.NewRiwayatStatusHewanRepository(db)
+	lokasiRepo := repository.NewLokasiRepository(db)
+	petugasRepo := repository.NewPetugasRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
+	buktiRepo := repository.NewBuktiPenyembelihanRepository(db)
+	periodeRepo := repository.NewPeriodeKurbanRepository(db)
+	kalenderRepo := repository.NewKalenderRepository(db)
+	kuponRepo := repository.NewKuponRepository(db)
+	batchAntarRepo := repository.NewBatchAntarRepository(db)
+	txManager := repository.NewTxManager(db)
 
 	emailService := utilsservice.NewEmailService(
 		cfg.SendgridAPIKey,
@@ -84,20 +133,43 @@ func NewServer() *Server {
 		cfg.AppBaseURL,
 	)
 
+	fileStorage := utilsservice.NewLocalFileStorage(cfg.UploadDir, cfg.AppBaseURL+"/files", cfg.FileURLSecret, cfg.FileURLTTL)
+
 	jwtService := utilsservice.NewJWTServie(cfg, rtRepo, userRepo)
 	authService := service.NewAuthService(cfg, userRepo, rtRepo, emailRepo, resetRepo, jwtService, emailService)
 	userService := service.NewUserService(userRepo)
 	pekurbanService := service.NewPekurbanService(pekurbanRepo, userRepo)
-	hewanKurbanService := service.NewHewanKurbanService(hewanKurbanRepo, penyembelihanRepo)
-	pekurbanHewanService := service.NewPekurbanHewanService(pekurbanHewanRepo, pekurbanRepo, hewanKurbanRepo)
-	penyembelihanService := service.NewPenyembelihanService(penyembelihanRepo, pembayaranRepo)
-	penerimaService := service.NewPenerimaDagingService(penerimaRepo, pekurbanRepo)
-	distribusiService := service.NewDistribusiDagingService(distribusiRepo, penerimaRepo)
+	hewanLifecycle := service.NewHewanLifecycleService(hewanKurbanRepo, pekurbanHewanRepo, riwayatStatusRepo, txManager)
+	hewanKurbanService := service.NewHewanKurbanService(hewanKurbanRepo, penyembelihanRepo, pekurbanHewanRepo, jenisRepo, hewanLifecycle, txManager)
+	pekurbanHewanService := service.NewPekurbanHewanService(pekurbanHewanRepo, pekurbanRepo, hewanKurbanRepo, jenisRepo, atasNamaRepo, penyembelihanRepo, pembayaranRepo, txManager, hewanLifecycle, cfg.ReservationTTL)
+	periodeService := service.NewPeriodeKurbanService(periodeRepo, txManager)
+	penyembelihanService := service.NewPenyembelihanService(penyembelihanRepo, hewanKurbanRepo, atasNamaRepo, lokasiRepo, petugasRepo, shiftRepo, periodeService, hewanLifecycle, txManager)
+	penerimaService := service.NewPenerimaDagingService(penerimaRepo, pekurbanRepo, txManager, cfg.UploadMaxSize)
+	distribusiService := service.NewDistribusiDagingService(distribusiRepo, penerimaRepo, lokasiRepo, hewanKurbanRepo, penyembelihanRepo, pekurbanHewanRepo, txManager, periodeService, cfg.DistribusiMaksPerPenerima)
+	kuponService := service.NewKuponService(kuponRepo, penerimaRepo, lokasiRepo, distribusiService, txManager, cfg.KuponSecret)
+	batchAntarService := service.NewBatchAntarService(batchAntarRepo, penerimaRepo, lokasiRepo, petugasRepo, distribusiRepo, distribusiService, periodeService, txManager)
 	midtransService := payserv.NewMidtransService()
-	pembayaranService := service.NewPembayaranKurbanService(pembayaranRepo, midtransService, pekurbanHewanRepo, hewanKurbanRepo, pekurbanRepo)
+	pembayaranService := service.NewPembayaranKurbanService(pembayaranRepo, midtransService, pekurbanHewanRepo, hewanKurbanRepo, pekurbanRepo, hewanLifecycle, txManager)
 	laporanService := service.NewReportService(laporanRepo)
+	jenisService := service.NewJenisHewanService(jenisRepo)
+	lokasiService := service.NewLokasiService(lokasiRepo, txManager)
+	petugasService := service.NewPetugasService(petugasRepo, shiftRepo, penyembelihanRepo, periodeService)
+	shiftService := service.NewShiftService(shiftRepo, petugasRepo, lokasiRepo, txManager)
+	buktiService := service.NewBuktiPenyembelihanService(buktiRepo, penyembelihanRepo, hewanKurbanRepo, pekurbanRepo, fileStorage, emailService, periodeService, cfg.UploadMaxSize, cfg.UploadMaxFiles)
+	kalenderService := service.NewKalenderService(kalenderRepo, userRepo, pekurbanRepo, periodeService, cfg.AppBaseURL, cfg.AntreanDurasiDefault)
+	antreanService := service.NewAntreanPenyembelihanService(penyembelihanRepo, pekurbanHewanRepo, cfg.AntreanDurasiDefault, cfg.LiveTokenSecret, cfg.LiveTokenTTL)
+	transferService := service.NewTransferPorsiService(transferRepo, pekurbanHewanRepo, pekurbanRepo, hewanKurbanRepo, penyembelihanRepo, pembayaranRepo, pembatalanRepo, txManager)
+	pembatalanService := service.NewPembatalanPatunganService(pembatalanRepo, pekurbanHewanRepo, hewanKurbanRepo, penyembelihanRepo, pembayaranRepo, txManager, hewanLifecycle, service.RefundPolicy{
+		FullRefundBefore: cfg.FullRefundBefore,
+		PartialPercent:   cfg.PartialRefundPercent,
+	})
+	permintaanService := service.NewPermintaanPatunganService(permintaanRepo, pekurbanRepo, hewanKurbanRepo, jenisRepo, txManager, hewanLifecycle, emailService, cfg.ReservationTTL)
 
 	engine := gin.Default()
+	// tanpa ini gin mempercayai X-Forwarded-For dari semua klien sehingga rate limit per IP bisa diakali
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 	host := fmt.Sprintf(":%s", cfg.ApiPort)
 
 	seedInitialAdmin(userRepo)
@@ -115,6 +187,19 @@ func NewServer() *Server {
 		pembayaranRepo: pembayaranRepo,
 		emailRepo: emailRepo,
 		laporanRepo: laporanRepo,
+		permintaanRepo: permintaanRepo,
+		atasNamaRepo: atasNamaRepo,
+		transferRepo: transferRepo,
+		jenisRepo: jenisRepo,
+		pembatalanRepo: pembatalanRepo,
+		lokasiRepo: lokasiRepo,
+		petugasRepo: petugasRepo,
+		shiftRepo: shiftRepo,
+		buktiRepo: buktiRepo,
+		periodeRepo: periodeRepo,
+		kalenderRepo: kalenderRepo,
+		kuponRepo: kuponRepo,
+		batchAntarRepo: batchAntarRepo,
 		db: db,
 		authService: authService,
 		userService: userService,
@@ -129,6 +214,22 @@ func NewServer() *Server {
 		midtransService: midtransService,
 		pembayaranService: pembayaranService,
 		laporanService: laporanService,
+		permintaanService: permintaanService,
+		transferService: transferService,
+		jenisService: jenisService,
+		pembatalanService: pembatalanService,
+		lokasiService: lokasiService,
+		antreanService: antreanService,
+		petugasService: petugasService,
+		shiftService: shiftService,
+		buktiService: buktiService,
+		periodeService: periodeService,
+		kalenderService: kalenderService,
+		kuponService: kuponService,
+		batchAntarService: batchAntarService,
+		fileStorage: fileStorage,
+		cfg: cfg,
+		dsn: dsn,
 		engine: engine,
 		host: host,
 	}
@@ -138,38 +239,121 @@ func (s *Server) SetupRoutes() {
 	s.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	apiV1 := s.engine.Group("/api/v1")
 	authMw := middleware.NewAuthMiddleware(s.jwtService)
+	publicRl := middleware.NewRateLimitMiddleware(s.cfg.PublicRateLimit, s.cfg.PublicRateWindow)
 
 	authController := controller.NewAuthController(s.authService)
 	userController := controller.NewUserController(s.userService)
 	pekurbanController := controller.NewPekurbanController(s.pekurbanService)
 	hewanKurbanController := controller.NewHewanKurbanController(s.hewanKurbanService)
 	pekurbanHewanController := controller.NewPekurbanHewanController(s.pekurbanHewanService, s.pekurbanService)
-	penyembelihanController := controller.NewPenyembelihanController(s.penyembelihanService)
-	penerimaController := controller.NewPenerimaDagingController(s.penerimaService)
+	penyembelihanController := controller.NewPenyembelihanController(s.penyembelihanService, s.antreanService, s.pekurbanService)
+	penerimaController := controller.NewPenerimaDagingController(s.penerimaService, s.cfg.UploadMaxSize)
 	distribusiController := controller.NewDistribusiDagingController(s.distribusiService)
+	kuponController := controller.NewKuponController(s.kuponService)
+	batchAntarController := controller.NewBatchAntarController(s.batchAntarService)
 	pembayaranController := controller.NewPembayaranController(s.pembayaranService, s.pekurbanService)
 	laporanController := controller.NewReportController(s.laporanService)
+	permintaanController := controller.NewPermintaanPatunganController(s.permintaanService, s.pekurbanService)
+	transferController := controller.NewTransferPorsiController(s.transferService)
+	jenisController := controller.NewJenisHewanController(s.jenisService)
+	lokasiController := controller.NewLokasiController(s.lokasiService)
+	petugasController := controller.NewPetugasController(s.petugasService)
+	shiftController := controller.NewShiftController(s.shiftService)
+	periodeController := controller.NewPeriodeKurbanController(s.periodeService)
+	buktiController := controller.NewBuktiPenyembelihanController(s.buktiService, s.pekurbanService, s.cfg.UploadMaxSize, s.cfg.UploadMaxFiles)
+	kalenderController := controller.NewKalenderController(s.kalenderService)
+	pembatalanController := controller.NewPembatalanPatunganController(s.pembatalanService, s.pekurbanService)
+	fileController := controller.NewFileController(s.fileStorage)
+
+	// file bukti tidak disajikan statis; hanya tautan bertanda tangan yang bisa dibuka
+	s.engine.GET("/files/*filepath", fileController.Serve)
 
 	routes.AuthRoute(apiV1, authController)
 	routes.UserRoute(apiV1, userController, authMw)
 	routes.PekurbanRoute(apiV1, pekurbanController, authMw)
+	routes.JenisHewanRoute(apiV1, jenisController, authMw)
+	routes.LokasiRoute(apiV1, lokasiController, authMw)
+	routes.PeriodeKurbanRoute(apiV1, periodeController, authMw)
 	routes.HewanKurbanRoute(apiV1, hewanKurbanController, authMw)
 	routes.PekurbanHewanRoute(apiV1, pekurbanHewanController, authMw)
+	routes.TransferPorsiRoute(apiV1, transferController, authMw)
+	routes.PembatalanPatunganRoute(apiV1, pembatalanController, authMw)
+	routes.PermintaanPatunganRoute(apiV1, permintaanController, authMw)
+	routes.PetugasRoute(apiV1, petugasController, authMw)
+	routes.ShiftRoute(apiV1, shiftController, authMw)
+	routes.KalenderRoute(apiV1, kalenderController, authMw, publicRl)
 	routes.PenyembelihanRoute(apiV1, penyembelihanController, authMw)
+	routes.BuktiPenyembelihanRoute(apiV1, buktiController, authMw)
 	routes.PenerimaDagingRoute(apiV1, penerimaController, authMw)
 	routes.DistribusiDagingRoute(apiV1, distribusiController, authMw)
+	routes.KuponRoute(apiV1, kuponController, authMw)
+	routes.BatchAntarRoute(apiV1, batchAntarController, authMw)
 	routes.PembayaranRoute(apiV1, pembayaranController, authMw)
 	routes.RegisterReportRoutes(apiV1, authMw, laporanController)
+	routes.PublicRoute(apiV1, hewanKurbanController, publicRl)
+}
+
+// startReservationSweeper melepas porsi ditahan yang sudah kedaluwarsa secara berkala
+func (s *Server) startReservationSweeper() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopSweeper = cancel
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.ReservationSweep)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := s.pekurbanHewanService.ReleaseExpiredReservations(ctx)
+				if err != nil {
+					log.Printf("failed to release expired reservations: %v", err)
+					continue
+				}
+				if n > 0 {
+					log.Printf("released %d expired reservations", n)
+				}
+			}
+		}
+	}()
+}
+
+// startAntreanListener mendengarkan NOTIFY antrean penyembelihan dan meneruskannya ke klien live.
+// Jika gagal, endpoint live tetap mengirim antrean saat terhubung tetapi tanpa pembaruan otomatis.
+func (s *Server) startAntreanListener() {
+	listener, err := repository.NewAntreanListener(s.dsn)
+	if err != nil {
+		log.Printf("failed to listen antrean penyembelihan: %v", err)
+		return
+	}
+	s.antreanListener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopAntrean = cancel
+	go s.antreanService.Run(ctx, listener.Notifications())
 }
 
 func (s *Server) Run() {
 	s.SetupRoutes()
+	s.startReservationSweeper()
+	s.startAntreanListener()
 	if err := s.engine.Run(s.host); err != nil {
 		log.Fatalf("failed to run server on %s: %v", s.host, err)
 	}
 }
 
 func (s *Server) Close() {
+	if s.stopSweeper != nil {
+		s.stopSweeper()
+	}
+	if s.stopAntrean != nil {
+		s.stopAntrean()
+	}
+	if s.antreanListener != nil {
+		s.antreanListener.Close()
+	}
 	if s.db != nil {
 		s.db.Close()
 	}
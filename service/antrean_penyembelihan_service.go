@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/security"
+)
+
+// AntreanPenyembelihanService menyusun antrean penyembelihan harian dan meneruskan perubahan ke klien
+// live. Perubahan datang dari NOTIFY database sehingga klien di semua instance server ikut diperbarui.
+type AntreanPenyembelihanService interface {
+	GetAntrean(ctx context.Context, tanggal time.Time, n int, pekurbanID *uuid.UUID) (*dto.AntreanPenyembelihanResponse, error)
+	// Subscribe mendaftarkan klien untuk satu tanggal; channel menerima sinyal setiap antrean tanggal itu berubah
+	Subscribe(tanggal time.Time) (<-chan struct{}, func())
+	// Run membagikan notifikasi ke semua klien sampai ctx selesai atau channel notifikasi ditutup
+	Run(ctx context.Context, notifications <-chan string)
+	// CreateLiveToken membuat token stream untuk panitia (pekurbanID nil) atau untuk satu pekurban
+	CreateLiveToken(pekurbanID *uuid.UUID) dto.LiveTokenResponse
+	VerifyLiveToken(token string) (*uuid.UUID, time.Time, error)
+}
+
+type antreanPenyembelihanService struct {
+	repo          repository.PenyembelihanRepository
+	phRepo        repository.PekurbanHewanRepository
+	durasiDefault time.Duration
+	tokenSecret   []byte
+	tokenTTL      time.Duration
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func NewAntreanPenyembelihanService(repo repository.PenyembelihanRepository, phRepo repository.PekurbanHewanRepository, durasiDefault time.Duration, tokenSecret []byte, tokenTTL time.Duration) AntreanPenyembelihanService {
+	return &antreanPenyembelihanService{
+		repo:          repo,
+		phRepo:        phRepo,
+		durasiDefault: durasiDefault,
+		tokenSecret:   tokenSecret,
+		tokenTTL:      tokenTTL,
+		subscribers:   map[string]map[chan struct{}]struct{}{},
+	}
+}
+
+func (s *antreanPenyembelihanService) CreateLiveToken(pekurbanID *uuid.UUID) dto.LiveTokenResponse {
+	exp := time.Now().Add(s.tokenTTL)
+	return dto.LiveTokenResponse{
+		Token:     security.SignLive(s.tokenSecret, pekurbanID, exp),
+		ExpiresAt: exp.Truncate(time.Second),
+	}
+}
+
+func (s *antreanPenyembelihanService) VerifyLiveToken(token string) (*uuid.UUID, time.Time, error) {
+	return security.VerifyLive(s.tokenSecret, token, time.Now())
+}
+
+func (s *antreanPenyembelihanService) Subscribe(tanggal time.Time) (<-chan struct{}, func()) {
+	key := tanggal.Format("2006-01-02")
+	ch := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	if s.subscribers[key] == nil {
+		s.subscribers[key] = map[chan struct{}]struct{}{}
+	}
+	s.subscribers[key][ch] = struct{}{}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers[key], ch)
+		if len(s.subscribers[key]) == 0 {
+			delete(s.subscribers, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *antreanPenyembelihanService) Run(ctx context.Context, notifications <-chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tanggal, ok := <-notifications:
+			if !ok {
+				return
+			}
+			s.broadcast(tanggal)
+		}
+	}
+}
+
+// broadcast memberi sinyal ke klien tanggal tersebut, atau ke semua klien jika tanggal kosong. Klien yang
+// belum sempat memproses sinyal sebelumnya tidak ditunggu: satu sinyal tertunda sudah cukup untuk memuat ulang.
+func (s *antreanPenyembelihanService) broadcast(tanggal string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, subs := range s.subscribers {
+		if tanggal != "" && key != tanggal {
+			continue
+		}
+		for ch := range subs {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *antreanPenyembelihanService) GetAntrean(ctx context.Context, tanggal time.Time, n int, pekurbanID *uuid.UUID) (*dto.AntreanPenyembelihanResponse, error) {
+	items, err := s.repo.GetAntrean(ctx, tanggal)
+	if err != nil {
+		return nil, err
+	}
+
+	milik := map[uuid.UUID]bool{}
+	if pekurbanID != nil {
+		shares, err := s.phRepo.GetByPekurbanId(ctx, *pekurbanID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sh := range shares {
+			if id, err := uuid.Parse(sh.HewanID); err == nil {
+				milik[id] = true
+			}
+		}
+	}
+
+	now := time.Now()
+	res := &dto.AntreanPenyembelihanResponse{
+		Tanggal:    tanggal.Format("2006-01-02"),
+		Diperbarui: now,
+		Lokasi:     []dto.AntreanLokasiResponse{},
+	}
+
+	// item sudah terurut per lokasi: yang sudah disembelih lebih dulu, lalu sisa antrean
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && items[end].LokasiID == items[start].LokasiID {
+			end++
+		}
+		lok, saya := s.susunLokasi(items[start:end], n, milik, now)
+		res.Lokasi = append(res.Lokasi, lok)
+		res.HewanSaya = append(res.HewanSaya, saya...)
+		start = end
+	}
+	return res, nil
+}
+
+// susunLokasi menghitung posisi dan perkiraan jam setiap hewan di satu lokasi. Interval per hewan diambil
+// dari median jeda antar penyembelihan yang sudah terjadi (tahan terhadap jeda istirahat), atau durasi
+// default jika belum ada data.
+func (s *antreanPenyembelihanService) susunLokasi(items []*model.AntreanPenyembelihan, n int, milik map[uuid.UUID]bool, now time.Time) (dto.AntreanLokasiResponse, []dto.AntreanItemResponse) {
+	lok := dto.AntreanLokasiResponse{
+		LokasiID:   items[0].LokasiID.String(),
+		Lokasi:     items[0].Lokasi,
+		TotalHewan: len(items),
+		Berikutnya: []dto.AntreanItemResponse{},
+	}
+
+	var waktu []time.Time
+	var terakhir *time.Time
+	antre := items[:0:0]
+	for _, it := range items {
+		if it.UrutanAktual == nil {
+			antre = append(antre, it)
+			continue
+		}
+		lok.Selesai++
+		if it.DisembelihAt != nil {
+			waktu = append(waktu, *it.DisembelihAt)
+			if terakhir == nil || it.DisembelihAt.After(*terakhir) {
+				terakhir = it.DisembelihAt
+			}
+		}
+	}
+
+	interval := estimasiInterval(waktu, s.durasiDefault)
+	lok.IntervalMenit = math.Round(interval.Minutes()*10) / 10
+
+	mulai := now
+	if terakhir != nil && terakhir.Add(interval).After(now) {
+		mulai = terakhir.Add(interval)
+	}
+
+	var saya []dto.AntreanItemResponse
+	for i := 0; i < lok.Selesai; i++ {
+		item := toAntreanItem(items[i], 0, nil, milik)
+		if i == lok.Selesai-1 {
+			lok.Terakhir = &item
+		}
+		if item.MilikSaya {
+			saya = append(saya, item)
+		}
+	}
+	// hewan yang sudah check-in mulai memakai waktu mulainya, antrean setelahnya dihitung dari situ
+	offset := 0
+	if len(antre) > 0 && antre[0].MulaiAt != nil {
+		mulai = laterOf(now, antre[0].MulaiAt.Add(interval))
+		offset = 1
+	}
+	for k, it := range antre {
+		perkiraan := mulai.Add(time.Duration(k-offset) * interval)
+		if k < offset {
+			perkiraan = *it.MulaiAt
+		}
+		item := toAntreanItem(it, k+1, &perkiraan, milik)
+		switch {
+		case k == 0:
+			lok.SaatIni = &item
+		case k <= n:
+			lok.Berikutnya = append(lok.Berikutnya, item)
+		}
+		if item.MilikSaya {
+			saya = append(saya, item)
+		}
+	}
+	return lok, saya
+}
+
+func estimasiInterval(waktu []time.Time, fallback time.Duration) time.Duration {
+	if len(waktu) < 2 {
+		return fallback
+	}
+	sort.Slice(waktu, func(i, j int) bool { return waktu[i].Before(waktu[j]) })
+	jeda := make([]time.Duration, 0, len(waktu)-1)
+	for i := 1; i < len(waktu); i++ {
+		jeda = append(jeda, waktu[i].Sub(waktu[i-1]))
+	}
+	sort.Slice(jeda, func(i, j int) bool { return jeda[i] < jeda[j] })
+	median := jeda[len(jeda)/2]
+	if len(jeda)%2 == 0 {
+		median = (jeda[len(jeda)/2-1] + median) / 2
+	}
+	if median <= 0 {
+		return fallback
+	}
+	return median
+}
+
+func toAntreanItem(a *model.AntreanPenyembelihan, posisi int, perkiraan *time.Time, milik map[uuid.UUID]bool) dto.AntreanItemResponse {
+	return dto.AntreanItemResponse{
+		HewanID:       a.HewanID.String(),
+		Jenis:         a.Jenis,
+		Status:        a.Status,
+		Lokasi:        a.Lokasi,
+		UrutanRencana: a.UrutanRencana,
+		UrutanAktual:  a.UrutanAktual,
+		Posisi:        posisi,
+		PerkiraanJam:  perkiraan,
+		MulaiAt:       a.MulaiAt,
+		DisembelihAt:  a.DisembelihAt,
+		MilikSaya:     milik[a.HewanID],
+	}
+}
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/pdf"
+)
+
+var (
+	ErrBatchAntarNotFound = errors.New("Batch antar not found")
+	ErrStopAntarNotFound  = errors.New("Stop not found in this batch")
+	ErrStopAntarSelesai   = errors.New("Stop is already delivered or refused")
+	ErrBukanKurirBatch    = errors.New("You are not the courier of this batch")
+)
+
+type BatchAntarService interface {
+	Create(ctx context.Context, req dto.CreateBatchAntarRequest) (*dto.BatchAntarResponse, error)
+	// Kelompokkan membuat batch per RT/RW dari penerima antar_rumah yang belum terdistribusi dan belum terjadwal
+	Kelompokkan(ctx context.Context, req dto.KelompokkanBatchAntarRequest) ([]dto.BatchAntarResponse, error)
+	GetAll(ctx context.Context, tanggal *time.Time) ([]dto.BatchAntarResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.BatchAntarResponse, error)
+	// GetMine mengambil batch lengkap milik kurir yang akunnya ditautkan ke profil petugas
+	GetMine(ctx context.Context, userID uuid.UUID, tanggal *time.Time) ([]dto.BatchAntarResponse, error)
+	AssignKurir(ctx context.Context, id uuid.UUID, req dto.AssignKurirRequest) (*dto.BatchAntarResponse, error)
+	// CetakManifest menulis manifest pengantaran; selain admin/panitia hanya kurir batch yang boleh mencetak
+	CetakManifest(ctx context.Context, user model.User, id uuid.UUID, w io.Writer) error
+	// UpdateStop mencatat hasil kunjungan; status terkirim sekaligus mencatat distribusi daging
+	UpdateStop(ctx context.Context, user model.User, batchID, stopID uuid.UUID, req dto.UpdateStopAntarRequest) (*dto.UpdateStopAntarResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type batchAntarService struct {
+	repo              repository.BatchAntarRepository
+	penerimaRepo      repository.PenerimaDagingRepository
+	lokasiRepo        repository.LokasiRepository
+	petugasRepo       repository.PetugasRepository
+	distribusiRepo    repository.DistribusiDagingRepository
+	distribusiService DistribusiDagingService
+	periode           PeriodeKurbanService
+	tx                repository.TxManager
+}
+
+func NewBatchAntarService(repo repository.BatchAntarRepository, penerimaRepo repository.PenerimaDagingRepository, lokasiRepo repository.LokasiRepository, petugasRepo repository.PetugasRepository, distribusiRepo repository.DistribusiDagingRepository, distribusiService DistribusiDagingService, periode PeriodeKurbanService, tx repository.TxManager) BatchAntarService {
+	return &batchAntarService{repo: repo, penerimaRepo: penerimaRepo, lokasiRepo: lokasiRepo, petugasRepo: petugasRepo, distribusiRepo: distribusiRepo, distribusiService: distribusiService, periode: periode, tx: tx}
+}
+
+func (s *batchAntarService) Create(ctx context.Context, req dto.CreateBatchAntarRequest) (*dto.BatchAntarResponse, error) {
+	tanggal, err := time.Parse("2006-01-02", req.Tanggal)
+	if err != nil {
+		return nil, errors.New("Invalid date format. Use YYYY-MM-DD")
+	}
+	lokasiID, err := s.lokasi(ctx, req.LokasiID)
+	if err != nil {
+		return nil, err
+	}
+	kurirID, err := s.kurir(ctx, req.KurirID)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := nomorWilayah(req.RT)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := nomorWilayah(req.RW)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	b := &model.BatchAntar{
+		ID:         uuid.New(),
+		Nama:       strings.TrimSpace(req.Nama),
+		Tanggal:    tanggal,
+		LokasiID:   lokasiID,
+		RT:         rt,
+		RW:         rw,
+		KurirID:    kurirID,
+		Catatan:    req.Catatan,
+		Created_At: now,
+		Updated_At: now,
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for i, item := range req.Stop {
+		penerimaID, err := uuid.Parse(item.PenerimaID)
+		if err != nil {
+			return nil, errors.New("Invalid Penerima ID")
+		}
+		if seen[penerimaID] {
+			return nil, fmt.Errorf("Penerima %s is listed more than once", penerimaID)
+		}
+		seen[penerimaID] = true
+
+		p, err := s.penerimaRepo.GetByID(ctx, penerimaID)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, fmt.Errorf("Penerima %s not found", penerimaID)
+		}
+		if strings.TrimSpace(derefString(p.Alamat)) == "" {
+			return nil, fmt.Errorf("Penerima %s has no alamat", p.Name)
+		}
+
+		b.Stop = append(b.Stop, model.BatchAntarStop{
+			ID:          uuid.New(),
+			PenerimaID:  penerimaID,
+			Urutan:      i + 1,
+			JumlahPaket: item.JumlahPaket,
+			Status:      model.StopMenunggu,
+		})
+	}
+
+	var res dto.BatchAntarResponse
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, b); err != nil {
+			return err
+		}
+		saved, err := s.repo.GetById(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+		res = dto.ToBatchAntarResponse(saved)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *batchAntarService) Kelompokkan(ctx context.Context, req dto.KelompokkanBatchAntarRequest) ([]dto.BatchAntarResponse, error) {
+	tanggal, err := time.Parse("2006-01-02", req.Tanggal)
+	if err != nil {
+		return nil, errors.New("Invalid date format. Use YYYY-MM-DD")
+	}
+	lokasiID, err := s.lokasi(ctx, req.LokasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.BatchAntarResponse{}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		rekap, err := s.distribusiRepo.GetRekapPenerima(ctx, model.TahunDistribusi(tanggal, offset), offset)
+		if err != nil {
+			return err
+		}
+		terjadwal, err := s.repo.GetPenerimaTerjadwal(ctx)
+		if err != nil {
+			return err
+		}
+
+		// kunci kelompok: RW saja, atau RW/RT; penerima tanpa RT/RW dikumpulkan di kelompok kosong
+		kelompok := map[string][]*model.RekapDistribusiPenerima{}
+		for _, rk := range rekap {
+			p := rk.Penerima
+			if !p.AntarRumah || !rk.BelumTerdistribusi() || terjadwal[p.ID] || strings.TrimSpace(derefString(p.Alamat)) == "" {
+				continue
+			}
+			key := derefString(p.RW)
+			if req.Kelompok == "rt" {
+				key += "/" + derefString(p.RT)
+			}
+			kelompok[key] = append(kelompok[key], rk)
+		}
+
+		keys := make([]string, 0, len(kelompok))
+		for k := range kelompok {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		now := time.Now()
+		for _, key := range keys {
+			anggota := kelompok[key]
+			// alamat yang berdekatan biasanya berurutan secara alfabet, jadi dipakai sebagai urutan rute awal
+			sort.SliceStable(anggota, func(i, j int) bool {
+				return derefString(anggota[i].Penerima.Alamat) < derefString(anggota[j].Penerima.Alamat)
+			})
+
+			first := anggota[0].Penerima
+			rw, rt := first.RW, first.RT
+			if req.Kelompok == "rw" {
+				rt = nil
+			}
+
+			bagian := [][]*model.RekapDistribusiPenerima{anggota}
+			if req.MaksStop > 0 && len(anggota) > req.MaksStop {
+				bagian = nil
+				for i := 0; i < len(anggota); i += req.MaksStop {
+					bagian = append(bagian, anggota[i:min(i+req.MaksStop, len(anggota))])
+				}
+			}
+
+			for i, list := range bagian {
+				nama := namaWilayah(rt, rw)
+				if len(bagian) > 1 {
+					nama = fmt.Sprintf("%s (%d)", nama, i+1)
+				}
+				b := &model.BatchAntar{
+					ID:         uuid.New(),
+					Nama:       "Antar " + nama,
+					Tanggal:    tanggal,
+					LokasiID:   lokasiID,
+					RT:         rt,
+					RW:         rw,
+					Created_At: now,
+					Updated_At: now,
+				}
+				for j, rk := range list {
+					paket := req.JumlahPaket
+					if rk.AlokasiPaket != nil {
+						paket = *rk.AlokasiPaket - rk.PaketDiterima
+					}
+					b.Stop = append(b.Stop, model.BatchAntarStop{
+						ID:          uuid.New(),
+						PenerimaID:  rk.Penerima.ID,
+						Urutan:      j + 1,
+						JumlahPaket: paket,
+						Status:      model.StopMenunggu,
+					})
+				}
+
+				if err := s.repo.Create(ctx, b); err != nil {
+					return err
+				}
+				saved, err := s.repo.GetById(ctx, b.ID)
+				if err != nil {
+					return err
+				}
+				res = append(res, dto.ToBatchAntarResponse(saved))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *batchAntarService) GetAll(ctx context.Context, tanggal *time.Time) ([]dto.BatchAntarResponse, error) {
+	list, err := s.repo.GetAll(ctx, tanggal, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.BatchAntarResponse{}
+	for _, b := range list {
+		res = append(res, dto.ToBatchAntarResponse(b))
+	}
+	return res, nil
+}
+
+func (s *batchAntarService) GetByID(ctx context.Context, id uuid.UUID) (*dto.BatchAntarResponse, error) {
+	b, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBatchAntarNotFound
+	}
+
+	res := dto.ToBatchAntarResponse(b)
+	return &res, nil
+}
+
+func (s *batchAntarService) GetMine(ctx context.Context, userID uuid.UUID, tanggal *time.Time) ([]dto.BatchAntarResponse, error) {
+	p, err := s.petugasRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("You are not registered as petugas")
+	}
+
+	list, err := s.repo.GetAll(ctx, tanggal, &p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.BatchAntarResponse{}
+	for _, b := range list {
+		full, err := s.repo.GetById(ctx, b.ID)
+		if err != nil {
+			return nil, err
+		}
+		if full != nil {
+			res = append(res, dto.ToBatchAntarResponse(full))
+		}
+	}
+	return res, nil
+}
+
+func (s *batchAntarService) AssignKurir(ctx context.Context, id uuid.UUID, req dto.AssignKurirRequest) (*dto.BatchAntarResponse, error) {
+	kurirID, err := s.kurir(ctx, req.KurirID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetKurir(ctx, id, kurirID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *batchAntarService) CetakManifest(ctx context.Context, user model.User, id uuid.UUID, w io.Writer) error {
+	b, err := s.batchUntuk(ctx, user, id)
+	if err != nil {
+		return err
+	}
+
+	m := pdf.Manifest{
+		Judul:      "Manifest Pengantaran: " + b.Nama,
+		Tanggal:    b.Tanggal.Format("02-01-2006"),
+		Wilayah:    namaWilayah(b.RT, b.RW),
+		Kurir:      derefString(b.KurirNama),
+		KurirPhone: derefString(b.KurirPhone),
+		Catatan:    derefString(b.Catatan),
+	}
+	if b.LokasiNama != nil {
+		m.Wilayah += ", berangkat dari " + *b.LokasiNama
+	}
+
+	stops := make([]pdf.ManifestStop, 0, len(b.Stop))
+	for _, st := range b.Stop {
+		ms := pdf.ManifestStop{
+			Urutan:      st.Urutan,
+			Nama:        st.PenerimaNama,
+			Alamat:      derefString(st.PenerimaAlamat),
+			Phone:       derefString(st.PenerimaPhone),
+			JumlahPaket: st.JumlahPaket,
+		}
+		if st.PenerimaRT != nil || st.PenerimaRW != nil {
+			ms.Alamat += " (" + namaWilayah(st.PenerimaRT, st.PenerimaRW) + ")"
+		}
+		if st.Status != model.StopMenunggu {
+			ms.Status = strings.ReplaceAll(st.Status, "_", " ")
+		}
+		stops = append(stops, ms)
+	}
+	return pdf.ManifestAntar(w, m, stops)
+}
+
+func (s *batchAntarService) UpdateStop(ctx context.Context, user model.User, batchID, stopID uuid.UUID, req dto.UpdateStopAntarRequest) (*dto.UpdateStopAntarResponse, error) {
+	b, err := s.batchUntuk(ctx, user, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res dto.UpdateStopAntarResponse
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		st, err := s.repo.LockStop(ctx, batchID, stopID)
+		if err != nil {
+			return err
+		}
+		if st == nil {
+			return ErrStopAntarNotFound
+		}
+		if !st.Terbuka() {
+			return ErrStopAntarSelesai
+		}
+
+		now := time.Now()
+		if req.Status == model.StopTerkirim {
+			// tanggal distribusi adalah hari daging benar-benar diserahkan, bisa berbeda dari rencana batch
+			var lokasiID *string
+			if b.LokasiID != nil {
+				id := b.LokasiID.String()
+				lokasiID = &id
+			}
+			dis, err := s.distribusiService.CreateOtomatis(ctx, dto.CreateDistribusiRequest{
+				PenerimaID:        st.PenerimaID.String(),
+				LokasiID:          lokasiID,
+				JumlahPaket:       st.JumlahPaket,
+				TanggalDistribusi: now.Format("2006-01-02"),
+			})
+			if err != nil {
+				return err
+			}
+			disID, err := uuid.Parse(dis.ID)
+			if err != nil {
+				return err
+			}
+			st.DistribusiID = &disID
+			res.Distribusi = dis
+		}
+
+		st.Status = req.Status
+		st.Catatan = req.Catatan
+		st.DiperbaruiOleh = utils.ActorFromContext(ctx)
+		st.DiperbaruiAt = &now
+		if err := s.repo.UpdateStop(ctx, st); err != nil {
+			return err
+		}
+		res.Stop = dto.ToBatchAntarStopResponse(st)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *batchAntarService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// batchUntuk mengambil batch lengkap dan memastikan user boleh mengaksesnya: admin/panitia,
+// atau kurir yang ditugaskan pada batch tersebut
+func (s *batchAntarService) batchUntuk(ctx context.Context, user model.User, id uuid.UUID) (*model.BatchAntar, error) {
+	b, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBatchAntarNotFound
+	}
+	if user.Role == "admin" || user.Role == "panitia" {
+		return b, nil
+	}
+
+	p, err := s.petugasRepo.GetByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || b.KurirID == nil || *b.KurirID != p.ID {
+		return nil, ErrBukanKurirBatch
+	}
+	return b, nil
+}
+
+func (s *batchAntarService) lokasi(ctx context.Context, raw *string) (*uuid.UUID, error) {
+	if raw == nil || *raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*raw)
+	if err != nil {
+		return nil, errors.New("Invalid lokasi ID")
+	}
+	if _, err := getLokasi(ctx, s.lokasiRepo, id, model.LokasiDistribusi); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// kurir memastikan petugas ada dan berperan kurir; nil berarti batch tanpa kurir
+func (s *batchAntarService) kurir(ctx context.Context, raw *string) (*uuid.UUID, error) {
+	if raw == nil || *raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*raw)
+	if err != nil {
+		return nil, errors.New("Invalid kurir ID")
+	}
+	p, err := s.petugasRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Petugas not found")
+	}
+	if !p.MemilikiPeran(model.PeranKurir) {
+		return nil, fmt.Errorf("Petugas %s is not a kurir", p.Nama)
+	}
+	return &id, nil
+}
+
+// namaWilayah menulis "RT 001/RW 002", "RW 002", atau "Tanpa RT/RW"
+func namaWilayah(rt, rw *string) string {
+	switch {
+	case rt != nil && rw != nil:
+		return fmt.Sprintf("RT %s/RW %s", *rt, *rw)
+	case rw != nil:
+		return "RW " + *rw
+	case rt != nil:
+		return "RT " + *rt
+	}
+	return "Tanpa RT/RW"
+}
+
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	utilsservice "github.com/wahyujatirestu/sahabat-kurban/utils/service"
+)
+
+type BuktiPenyembelihanService interface {
+	Upload(ctx context.Context, penyembelihanID uuid.UUID, uploadedBy *uuid.UUID, files []*multipart.FileHeader) ([]dto.BuktiPenyembelihanResponse, error)
+	GetAll(ctx context.Context, penyembelihanID uuid.UUID, pekurbanID *uuid.UUID) ([]dto.BuktiPenyembelihanResponse, error)
+	Delete(ctx context.Context, penyembelihanID, id uuid.UUID) error
+	TandaiLengkap(ctx context.Context, penyembelihanID uuid.UUID) (*dto.BuktiLengkapResponse, error)
+}
+
+type buktiPenyembelihanService struct {
+	repo         repository.BuktiPenyembelihanRepository
+	psRepo       repository.PenyembelihanRepository
+	hRepo        repository.HewanKurbanRepository
+	pRepo        repository.PekurbanRepository
+	storage      utilsservice.FileStorage
+	emailService utilsservice.EmailService
+	periode      PeriodeKurbanService
+	maxSize      int64
+	maxFiles     int
+}
+
+func NewBuktiPenyembelihanService(repo repository.BuktiPenyembelihanRepository, psRepo repository.PenyembelihanRepository, hRepo repository.HewanKurbanRepository, pRepo repository.PekurbanRepository, storage utilsservice.FileStorage, emailService utilsservice.EmailService, periode PeriodeKurbanService, maxSize int64, maxFiles int) BuktiPenyembelihanService {
+	return &buktiPenyembelihanService{repo: repo, psRepo: psRepo, hRepo: hRepo, pRepo: pRepo, storage: storage, emailService: emailService, periode: periode, maxSize: maxSize, maxFiles: maxFiles}
+}
+
+// tipe file bukti yang diterima beserta ekstensi simpannya
+var buktiContentTypes = map[string]struct {
+	tipe string
+	ext  string
+}{
+	"image/jpeg":      {model.BuktiFoto, ".jpg"},
+	"image/png":       {model.BuktiFoto, ".png"},
+	"image/webp":      {model.BuktiFoto, ".webp"},
+	"video/mp4":       {model.BuktiVideo, ".mp4"},
+	"video/webm":      {model.BuktiVideo, ".webm"},
+	"video/quicktime": {model.BuktiVideo, ".mov"},
+}
+
+// Upload menyimpan foto/video bukti penyembelihan. Semua file divalidasi lebih dulu sehingga
+// satu file yang ditolak membatalkan seluruh unggahan.
+func (s *buktiPenyembelihanService) Upload(ctx context.Context, penyembelihanID uuid.UUID, uploadedBy *uuid.UUID, files []*multipart.FileHeader) ([]dto.BuktiPenyembelihanResponse, error) {
+	if len(files) == 0 {
+		return nil, errors.New("No file uploaded")
+	}
+	if len(files) > s.maxFiles {
+		return nil, fmt.Errorf("Upload at most %d files at once", s.maxFiles)
+	}
+
+	p, err := s.getPenyembelihan(ctx, penyembelihanID)
+	if err != nil {
+		return nil, err
+	}
+	if p.MulaiAt == nil && p.UrutanAktual == nil {
+		return nil, errors.New("Penyembelihan has not started")
+	}
+	if p.BuktiLengkapAt != nil {
+		return nil, errors.New("Bukti penyembelihan is already complete")
+	}
+
+	for _, fh := range files {
+		if fh.Size > s.maxSize {
+			return nil, fmt.Errorf("File %s exceeds maximum size of %d MB", fh.Filename, s.maxSize>>20)
+		}
+	}
+
+	var saved []*model.BuktiPenyembelihan
+	hapus := func() {
+		for _, b := range saved {
+			if err := s.storage.Delete(b.Path); err != nil {
+				log.Printf("failed to delete bukti file %s: %v", b.Path, err)
+			}
+		}
+	}
+
+	for _, fh := range files {
+		b, err := s.simpanFile(p.ID, fh)
+		if err != nil {
+			hapus()
+			return nil, err
+		}
+		b.UploadedBy = uploadedBy
+		saved = append(saved, b)
+	}
+
+	res := []dto.BuktiPenyembelihanResponse{}
+	for _, b := range saved {
+		if err := s.repo.Create(ctx, b); err != nil {
+			// bukti yang sudah tercatat tetap disimpan, hanya file yang belum tercatat yang dibuang
+			saved = saved[len(res):]
+			hapus()
+			return nil, err
+		}
+		res = append(res, dto.ToBuktiPenyembelihanResponse(b, s.storage.URL(b.Path)))
+	}
+	return res, nil
+}
+
+func (s *buktiPenyembelihanService) simpanFile(penyembelihanID uuid.UUID, fh *multipart.FileHeader) (*model.BuktiPenyembelihan, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" && strings.EqualFold(filepath.Ext(fh.Filename), ".mov") {
+		contentType = "video/quicktime"
+	}
+	jenis, ok := buktiContentTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("File %s must be a photo (jpeg/png/webp) or video (mp4/webm/mov)", fh.Filename)
+	}
+
+	p, err := s.storage.Save("bukti/"+penyembelihanID.String(), jenis.ext, io.MultiReader(bytes.NewReader(head), f))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BuktiPenyembelihan{
+		ID:              uuid.New(),
+		PenyembelihanID: penyembelihanID,
+		Tipe:            jenis.tipe,
+		Path:            p,
+		NamaAsli:        filepath.Base(fh.Filename),
+		ContentType:     contentType,
+		Ukuran:          fh.Size,
+		Created_At:      time.Now(),
+	}, nil
+}
+
+// GetAll menampilkan bukti penyembelihan; jika pekurbanID diisi, hanya pekurban pemilik porsi hewan yang boleh melihat
+func (s *buktiPenyembelihanService) GetAll(ctx context.Context, penyembelihanID uuid.UUID, pekurbanID *uuid.UUID) ([]dto.BuktiPenyembelihanResponse, error) {
+	p, err := s.getPenyembelihan(ctx, penyembelihanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pekurbanID != nil {
+		pekurban, err := s.pRepo.FindByHewanId(ctx, p.HewanID)
+		if err != nil {
+			return nil, err
+		}
+		milik := false
+		for _, pk := range pekurban {
+			if pk.ID == *pekurbanID {
+				milik = true
+				break
+			}
+		}
+		if !milik {
+			return nil, errors.New("You are not a pekurban of this hewan")
+		}
+	}
+
+	bukti, err := s.repo.GetByPenyembelihan(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(bukti), nil
+}
+
+func (s *buktiPenyembelihanService) Delete(ctx context.Context, penyembelihanID, id uuid.UUID) error {
+	p, err := s.getPenyembelihan(ctx, penyembelihanID)
+	if err != nil {
+		return err
+	}
+	if p.BuktiLengkapAt != nil {
+		return errors.New("Bukti penyembelihan is already complete")
+	}
+
+	b, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b == nil || b.PenyembelihanID != p.ID {
+		return errors.New("Bukti penyembelihan not found")
+	}
+
+	if err := s.repo.Delete(ctx, b.ID); err != nil {
+		return err
+	}
+	// data sudah terhapus; file yatim cukup dicatat
+	if err := s.storage.Delete(b.Path); err != nil {
+		log.Printf("failed to delete bukti file %s: %v", b.Path, err)
+	}
+	return nil
+}
+
+// TandaiLengkap menandai bukti lengkap lalu mengirim email berisi tautan bukti dan waktu penyembelihan
+// ke setiap pekurban yang porsinya terkonfirmasi. Kegagalan email tidak membatalkan penandaan.
+func (s *buktiPenyembelihanService) TandaiLengkap(ctx context.Context, penyembelihanID uuid.UUID) (*dto.BuktiLengkapResponse, error) {
+	p, err := s.getPenyembelihan(ctx, penyembelihanID)
+	if err != nil {
+		return nil, err
+	}
+	if p.UrutanAktual == nil {
+		return nil, errors.New("Penyembelihan has not finished")
+	}
+
+	bukti, err := s.repo.GetByPenyembelihan(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bukti) == 0 {
+		return nil, errors.New("Upload at least one photo or video first")
+	}
+
+	hewan, err := s.hRepo.GetById(ctx, p.HewanID)
+	if err != nil {
+		return nil, err
+	}
+	if hewan == nil {
+		return nil, errors.New("Hewan not found")
+	}
+	pekurban, err := s.pRepo.FindByHewanId(ctx, p.HewanID)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.psRepo.TandaiBuktiLengkap(ctx, p.ID, now); err != nil {
+		return nil, err
+	}
+	p.BuktiLengkapAt = &now
+
+	res := &dto.BuktiLengkapResponse{
+		Penyembelihan: dto.ToPenyembelihanResponse(p, offset),
+		Bukti:         s.toResponses(bukti),
+		TanpaEmail:    []string{},
+		Gagal:         []string{},
+	}
+
+	links := make([]string, 0, len(res.Bukti))
+	for _, b := range res.Bukti {
+		links = append(links, b.URL)
+	}
+	waktu := waktuDisembelih(p)
+
+	for _, pk := range pekurban {
+		nama := ""
+		if pk.Name != nil {
+			nama = *pk.Name
+		}
+		if pk.Email == nil || *pk.Email == "" {
+			res.TanpaEmail = append(res.TanpaEmail, nama)
+			continue
+		}
+		if err := s.emailService.SendBuktiPenyembelihanEmail(*pk.Email, nama, string(hewan.Jenis), hewan.ID.String(), waktu, links); err != nil {
+			log.Printf("failed to send bukti penyembelihan to %s: %v", *pk.Email, err)
+			res.Gagal = append(res.Gagal, nama)
+			continue
+		}
+		res.Terkirim++
+	}
+	return res, nil
+}
+
+func (s *buktiPenyembelihanService) getPenyembelihan(ctx context.Context, id uuid.UUID) (*model.Penyembelihan, error) {
+	p, err := s.psRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
+	return p, nil
+}
+
+func (s *buktiPenyembelihanService) toResponses(bukti []*model.BuktiPenyembelihan) []dto.BuktiPenyembelihanResponse {
+	res := []dto.BuktiPenyembelihanResponse{}
+	for _, b := range bukti {
+		res = append(res, dto.ToBuktiPenyembelihanResponse(b, s.storage.URL(b.Path)))
+	}
+	return res
+}
+
+// waktuDisembelih memakai check-in selesai, lalu check-in mulai, lalu tanggal jadwal
+func waktuDisembelih(p *model.Penyembelihan) time.Time {
+	switch {
+	case p.SelesaiAt != nil:
+		return p.SelesaiAt.In(time.Local)
+	case p.MulaiAt != nil:
+		return p.MulaiAt.In(time.Local)
+	}
+	t := p.TglPenyembelihan
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
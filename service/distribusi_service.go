@@ -2,13 +2,17 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
 	"github.com/wahyujatirestu/sahabat-kurban/model"
 	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils"
 )
 
 type DistribusiDagingService interface {
@@ -17,19 +21,201 @@ type DistribusiDagingService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*dto.DistribusiResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetTotalDistribusiPaket(ctx context.Context) (int, error)
-	GetPenerimaBelumTerdistribusi(ctx context.Context) ([]dto.PenerimaResponse, error)
+	// GetPenerimaBelumTerdistribusi mengambil penerima yang paket diterimanya belum memenuhi alokasi pada tahun
+	// Hijriah tersebut (0: tahun berjalan); penerima tanpa alokasi dianggap belum bila belum menerima apa pun
+	GetPenerimaBelumTerdistribusi(ctx context.Context, tahunHijri int) ([]dto.RekapDistribusiPenerimaResponse, error)
+	GetRekapPenerima(ctx context.Context, tahunHijri int) ([]dto.RekapDistribusiPenerimaResponse, error)
+	SaveAlokasi(ctx context.Context, req dto.SaveAlokasiRequest) ([]dto.RekapDistribusiPenerimaResponse, error)
+	GetRiwayatPenerima(ctx context.Context, penerimaID uuid.UUID) (*dto.RiwayatDistribusiPenerimaResponse, error)
+	GetRekapHewan(ctx context.Context) ([]dto.RekapDistribusiHewanResponse, error)
+	Rencanakan(ctx context.Context, req dto.RencanaDistribusiRequest) (*dto.RencanaDistribusiResponse, error)
+	CreateBulk(ctx context.Context, req dto.BulkDistribusiRequest) ([]dto.DistribusiResponse, error)
+	// CreateOtomatis membuat distribusi tanpa hewan_id/hewan; asal hewan dipilih otomatis dari stok
+	CreateOtomatis(ctx context.Context, req dto.CreateDistribusiRequest) (*dto.DistribusiResponse, error)
 }
 
 type distribusiDagingService struct {
 	repo repository.DistribusiDagingRepository
 	penerimaRepo repository.PenerimaDagingRepository	
+	lokasiRepo repository.LokasiRepository
+	hRepo repository.HewanKurbanRepository
+	psRepo repository.PenyembelihanRepository
+	phRepo repository.PekurbanHewanRepository
+	tx repository.TxManager
+	periode PeriodeKurbanService // offset hari periode aktif untuk menentukan tahun Hijriah
+	maksPerPenerima int // batas jumlah distribusi per penerima dalam satu tahun Hijriah
 }
 
-func NewDistribusiDagingService(repo repository.DistribusiDagingRepository, penerimaRepo repository.PenerimaDagingRepository, ) DistribusiDagingService {
-	return &distribusiDagingService{repo: repo, penerimaRepo: penerimaRepo}
+func NewDistribusiDagingService(repo repository.DistribusiDagingRepository, penerimaRepo repository.PenerimaDagingRepository, lokasiRepo repository.LokasiRepository, hRepo repository.HewanKurbanRepository, psRepo repository.PenyembelihanRepository, phRepo repository.PekurbanHewanRepository, tx repository.TxManager, periode PeriodeKurbanService, maksPerPenerima int) DistribusiDagingService {
+	return &distribusiDagingService{repo: repo, penerimaRepo: penerimaRepo, lokasiRepo: lokasiRepo, hRepo: hRepo, psRepo: psRepo, phRepo: phRepo, tx: tx, periode: periode, maksPerPenerima: maksPerPenerima}
+}
+
+// status hewan yang dagingnya sudah boleh dibagikan
+var hewanSiapDistribusi = map[string]bool{
+	model.HewanDisembelih:      true,
+	model.HewanDicacah:         true,
+	model.HewanDidistribusikan: true,
 }
 
 func (s *distribusiDagingService) Create(ctx context.Context, req dto.CreateDistribusiRequest) (*dto.DistribusiResponse, error) {
+	dis, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.simpan(ctx, []*model.DistribusiDaging{dis}); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToDistribusiResponse(dis)
+	return &res, nil
+}
+
+// CreateBulk menyimpan banyak distribusi (mis. hasil rencana yang sudah ditinjau panitia) dalam satu transaksi
+func (s *distribusiDagingService) CreateBulk(ctx context.Context, req dto.BulkDistribusiRequest) ([]dto.DistribusiResponse, error) {
+	var list []*model.DistribusiDaging
+	seen := map[string]bool{}
+	for _, it := range req.Distribusi {
+		if seen[it.PenerimaID] {
+			return nil, fmt.Errorf("Penerima %s is listed more than once", it.PenerimaID)
+		}
+		seen[it.PenerimaID] = true
+
+		dis, err := s.prepare(ctx, dto.CreateDistribusiRequest{
+			PenerimaID: it.PenerimaID,
+			LokasiID: req.LokasiID,
+			JumlahPaket: it.JumlahPaket,
+			TanggalDistribusi: req.TanggalDistribusi,
+			Hewan: it.Hewan,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Penerima %s: %w", it.PenerimaID, err)
+		}
+		list = append(list, dis)
+	}
+
+	if err := s.simpan(ctx, list); err != nil {
+		return nil, err
+	}
+
+	res := []dto.DistribusiResponse{}
+	for _, d := range list {
+		res = append(res, dto.ToDistribusiResponse(d))
+	}
+	return res, nil
+}
+
+func (s *distribusiDagingService) CreateOtomatis(ctx context.Context, req dto.CreateDistribusiRequest) (*dto.DistribusiResponse, error) {
+	penerimaID, err := uuid.Parse(req.PenerimaID)
+	if err != nil {
+		return nil, errors.New("Invalid Penerima ID")
+	}
+	penerima, err := s.penerimaRepo.GetByID(ctx, penerimaID)
+	if err != nil {
+		return nil, err
+	}
+	if penerima == nil {
+		return nil, errors.New("Penerima not found")
+	}
+
+	var dis *model.DistribusiDaging
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		asal, err := s.pilihHewan(ctx, penerima, req.JumlahPaket)
+		if err != nil {
+			return err
+		}
+		req.HewanID = nil
+		req.Hewan = asal
+
+		dis, err = s.prepare(ctx, req)
+		if err != nil {
+			return err
+		}
+		return s.simpan(ctx, []*model.DistribusiDaging{dis})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToDistribusiResponse(dis)
+	return &res, nil
+}
+
+// pilihHewan memilih asal daging untuk sejumlah paket: hewan milik pekurban penerima lebih dulu, lalu hewan
+// yang paling awal disembelih. Hewan yang jumlah paketnya belum dicatat dipakai terakhir tanpa batas stok.
+// Semua kandidat dikunci lebih dulu agar sisa paket yang dibaca tidak berubah sampai transaksi selesai.
+func (s *distribusiDagingService) pilihHewan(ctx context.Context, penerima *model.PenerimaDaging, jumlah int) ([]dto.DistribusiHewanRequest, error) {
+	rekap, err := s.repo.GetRekapHewan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	milik := map[uuid.UUID]bool{}
+	if penerima.PekurbanID != nil {
+		list, err := s.phRepo.GetByPekurbanId(ctx, *penerima.PekurbanID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ph := range list {
+			if id, err := uuid.Parse(ph.HewanID); err == nil && ph.Status == model.PorsiTerkonfirmasi {
+				milik[id] = true
+			}
+		}
+	}
+
+	var kandidat []*model.RekapDistribusiHewan
+	for _, r := range rekap {
+		if hewanSiapDistribusi[r.Status] {
+			kandidat = append(kandidat, r)
+		}
+	}
+	// urutan rekap sudah dari tanggal penyembelihan paling awal
+	sort.SliceStable(kandidat, func(i, j int) bool {
+		ci, cj := kandidat[i].PaketDihasilkan != nil, kandidat[j].PaketDihasilkan != nil
+		if ci != cj {
+			return ci
+		}
+		return milik[kandidat[i].HewanID] && !milik[kandidat[j].HewanID]
+	})
+
+	ids := make([]uuid.UUID, 0, len(kandidat))
+	for _, k := range kandidat {
+		ids = append(ids, k.HewanID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	for _, id := range ids {
+		if _, err := s.hRepo.LockById(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var asal []dto.DistribusiHewanRequest
+	perlu := jumlah
+	for _, k := range kandidat {
+		if perlu == 0 {
+			break
+		}
+		n := perlu
+		if k.PaketDihasilkan != nil {
+			terbagi, err := s.repo.SumPaketByHewan(ctx, k.HewanID)
+			if err != nil {
+				return nil, err
+			}
+			n = min(perlu, *k.PaketDihasilkan-terbagi)
+		}
+		if n <= 0 {
+			continue
+		}
+		asal = append(asal, dto.DistribusiHewanRequest{HewanID: k.HewanID.String(), JumlahPaket: n})
+		perlu -= n
+	}
+	if perlu > 0 {
+		return nil, fmt.Errorf("Not enough paket left: %d paket requested, %d available", jumlah, jumlah-perlu)
+	}
+	return asal, nil
+}
+
+// prepare memvalidasi request dan menyusun distribusi tanpa menyimpannya
+func (s *distribusiDagingService) prepare(ctx context.Context, req dto.CreateDistribusiRequest) (*model.DistribusiDaging, error) {
 	penerimaID, err := uuid.Parse(req.PenerimaID)
 	if err != nil {
 		return nil, errors.New("Invalid Penerima ID")
@@ -48,35 +234,109 @@ func (s *distribusiDagingService) Create(ctx context.Context, req dto.CreateDist
 		return nil, errors.New("Penerima not found")
 	}
 
-	existing, err := s.repo.FindByPenerimaID(ctx, penerimaID)
+	asal, err := parseAsalHewan(req)
 	if err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return nil, errors.New("Penerima already received distribution")
+
+	// lokasi distribusi opsional, tetapi harus lokasi bertipe distribusi atau keduanya
+	var lokasi *model.Lokasi
+	if req.LokasiID != nil && *req.LokasiID != "" {
+		lokasiID, err := uuid.Parse(*req.LokasiID)
+		if err != nil {
+			return nil, errors.New("Invalid lokasi ID")
+		}
+		lokasi, err = getLokasi(ctx, s.lokasiRepo, lokasiID, model.LokasiDistribusi)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	dis := &model.DistribusiDaging{
 		ID: uuid.New(),	
 		PenerimaID: penerimaID,
+		PenerimaName: penerima.Name,
+		DicatatOleh: utils.ActorFromContext(ctx),
 		JumlahPaket: req.JumlahPaket,
 		TanggalDistribusi: tanggalDistribusi,
 		Created_At: time.Now(),
 		Updated_At: time.Now(),
+		Hewan: asal,
 	}
 
-	if err := s.repo.Create(ctx, dis); err != nil {
-		return nil, err
+	if lokasi != nil {
+		dis.LokasiID = &lokasi.ID
+		dis.LokasiNama = &lokasi.Nama
 	}
 
-	res := dto.DistribusiResponse{
-		ID: dis.ID.String(),
-		PenerimaID: dis.PenerimaID.String(),
-		PenerimaName: penerima.Name,
-		JumlahPaket: dis.JumlahPaket,
-		TanggalDistribusi: dis.TanggalDistribusi,
+	return dis, nil
+}
+
+// simpan menyimpan semua distribusi dalam satu transaksi. Seluruh hewan asal lalu penerimanya dikunci lebih
+// dulu dengan urutan yang sama supaya simpan paralel tidak saling menunggu, lalu stok tiap hewan dan batas
+// distribusi tiap penerima diperiksa.
+func (s *distribusiDagingService) simpan(ctx context.Context, list []*model.DistribusiDaging) error {
+	var hewanIDs, penerimaIDs []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, d := range list {
+		for _, h := range d.Hewan {
+			if !seen[h.HewanID] {
+				seen[h.HewanID] = true
+				hewanIDs = append(hewanIDs, h.HewanID)
+			}
+		}
+		if !seen[d.PenerimaID] {
+			seen[d.PenerimaID] = true
+			penerimaIDs = append(penerimaIDs, d.PenerimaID)
+		}
 	}
-	return &res, nil
+	sort.Slice(hewanIDs, func(i, j int) bool { return hewanIDs[i].String() < hewanIDs[j].String() })
+	sort.Slice(penerimaIDs, func(i, j int) bool { return penerimaIDs[i].String() < penerimaIDs[j].String() })
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, id := range hewanIDs {
+			if _, err := s.hRepo.LockById(ctx, id); err != nil {
+				return err
+			}
+		}
+		for _, id := range penerimaIDs {
+			if _, err := s.penerimaRepo.LockById(ctx, id); err != nil {
+				return err
+			}
+		}
+		for _, d := range list {
+			if err := s.cekBatasPenerima(ctx, d); err != nil {
+				return err
+			}
+			for i := range d.Hewan {
+				if err := s.cekStokHewan(ctx, &d.Hewan[i]); err != nil {
+					return err
+				}
+			}
+			if err := s.repo.Create(ctx, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// cekBatasPenerima menolak distribusi yang melebihi batas jumlah distribusi penerima dalam tahun Hijriah yang sama
+func (s *distribusiDagingService) cekBatasPenerima(ctx context.Context, d *model.DistribusiDaging) error {
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return err
+	}
+	tahun := model.TahunDistribusi(d.TanggalDistribusi, offset)
+	mulai, selesai := model.RentangTahunHijri(tahun, offset)
+	n, err := s.repo.CountByPenerima(ctx, d.PenerimaID, mulai, selesai)
+	if err != nil {
+		return err
+	}
+	if n >= s.maksPerPenerima {
+		return fmt.Errorf("Penerima %s already received %d distributions in %d H (maximum %d)", d.PenerimaName, n, tahun, s.maksPerPenerima)
+	}
+	return nil
 }
 
 func (s *distribusiDagingService) GetAll(ctx context.Context) ([]dto.DistribusiResponse, error) {
@@ -107,26 +367,242 @@ func (s *distribusiDagingService) GetTotalDistribusiPaket(ctx context.Context) (
 	return s.repo.CountTotalPaket(ctx)
 }
 
-func (s *distribusiDagingService) GetPenerimaBelumTerdistribusi(ctx context.Context) ([]dto.PenerimaResponse, error) {
-	list, err := s.penerimaRepo.GetAll(ctx)
+func (s *distribusiDagingService) GetPenerimaBelumTerdistribusi(ctx context.Context, tahunHijri int) ([]dto.RekapDistribusiPenerimaResponse, error) {
+	offset, err := s.periode.OffsetHijri(ctx)
 	if err != nil {
 		return nil, err
-	}	
+	}
+	list, err := s.repo.GetRekapPenerima(ctx, tahunAtauBerjalan(tahunHijri, offset), offset)
+	if err != nil {
+		return nil, err
+	}
 
-	var result []dto.PenerimaResponse
-	for _, penerima := range list {
-		exist, err := s.repo.FindByPenerimaID(ctx, penerima.ID)
-		if err != nil {
-			return nil, err
+	result := []dto.RekapDistribusiPenerimaResponse{}
+	for _, r := range list {
+		if r.BelumTerdistribusi() {
+			result = append(result, dto.ToRekapDistribusiPenerimaResponse(r))
 		}
-		if exist == nil {
-			result = append(result, dto.ToPenerimaResponse(penerima))
+	}
+	return result, nil
+}
+
+func (s *distribusiDagingService) GetRekapPenerima(ctx context.Context, tahunHijri int) ([]dto.RekapDistribusiPenerimaResponse, error) {
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.GetRekapPenerima(ctx, tahunAtauBerjalan(tahunHijri, offset), offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.RekapDistribusiPenerimaResponse{}
+	for _, r := range list {
+		result = append(result, dto.ToRekapDistribusiPenerimaResponse(r))
+	}
+	return result, nil
+}
+
+func (s *distribusiDagingService) SaveAlokasi(ctx context.Context, req dto.SaveAlokasiRequest) ([]dto.RekapDistribusiPenerimaResponse, error) {
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tahun := tahunAtauBerjalan(req.TahunHijri, offset)
+	disimpan := map[uuid.UUID]bool{}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, it := range req.Alokasi {
+			penerimaID, err := uuid.Parse(it.PenerimaID)
+			if err != nil {
+				return errors.New("Invalid Penerima ID")
+			}
+			if disimpan[penerimaID] {
+				return fmt.Errorf("Penerima %s is listed more than once", penerimaID)
+			}
+			disimpan[penerimaID] = true
+
+			penerima, err := s.penerimaRepo.GetByID(ctx, penerimaID)
+			if err != nil {
+				return err
+			}
+			if penerima == nil {
+				return fmt.Errorf("Penerima %s not found", penerimaID)
+			}
+
+			if it.JumlahPaket == 0 {
+				if err := s.repo.DeleteAlokasi(ctx, penerimaID, tahun); err != nil {
+					return err
+				}
+				continue
+			}
+			now := time.Now()
+			if err := s.repo.SaveAlokasi(ctx, &model.AlokasiDistribusi{
+				PenerimaID: penerimaID,
+				TahunHijri: tahun,
+				JumlahPaket: it.JumlahPaket,
+				Created_At: now,
+				Updated_At: now,
+			}); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	list, err := s.repo.GetRekapPenerima(ctx, tahun, offset)
+	if err != nil {
+		return nil, err
+	}
+	result := []dto.RekapDistribusiPenerimaResponse{}
+	for _, r := range list {
+		if disimpan[r.Penerima.ID] {
+			result = append(result, dto.ToRekapDistribusiPenerimaResponse(r))
+		}
+	}
 	return result, nil
 }
 
+func (s *distribusiDagingService) GetRiwayatPenerima(ctx context.Context, penerimaID uuid.UUID) (*dto.RiwayatDistribusiPenerimaResponse, error) {
+	penerima, err := s.penerimaRepo.GetByID(ctx, penerimaID)
+	if err != nil {
+		return nil, err
+	}
+	if penerima == nil {
+		return nil, errors.New("Penerima not found")
+	}
+
+	list, err := s.repo.GetByPenerimaID(ctx, penerimaID)
+	if err != nil {
+		return nil, err
+	}
+	alokasi, err := s.repo.GetAlokasiByPenerima(ctx, penerimaID)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	periode := map[int]*dto.PeriodeDistribusiResponse{}
+	ambil := func(tahun int) *dto.PeriodeDistribusiResponse {
+		p, ok := periode[tahun]
+		if !ok {
+			p = &dto.PeriodeDistribusiResponse{TahunHijri: tahun, Distribusi: []dto.DistribusiResponse{}}
+			periode[tahun] = p
+		}
+		return p
+	}
+	for _, a := range alokasi {
+		jumlah := a.JumlahPaket
+		ambil(a.TahunHijri).AlokasiPaket = &jumlah
+	}
+	for _, d := range list {
+		p := ambil(model.TahunDistribusi(d.TanggalDistribusi, offset))
+		p.PaketDiterima += d.JumlahPaket
+		p.Distribusi = append(p.Distribusi, dto.ToDistribusiResponse(d))
+	}
+
+	res := &dto.RiwayatDistribusiPenerimaResponse{Penerima: dto.ToPenerimaResponse(penerima), Periode: []dto.PeriodeDistribusiResponse{}}
+	for _, p := range periode {
+		res.Periode = append(res.Periode, *p)
+	}
+	sort.Slice(res.Periode, func(i, j int) bool { return res.Periode[i].TahunHijri > res.Periode[j].TahunHijri })
+	return res, nil
+}
+
+// tahunAtauBerjalan mengganti tahun 0 dengan tahun Hijriah hari ini
+func tahunAtauBerjalan(tahunHijri, offset int) int {
+	if tahunHijri == 0 {
+		return model.TahunDistribusi(time.Now(), offset)
+	}
+	return tahunHijri
+}
+
+func (s *distribusiDagingService) GetRekapHewan(ctx context.Context) ([]dto.RekapDistribusiHewanResponse, error) {
+	list, err := s.repo.GetRekapHewan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.RekapDistribusiHewanResponse{}
+	for _, r := range list {
+		res = append(res, dto.ToRekapDistribusiHewanResponse(r))
+	}
+	return res, nil
+}
+
+// parseAsalHewan membaca asal daging dari request; hewan_id berarti seluruh paket dari satu hewan.
+func parseAsalHewan(req dto.CreateDistribusiRequest) ([]model.DistribusiHewan, error) {
+	hewanID := req.HewanID != nil && *req.HewanID != ""
+	if hewanID == (len(req.Hewan) > 0) {
+		return nil, errors.New("Provide either hewan_id or hewan")
+	}
+
+	items := req.Hewan
+	if hewanID {
+		items = []dto.DistribusiHewanRequest{{HewanID: *req.HewanID, JumlahPaket: req.JumlahPaket}}
+	}
+
+	total := 0
+	seen := map[uuid.UUID]bool{}
+	var asal []model.DistribusiHewan
+	for _, it := range items {
+		id, err := uuid.Parse(it.HewanID)
+		if err != nil {
+			return nil, errors.New("Invalid hewan ID")
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("Hewan %s is listed more than once", id)
+		}
+		seen[id] = true
+		total += it.JumlahPaket
+		asal = append(asal, model.DistribusiHewan{HewanID: id, JumlahPaket: it.JumlahPaket})
+	}
+	if total != req.JumlahPaket {
+		return nil, fmt.Errorf("Total paket per hewan (%d) must equal jumlah_paket (%d)", total, req.JumlahPaket)
+	}
+	return asal, nil
+}
+
+// cekStokHewan memastikan hewan sudah disembelih dan, jika jumlah paket hasil penyembelihan sudah dicatat,
+// paket yang dibagikan tidak melebihinya. Baris hewan dikunci agar distribusi paralel tidak saling menyalip.
+func (s *distribusiDagingService) cekStokHewan(ctx context.Context, dh *model.DistribusiHewan) error {
+	h, err := s.hRepo.LockById(ctx, dh.HewanID)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return errors.New("Hewan kurban not found")
+	}
+	if !hewanSiapDistribusi[h.Status] {
+		return fmt.Errorf("Hewan %s has not been slaughtered (status %s)", h.ID, h.Status)
+	}
+	dh.Jenis = h.Jenis
+
+	ps, err := s.psRepo.GetByHewanID(ctx, h.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ps.JumlahPaket == nil {
+		return nil
+	}
+
+	terbagi, err := s.repo.SumPaketByHewan(ctx, h.ID)
+	if err != nil {
+		return err
+	}
+	if sisa := *ps.JumlahPaket - terbagi; dh.JumlahPaket > sisa {
+		return fmt.Errorf("Hewan %s only has %d paket left of %d produced", h.ID, max(sisa, 0), *ps.JumlahPaket)
+	}
+	return nil
+}
 
 func (s *distribusiDagingService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
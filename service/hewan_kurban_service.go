@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,18 +15,28 @@ import (
 type HewanKurbanService interface {
 	Create(ctx context.Context, req dto.CreateHewanKurbanRequest) (*dto.HewanKurbanResponse, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*dto.HewanKurbanResponse, error)
-	GetAll(ctx context.Context) ([]dto.HewanKurbanResponse, error)
+	GetAll(ctx context.Context, status string) ([]dto.HewanKurbanResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req dto.UpdateHewanKurbanRequest) (*dto.HewanKurbanResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetPublicCatalog(ctx context.Context, q dto.PublicHewanQuery) ([]dto.PublicHewanResponse, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, req dto.UpdateStatusHewanRequest) (*dto.HewanKurbanResponse, error)
+	GetRiwayatStatus(ctx context.Context, id uuid.UUID) ([]dto.RiwayatStatusHewanResponse, error)
 }
 
+// ErrRentangHargaInvalid dikembalikan ketika filter min_harga lebih besar dari max_harga
+var ErrRentangHargaInvalid = errors.New("min_harga must not be greater than max_harga")
+
 type hewanKurbanService struct {
 	repo 	repository.HewanKurbanRepository
 	pRepo	repository.PenyembelihanRepository
+	phRepo	repository.PekurbanHewanRepository
+	jRepo	repository.JenisHewanRepository
+	lifecycle	HewanLifecycleService
+	tx		repository.TxManager
 }
 
-func NewHewanKurbanService(r repository.HewanKurbanRepository, pr repository.PenyembelihanRepository) HewanKurbanService {
-	return &hewanKurbanService{repo: r, pRepo: pr}
+func NewHewanKurbanService(r repository.HewanKurbanRepository, pr repository.PenyembelihanRepository, phr repository.PekurbanHewanRepository, jr repository.JenisHewanRepository, lifecycle HewanLifecycleService, tx repository.TxManager) HewanKurbanService {
+	return &hewanKurbanService{repo: r, pRepo: pr, phRepo: phr, jRepo: jr, lifecycle: lifecycle, tx: tx}
 }
 
 func (s *hewanKurbanService) Create(ctx context.Context, req dto.CreateHewanKurbanRequest) (*dto.HewanKurbanResponse, error) {
@@ -34,6 +45,14 @@ func (s *hewanKurbanService) Create(ctx context.Context, req dto.CreateHewanKurb
 		return nil, errors.New("Invalid date format, must be YYYY-MM-DD")
 	}
 
+	jenis, err := getJenis(ctx, s.jRepo, model.JenisHewan(req.Jenis))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUmur(jenis, req.UmurBulan); err != nil {
+		return nil, err
+	}
+
 	isPrivate := false
 	if req.IsPrivate != nil {
 		isPrivate = *req.IsPrivate
@@ -44,6 +63,10 @@ func (s *hewanKurbanService) Create(ctx context.Context, req dto.CreateHewanKurb
 	if isPrivate {
 		harga = 0
 	} else {
+		// harga kosong memakai harga default jenis jika tersedia
+		if req.Harga == nil && jenis.HargaDefault != nil {
+			req.Harga = jenis.HargaDefault
+		}
 		if req.Harga == nil || *req.Harga <= 0 {
 			return nil, errors.New("Field harga must be filled and greater than 0 if hewan is not private")
 		}
@@ -54,14 +77,23 @@ func (s *hewanKurbanService) Create(ctx context.Context, req dto.CreateHewanKurb
 		ID: 				uuid.New(),
 		Jenis: 				model.JenisHewan(req.Jenis),
 		Berat: 				req.Berat,
+		UmurBulan:			req.UmurBulan,
 		Harga:   			harga,
 		IsPrivate:          isPrivate,
+		FotoURL:            req.FotoURL,
 		TanggalPendaftaran: tanggal,
+		Status:				model.HewanTerdaftar,
 		Created_At: 		time.Now(),
 		Updated_At: 		time.Now(),
 	}
 
-	if err := s.repo.Create(ctx, h); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, h); err != nil {
+			return err
+		}
+		return s.lifecycle.RecordInitial(ctx, h)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -87,8 +119,19 @@ func (s *hewanKurbanService) GetByID(ctx context.Context, id uuid.UUID) (*dto.He
 	return &res, nil
 }
 
-func (s *hewanKurbanService) GetAll(ctx context.Context) ([]dto.HewanKurbanResponse, error) {
-	data, err := s.repo.GetAll(ctx)
+func (s *hewanKurbanService) GetAll(ctx context.Context, status string) ([]dto.HewanKurbanResponse, error) {
+	var (
+		data []*model.HewanKurban
+		err  error
+	)
+	if status != "" {
+		if !isValidHewanStatus(status) {
+			return nil, fmt.Errorf("Invalid status %s", status)
+		}
+		data, err = s.repo.GetByStatus(ctx, status)
+	} else {
+		data, err = s.repo.GetAll(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -103,47 +146,158 @@ func (s *hewanKurbanService) GetAll(ctx context.Context) ([]dto.HewanKurbanRespo
 	return result, nil
 }
 
+// Update mengunci hewan selama perubahan; jenis dan is_private hanya boleh diubah jika porsi aktif
+// yang sudah ada tetap sah untuk jenis dan aturan private yang baru
 func (s *hewanKurbanService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateHewanKurbanRequest) (*dto.HewanKurbanResponse, error) {
-	existing, err := s.repo.GetById(ctx, id)
+	var existing *model.HewanKurban
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		existing, err = s.repo.LockById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return errors.New("Hewan kurban not found")
+		}
+
+		jenisLama, privateLama := existing.Jenis, existing.IsPrivate
+		if req.Jenis != "" {
+			existing.Jenis = model.JenisHewan(req.Jenis)
+		}
+		if req.Berat > 0 {
+			existing.Berat = req.Berat
+		}
+		if req.UmurBulan != nil {
+			existing.UmurBulan = req.UmurBulan
+		}
+
+		jenis, err := getJenis(ctx, s.jRepo, existing.Jenis)
+		if err != nil {
+			return err
+		}
+		if err := validateUmur(jenis, existing.UmurBulan); err != nil {
+			return err
+		}
+		if req.Harga > 0 {
+			existing.Harga = req.Harga
+		}
+		if req.IsPrivate != nil {
+			existing.IsPrivate = *req.IsPrivate
+		}
+		if req.FotoURL != nil {
+			existing.FotoURL = req.FotoURL
+		}
+
+		if req.TglPendaftaran != "" {
+			tgl, err := time.Parse("2006-01-02", req.TglPendaftaran)
+			if err != nil {
+				return errors.New("Invalid date format")
+			}
+			existing.TanggalPendaftaran = tgl
+		}
+
+		if existing.Jenis != jenisLama || existing.IsPrivate != privateLama {
+			shares, err := s.phRepo.GetByHewanId(ctx, id)
+			if err != nil {
+				return err
+			}
+			if err := validatePorsiHewan(jenis, existing.IsPrivate, shares); err != nil {
+				return err
+			}
+		}
+
+		return s.repo.Update(ctx, existing)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if existing == nil {
-		return nil, errors.New("Hewan kurban not found")
-	}
+	res := dto.ToHewanKurbanResponse(existing, false)
+	return &res, nil
+}
 
-	if req.Jenis != "" {
-		existing.Jenis = model.JenisHewan(req.Jenis)
-	}
-	if req.Berat > 0 {
-		existing.Berat = req.Berat
+// validatePorsiHewan memeriksa porsi aktif terhadap jenis dan aturan private: jumlah pekurban tidak melebihi
+// max_porsi jenis, setiap porsi minimal satu orang, dan hewan private hanya dimiliki utuh oleh satu pekurban
+func validatePorsiHewan(jenis *model.JenisHewanMaster, isPrivate bool, shares []*model.PekurbanHewanJoin) error {
+	if len(shares) > jenis.MaxPorsi {
+		return fmt.Errorf("Hewan already has %d pekurban, more than the maximum %d for a %s", len(shares), jenis.MaxPorsi, jenis.Nama)
 	}
-	if req.Harga > 0 {
-    existing.Harga = req.Harga
+	for _, ph := range shares {
+		if ph.Porsi.JumlahOrang(jenis.MaxPorsi) < 1 {
+			return fmt.Errorf("Share %s is smaller than one portion of a %s", ph.Porsi.String(), jenis.Nama)
+		}
 	}
-	if req.IsPrivate != nil {
-		existing.IsPrivate = *req.IsPrivate
+	if isPrivate {
+		if len(shares) > 1 {
+			return errors.New("Hewan private can only be owned by one pekurban")
+		}
+		if len(shares) == 1 && !shares[0].Porsi.IsUtuh() {
+			return errors.New("Hewan private must be fully owned (porsi 1.0)")
+		}
 	}
+	return nil
+}
 
-	if req.TglPendaftaran != "" {
-		tgl, err := time.Parse("2006-01-02", req.TglPendaftaran)
-		if err != nil {
-			return nil, errors.New("Invalid date format")
-		}
-		existing.TanggalPendaftaran = tgl
+func (s *hewanKurbanService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// UpdateStatus mengubah status secara manual untuk tahap setelah penyembelihan; tahap sebelumnya
+// mengikuti porsi, pembayaran, dan jadwal penyembelihan secara otomatis
+func (s *hewanKurbanService) UpdateStatus(ctx context.Context, id uuid.UUID, req dto.UpdateStatusHewanRequest) (*dto.HewanKurbanResponse, error) {
+	if !isValidHewanStatus(req.Status) {
+		return nil, fmt.Errorf("Invalid status %s", req.Status)
+	}
+	if !manualHewanStatuses[req.Status] {
+		return nil, fmt.Errorf("Status %s is set automatically and cannot be changed manually", req.Status)
 	}
 
-	if err := s.repo.Update(ctx, existing); err != nil {
+	if err := s.lifecycle.Transition(ctx, id, req.Status, req.Keterangan); err != nil {
 		return nil, err
 	}
 
-	res := dto.ToHewanKurbanResponse(existing, false)
-	return &res, nil
+	return s.GetByID(ctx, id)
 }
 
-func (s *hewanKurbanService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+func (s *hewanKurbanService) GetRiwayatStatus(ctx context.Context, id uuid.UUID) ([]dto.RiwayatStatusHewanResponse, error) {
+	list, err := s.lifecycle.GetRiwayat(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.RiwayatStatusHewanResponse{}
+	for _, r := range list {
+		res = append(res, dto.ToRiwayatStatusHewanResponse(r))
+	}
+	return res, nil
 }
 
+func (s *hewanKurbanService) GetPublicCatalog(ctx context.Context, q dto.PublicHewanQuery) ([]dto.PublicHewanResponse, error) {
+	if q.MinHarga != nil && q.MaxHarga != nil && *q.MinHarga > *q.MaxHarga {
+		return nil, ErrRentangHargaInvalid
+	}
+
+	list, err := s.repo.GetPublicCatalog(ctx, model.PublicHewanFilter{
+		Jenis:    q.Jenis,
+		MinHarga: q.MinHarga,
+		MaxHarga: q.MaxHarga,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PublicHewanResponse, 0, len(list))
+	for _, h := range list {
+		result = append(result, dto.ToPublicHewanResponse(h))
+	}
 
+	return result, nil
+}
+
+func validateUmur(jenis *model.JenisHewanMaster, umurBulan *int) error {
+	if umurBulan != nil && *umurBulan < jenis.MinUmurBulan {
+		return fmt.Errorf("Minimum age for %s is %d months", jenis.Nama, jenis.MinUmurBulan)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils"
+)
+
+// hewanTransitions adalah transisi status hewan yang diizinkan. Mundur ke terdaftar/terisi terjadi
+// ketika porsi dilepas (pembatalan, tahanan kedaluwarsa), dan dijadwalkan -> lunas ketika jadwal dihapus.
+var hewanTransitions = map[string][]string{
+	model.HewanTerdaftar:   {model.HewanTerisi},
+	model.HewanTerisi:      {model.HewanTerdaftar, model.HewanLunas},
+	model.HewanLunas:       {model.HewanTerdaftar, model.HewanTerisi, model.HewanDijadwalkan},
+	model.HewanDijadwalkan: {model.HewanLunas, model.HewanDisembelih},
+	model.HewanDisembelih:  {model.HewanDicacah},
+	model.HewanDicacah:     {model.HewanDidistribusikan},
+}
+
+// status yang boleh diubah manual oleh panitia; status sebelumnya diturunkan dari porsi, pembayaran, dan jadwal
+var manualHewanStatuses = map[string]bool{
+	model.HewanDisembelih:      true,
+	model.HewanDicacah:         true,
+	model.HewanDidistribusikan: true,
+}
+
+func canTransitionHewan(from, to string) bool {
+	for _, s := range hewanTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidHewanStatus(status string) bool {
+	if status == model.HewanDidistribusikan {
+		return true
+	}
+	_, ok := hewanTransitions[status]
+	return ok
+}
+
+// HewanLifecycleService menjaga status siklus hidup hewan dan mencatat setiap perubahannya.
+// Pelaku perubahan diambil dari context request (nil untuk proses sistem seperti sweeper atau notifikasi Midtrans).
+type HewanLifecycleService interface {
+	RecordInitial(ctx context.Context, h *model.HewanKurban) error
+	Transition(ctx context.Context, hewanID uuid.UUID, to string, keterangan *string) error
+	SyncKepemilikan(ctx context.Context, hewanID uuid.UUID) error
+	GetRiwayat(ctx context.Context, hewanID uuid.UUID) ([]*model.RiwayatStatusHewan, error)
+}
+
+type hewanLifecycleService struct {
+	hRepo  repository.HewanKurbanRepository
+	phRepo repository.PekurbanHewanRepository
+	rRepo  repository.RiwayatStatusHewanRepository
+	tx     repository.TxManager
+}
+
+func NewHewanLifecycleService(hRepo repository.HewanKurbanRepository, phRepo repository.PekurbanHewanRepository, rRepo repository.RiwayatStatusHewanRepository, tx repository.TxManager) HewanLifecycleService {
+	return &hewanLifecycleService{hRepo: hRepo, phRepo: phRepo, rRepo: rRepo, tx: tx}
+}
+
+// RecordInitial mencatat status awal hewan yang baru didaftarkan
+func (s *hewanLifecycleService) RecordInitial(ctx context.Context, h *model.HewanKurban) error {
+	return s.rRepo.Create(ctx, &model.RiwayatStatusHewan{
+		ID:         uuid.New(),
+		HewanID:    h.ID,
+		KeStatus:   h.Status,
+		DiubahOleh: utils.ActorFromContext(ctx),
+		Created_At: time.Now(),
+	})
+}
+
+func (s *hewanLifecycleService) Transition(ctx context.Context, hewanID uuid.UUID, to string, keterangan *string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		h, err := s.hRepo.LockById(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+		if h == nil {
+			return errors.New("Hewan kurban not found")
+		}
+		return s.transition(ctx, h, to, keterangan)
+	})
+}
+
+// SyncKepemilikan menyesuaikan status terdaftar/terisi/lunas dengan porsi aktif hewan:
+// terisi jika total porsi tepat satu hewan, lunas jika seluruh porsinya juga sudah terkonfirmasi pembayaran.
+// Hewan yang sudah dijadwalkan atau lebih lanjut tidak disentuh.
+func (s *hewanLifecycleService) SyncKepemilikan(ctx context.Context, hewanID uuid.UUID) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		h, err := s.hRepo.LockById(ctx, hewanID)
+		if err != nil || h == nil {
+			return err
+		}
+		if h.Status != model.HewanTerdaftar && h.Status != model.HewanTerisi && h.Status != model.HewanLunas {
+			return nil
+		}
+
+		shares, err := s.phRepo.GetByHewanId(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+
+		target := model.HewanTerdaftar
+		porsi := make([]model.Pecahan, 0, len(shares))
+		terkonfirmasi := true
+		for _, ph := range shares {
+			porsi = append(porsi, ph.Porsi)
+			terkonfirmasi = terkonfirmasi && ph.Status == model.PorsiTerkonfirmasi
+		}
+		if len(shares) > 0 && model.TotalPecahan(porsi...).Cmp(model.PecahanUtuh.Rat()) == 0 {
+			target = model.HewanTerisi
+			if terkonfirmasi {
+				target = model.HewanLunas
+			}
+		}
+
+		keterangan := "Diperbarui otomatis dari porsi dan pembayaran"
+		for h.Status != target {
+			next := target
+			if !canTransitionHewan(h.Status, next) {
+				next = model.HewanTerisi
+			}
+			if err := s.transition(ctx, h, next, &keterangan); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *hewanLifecycleService) GetRiwayat(ctx context.Context, hewanID uuid.UUID) ([]*model.RiwayatStatusHewan, error) {
+	return s.rRepo.GetByHewanId(ctx, hewanID)
+}
+
+func (s *hewanLifecycleService) transition(ctx context.Context, h *model.HewanKurban, to string, keterangan *string) error {
+	if h.Status == to {
+		return nil
+	}
+	if !canTransitionHewan(h.Status, to) {
+		return fmt.Errorf("Hewan status cannot change from %s to %s", h.Status, to)
+	}
+
+	if err := s.hRepo.UpdateStatus(ctx, h.ID, to); err != nil {
+		return err
+	}
+
+	dari := h.Status
+	h.Status = to
+	return s.rRepo.Create(ctx, &model.RiwayatStatusHewan{
+		ID:         uuid.New(),
+		HewanID:    h.ID,
+		DariStatus: &dari,
+		KeStatus:   to,
+		DiubahOleh: utils.ActorFromContext(ctx),
+		Keterangan: keterangan,
+		Created_At: time.Now(),
+	})
+}
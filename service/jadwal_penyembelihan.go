@@ -0,0 +1,455 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
+)
+
+// aturan prioritas penjadwalan otomatis
+const (
+	prioritasPrivate = "private"
+	prioritasLunas   = "lunas"
+	prioritasHadir   = "hadir"
+)
+
+var defaultPrioritas = []string{prioritasPrivate, prioritasLunas, prioritasHadir}
+
+// urutan_rencana bawaan untuk jadwal yang diisi tanpa urutan
+const urutanRencanaDefault = 9999
+
+type hariJadwal struct {
+	tanggal time.Time
+	mulai   time.Time
+	selesai time.Time
+}
+
+// rentang adalah selang waktu [mulai, selesai) yang sudah terpakai
+type rentang struct {
+	mulai   time.Time
+	selesai time.Time
+}
+
+type lokasiJadwal struct {
+	id         uuid.UUID
+	nama       string
+	durasi     map[string]time.Duration
+	titikSibuk [][][]rentang // [hari][titik] selang waktu yang sudah terpakai di titik pemotongan
+}
+
+type jagalJadwal struct {
+	nama    string
+	hari    int
+	lokasi  uuid.UUID // uuid.Nil berarti bisa di lokasi mana pun
+	mulai   time.Time
+	selesai time.Time
+	sibuk   []rentang
+}
+
+type slotJadwal struct {
+	hari    int
+	lokasi  int
+	titik   int
+	jagal   *jagalJadwal
+	mulai   time.Time
+	selesai time.Time
+}
+
+type rencanaJadwal struct {
+	hewan  model.HewanSiapJadwal
+	slot   *slotJadwal
+	urutan int
+}
+
+// penjadwal menempatkan hewan satu per satu ke slot paling awal yang tersedia (list scheduling):
+// hari paling awal didahulukan, lalu waktu mulai paling awal di antara semua titik pemotongan. Celah
+// di antara jadwal yang sudah ada ikut dipakai selama durasinya cukup.
+type penjadwal struct {
+	hari   []hariJadwal
+	lokasi []*lokasiJadwal
+	jagal  []*jagalJadwal
+	offset int // offset hari periode aktif untuk tanggal Hijriah
+}
+
+func parseJam(tanggal time.Time, jam string) (time.Time, error) {
+	t, err := time.Parse("15:04", jam)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid time %s, use HH:MM", jam)
+	}
+	return time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// newPenjadwal menyiapkan hari, lokasi, dan jagal; lokasi[i] adalah data master untuk req.Lokasi[i]
+func newPenjadwal(req dto.GenerateJadwalRequest, lokasi []*model.Lokasi) (*penjadwal, error) {
+	p := &penjadwal{}
+
+	seen := map[string]bool{}
+	for _, tgl := range req.Tanggal {
+		d, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(tgl), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tanggal %s, use YYYY-MM-DD", tgl)
+		}
+		if seen[d.Format("2006-01-02")] {
+			continue
+		}
+		seen[d.Format("2006-01-02")] = true
+
+		mulai, err := parseJam(d, req.JamMulai)
+		if err != nil {
+			return nil, err
+		}
+		selesai, err := parseJam(d, req.JamSelesai)
+		if err != nil {
+			return nil, err
+		}
+		if !selesai.After(mulai) {
+			return nil, errors.New("jam_selesai must be after jam_mulai")
+		}
+		p.hari = append(p.hari, hariJadwal{tanggal: d, mulai: mulai, selesai: selesai})
+	}
+	sort.Slice(p.hari, func(i, j int) bool { return p.hari[i].tanggal.Before(p.hari[j].tanggal) })
+
+	for i, l := range req.Lokasi {
+		nama := lokasi[i].Nama
+		if p.lokasiIndex(lokasi[i].ID) >= 0 {
+			return nil, fmt.Errorf("Lokasi %s is listed more than once", nama)
+		}
+
+		titikPemotongan := l.TitikPemotongan
+		if titikPemotongan == 0 {
+			titikPemotongan = lokasi[i].Kapasitas
+		}
+
+		lok := &lokasiJadwal{id: lokasi[i].ID, nama: nama, durasi: map[string]time.Duration{}}
+		for jenis, menit := range l.DurasiMenit {
+			if menit <= 0 {
+				return nil, fmt.Errorf("Durasi for jenis %s at lokasi %s must be greater than 0", jenis, nama)
+			}
+			lok.durasi[strings.ToLower(strings.TrimSpace(jenis))] = time.Duration(menit) * time.Minute
+		}
+		for range p.hari {
+			lok.titikSibuk = append(lok.titikSibuk, make([][]rentang, titikPemotongan))
+		}
+		p.lokasi = append(p.lokasi, lok)
+	}
+
+	for _, j := range req.Jagal {
+		d, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(j.Tanggal), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tanggal %s for jagal %s, use YYYY-MM-DD", j.Tanggal, j.Nama)
+		}
+		h := p.hariIndex(d.Format("2006-01-02"))
+		if h < 0 {
+			return nil, fmt.Errorf("Jagal %s is available on %s which is not a slaughter date", j.Nama, j.Tanggal)
+		}
+		mulai, err := parseJam(d, j.JamMulai)
+		if err != nil {
+			return nil, err
+		}
+		selesai, err := parseJam(d, j.JamSelesai)
+		if err != nil {
+			return nil, err
+		}
+		if !selesai.After(mulai) {
+			return nil, fmt.Errorf("jam_selesai for jagal %s must be after jam_mulai", j.Nama)
+		}
+
+		jagal := &jagalJadwal{nama: strings.TrimSpace(j.Nama), hari: h, mulai: laterOf(mulai, p.hari[h].mulai), selesai: selesai}
+		if j.LokasiID != nil && *j.LokasiID != "" {
+			lokasiID, err := uuid.Parse(*j.LokasiID)
+			if err != nil || p.lokasiIndex(lokasiID) < 0 {
+				return nil, fmt.Errorf("Jagal %s is assigned to a lokasi that is not part of this schedule", j.Nama)
+			}
+			jagal.lokasi = lokasiID
+		}
+		p.jagal = append(p.jagal, jagal)
+	}
+
+	return p, nil
+}
+
+func (p *penjadwal) hariIndex(tanggal string) int {
+	for i, h := range p.hari {
+		if h.tanggal.Format("2006-01-02") == tanggal {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *penjadwal) lokasiIndex(id uuid.UUID) int {
+	for i, l := range p.lokasi {
+		if l.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// awalBebas menggeser mulai ke waktu paling awal yang tidak bentrok dengan selang terpakai mana pun
+func awalBebas(mulai time.Time, durasi time.Duration, sibuk ...[]rentang) time.Time {
+	for {
+		geser := false
+		for _, list := range sibuk {
+			for _, r := range list {
+				if mulai.Before(r.selesai) && r.mulai.Before(mulai.Add(durasi)) {
+					mulai = r.selesai
+					geser = true
+				}
+			}
+		}
+		if !geser {
+			return mulai
+		}
+	}
+}
+
+// cariSlot mencari waktu mulai paling awal pada satu titik pemotongan. Tanpa daftar jagal setiap titik
+// dianggap punya jagal sendiri; dengan daftar jagal, jagal yang paling cepat kosong dipilih.
+func (p *penjadwal) cariSlot(h, l, t int, durasi time.Duration) *slotJadwal {
+	hari := p.hari[h]
+	sibuk := p.lokasi[l].titikSibuk[h][t]
+
+	if len(p.jagal) == 0 {
+		mulai := awalBebas(hari.mulai, durasi, sibuk)
+		selesai := mulai.Add(durasi)
+		if selesai.After(hari.selesai) {
+			return nil
+		}
+		return &slotJadwal{hari: h, lokasi: l, titik: t, mulai: mulai, selesai: selesai}
+	}
+
+	var best *slotJadwal
+	for _, j := range p.jagal {
+		if j.hari != h || (j.lokasi != uuid.Nil && j.lokasi != p.lokasi[l].id) {
+			continue
+		}
+		mulai := awalBebas(j.mulai, durasi, sibuk, j.sibuk)
+		selesai := mulai.Add(durasi)
+		if selesai.After(hari.selesai) || selesai.After(j.selesai) {
+			continue
+		}
+		if best == nil || mulai.Before(best.mulai) {
+			best = &slotJadwal{hari: h, lokasi: l, titik: t, jagal: j, mulai: mulai, selesai: selesai}
+		}
+	}
+	return best
+}
+
+// tempatkan memesan slot paling awal untuk satu hewan. hariTetap/lokasiTetap bernilai -1 jika bebas.
+func (p *penjadwal) tempatkan(jenis string, hariTetap, lokasiTetap int) (*slotJadwal, string) {
+	jenis = strings.ToLower(jenis)
+
+	var best *slotJadwal
+	dilayani := false
+	for h := range p.hari {
+		if hariTetap >= 0 && h != hariTetap {
+			continue
+		}
+		for l, lok := range p.lokasi {
+			if lokasiTetap >= 0 && l != lokasiTetap {
+				continue
+			}
+			durasi, ok := lok.durasi[jenis]
+			if !ok {
+				continue
+			}
+			dilayani = true
+			for t := range lok.titikSibuk[h] {
+				slot := p.cariSlot(h, l, t, durasi)
+				if slot != nil && (best == nil || slot.mulai.Before(best.mulai)) {
+					best = slot
+				}
+			}
+		}
+		if best != nil {
+			break
+		}
+	}
+
+	if best == nil {
+		if !dilayani {
+			return nil, fmt.Sprintf("No lokasi handles jenis %s", jenis)
+		}
+		return nil, "No slaughter capacity left on the given dates"
+	}
+
+	p.pakai(best)
+	return best, ""
+}
+
+func (p *penjadwal) pakai(slot *slotJadwal) {
+	r := rentang{mulai: slot.mulai, selesai: slot.selesai}
+	titik := p.lokasi[slot.lokasi].titikSibuk[slot.hari]
+	titik[slot.titik] = append(titik[slot.titik], r)
+	if slot.jagal != nil {
+		slot.jagal.sibuk = append(slot.jagal.sibuk, r)
+	}
+}
+
+// pesan menandai jadwal yang sudah tersimpan sebagai terpakai pada jam rencana_mulai-nya: titik pertama
+// yang kosong pada jam itu dipakai, begitu juga jagal yang namanya sama pada hari itu
+func (p *penjadwal) pesan(ps *model.Penyembelihan, h, l int) {
+	lok := p.lokasi[l]
+	durasi, ok := lok.durasi[strings.ToLower(string(ps.JenisHewan))]
+	if !ok || len(lok.titikSibuk[h]) == 0 {
+		return
+	}
+
+	slot := &slotJadwal{hari: h, lokasi: l, mulai: *ps.RencanaMulai, selesai: ps.RencanaMulai.Add(durasi)}
+	for t, sibuk := range lok.titikSibuk[h] {
+		if awalBebas(slot.mulai, durasi, sibuk).Equal(slot.mulai) {
+			slot.titik = t
+			break
+		}
+	}
+	if ps.Jagal != nil {
+		for _, j := range p.jagal {
+			if j.hari == h && strings.EqualFold(j.nama, strings.TrimSpace(*ps.Jagal)) {
+				slot.jagal = j
+				break
+			}
+		}
+	}
+	p.pakai(slot)
+}
+
+// susun memesan jadwal yang sudah ada lebih dulu pada jam rencana_mulai-nya agar slot itu tidak dipakai
+// lagi; jadwal lama tanpa rencana_mulai mengambil slot paling awal di tanggal dan lokasinya. Setelah itu
+// hewan baru ditempatkan sesuai prioritas. urutan_rencana baru melanjutkan urutan yang sudah ada per
+// tanggal dan lokasi.
+func (p *penjadwal) susun(hewan []model.HewanSiapJadwal, terjadwal []*model.Penyembelihan, aturan []string) ([]rencanaJadwal, []dto.JadwalGagalResponse) {
+	urutanTerakhir := map[string]int{}
+	var tanpaJam []*model.Penyembelihan
+	for _, ps := range terjadwal {
+		tanggal := ps.TglPenyembelihan.Format("2006-01-02")
+		key := tanggal + "|" + ps.LokasiID.String()
+		if ps.UrutanRencana < urutanRencanaDefault && ps.UrutanRencana > urutanTerakhir[key] {
+			urutanTerakhir[key] = ps.UrutanRencana
+		}
+
+		h, l := p.hariIndex(tanggal), p.lokasiIndex(ps.LokasiID)
+		if h < 0 || l < 0 {
+			continue
+		}
+		if ps.RencanaMulai == nil {
+			tanpaJam = append(tanpaJam, ps)
+			continue
+		}
+		p.pesan(ps, h, l)
+	}
+	for _, ps := range tanpaJam {
+		p.tempatkan(string(ps.JenisHewan), p.hariIndex(ps.TglPenyembelihan.Format("2006-01-02")), p.lokasiIndex(ps.LokasiID))
+	}
+
+	urutkanPrioritas(hewan, aturan)
+
+	rencana := []rencanaJadwal{}
+	gagal := []dto.JadwalGagalResponse{}
+	for _, hw := range hewan {
+		slot, alasan := p.tempatkan(string(hw.Jenis), -1, -1)
+		if slot == nil {
+			gagal = append(gagal, dto.JadwalGagalResponse{HewanID: hw.ID.String(), Jenis: string(hw.Jenis), Alasan: alasan})
+			continue
+		}
+		rencana = append(rencana, rencanaJadwal{hewan: hw, slot: slot})
+	}
+
+	sort.SliceStable(rencana, func(i, j int) bool {
+		a, b := rencana[i].slot, rencana[j].slot
+		if a.hari != b.hari {
+			return a.hari < b.hari
+		}
+		if a.lokasi != b.lokasi {
+			return a.lokasi < b.lokasi
+		}
+		if !a.mulai.Equal(b.mulai) {
+			return a.mulai.Before(b.mulai)
+		}
+		return a.titik < b.titik
+	})
+	for i := range rencana {
+		key := p.hari[rencana[i].slot.hari].tanggal.Format("2006-01-02") + "|" + p.lokasi[rencana[i].slot.lokasi].id.String()
+		urutanTerakhir[key]++
+		rencana[i].urutan = urutanTerakhir[key]
+	}
+
+	return rencana, gagal
+}
+
+func (p *penjadwal) toItem(r rencanaJadwal) dto.JadwalItemResponse {
+	item := dto.JadwalItemResponse{
+		HewanID:       r.hewan.ID.String(),
+		Jenis:         string(r.hewan.Jenis),
+		IsPrivate:     r.hewan.IsPrivate,
+		InginHadir:    r.hewan.InginHadir,
+		Tanggal:       p.hari[r.slot.hari].tanggal.Format("2006-01-02"),
+		TanggalHijri:  hijri.Format(p.hari[r.slot.hari].tanggal, p.offset),
+		LokasiID:      p.lokasi[r.slot.lokasi].id.String(),
+		Lokasi:        p.lokasi[r.slot.lokasi].nama,
+		Titik:         r.slot.titik + 1,
+		JamMulai:      r.slot.mulai.Format("15:04"),
+		JamSelesai:    r.slot.selesai.Format("15:04"),
+		UrutanRencana: r.urutan,
+	}
+	if r.slot.jagal != nil {
+		item.Jagal = &r.slot.jagal.nama
+	}
+	return item
+}
+
+// aturanPrioritas memakai urutan bawaan jika kosong dan menolak aturan yang disebut dua kali
+func aturanPrioritas(list []string) ([]string, error) {
+	if len(list) == 0 {
+		return defaultPrioritas, nil
+	}
+	seen := map[string]bool{}
+	for _, a := range list {
+		if seen[a] {
+			return nil, fmt.Errorf("Prioritas %s is listed more than once", a)
+		}
+		seen[a] = true
+	}
+	return list, nil
+}
+
+// urutkanPrioritas mengurutkan hewan berdasarkan aturan prioritas; urutan pendaftaran menjadi penentu terakhir
+func urutkanPrioritas(list []model.HewanSiapJadwal, aturan []string) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		for _, r := range aturan {
+			switch r {
+			case prioritasPrivate:
+				if a.IsPrivate != b.IsPrivate {
+					return a.IsPrivate
+				}
+			case prioritasHadir:
+				if a.InginHadir != b.InginHadir {
+					return a.InginHadir
+				}
+			case prioritasLunas:
+				if (a.LunasAt == nil) != (b.LunasAt == nil) {
+					return a.LunasAt != nil
+				}
+				if a.LunasAt != nil && !a.LunasAt.Equal(*b.LunasAt) {
+					return a.LunasAt.Before(*b.LunasAt)
+				}
+			}
+		}
+		return false
+	})
+}
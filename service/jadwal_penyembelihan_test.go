@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// TestPenjadwalSusun memastikan jadwal yang sudah tersimpan dipesan pada jam rencana_mulai-nya, sehingga
+// hewan baru tidak ditumpuk di jam yang sama dan celah sebelum jadwal lama tetap bisa dipakai
+func TestPenjadwalSusun(t *testing.T) {
+	lokasi := &model.Lokasi{ID: uuid.New(), Nama: "Masjid", Kapasitas: 1}
+	tanggal := time.Date(2025, 6, 6, 0, 0, 0, 0, time.Local)
+	jam := func(j string) *time.Time {
+		v, err := parseJam(tanggal, j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &v
+	}
+	jagal := func(nama string) *string { return &nama }
+
+	tests := []struct {
+		name       string
+		titik      int
+		jamSelesai string
+		jagal      []dto.JagalRequest
+		terjadwal  []*model.Penyembelihan
+		hewanBaru  int
+		want       []string // jam mulai@titik, ditambah /jagal jika ada
+		gagal      int
+	}{
+		{
+			name:       "tanpa jadwal lama",
+			titik:      1,
+			jamSelesai: "10:00",
+			hewanBaru:  2,
+			want:       []string{"08:00@1", "09:00@1"},
+		},
+		{
+			name:       "jadwal lama di tengah hari dipesan pada jamnya",
+			titik:      1,
+			jamSelesai: "11:00",
+			terjadwal:  []*model.Penyembelihan{{RencanaMulai: jam("09:00")}},
+			hewanBaru:  2,
+			want:       []string{"08:00@1", "10:00@1"},
+		},
+		{
+			name:       "celah yang terlalu sempit dilewati",
+			titik:      1,
+			jamSelesai: "11:00",
+			terjadwal:  []*model.Penyembelihan{{RencanaMulai: jam("08:30")}},
+			hewanBaru:  1,
+			want:       []string{"09:30@1"},
+		},
+		{
+			name:       "titik lain dipakai saat titik pertama terpesan",
+			titik:      2,
+			jamSelesai: "10:00",
+			terjadwal:  []*model.Penyembelihan{{RencanaMulai: jam("08:00")}},
+			hewanBaru:  1,
+			want:       []string{"08:00@2"},
+		},
+		{
+			name:       "jagal jadwal lama ikut terpakai",
+			titik:      2,
+			jamSelesai: "10:00",
+			jagal:      []dto.JagalRequest{{Nama: "Pak Slamet", Tanggal: "2025-06-06", JamMulai: "08:00", JamSelesai: "10:00"}},
+			terjadwal:  []*model.Penyembelihan{{RencanaMulai: jam("08:00"), Jagal: jagal("pak slamet")}},
+			hewanBaru:  1,
+			want:       []string{"09:00@1/Pak Slamet"},
+		},
+		{
+			name:       "jadwal lama tanpa rencana_mulai mengambil slot paling awal",
+			titik:      1,
+			jamSelesai: "10:00",
+			terjadwal:  []*model.Penyembelihan{{}},
+			hewanBaru:  1,
+			want:       []string{"09:00@1"},
+		},
+		{
+			name:       "kapasitas habis oleh jadwal lama",
+			titik:      1,
+			jamSelesai: "09:00",
+			terjadwal:  []*model.Penyembelihan{{RencanaMulai: jam("08:00")}},
+			hewanBaru:  1,
+			want:       []string{},
+			gagal:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dto.GenerateJadwalRequest{
+				Tanggal:    []string{"2025-06-06"},
+				JamMulai:   "08:00",
+				JamSelesai: tt.jamSelesai,
+				Lokasi: []dto.JadwalLokasiRequest{{
+					LokasiID:        lokasi.ID.String(),
+					TitikPemotongan: tt.titik,
+					DurasiMenit:     map[string]int{"sapi": 60},
+				}},
+				Jagal: tt.jagal,
+			}
+			p, err := newPenjadwal(req, []*model.Lokasi{lokasi})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, ps := range tt.terjadwal {
+				ps.HewanID = uuid.New()
+				ps.JenisHewan = "sapi"
+				ps.TglPenyembelihan = tanggal
+				ps.LokasiID = lokasi.ID
+				ps.UrutanRencana = i + 1
+			}
+			hewan := make([]model.HewanSiapJadwal, tt.hewanBaru)
+			for i := range hewan {
+				hewan[i] = model.HewanSiapJadwal{ID: uuid.New(), Jenis: "sapi", TanggalPendaftaran: tanggal}
+			}
+
+			rencana, gagal := p.susun(hewan, tt.terjadwal, defaultPrioritas)
+
+			got := []string{}
+			for _, r := range rencana {
+				s := fmt.Sprintf("%s@%d", r.slot.mulai.Format("15:04"), r.slot.titik+1)
+				if r.slot.jagal != nil {
+					s += "/" + r.slot.jagal.nama
+				}
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slots = %v, want %v", got, tt.want)
+			}
+			if len(gagal) != tt.gagal {
+				t.Errorf("gagal = %d, want %d", len(gagal), tt.gagal)
+			}
+			for i, r := range rencana {
+				if want := len(tt.terjadwal) + i + 1; r.urutan != want {
+					t.Errorf("urutan[%d] = %d, want %d", i, r.urutan, want)
+				}
+			}
+		})
+	}
+}
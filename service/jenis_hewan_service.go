@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+type JenisHewanService interface {
+	Create(ctx context.Context, req dto.CreateJenisHewanRequest) (*dto.JenisHewanResponse, error)
+	GetAll(ctx context.Context) ([]dto.JenisHewanResponse, error)
+	Update(ctx context.Context, nama string, req dto.UpdateJenisHewanRequest) (*dto.JenisHewanResponse, error)
+	Delete(ctx context.Context, nama string) error
+}
+
+type jenisHewanService struct {
+	repo repository.JenisHewanRepository
+}
+
+func NewJenisHewanService(repo repository.JenisHewanRepository) JenisHewanService {
+	return &jenisHewanService{repo: repo}
+}
+
+func (s *jenisHewanService) Create(ctx context.Context, req dto.CreateJenisHewanRequest) (*dto.JenisHewanResponse, error) {
+	nama := strings.ToLower(strings.TrimSpace(req.Nama))
+	if nama == "" {
+		return nil, errors.New("Nama is required")
+	}
+
+	existing, err := s.repo.GetByNama(ctx, nama)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("Jenis hewan already exists")
+	}
+
+	j := &model.JenisHewanMaster{
+		Nama:         model.JenisHewan(nama),
+		MaxPorsi:     req.MaxPorsi,
+		MinUmurBulan: req.MinUmurBulan,
+		HargaDefault: req.HargaDefault,
+		Created_At:   time.Now(),
+		Updated_At:   time.Now(),
+	}
+	if err := s.repo.Create(ctx, j); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToJenisHewanResponse(j)
+	return &res, nil
+}
+
+func (s *jenisHewanService) GetAll(ctx context.Context) ([]dto.JenisHewanResponse, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.JenisHewanResponse{}
+	for _, j := range list {
+		res = append(res, dto.ToJenisHewanResponse(j))
+	}
+	return res, nil
+}
+
+// Update mengubah kapasitas dan syarat jenis; porsi yang sudah tercatat tidak dihitung ulang
+func (s *jenisHewanService) Update(ctx context.Context, nama string, req dto.UpdateJenisHewanRequest) (*dto.JenisHewanResponse, error) {
+	j, err := s.repo.GetByNama(ctx, nama)
+	if err != nil {
+		return nil, err
+	}
+	if j == nil {
+		return nil, errors.New("Jenis hewan not found")
+	}
+
+	j.MaxPorsi = req.MaxPorsi
+	j.MinUmurBulan = req.MinUmurBulan
+	j.HargaDefault = req.HargaDefault
+
+	if err := s.repo.Update(ctx, j); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToJenisHewanResponse(j)
+	return &res, nil
+}
+
+func (s *jenisHewanService) Delete(ctx context.Context, nama string) error {
+	return s.repo.Delete(ctx, nama)
+}
+
+// getJenis mengambil master jenis dan mengembalikan error yang jelas jika tidak terdaftar
+func getJenis(ctx context.Context, repo repository.JenisHewanRepository, nama model.JenisHewan) (*model.JenisHewanMaster, error) {
+	j, err := repo.GetByNama(ctx, string(nama))
+	if err != nil {
+		return nil, err
+	}
+	if j == nil {
+		return nil, errors.New("Invalid jenis hewan")
+	}
+	return j, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/ical"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/security"
+)
+
+// ErrKalenderTokenInvalid dikembalikan bila token feed tidak dikenal atau pemiliknya tidak lagi berhak
+var ErrKalenderTokenInvalid = errors.New("Invalid calendar token")
+
+type KalenderService interface {
+	// Feed membangun kalender lengkap untuk panitia, atau kalender milik satu pekurban bila pekurbanID diisi
+	Feed(ctx context.Context, pekurbanID *uuid.UUID) (*ical.Calendar, error)
+	FeedByToken(ctx context.Context, token string) (*ical.Calendar, error)
+	CreateToken(ctx context.Context, user model.User) (*dto.KalenderTokenResponse, error)
+	CreateTokenPekurban(ctx context.Context, pekurbanID uuid.UUID) (*dto.KalenderTokenResponse, error)
+}
+
+type kalenderService struct {
+	repo    repository.KalenderRepository
+	uRepo   repository.UserRepository
+	pRepo   repository.PekurbanRepository
+	periode PeriodeKurbanService
+	baseURL string
+	durasi  time.Duration
+}
+
+func NewKalenderService(repo repository.KalenderRepository, uRepo repository.UserRepository, pRepo repository.PekurbanRepository, periode PeriodeKurbanService, baseURL string, durasi time.Duration) KalenderService {
+	return &kalenderService{repo: repo, uRepo: uRepo, pRepo: pRepo, periode: periode, baseURL: strings.TrimRight(baseURL, "/"), durasi: durasi}
+}
+
+// aplikasi kalender disarankan menyinkronkan ulang tiap jam agar perubahan jadwal cepat terlihat
+const kalenderRefresh = time.Hour
+
+func (s *kalenderService) Feed(ctx context.Context, pekurbanID *uuid.UUID) (*ical.Calendar, error) {
+	sembelih, err := s.repo.GetPenyembelihan(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	distribusi, err := s.repo.GetDistribusi(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "Jadwal Kurban"
+	if pekurbanID != nil {
+		name = "Jadwal Kurban Saya"
+	}
+	cal := &ical.Calendar{Name: name, Refresh: kalenderRefresh}
+	for _, j := range sembelih {
+		cal.Events = append(cal.Events, s.eventPenyembelihan(j, offset))
+	}
+	for _, j := range distribusi {
+		cal.Events = append(cal.Events, eventDistribusi(j))
+	}
+	return cal, nil
+}
+
+func (s *kalenderService) FeedByToken(ctx context.Context, token string) (*ical.Calendar, error) {
+	t, err := s.repo.GetToken(ctx, security.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrKalenderTokenInvalid
+	}
+
+	if t.PekurbanID != nil {
+		return s.Feed(ctx, t.PekurbanID)
+	}
+
+	// token panitia hanya berlaku selama pemiliknya masih admin/panitia
+	u, err := s.uRepo.FindById(ctx, *t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil || (u.Role != "admin" && u.Role != "panitia") {
+		return nil, ErrKalenderTokenInvalid
+	}
+	return s.Feed(ctx, nil)
+}
+
+// CreateToken membuat (atau merotasi) token feed untuk user yang login. Admin/panitia mendapat feed lengkap,
+// user biasa mendapat feed milik data pekurbannya.
+func (s *kalenderService) CreateToken(ctx context.Context, user model.User) (*dto.KalenderTokenResponse, error) {
+	t := &model.KalenderToken{}
+	if user.Role == "admin" || user.Role == "panitia" {
+		t.UserID = &user.ID
+	} else {
+		p, err := s.pRepo.FindByUserId(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, errors.New("You are not registered as pekurban")
+		}
+		t.PekurbanID = &p.ID
+	}
+	return s.saveToken(ctx, t)
+}
+
+func (s *kalenderService) CreateTokenPekurban(ctx context.Context, pekurbanID uuid.UUID) (*dto.KalenderTokenResponse, error) {
+	p, err := s.pRepo.FindById(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Pekurban not found")
+	}
+	return s.saveToken(ctx, &model.KalenderToken{PekurbanID: &p.ID})
+}
+
+func (s *kalenderService) saveToken(ctx context.Context, t *model.KalenderToken) (*dto.KalenderTokenResponse, error) {
+	token, hash, err := security.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	t.TokenHash = hash
+	t.Created_At = time.Now()
+
+	if err := s.repo.SaveToken(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return &dto.KalenderTokenResponse{
+		URL:       fmt.Sprintf("%s/api/v1/kalender/feed/%s.ics", s.baseURL, token),
+		Token:     token,
+		CreatedAt: t.Created_At,
+	}, nil
+}
+
+// tagHewan adalah penanda singkat hewan di kalender, misalnya "Sapi #1a2b3c4d"
+func tagHewan(jenis model.JenisHewan, id uuid.UUID) string {
+	return fmt.Sprintf("%s #%s", jenis, id.String()[:8])
+}
+
+func (s *kalenderService) eventPenyembelihan(j *model.JadwalPenyembelihanKalender, offset int) ical.Event {
+	e := ical.Event{
+		UID:      fmt.Sprintf("penyembelihan-%s@sahabat-kurban", j.ID),
+		Summary:  "Penyembelihan " + tagHewan(j.Jenis, j.HewanID),
+		Location: lokasiKalender(j.Lokasi, j.Alamat),
+		Geo:      geoKalender(j.Latitude, j.Longitude),
+		Modified: j.Updated_At,
+	}
+
+	desc := []string{
+		"Hewan: " + tagHewan(j.Jenis, j.HewanID),
+		"Status: " + j.StatusHewan,
+		"Tanggal Hijriah: " + hijri.Format(j.Tanggal, offset),
+		fmt.Sprintf("Urutan: %d", j.UrutanRencana),
+	}
+	if j.Jagal != nil && *j.Jagal != "" {
+		desc = append(desc, "Jagal: "+*j.Jagal)
+	}
+	e.Description = strings.Join(desc, "\n")
+
+	// waktu aktual lebih diutamakan daripada rencana; tanpa keduanya acara dibuat sepanjang hari
+	var mulai *time.Time
+	switch {
+	case j.MulaiAt != nil:
+		mulai = j.MulaiAt
+	case j.RencanaMulai != nil:
+		mulai = j.RencanaMulai
+	}
+	if mulai == nil {
+		e.AllDay = true
+		e.Start = j.Tanggal
+		e.End = j.Tanggal.AddDate(0, 0, 1)
+		return e
+	}
+
+	e.Start = *mulai
+	if j.SelesaiAt != nil {
+		e.End = *j.SelesaiAt
+	} else {
+		e.End = mulai.Add(s.durasi)
+	}
+	return e
+}
+
+func eventDistribusi(j *model.JadwalDistribusiKalender) ical.Event {
+	e := ical.Event{
+		UID:         fmt.Sprintf("distribusi-%s@sahabat-kurban", j.ID),
+		Summary:     fmt.Sprintf("Pengambilan daging (%d paket)", j.JumlahPaket),
+		Description: fmt.Sprintf("Penerima: %s\nJumlah paket: %d", j.PenerimaNama, j.JumlahPaket),
+		Geo:         geoKalender(j.Latitude, j.Longitude),
+		Start:       j.Tanggal,
+		End:         j.Tanggal.AddDate(0, 0, 1),
+		AllDay:      true,
+		Modified:    j.Updated_At,
+	}
+	if j.Lokasi != nil {
+		e.Location = lokasiKalender(*j.Lokasi, j.Alamat)
+	}
+	return e
+}
+
+func lokasiKalender(nama string, alamat *string) string {
+	if alamat != nil && *alamat != "" {
+		return nama + ", " + *alamat
+	}
+	return nama
+}
+
+func geoKalender(lat, lng *float64) *[2]float64 {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	return &[2]float64{*lat, *lng}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/pdf"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/security"
+)
+
+var (
+	ErrKuponNotFound     = errors.New("Kupon not found")
+	ErrKuponSudahDitukar = errors.New("Kupon already redeemed")
+)
+
+type KuponService interface {
+	Generate(ctx context.Context, req dto.GenerateKuponRequest) (*dto.GenerateKuponResponse, error)
+	GetAll(ctx context.Context, belumDitukar bool) ([]dto.KuponResponse, error)
+	// CetakPDF menulis kartu kupon siap cetak, delapan per halaman A4
+	CetakPDF(ctx context.Context, w io.Writer, belumDitukar bool) error
+	// Scan memverifikasi QR lalu mencatat distribusi atas nama user yang memindai, sekali per kupon
+	Scan(ctx context.Context, req dto.ScanKuponRequest) (*dto.ScanKuponResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type kuponService struct {
+	repo              repository.KuponRepository
+	penerimaRepo      repository.PenerimaDagingRepository
+	lokasiRepo        repository.LokasiRepository
+	distribusiService DistribusiDagingService
+	tx                repository.TxManager
+	secret            []byte
+}
+
+func NewKuponService(repo repository.KuponRepository, penerimaRepo repository.PenerimaDagingRepository, lokasiRepo repository.LokasiRepository, distribusiService DistribusiDagingService, tx repository.TxManager, secret []byte) KuponService {
+	return &kuponService{repo: repo, penerimaRepo: penerimaRepo, lokasiRepo: lokasiRepo, distribusiService: distribusiService, tx: tx, secret: secret}
+}
+
+func (s *kuponService) Generate(ctx context.Context, req dto.GenerateKuponRequest) (*dto.GenerateKuponResponse, error) {
+	var tanggal *time.Time
+	if req.TanggalDistribusi != "" {
+		t, err := time.Parse("2006-01-02", req.TanggalDistribusi)
+		if err != nil {
+			return nil, errors.New("Invalid date format. Use YYYY-MM-DD")
+		}
+		tanggal = &t
+	}
+
+	var lokasiID *uuid.UUID
+	if req.LokasiID != nil && *req.LokasiID != "" {
+		id, err := uuid.Parse(*req.LokasiID)
+		if err != nil {
+			return nil, errors.New("Invalid lokasi ID")
+		}
+		if _, err := getLokasi(ctx, s.lokasiRepo, id, model.LokasiDistribusi); err != nil {
+			return nil, err
+		}
+		lokasiID = &id
+	}
+
+	res := &dto.GenerateKuponResponse{Dibuat: []dto.KuponResponse{}, Dilewati: []dto.KuponResponse{}}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		seen := map[uuid.UUID]bool{}
+		for _, item := range req.Kupon {
+			penerimaID, err := uuid.Parse(item.PenerimaID)
+			if err != nil {
+				return errors.New("Invalid Penerima ID")
+			}
+			if seen[penerimaID] {
+				return fmt.Errorf("Penerima %s is listed more than once", penerimaID)
+			}
+			seen[penerimaID] = true
+
+			existing, err := s.repo.GetAktifByPenerimaID(ctx, penerimaID)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				res.Dilewati = append(res.Dilewati, s.toResponse(existing))
+				continue
+			}
+
+			penerima, err := s.penerimaRepo.GetByID(ctx, penerimaID)
+			if err != nil {
+				return err
+			}
+			if penerima == nil {
+				return fmt.Errorf("Penerima %s not found", penerimaID)
+			}
+
+			now := time.Now()
+			k := &model.KuponDistribusi{
+				ID:                uuid.New(),
+				PenerimaID:        penerimaID,
+				JumlahPaket:       item.JumlahPaket,
+				TanggalDistribusi: tanggal,
+				LokasiID:          lokasiID,
+				Created_At:        now,
+				Updated_At:        now,
+			}
+			if err := s.repo.Create(ctx, k); err != nil {
+				return err
+			}
+
+			k, err = s.repo.GetById(ctx, k.ID)
+			if err != nil {
+				return err
+			}
+			res.Dibuat = append(res.Dibuat, s.toResponse(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *kuponService) GetAll(ctx context.Context, belumDitukar bool) ([]dto.KuponResponse, error) {
+	list, err := s.repo.GetAll(ctx, belumDitukar)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.KuponResponse{}
+	for _, k := range list {
+		res = append(res, s.toResponse(k))
+	}
+	return res, nil
+}
+
+func (s *kuponService) CetakPDF(ctx context.Context, w io.Writer, belumDitukar bool) error {
+	list, err := s.repo.GetAll(ctx, belumDitukar)
+	if err != nil {
+		return err
+	}
+
+	cards := make([]pdf.KuponCard, 0, len(list))
+	for _, k := range list {
+		c := pdf.KuponCard{
+			Kode:        security.SignKupon(s.secret, k.ID),
+			Nomor:       dto.NomorKupon(k),
+			Nama:        k.PenerimaNama,
+			Kategori:    k.PenerimaStatus,
+			JumlahPaket: k.JumlahPaket,
+		}
+		if k.PenerimaAlamat != nil {
+			c.Alamat = *k.PenerimaAlamat
+		}
+		if k.TanggalDistribusi != nil {
+			c.Tanggal = k.TanggalDistribusi.Format("02-01-2006")
+		}
+		if k.LokasiNama != nil {
+			c.Lokasi = *k.LokasiNama
+		}
+		cards = append(cards, c)
+	}
+	return pdf.KuponCards(w, "Kupon Daging Kurban", cards)
+}
+
+func (s *kuponService) Scan(ctx context.Context, req dto.ScanKuponRequest) (*dto.ScanKuponResponse, error) {
+	id, err := security.VerifyKupon(s.secret, req.Kode)
+	if err != nil {
+		return nil, err
+	}
+
+	var res dto.ScanKuponResponse
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		k, err := s.repo.LockById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if k == nil {
+			return ErrKuponNotFound
+		}
+		if k.SudahDitukar() {
+			return ErrKuponSudahDitukar
+		}
+
+		now := time.Now()
+		tanggal := now.Format("2006-01-02")
+		if k.TanggalDistribusi != nil {
+			tanggal = k.TanggalDistribusi.Format("2006-01-02")
+		}
+		var lokasiID *string
+		if k.LokasiID != nil {
+			id := k.LokasiID.String()
+			lokasiID = &id
+		}
+
+		dis, err := s.distribusiService.CreateOtomatis(ctx, dto.CreateDistribusiRequest{
+			PenerimaID:        k.PenerimaID.String(),
+			LokasiID:          lokasiID,
+			JumlahPaket:       k.JumlahPaket,
+			TanggalDistribusi: tanggal,
+		})
+		if err != nil {
+			return err
+		}
+
+		disID, err := uuid.Parse(dis.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Tukar(ctx, k.ID, disID, utils.ActorFromContext(ctx), now); err != nil {
+			return err
+		}
+
+		k, err = s.repo.GetById(ctx, k.ID)
+		if err != nil {
+			return err
+		}
+		res = dto.ScanKuponResponse{Kupon: s.toResponse(k), Distribusi: *dis}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *kuponService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *kuponService) toResponse(k *model.KuponDistribusi) dto.KuponResponse {
+	return dto.ToKuponResponse(k, security.SignKupon(s.secret, k.ID))
+}
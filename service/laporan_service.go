@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/wahyujatirestu/sahabat-kurban/model"
@@ -31,6 +32,10 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 	if err != nil {
 		return nil, err
 	}
+	atasNama, err := s.repo.GetAtasNamaDetails(ctx, f)
+	if err != nil {
+		return nil, err
+	}
 	rekapHewan, err := s.repo.GetHewanAggregate(ctx, f)
 	if err != nil {
 		return nil, err
@@ -43,6 +48,14 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 	if err != nil {
 		return nil, err
 	}
+	rekapRendemen, err := s.repo.GetRendemenAggregate(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	rekapLokasi, err := s.repo.GetLokasiAggregate(ctx, f)
+	if err != nil {
+		return nil, err
+	}
 
 	// summary
 	totalPekurban, err := s.repo.CountPekurban(ctx)
@@ -72,6 +85,13 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 		hewanByPekurban[h.PekurbanID] = append(hewanByPekurban[h.PekurbanID], h)
 	}
 
+	// map atas nama per porsi (pekurban + hewan)
+	atasNamaByShare := map[string][]dto.AtasNamaResponse{}
+	for i := range atasNama {
+		key := dto.ShareKey(atasNama[i].PekurbanID.String(), atasNama[i].HewanID.String())
+		atasNamaByShare[key] = append(atasNamaByShare[key], dto.ToAtasNamaResponse(&atasNama[i]))
+	}
+
 	// build DTO pekurban + status "lunas" vs "belum"
 	pekurbanDTOs := make([]dto.PekurbanDTO, 0, len(pekurbanAgg))
 	for _, a := range pekurbanAgg {
@@ -89,6 +109,7 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 				Berat:   h.Berat,
 				Harga:   h.Harga,
 				Porsi:   h.Porsi,
+				AtasNama: atasNamaOrEmpty(atasNamaByShare[dto.ShareKey(h.PekurbanID, h.HewanID)]),
 			})
 		}
 		pekurbanDTOs = append(pekurbanDTOs, dto.PekurbanDTO{
@@ -135,6 +156,45 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 		})
 	}
 
+	// map rekap rendemen per jenis
+	rendemenDTOs := make([]dto.RendemenDTO, 0, len(rekapRendemen))
+	for _, r := range rekapRendemen {
+		rataDaging := 0.0
+		if r.TotalHewan > 0 {
+			rataDaging = math.Round(r.BeratDaging/float64(r.TotalHewan)*100) / 100
+		}
+		rendemenDTOs = append(rendemenDTOs, dto.RendemenDTO{
+			Jenis:                   r.Jenis,
+			TotalHewan:              r.TotalHewan,
+			BeratTerdaftar:          r.BeratTerdaftar,
+			BeratHidup:              r.BeratHidup,
+			BeratKarkas:             r.BeratKarkas,
+			BeratDaging:             r.BeratDaging,
+			BeratTulang:             r.BeratTulang,
+			BeratJeroan:             r.BeratJeroan,
+			JumlahPaket:             r.JumlahPaket,
+			RataDagingPerHewan:      rataDaging,
+			RendemenKarkas:          r.RendemenKarkas,
+			KarkasTerhadapTerdaftar: r.KarkasTerhadapTerdaftar,
+			DagingTerhadapTerdaftar: r.DagingTerhadapTerdaftar,
+		})
+	}
+
+	// map rekap per lokasi
+	lokasiDTOs := make([]dto.LokasiRekapDTO, 0, len(rekapLokasi))
+	for _, l := range rekapLokasi {
+		lokasiDTOs = append(lokasiDTOs, dto.LokasiRekapDTO{
+			Nama:             l.Nama,
+			Tipe:             l.Tipe,
+			HewanDijadwalkan: l.HewanDijadwalkan,
+			HewanDisembelih:  l.HewanDisembelih,
+			BeratDaging:      l.BeratDaging,
+			PaketDihasilkan:  l.PaketDihasilkan,
+			TotalDistribusi:  l.TotalDistribusi,
+			PaketDistribusi:  l.PaketDistribusi,
+		})
+	}
+
 	now := time.Now()
 	resp := &dto.LaporanResponse{
 		Tanggal: now,
@@ -149,6 +209,8 @@ func (s *reportService) GetConsolidatedReport(ctx context.Context, f model.Repor
 		RekapHewan:       hewanDTOs,
 		RekapDistribusi:  distribusiDTOs,
 		RekapPembayaran:  paymentDTOs,
+		RekapRendemen:    rendemenDTOs,
+		RekapLokasi:      lokasiDTOs,
 	}
 	return resp, nil
 }
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+type LokasiService interface {
+	Create(ctx context.Context, req dto.CreateLokasiRequest) (*dto.LokasiResponse, error)
+	GetAll(ctx context.Context, tipe string) ([]dto.LokasiResponse, error)
+	GetById(ctx context.Context, id uuid.UUID) (*dto.LokasiResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req dto.UpdateLokasiRequest) (*dto.LokasiResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Merge(ctx context.Context, id uuid.UUID, req dto.MergeLokasiRequest) (*dto.LokasiResponse, error)
+}
+
+type lokasiService struct {
+	repo repository.LokasiRepository
+	tx   repository.TxManager
+}
+
+func NewLokasiService(repo repository.LokasiRepository, tx repository.TxManager) LokasiService {
+	return &lokasiService{repo: repo, tx: tx}
+}
+
+func (s *lokasiService) Create(ctx context.Context, req dto.CreateLokasiRequest) (*dto.LokasiResponse, error) {
+	nama := strings.TrimSpace(req.Nama)
+	if nama == "" {
+		return nil, errors.New("Nama is required")
+	}
+	if err := validateKoordinat(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+
+	kapasitas := req.Kapasitas
+	if kapasitas == 0 {
+		kapasitas = 1
+	}
+
+	l := &model.Lokasi{
+		ID:          uuid.New(),
+		Nama:        nama,
+		Alamat:      req.Alamat,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Kapasitas:   kapasitas,
+		KontakNama:  req.KontakNama,
+		KontakPhone: req.KontakPhone,
+		Tipe:        req.Tipe,
+		Created_At:  time.Now(),
+		Updated_At:  time.Now(),
+	}
+	if err := s.repo.Create(ctx, l); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToLokasiResponse(l)
+	return &res, nil
+}
+
+func (s *lokasiService) GetAll(ctx context.Context, tipe string) ([]dto.LokasiResponse, error) {
+	if tipe != "" && tipe != model.LokasiPenyembelihan && tipe != model.LokasiDistribusi {
+		return nil, fmt.Errorf("Invalid tipe %s", tipe)
+	}
+
+	list, err := s.repo.GetAll(ctx, tipe)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.LokasiResponse{}
+	for _, l := range list {
+		res = append(res, dto.ToLokasiResponse(l))
+	}
+	return res, nil
+}
+
+func (s *lokasiService) GetById(ctx context.Context, id uuid.UUID) (*dto.LokasiResponse, error) {
+	l, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("Lokasi not found")
+	}
+
+	res := dto.ToLokasiResponse(l)
+	return &res, nil
+}
+
+func (s *lokasiService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateLokasiRequest) (*dto.LokasiResponse, error) {
+	l, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("Lokasi not found")
+	}
+
+	if strings.TrimSpace(req.Nama) != "" {
+		l.Nama = strings.TrimSpace(req.Nama)
+	}
+	if req.Alamat != nil {
+		l.Alamat = req.Alamat
+	}
+	if req.Latitude != nil {
+		l.Latitude = req.Latitude
+	}
+	if req.Longitude != nil {
+		l.Longitude = req.Longitude
+	}
+	if req.Kapasitas > 0 {
+		l.Kapasitas = req.Kapasitas
+	}
+	if req.KontakNama != nil {
+		l.KontakNama = req.KontakNama
+	}
+	if req.KontakPhone != nil {
+		l.KontakPhone = req.KontakPhone
+	}
+	if req.Tipe != "" {
+		l.Tipe = req.Tipe
+	}
+	if err := validateKoordinat(l.Latitude, l.Longitude); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, l); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToLokasiResponse(l)
+	return &res, nil
+}
+
+func (s *lokasiService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Merge memindahkan semua data dari lokasi id ke lokasi tujuan lalu menghapus lokasi id
+func (s *lokasiService) Merge(ctx context.Context, id uuid.UUID, req dto.MergeLokasiRequest) (*dto.LokasiResponse, error) {
+	toID, err := uuid.Parse(req.KeLokasiID)
+	if err != nil {
+		return nil, errors.New("Invalid ke_lokasi_id")
+	}
+	if toID == id {
+		return nil, errors.New("Cannot merge a lokasi into itself")
+	}
+
+	from, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, errors.New("Lokasi not found")
+	}
+	to, err := s.repo.GetById(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		return nil, errors.New("Target lokasi not found")
+	}
+
+	// lokasi tujuan harus bisa melayani semua kegiatan lokasi asal
+	if !to.Melayani(from.Tipe) {
+		return nil, fmt.Errorf("Target lokasi of tipe %s cannot take over a lokasi of tipe %s", to.Tipe, from.Tipe)
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Merge(ctx, id, toID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToLokasiResponse(to)
+	return &res, nil
+}
+
+// validateKoordinat memastikan latitude dan longitude diisi berpasangan
+func validateKoordinat(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errors.New("latitude and longitude must be filled together")
+	}
+	return nil
+}
+
+// getLokasi mengambil lokasi dan memastikan tipenya sesuai kegiatan
+func getLokasi(ctx context.Context, repo repository.LokasiRepository, id uuid.UUID, tipe string) (*model.Lokasi, error) {
+	l, err := repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("Lokasi not found")
+	}
+	if !l.Melayani(tipe) {
+		return nil, fmt.Errorf("Lokasi %s is not a %s lokasi", l.Nama, tipe)
+	}
+	return l, nil
+}
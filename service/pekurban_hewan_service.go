@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
-	"math"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wahyujatirestu/sahabat-kurban/dto"
@@ -18,16 +21,30 @@ type PekurbanHewanService interface {
 	GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]dto.PekurbanHewanResponse, error)
 	Update(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdatePekurbanHewanRequest) (*dto.PekurbanHewanResponse, error)
 	Delete(ctx context.Context, pekurbanID, hewanID uuid.UUID) error
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+	UpdateAtasNama(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdateAtasNamaRequest) ([]dto.AtasNamaResponse, error)
+	UpdateKehadiran(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdateKehadiranRequest) error
 }
 
 type pekurbanHewanService struct {
 	repo 		 repository.PekurbanHewanRepository
 	pRepo		 repository.PekurbanRepository
 	hRepo 		 repository.HewanKurbanRepository
+	jRepo		 repository.JenisHewanRepository
+	aRepo		 repository.AtasNamaRepository
+	sRepo		 repository.PenyembelihanRepository
+	payRepo		 repository.PembayaranKurbanRepository
+	tx			 repository.TxManager
+	lifecycle	 HewanLifecycleService
+	holdTTL		 time.Duration
 }
 
-func NewPekurbanHewanService(repo repository.PekurbanHewanRepository, pRepo repository.PekurbanRepository, hRepo repository.HewanKurbanRepository) PekurbanHewanService {
-	return &pekurbanHewanService{repo: repo, pRepo: pRepo, hRepo: hRepo}
+// ErrPorsiExceeded dikembalikan ketika porsi baru akan membuat total porsi hewan melebihi 1.0
+// (juga dikembalikan repository saat trigger database menolak penulisan)
+var ErrPorsiExceeded = repository.ErrPorsiExceeded
+
+func NewPekurbanHewanService(repo repository.PekurbanHewanRepository, pRepo repository.PekurbanRepository, hRepo repository.HewanKurbanRepository, jRepo repository.JenisHewanRepository, aRepo repository.AtasNamaRepository, sRepo repository.PenyembelihanRepository, payRepo repository.PembayaranKurbanRepository, tx repository.TxManager, lifecycle HewanLifecycleService, holdTTL time.Duration) PekurbanHewanService {
+	return &pekurbanHewanService{repo: repo, pRepo: pRepo, hRepo: hRepo, jRepo: jRepo, aRepo: aRepo, sRepo: sRepo, payRepo: payRepo, tx: tx, lifecycle: lifecycle, holdTTL: holdTTL}
 }
 
 func (s *pekurbanHewanService) Create(ctx context.Context, req dto.CreatePekurbanHewanRequest) (*dto.PekurbanHewanResponse, error) {
@@ -49,59 +66,68 @@ func (s *pekurbanHewanService) Create(ctx context.Context, req dto.CreatePekurba
 		return nil, errors.New("Invalid hewan ID")
 	}
 
-	hewan, err := s.hRepo.GetById(ctx, hewanID)
-	if err != nil {
-		return nil, err
-	}
-	if hewan == nil {
-		return nil, errors.New("Hewan kurban not found")
-	}
+	var hewan *model.HewanKurban
+	var data *model.PekurbanHewan
+	var kapasitas int
 
-	var porsi float64
-	switch hewan.Jenis {
-	case "sapi":
-		if req.JumlahOrang > 7 {
-			return nil, errors.New("The maximum number of people for a sapi is 7")
+	// baris hewan dikunci selama validasi dan insert, sehingga request paralel pada hewan yang sama
+	// diproses bergantian dan total porsi tidak bisa melewati 1.0
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		hewan, err = s.hRepo.LockById(ctx, hewanID)
+		if err != nil {
+			return err
 		}
-		porsi = float64(req.JumlahOrang) / 7.0
-	case "kambing", "domba":
-		if req.JumlahOrang != 1 {
-			return nil, errors.New("Kambing/domba can only be for one person")
+		if hewan == nil {
+			return errors.New("Hewan kurban not found")
 		}
-		porsi = 1.0
-	default:
-		return nil, errors.New("Invalid jenis hewan")
-	}
 
-	existing, err := s.repo.GetByHewanId(ctx, hewanID)
-	if err != nil {
-		return nil, err
-	}
+		jenis, err := getJenis(ctx, s.jRepo, hewan.Jenis)
+		if err != nil {
+			return err
+		}
+		kapasitas = jenis.MaxPorsi
+		porsi, err := resolvePorsi(jenis, req.JumlahOrang, req.PorsiPembilang, req.PorsiPenyebut)
+		if err != nil {
+			return err
+		}
 
-	if hewan.IsPrivate {
-		if len(existing) > 0 {
-			return nil, errors.New("Hewan private can only be owned by one pekurban")
+		existing, err := s.repo.GetByHewanId(ctx, hewanID)
+		if err != nil {
+			return err
 		}
-		if porsi != 1.0 {
-			return nil, errors.New("Hewan private must be fully owned (porsi 1.0)")
+
+		if hewan.IsPrivate {
+			if len(existing) > 0 {
+				return errors.New("Hewan private can only be owned by one pekurban")
+			}
+			if !porsi.IsUtuh() {
+				return errors.New("Hewan private must be fully owned (porsi 1.0)")
+			}
 		}
-	}
 
-	var totalPorsi float64
-	for _, ph := range existing {
-		totalPorsi += ph.Porsi
-	}
-	if totalPorsi+porsi > 1.0 {
-		return nil, errors.New("Total portion exceeds the maximum limit")
-	}
+		if model.MelebihiSatuHewan(totalPorsi(existing, porsi, "")) {
+			return ErrPorsiExceeded
+		}
 
-	data := &model.PekurbanHewan{
-		PekurbanID: pekurbanID,
-		HewanID:    hewanID,
-		Porsi:      porsi,
-	}
+		// porsi baru ditahan sampai pembayaran pertama settle, agar slot terakhir tidak terkunci selamanya.
+		// Hewan private dan porsi tanpa tagihan tidak melalui pembayaran Midtrans, sehingga langsung terkonfirmasi.
+		data = &model.PekurbanHewan{
+			PekurbanID: pekurbanID,
+			HewanID:    hewanID,
+			Porsi:      porsi,
+			Status:     model.PorsiTerkonfirmasi,
+		}
+		if !hewan.IsPrivate && porsi.TagihanSen(hewan.Harga) > 0 {
+			expiresAt := time.Now().Add(s.holdTTL)
+			data.Status = model.PorsiDitahan
+			data.ExpiresAt = &expiresAt
+		}
 
-	err = s.repo.Create(ctx, data)
+		if err := s.repo.Create(ctx, data); err != nil {
+			return err
+		}
+		return s.lifecycle.SyncKepemilikan(ctx, hewanID)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +137,12 @@ func (s *pekurbanHewanService) Create(ctx context.Context, req dto.CreatePekurba
 		Pekurban:    *pekurban.Name,
 		Hewan:       string(hewan.Jenis),
 		HewanID:     data.HewanID.String(),
-		Porsi:       data.Porsi,
+		Porsi:       data.Porsi.Float64(),
+		PorsiPecahan: data.Porsi.String(),
+		JumlahOrang: data.Porsi.JumlahOrang(kapasitas),
+		Status:      data.Status,
+		ExpiresAt:   data.ExpiresAt,
+		AtasNama:    []dto.AtasNamaResponse{},
 	}
 
 	return resp, nil
@@ -123,25 +154,26 @@ func (s *pekurbanHewanService) GetAll(ctx context.Context) ([]dto.PekurbanHewanR
 		return nil, err
 	}
 
+	atasNama, err := s.aRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	atasNamaByShare := dto.GroupAtasNamaByShare(atasNama)
+
 	var res []dto.PekurbanHewanResponse
 	for _, rel := range list {
-		jumlahOrang := 0
-		
-		switch rel.Hewan {
-		case "sapi":
-			jumlahOrang = int(math.Round(rel.Porsi * 7.0))
-		case "kambing", "domba":
-			jumlahOrang = 1
-		default:
-			return nil, errors.New("Invalid jenis hewan")
-		}
+		jumlahOrang := rel.Porsi.JumlahOrang(rel.Kapasitas)
 		res = append(res, dto.PekurbanHewanResponse{
 			PekurbanID: rel.PekurbanID,
 			Pekurban:   rel.Pekurban,
 			HewanID:    rel.HewanID,
 			Hewan:      rel.Hewan,
-			Porsi:      rel.Porsi,
+			Porsi:      rel.Porsi.Float64(),
+			PorsiPecahan: rel.Porsi.String(),
 			JumlahOrang: jumlahOrang,
+			Status:     rel.Status,
+			ExpiresAt:  rel.ExpiresAt,
+			AtasNama:   atasNamaOrEmpty(atasNamaByShare[dto.ShareKey(rel.PekurbanID, rel.HewanID)]),
 		})
 	}
 	return res, nil
@@ -153,26 +185,27 @@ func (s *pekurbanHewanService) GetByHewanId(ctx context.Context, hewanID uuid.UU
 		return nil, err
 	}
 
+	atasNama, err := s.aRepo.GetByHewanId(ctx, hewanID)
+	if err != nil {
+		return nil, err
+	}
+	atasNamaByShare := dto.GroupAtasNamaByShare(atasNama)
+
 	var res []dto.PekurbanHewanResponse
 	for _, ph := range list{
-		jumlahOrang := 0			
-		
-		switch ph.Hewan {
-		case "sapi":
-			jumlahOrang = int(math.Round(ph.Porsi * 7.0))
-		case "kambing", "domba":
-			jumlahOrang = 1
-		default:
-			return nil, errors.New("Invalid jenis hewan")
-		}
+		jumlahOrang := ph.Porsi.JumlahOrang(ph.Kapasitas)
 
 		res = append(res, dto.PekurbanHewanResponse{
 			PekurbanID:  ph.PekurbanID,
 			Pekurban:    ph.Pekurban,
 			HewanID:     ph.HewanID,
 			Hewan:       ph.Hewan,
-			Porsi:       ph.Porsi,
+			Porsi:       ph.Porsi.Float64(),
+			PorsiPecahan: ph.Porsi.String(),
 			JumlahOrang: jumlahOrang,
+			Status:      ph.Status,
+			ExpiresAt:   ph.ExpiresAt,
+			AtasNama:    atasNamaOrEmpty(atasNamaByShare[dto.ShareKey(ph.PekurbanID, ph.HewanID)]),
 		})
 	}
 
@@ -185,26 +218,27 @@ func (s *pekurbanHewanService) GetByPekurbanId(ctx context.Context, pekurbanID u
 		return nil, err
 	}
 
+	atasNama, err := s.aRepo.GetByPekurbanId(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	atasNamaByShare := dto.GroupAtasNamaByShare(atasNama)
+
 	var res []dto.PekurbanHewanResponse
 	for _, ph := range list{
-		jumlahOrang := 0			
-		
-		switch ph.Hewan {
-		case "sapi":
-			jumlahOrang = int(math.Round(ph.Porsi * 7.0))
-		case "kambing", "domba":
-			jumlahOrang = 1
-		default:
-			return nil, errors.New("Invalid jenis hewan")
-		}
+		jumlahOrang := ph.Porsi.JumlahOrang(ph.Kapasitas)
 
 		res = append(res, dto.PekurbanHewanResponse{
 			PekurbanID: ph.PekurbanID,
 			Pekurban:   ph.Pekurban,
 			HewanID:    ph.HewanID,
 			Hewan:      ph.Hewan,
-			Porsi:      ph.Porsi,
+			Porsi:      ph.Porsi.Float64(),
+			PorsiPecahan: ph.Porsi.String(),
 			JumlahOrang: jumlahOrang,
+			Status:     ph.Status,
+			ExpiresAt:  ph.ExpiresAt,
+			AtasNama:   atasNamaOrEmpty(atasNamaByShare[dto.ShareKey(ph.PekurbanID, ph.HewanID)]),
 		})
 	}
 
@@ -212,76 +246,227 @@ func (s *pekurbanHewanService) GetByPekurbanId(ctx context.Context, pekurbanID u
 }
 
 func (s *pekurbanHewanService) Update(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdatePekurbanHewanRequest) (*dto.PekurbanHewanResponse, error) {
-	if req.JumlahOrang <= 0 || req.JumlahOrang > 7 {
-		return nil, errors.New("jumlah orang harus antara 1 dan 7")
+	pekurbanId, err := s.pRepo.FindById(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	if pekurbanId == nil {
+		return nil, errors.New("pekurban tidak ditemukan")
 	}
 
-	hewan, err := s.hRepo.GetById(ctx, hewanID)
+	var hewan *model.HewanKurban
+	var data *model.PekurbanHewan
+	var kapasitas int
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		hewan, err = s.hRepo.LockById(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+		if hewan == nil {
+			return errors.New("hewan tidak ditemukan")
+		}
+
+		jenis, err := getJenis(ctx, s.jRepo, hewan.Jenis)
+		if err != nil {
+			return err
+		}
+
+		// Hitung porsi dari jumlah orang atau pecahan khusus; hewan private selalu utuh
+		porsi := model.PecahanUtuh
+		if !hewan.IsPrivate {
+			porsi, err = resolvePorsi(jenis, req.JumlahOrang, req.PorsiPembilang, req.PorsiPenyebut)
+			if err != nil {
+				return err
+			}
+		}
+		kapasitas = jenis.MaxPorsi
+
+		// Validasi total porsi
+		existing, err := s.repo.GetByHewanId(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+
+		if model.MelebihiSatuHewan(totalPorsi(existing, porsi, pekurbanID.String())) {
+			return ErrPorsiExceeded
+		}
+
+		// Build struct model dan update
+		data = &model.PekurbanHewan{
+			PekurbanID: pekurbanID,
+			HewanID:    hewanID,
+			Porsi:      porsi,
+		}
+
+		if err := s.repo.Update(ctx, data); err != nil {
+			return err
+		}
+		return s.lifecycle.SyncKepemilikan(ctx, hewanID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if hewan == nil {
-		return nil, errors.New("hewan tidak ditemukan")
-	}
 
-	// Validasi kambing/domba hanya 1 orang
-	if !hewan.IsPrivate && (hewan.Jenis == "kambing" || hewan.Jenis == "domba") && req.JumlahOrang != 1 {
-		return nil, errors.New("kambing dan domba hanya boleh 1 orang")
+	return &dto.PekurbanHewanResponse{
+		PekurbanID: data.PekurbanID.String(),
+		Pekurban:   *pekurbanId.Name,
+		HewanID:    data.HewanID.String(),
+		Hewan:      string(hewan.Jenis),
+		Porsi:      data.Porsi.Float64(),
+		PorsiPecahan: data.Porsi.String(),
+		JumlahOrang: data.Porsi.JumlahOrang(kapasitas),
+	}, nil
+}
+
+func (s *pekurbanHewanService) Delete(ctx context.Context, pekurbanID, hewanID uuid.UUID) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, pekurbanID, hewanID); err != nil {
+			return err
+		}
+		// sama seperti pembatalan: settlement yang terlambat tidak boleh memulihkan porsi yang dihapus
+		if err := s.payRepo.BatalkanPorsi(ctx, pekurbanID, hewanID); err != nil {
+			return err
+		}
+		return s.lifecycle.SyncKepemilikan(ctx, hewanID)
+	})
+}
+
+func (s *pekurbanHewanService) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	hewanIDs, err := s.repo.DeleteExpiredHolds(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	// Hitung porsi otomatis
-	porsi := float64(req.JumlahOrang) / 7.0
-	if hewan.IsPrivate || hewan.Jenis == "kambing" || hewan.Jenis == "domba" {
-		porsi = 1.0
+	synced := map[uuid.UUID]bool{}
+	for _, id := range hewanIDs {
+		if synced[id] {
+			continue
+		}
+		synced[id] = true
+		if err := s.lifecycle.SyncKepemilikan(ctx, id); err != nil {
+			return int64(len(hewanIDs)), err
+		}
 	}
+	return int64(len(hewanIDs)), nil
+}
 
-	// Validasi total porsi
-	existing, err := s.repo.GetByHewanId(ctx, hewanID)
+// UpdateAtasNama mengganti daftar atas nama pada satu porsi. Hanya bisa diubah sebelum hewan disembelih.
+func (s *pekurbanHewanService) UpdateAtasNama(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdateAtasNamaRequest) ([]dto.AtasNamaResponse, error) {
+	shares, err := s.repo.GetByPekurbanId(ctx, pekurbanID)
 	if err != nil {
 		return nil, err
 	}
 
-	pekurbanId, err := s.pRepo.FindById(ctx, pekurbanID)
+	var share *model.PekurbanHewanJoin
+	for _, ph := range shares {
+		if ph.HewanID == hewanID.String() {
+			share = ph
+			break
+		}
+	}
+	if share == nil {
+		return nil, errors.New("Patungan not found")
+	}
+
+	slaughtered, err := isHewanSlaughtered(ctx, s.sRepo, hewanID)
 	if err != nil {
 		return nil, err
 	}
-	if pekurbanId == nil {
-		return nil, errors.New("pekurban tidak ditemukan")
+	if slaughtered {
+		return nil, errors.New("Atas nama can no longer be changed after the hewan is slaughtered")
 	}
 
-	var total float64
-	for _, ph := range existing {
-		if ph.PekurbanID != pekurbanID.String() {
-			total += ph.Porsi
-		}
+	maxNama := share.Porsi.JumlahOrang(share.Kapasitas)
+	if maxNama < 1 {
+		maxNama = 1
 	}
-	if total+porsi > 1.0 {
-		return nil, errors.New("total porsi melebihi batas maksimal")
+	if len(req.AtasNama) > maxNama {
+		return nil, fmt.Errorf("This share can only be offered on behalf of at most %d names", maxNama)
 	}
 
-	// Build struct model dan update
-	data := &model.PekurbanHewan{
-		PekurbanID: pekurbanID,
-		HewanID:    hewanID,
-		Porsi:      porsi,
+	now := time.Now()
+	list := make([]*model.AtasNama, 0, len(req.AtasNama))
+	for _, a := range req.AtasNama {
+		nama := strings.TrimSpace(a.Nama)
+		if nama == "" {
+			return nil, errors.New("Nama is required")
+		}
+		list = append(list, &model.AtasNama{
+			ID:         uuid.New(),
+			PekurbanID: pekurbanID,
+			HewanID:    hewanID,
+			Nama:       nama,
+			Hubungan:   a.Hubungan,
+			MasihHidup: *a.MasihHidup,
+			Created_At: now,
+			Updated_At: now,
+		})
 	}
 
-	err = s.repo.Update(ctx, data)
-
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.aRepo.ReplaceForShare(ctx, pekurbanID, hewanID, list)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.PekurbanHewanResponse{
-		PekurbanID: data.PekurbanID.String(),
-		Pekurban:   *pekurbanId.Name,
-		HewanID:    data.HewanID.String(),
-		Hewan:      string(hewan.Jenis),
-		Porsi:      data.Porsi,
-		JumlahOrang: req.JumlahOrang,
-	}, nil
+	res := make([]dto.AtasNamaResponse, 0, len(list))
+	for _, a := range list {
+		res = append(res, dto.ToAtasNamaResponse(a))
+	}
+	return res, nil
 }
 
-func (s *pekurbanHewanService) Delete(ctx context.Context, pekurbanID, hewanID uuid.UUID) error {
-	return s.repo.Delete(ctx, pekurbanID, hewanID)
-}
\ No newline at end of file
+// UpdateKehadiran mencatat permintaan pekurban untuk hadir saat penyembelihan; dipakai sebagai prioritas
+// saat jadwal penyembelihan dibuat otomatis
+func (s *pekurbanHewanService) UpdateKehadiran(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.UpdateKehadiranRequest) error {
+	slaughtered, err := isHewanSlaughtered(ctx, s.sRepo, hewanID)
+	if err != nil {
+		return err
+	}
+	if slaughtered {
+		return errors.New("Kehadiran can no longer be changed after the hewan is slaughtered")
+	}
+
+	return s.repo.SetKehadiran(ctx, pekurbanID, hewanID, *req.InginHadir)
+}
+
+// resolvePorsi menentukan porsi dari jumlah orang (jumlah_orang/max_porsi) atau dari pecahan yang ditentukan pemilik
+func resolvePorsi(jenis *model.JenisHewanMaster, jumlahOrang int, pembilang, penyebut int64) (model.Pecahan, error) {
+	if pembilang > 0 || penyebut > 0 {
+		if jumlahOrang > 0 {
+			return model.Pecahan{}, errors.New("Use either jumlah_orang or porsi_pembilang/porsi_penyebut, not both")
+		}
+		if pembilang <= 0 || penyebut <= 0 {
+			return model.Pecahan{}, errors.New("porsi_pembilang and porsi_penyebut must be filled together")
+		}
+		return model.NewPecahan(pembilang, penyebut)
+	}
+
+	if jumlahOrang <= 0 {
+		return model.Pecahan{}, errors.New("jumlah_orang or porsi_pembilang/porsi_penyebut is required")
+	}
+	if jumlahOrang > jenis.MaxPorsi {
+		return model.Pecahan{}, fmt.Errorf("The maximum number of people for a %s is %d", jenis.Nama, jenis.MaxPorsi)
+	}
+	return jenis.Porsi(jumlahOrang)
+}
+
+// totalPorsi menjumlahkan porsi aktif pada hewan secara eksak ditambah porsi baru; porsi milik pekurban exclude tidak dihitung
+func totalPorsi(existing []*model.PekurbanHewanJoin, porsi model.Pecahan, exclude string) *big.Rat {
+	list := []model.Pecahan{porsi}
+	for _, ph := range existing {
+		if ph.PekurbanID != exclude {
+			list = append(list, ph.Porsi)
+		}
+	}
+	return model.TotalPecahan(list...)
+}
+
+func atasNamaOrEmpty(list []dto.AtasNamaResponse) []dto.AtasNamaResponse {
+	if list == nil {
+		return []dto.AtasNamaResponse{}
+	}
+	return list
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/testdb"
+)
+
+func TestPekurbanHewanTriggerTotalPorsi(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	phRepo := repository.NewPekurbanHewanRepository(db)
+
+	hewanID := uuid.New()
+	if _, err := db.Exec(`INSERT INTO hewan_kurban (id, jenis, berat, harga, is_private, tanggal_pendaftaran) VALUES ($1, 'sapi', 300, 21000000, false, CURRENT_DATE)`, hewanID); err != nil {
+		t.Fatal(err)
+	}
+
+	tambah := func(pembilang int64) error {
+		id := uuid.New()
+		if _, err := db.Exec(`INSERT INTO pekurban (id, name) VALUES ($1, 'Pekurban')`, id); err != nil {
+			t.Fatal(err)
+		}
+		return phRepo.Create(ctx, &model.PekurbanHewan{
+			PekurbanID: id,
+			HewanID:    hewanID,
+			Porsi:      model.Pecahan{Pembilang: pembilang, Penyebut: 7},
+			Status:     model.PorsiTerkonfirmasi,
+		})
+	}
+
+	// tanpa kunci service, trigger tetap menolak porsi yang melewati satu hewan
+	if err := tambah(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tambah(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := tambah(1); !errors.Is(err, ErrPorsiExceeded) {
+		t.Errorf("insert over capacity: got %v, want ErrPorsiExceeded", err)
+	}
+
+	// hold yang sudah kedaluwarsa tidak ikut dihitung
+	if _, err := db.Exec(`UPDATE pekurban_hewan SET status = 'ditahan', expires_at = now() - interval '1 minute' WHERE hewan_id = $1 AND porsi_pembilang = 2`, hewanID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tambah(2); err != nil {
+		t.Errorf("insert after expired hold: %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+// RefundPolicy menentukan refund pembatalan: penuh sebelum FullRefundBefore, sebagian (PartialPercent)
+// setelahnya, dan tidak ada setelah hewan disembelih. FullRefundBefore nil berarti selalu refund penuh.
+type RefundPolicy struct {
+	FullRefundBefore *time.Time
+	PartialPercent   int
+}
+
+// Evaluate mengembalikan kebijakan dan persentase refund untuk pengajuan pada waktu requestedAt
+func (p RefundPolicy) Evaluate(requestedAt time.Time, slaughtered bool) (string, int) {
+	if slaughtered {
+		return model.RefundTidakAda, 0
+	}
+	if p.FullRefundBefore == nil || requestedAt.Before(*p.FullRefundBefore) {
+		return model.RefundPenuh, 100
+	}
+	return model.RefundSebagian, p.PartialPercent
+}
+
+type PembatalanPatunganService interface {
+	Create(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.CreatePembatalanRequest) (*dto.PembatalanResponse, error)
+	GetAll(ctx context.Context) ([]dto.PembatalanResponse, error)
+	GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]dto.PembatalanResponse, error)
+	Approve(ctx context.Context, id, adminID uuid.UUID, req dto.ProsesPembatalanRequest) (*dto.PembatalanResponse, error)
+	Reject(ctx context.Context, id, adminID uuid.UUID, req dto.ProsesPembatalanRequest) (*dto.PembatalanResponse, error)
+}
+
+type pembatalanPatunganService struct {
+	repo      repository.PembatalanPatunganRepository
+	phRepo    repository.PekurbanHewanRepository
+	hRepo     repository.HewanKurbanRepository
+	sRepo     repository.PenyembelihanRepository
+	payRepo   repository.PembayaranKurbanRepository
+	tx        repository.TxManager
+	lifecycle HewanLifecycleService
+	policy    RefundPolicy
+}
+
+func NewPembatalanPatunganService(repo repository.PembatalanPatunganRepository, phRepo repository.PekurbanHewanRepository, hRepo repository.HewanKurbanRepository, sRepo repository.PenyembelihanRepository, payRepo repository.PembayaranKurbanRepository, tx repository.TxManager, lifecycle HewanLifecycleService, policy RefundPolicy) PembatalanPatunganService {
+	return &pembatalanPatunganService{repo: repo, phRepo: phRepo, hRepo: hRepo, sRepo: sRepo, payRepo: payRepo, tx: tx, lifecycle: lifecycle, policy: policy}
+}
+
+// Create mencatat pengajuan pembatalan beserta perkiraan refund menurut kebijakan saat ini
+func (s *pembatalanPatunganService) Create(ctx context.Context, pekurbanID, hewanID uuid.UUID, req dto.CreatePembatalanRequest) (*dto.PembatalanResponse, error) {
+	share, err := s.findShare(ctx, pekurbanID, hewanID)
+	if err != nil {
+		return nil, err
+	}
+
+	slaughtered, err := isHewanSlaughtered(ctx, s.sRepo, hewanID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	kebijakan, persen := s.policy.Evaluate(now, slaughtered)
+	if kebijakan == model.RefundTidakAda {
+		return nil, errors.New("Patungan can no longer be cancelled after the hewan is slaughtered")
+	}
+
+	hewan, err := s.hRepo.GetById(ctx, hewanID)
+	if err != nil {
+		return nil, err
+	}
+	dibayar, err := s.allocatedPayment(ctx, pekurbanID, share.Porsi, hewan)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &model.PembatalanPatungan{
+		ID:               uuid.New(),
+		PekurbanID:       pekurbanID,
+		HewanID:          hewanID,
+		Porsi:            share.Porsi,
+		Alasan:           req.Alasan,
+		Status:           model.PembatalanMenunggu,
+		Kebijakan:        kebijakan,
+		PersentaseRefund: persen,
+		JumlahDibayar:    dibayar,
+		JumlahRefund:     hitungRefund(dibayar, persen),
+		Created_At:       now,
+		Updated_At:       now,
+		Pekurban:         share.Pekurban,
+		Hewan:            share.Hewan,
+	}
+	if err := s.repo.Create(ctx, data); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPembatalanResponse(data)
+	return &res, nil
+}
+
+func (s *pembatalanPatunganService) GetAll(ctx context.Context) ([]dto.PembatalanResponse, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.PembatalanResponse{}
+	for _, p := range list {
+		res = append(res, dto.ToPembatalanResponse(p))
+	}
+	return res, nil
+}
+
+func (s *pembatalanPatunganService) GetByPekurbanId(ctx context.Context, pekurbanID uuid.UUID) ([]dto.PembatalanResponse, error) {
+	list, err := s.repo.GetByPekurbanId(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.PembatalanResponse{}
+	for _, p := range list {
+		res = append(res, dto.ToPembatalanResponse(p))
+	}
+	return res, nil
+}
+
+// Approve menyetujui pembatalan: porsi dilepas sehingga slot hewan kembali tersedia, dan refund
+// dihitung ulang dari pembayaran terkini memakai kebijakan pada tanggal pengajuan. Porsi yang sudah
+// dilepas lebih dulu tetap disetujui dengan refund nol.
+func (s *pembatalanPatunganService) Approve(ctx context.Context, id, adminID uuid.UUID, req dto.ProsesPembatalanRequest) (*dto.PembatalanResponse, error) {
+	var data *model.PembatalanPatungan
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = s.lockPending(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		hewan, err := s.hRepo.LockById(ctx, data.HewanID)
+		if err != nil {
+			return err
+		}
+		if hewan == nil {
+			return errors.New("Hewan kurban not found")
+		}
+
+		slaughtered, err := isHewanSlaughtered(ctx, s.sRepo, data.HewanID)
+		if err != nil {
+			return err
+		}
+		kebijakan, persen := s.policy.Evaluate(data.Created_At, slaughtered)
+		if kebijakan == model.RefundTidakAda {
+			return errors.New("Hewan has already been slaughtered; the request can only be rejected")
+		}
+
+		shares, err := s.phRepo.GetByHewanId(ctx, data.HewanID)
+		if err != nil {
+			return err
+		}
+		aktif := false
+		for _, ph := range shares {
+			if ph.PekurbanID == data.PekurbanID.String() {
+				aktif = true
+				break
+			}
+		}
+
+		// porsi yang sudah dilepas sweeper karena masa tahannya habis tidak punya pembayaran untuk direfund
+		var dibayar float64
+		if aktif {
+			dibayar, err = s.allocatedPayment(ctx, data.PekurbanID, data.Porsi, hewan)
+			if err != nil {
+				return err
+			}
+			if err := s.phRepo.Delete(ctx, data.PekurbanID, data.HewanID); err != nil {
+				return err
+			}
+		}
+		// pembayaran porsi ini yang belum settle dibatalkan agar settlement yang terlambat tidak memulihkannya
+		if err := s.payRepo.BatalkanPorsi(ctx, data.PekurbanID, data.HewanID); err != nil {
+			return err
+		}
+		if err := s.lifecycle.SyncKepemilikan(ctx, data.HewanID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		data.Status = model.PembatalanDisetujui
+		data.Kebijakan = kebijakan
+		data.PersentaseRefund = persen
+		data.JumlahDibayar = dibayar
+		data.JumlahRefund = hitungRefund(dibayar, persen)
+		data.DiprosesOleh = &adminID
+		data.CatatanAdmin = req.Catatan
+		data.DiprosesAt = &now
+		return s.repo.Process(ctx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPembatalanResponse(data)
+	return &res, nil
+}
+
+func (s *pembatalanPatunganService) Reject(ctx context.Context, id, adminID uuid.UUID, req dto.ProsesPembatalanRequest) (*dto.PembatalanResponse, error) {
+	var data *model.PembatalanPatungan
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = s.lockPending(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		data.Status = model.PembatalanDitolak
+		data.JumlahRefund = 0
+		data.DiprosesOleh = &adminID
+		data.CatatanAdmin = req.Catatan
+		data.DiprosesAt = &now
+		return s.repo.Process(ctx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPembatalanResponse(data)
+	return &res, nil
+}
+
+func (s *pembatalanPatunganService) findShare(ctx context.Context, pekurbanID, hewanID uuid.UUID) (*model.PekurbanHewanJoin, error) {
+	shares, err := s.phRepo.GetByPekurbanId(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ph := range shares {
+		if ph.HewanID == hewanID.String() {
+			return ph, nil
+		}
+	}
+	return nil, errors.New("Patungan not found")
+}
+
+func (s *pembatalanPatunganService) lockPending(ctx context.Context, id uuid.UUID) (*model.PembatalanPatungan, error) {
+	data, err := s.repo.LockById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errors.New("Cancellation request not found")
+	}
+	if data.Status != model.PembatalanMenunggu {
+		return nil, errors.New("Cancellation request has already been processed")
+	}
+	return data, nil
+}
+
+// allocatedPayment menghitung bagian pembayaran settlement pekurban yang menjadi milik porsi ini.
+func (s *pembatalanPatunganService) allocatedPayment(ctx context.Context, pekurbanID uuid.UUID, porsi model.Pecahan, hewan *model.HewanKurban) (float64, error) {
+	return alokasiPembayaran(ctx, s.payRepo, s.repo, pekurbanID, porsi, hewan)
+}
+
+// alokasiPembayaran menghitung dana yang sudah dibayar untuk satu porsi dari catatan porsi pembayaran yang settle
+// pada hewan tersebut, dibatasi tagihan porsi. Pembayaran lama yang belum mencatat porsinya dialokasikan dari
+// saldo pekurban yang belum direfund. Dipakai juga saat porsi dialihkan ke pekurban lain.
+func alokasiPembayaran(ctx context.Context, payRepo repository.PembayaranKurbanRepository, bRepo repository.PembatalanPatunganRepository, pekurbanID uuid.UUID, porsi model.Pecahan, hewan *model.HewanKurban) (float64, error) {
+	tercatat, err := payRepo.HasPorsi(ctx, pekurbanID)
+	if err != nil {
+		return 0, err
+	}
+
+	var saldo float64
+	if tercatat && hewan != nil {
+		saldo, err = payRepo.SumPorsiDibayar(ctx, pekurbanID, hewan.ID)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		paid, err := payRepo.SumSettlementByPekurban(ctx, pekurbanID)
+		if err != nil {
+			return 0, err
+		}
+		refunded, err := bRepo.SumRefundByPekurban(ctx, pekurbanID)
+		if err != nil {
+			return 0, err
+		}
+		saldo = math.Max(paid-refunded, 0)
+	}
+
+	if hewan == nil || hewan.IsPrivate {
+		return saldo, nil
+	}
+	tagihan := float64(porsi.TagihanSen(hewan.Harga)) / 100
+	return math.Min(saldo, tagihan), nil
+}
+
+// hitungRefund mengambil persentase dari jumlah dibayar, dibulatkan ke sen
+func hitungRefund(dibayar float64, persen int) float64 {
+	sen := int64(math.Round(dibayar * 100))
+	return float64((sen*int64(persen)+50)/100) / 100
+}
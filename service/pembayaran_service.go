@@ -4,7 +4,6 @@ package service
 import (
 	"context"
 	"errors"
-	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,23 +21,30 @@ type PembayaranKurbanService interface {
 	GetAll(ctx context.Context) ([]dto.PaymentResponse, error)
 	GetRekapDanaPerHewan(ctx context.Context) ([]dto.RekapDanaHewanResponse, error)
 	GetProgressPembayaran(ctx context.Context) ([]dto.ProgressPembayaranPekurban, error)
+	HandleNotification(ctx context.Context, req dto.MidtransNotificationRequest) error
 }
 
+var ErrInvalidSignature = errors.New("invalid signature key")
+
 type pembayaranKurbanService struct {
 	repo            repository.PembayaranKurbanRepository
 	midtransService payserv.MidtransService
 	pRepo 			repository.PekurbanHewanRepository
 	hRepo			repository.HewanKurbanRepository
 	pekurbanRepo	repository.PekurbanRepository
+	lifecycle		HewanLifecycleService
+	tx				repository.TxManager
 }
 
-func NewPembayaranKurbanService(repo repository.PembayaranKurbanRepository, mid payserv.MidtransService, pRepo repository.PekurbanHewanRepository, hRepo repository.HewanKurbanRepository, pekurbanRepo repository.PekurbanRepository) PembayaranKurbanService {
+func NewPembayaranKurbanService(repo repository.PembayaranKurbanRepository, mid payserv.MidtransService, pRepo repository.PekurbanHewanRepository, hRepo repository.HewanKurbanRepository, pekurbanRepo repository.PekurbanRepository, lifecycle HewanLifecycleService, tx repository.TxManager) PembayaranKurbanService {
 	return &pembayaranKurbanService{
 		repo: repo,
 		midtransService: mid,
 		pRepo: pRepo,
 		hRepo: hRepo,
 		pekurbanRepo: pekurbanRepo,
+		lifecycle: lifecycle,
+		tx: tx,
 	}
 }
 
@@ -62,16 +68,26 @@ func (s *pembayaranKurbanService) Create(ctx context.Context, req dto.CreatePaym
 		return nil, errors.New("pekurban tidak memiliki relasi dengan hewan kurban")
 	}
 
-	var total float64
+	// tagihan dihitung dari pecahan eksak dalam satuan sen agar tidak ada selisih pembulatan float
+	var totalSen int64
+	var porsiDibayar []*model.PembayaranPorsi
 	for _, r := range patunganList {
 		hewanId, _ := uuid.Parse(r.HewanID)
 		hewan, err := s.hRepo.GetById(ctx, hewanId)
 		if err != nil || hewan == nil {
 			return nil, errors.New("data hewan kurban not found")
 		}
-		total += r.Porsi * hewan.Harga
-		total = math.Round(total*100) / 100
+		tagihan := r.Porsi.TagihanSen(hewan.Harga)
+		totalSen += tagihan
+		porsiDibayar = append(porsiDibayar, &model.PembayaranPorsi{
+			HewanID:    hewanId,
+			PekurbanID: req.PekurbanID,
+			Porsi:      r.Porsi,
+			Tagihan:    float64(tagihan) / 100,
+			Status:     model.PembayaranPorsiMenunggu,
+		})
 	}
+	total := float64(totalSen) / 100
 
 	payload := dto.ToMidtransChargeRequest(orderID, total, *pekurban.Name, *pekurban.Email, *pekurban.Phone, req)
 	midResp, err := s.midtransService.Charge(payload)
@@ -94,7 +110,7 @@ func (s *pembayaranKurbanService) Create(ctx context.Context, req dto.CreatePaym
 		Metode:           	req.Metode,
 		PaymentType:      	&midResp.PaymentType,
 		VANumber:         	vaNumber,
-		Status:           	midResp.TransactionStatus,
+		Status:           	toPaymentStatus(midResp.TransactionStatus),
 		FraudStatus:      	midResp.FraudStatus,
 		ApprovalCode:     	midResp.ApprovalCode,
 		TransactionTime:  	&trxTime,
@@ -104,14 +120,187 @@ func (s *pembayaranKurbanService) Create(ctx context.Context, req dto.CreatePaym
 		Updated_At:        	time.Now(),
 	}
 
-	if err := s.repo.Create(ctx, payment); err != nil {
+	for _, pp := range porsiDibayar {
+		pp.PembayaranID = payment.ID
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, payment); err != nil {
+			return err
+		}
+		return s.repo.CreatePorsi(ctx, porsiDibayar)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	if payment.Status == "settlement" {
+		if err := s.settle(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
 	res := dto.ToPaymentResponse(payment, total, midResp)
 	return &res, nil
 }
 
+// HandleNotification memproses notifikasi status transaksi dari Midtrans. Pembayaran yang settle
+// mengonfirmasi porsi yang ditagihkan padanya.
+func (s *pembayaranKurbanService) HandleNotification(ctx context.Context, req dto.MidtransNotificationRequest) error {
+	if !s.midtransService.VerifySignature(req.OrderID, req.StatusCode, req.GrossAmount, req.SignatureKey) {
+		return ErrInvalidSignature
+	}
+
+	p, err := s.repo.FindByOrderID(ctx, req.OrderID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errors.New("order ID not found")
+	}
+
+	status := toPaymentStatus(req.TransactionStatus)
+	if req.TransactionStatus == "capture" && req.FraudStatus != nil && *req.FraudStatus == "challenge" {
+		status = "pending"
+	}
+
+	if err := s.repo.UpdateStatus(ctx, req.OrderID, status, req.FraudStatus); err != nil {
+		return err
+	}
+
+	if status == "settlement" {
+		return s.settle(ctx, p)
+	}
+	return nil
+}
+
+// settle mengonfirmasi porsi yang ditagihkan pada pembayaran lalu memperbarui status hewan yang terkait.
+// Porsi yang masa tahannya sudah habis, atau sudah dilepas, dikonfirmasi ulang selama hewan masih terdaftar
+// atau terisi dan kapasitasnya cukup; jika tidak, atau porsinya sudah dibatalkan, porsi dan pembayarannya
+// ditandai perlu refund.
+func (s *pembayaranKurbanService) settle(ctx context.Context, p *model.PembayaranKurban) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		list, err := s.repo.GetPorsi(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return s.settleSemua(ctx, p.PekurbanID)
+		}
+
+		perluRefund := false
+		for _, pp := range list {
+			var ok bool
+			switch pp.Status {
+			case model.PembayaranPorsiMenunggu:
+				ok, err = s.konfirmasiPorsi(ctx, pp)
+				if err != nil {
+					return err
+				}
+			case model.PembayaranPorsiDibatalkan:
+				// porsi sudah dibatalkan sebelum pembayarannya settle, dananya harus dikembalikan
+				ok = false
+			default:
+				continue
+			}
+
+			status := model.PembayaranPorsiTerkonfirmasi
+			if !ok {
+				status = model.PembayaranPorsiPerluRefund
+				perluRefund = true
+			}
+			if err := s.repo.UpdatePorsiStatus(ctx, p.ID, pp.HewanID, status); err != nil {
+				return err
+			}
+			if ok {
+				if err := s.lifecycle.SyncKepemilikan(ctx, pp.HewanID); err != nil {
+					return err
+				}
+			}
+		}
+		if perluRefund {
+			return s.repo.SetPerluRefund(ctx, p.ID)
+		}
+		return nil
+	})
+}
+
+// konfirmasiPorsi mengunci hewan lalu mengonfirmasi porsi milik pemilik porsi saat ini. Porsi yang masih
+// ditahan atau sudah dilepas hanya dikonfirmasi jika hewan masih terdaftar atau terisi dan total porsi
+// aktifnya tetap tidak melebihi 1.
+func (s *pembayaranKurbanService) konfirmasiPorsi(ctx context.Context, pp *model.PembayaranPorsi) (bool, error) {
+	hewan, err := s.hRepo.LockById(ctx, pp.HewanID)
+	if err != nil {
+		return false, err
+	}
+	if hewan == nil {
+		return false, nil
+	}
+
+	shares, err := s.pRepo.GetByHewanId(ctx, pp.HewanID)
+	if err != nil {
+		return false, err
+	}
+	porsi := []model.Pecahan{pp.Porsi}
+	for _, ph := range shares {
+		if ph.PekurbanID == pp.PekurbanID.String() {
+			if ph.Status == model.PorsiTerkonfirmasi {
+				return true, nil
+			}
+			continue
+		}
+		porsi = append(porsi, ph.Porsi)
+	}
+	if hewan.Status != model.HewanTerdaftar && hewan.Status != model.HewanTerisi {
+		return false, nil
+	}
+	if model.MelebihiSatuHewan(model.TotalPecahan(porsi...)) {
+		return false, nil
+	}
+
+	if err := s.pRepo.Confirm(ctx, pp.PekurbanID, pp.HewanID, pp.Porsi); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// settleSemua dipakai untuk pembayaran lama yang belum mencatat porsinya: semua porsi ditahan milik
+// pekurban yang belum kedaluwarsa dikonfirmasi
+func (s *pembayaranKurbanService) settleSemua(ctx context.Context, pekurbanID uuid.UUID) error {
+	if _, err := s.pRepo.ConfirmByPekurbanId(ctx, pekurbanID); err != nil {
+		return err
+	}
+
+	shares, err := s.pRepo.GetByPekurbanId(ctx, pekurbanID)
+	if err != nil {
+		return err
+	}
+	for _, ph := range shares {
+		hewanID, err := uuid.Parse(ph.HewanID)
+		if err != nil {
+			return err
+		}
+		if err := s.lifecycle.SyncKepemilikan(ctx, hewanID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toPaymentStatus(transactionStatus string) string {
+	switch transactionStatus {
+	case "capture", "settlement":
+		return "settlement"
+	case "expire":
+		return "expired"
+	case "cancel", "failure":
+		return "failed"
+	case "deny":
+		return "deny"
+	default:
+		return "pending"
+	}
+}
+
 func (s *pembayaranKurbanService) GetByID(ctx context.Context, id uuid.UUID) (*dto.PaymentResponse, error) {
 	p, err := s.repo.FindByID(ctx, id)
 	if err != nil {
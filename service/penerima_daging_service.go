@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"mime/multipart"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,15 +21,20 @@ type PenerimaDagingService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*dto.PenerimaResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req dto.UpdatePenerimaRequest) (*dto.PenerimaResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Import membaca daftar penerima dari CSV/XLSX; dry run hanya melaporkan validasi, selain itu baris valid
+	// disimpan dalam satu transaksi
+	Import(ctx context.Context, fh *multipart.FileHeader, req dto.ImportPenerimaRequest) (*dto.ImportPenerimaResponse, error)
 }
 
 type penerimaDagingService struct {
 	repo repository.PenerimaDagingRepository
 	repoPk	repository.PekurbanRepository
+	tx	repository.TxManager
+	maxSize	int64 // ukuran maksimal berkas impor
 }
 
-func NewPenerimaDagingService(repo repository.PenerimaDagingRepository , repoPk repository.PekurbanRepository) PenerimaDagingService {
-	return &penerimaDagingService{repo: repo, repoPk: repoPk}
+func NewPenerimaDagingService(repo repository.PenerimaDagingRepository , repoPk repository.PekurbanRepository, tx repository.TxManager, maxSize int64) PenerimaDagingService {
+	return &penerimaDagingService{repo: repo, repoPk: repoPk, tx: tx, maxSize: maxSize}
 }
 
 func (s *penerimaDagingService) Create(ctx context.Context, req dto.CreatePenerimaRequest) (*dto.PenerimaResponse, error) {
@@ -59,11 +67,23 @@ func (s *penerimaDagingService) Create(ctx context.Context, req dto.CreatePeneri
 		}
 	}
 
+	rt, err := nomorWilayah(req.RT)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := nomorWilayah(req.RW)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &model.PenerimaDaging{
 		ID: uuid.New(),
 		Name: req.Name,
 		Alamat: req.Alamat,
 		Phone: req.Phone,
+		RT: rt,
+		RW: rw,
+		AntarRumah: req.AntarRumah != nil && *req.AntarRumah,
 		Status: req.Status,
 		PekurbanID: pekurbanID,
 		Created_At: time.Now(),
@@ -116,6 +136,19 @@ func (s *penerimaDagingService) Update(ctx context.Context, id uuid.UUID, req dt
 	if strings.TrimSpace(*req.Phone) != "" {
 		existing.Phone = req.Phone
 	}
+	if req.RT != nil {
+		if existing.RT, err = nomorWilayah(req.RT); err != nil {
+			return nil, err
+		}
+	}
+	if req.RW != nil {
+		if existing.RW, err = nomorWilayah(req.RW); err != nil {
+			return nil, err
+		}
+	}
+	if req.AntarRumah != nil {
+		existing.AntarRumah = *req.AntarRumah
+	}
 	existing.Status = req.Status
 
 	if req.PekurbanID != nil {
@@ -135,4 +168,18 @@ func (s *penerimaDagingService) Update(ctx context.Context, id uuid.UUID, req dt
 
 func (s *penerimaDagingService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)	
-}
\ No newline at end of file
+}
+
+// nomorWilayah menyeragamkan nomor RT/RW menjadi tiga digit ("3" -> "003") agar pengelompokan
+// batch antar tidak terpecah karena penulisan berbeda; string kosong berarti dikosongkan
+func nomorWilayah(v *string) (*string, error) {
+	if v == nil || strings.TrimSpace(*v) == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(*v))
+	if err != nil || n < 0 || n > 999 {
+		return nil, errors.New("RT/RW must be a number up to 999")
+	}
+	s := fmt.Sprintf("%03d", n)
+	return &s, nil
+}
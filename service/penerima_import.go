@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/spreadsheet"
+)
+
+// batas baris per berkas impor; daftar satu RT biasanya hanya puluhan sampai ratusan baris
+const maksBarisImport = 5000
+
+// hasil validasi per baris
+const (
+	importValid    = "valid"
+	importInvalid  = "invalid"
+	importDuplikat = "duplikat"
+	importDibuat   = "dibuat"
+)
+
+// kolomImport memetakan kolom tujuan ke judul kolom yang lazim dipakai ketua RT, sudah dinormalisasi
+var kolomImport = map[string][]string{
+	"name":        {"name", "nama", "nama penerima", "nama lengkap", "nama warga"},
+	"alamat":      {"alamat", "address", "alamat rumah", "alamat lengkap"},
+	"phone":       {"phone", "telepon", "telp", "no telp", "no telepon", "hp", "no hp", "nomor hp", "no wa", "whatsapp", "wa"},
+	"status":      {"status", "kategori", "status penerima"},
+	"rt":          {"rt"},
+	"rw":          {"rw"},
+	"antar_rumah": {"antar rumah", "diantar", "antar"},
+}
+
+var statusPenerimaValid = map[string]bool{"warga": true, "dhuafa": true, "panitia": true, "pekurban": true}
+
+var (
+	bukanAlfanumerik = regexp.MustCompile(`[^a-z0-9]+`)
+	pemisahTelepon   = regexp.MustCompile(`[\s\-.()]`)
+	teleponValid     = regexp.MustCompile(`^0[0-9]{8,13}$`)
+)
+
+func (s *penerimaDagingService) Import(ctx context.Context, fh *multipart.FileHeader, req dto.ImportPenerimaRequest) (*dto.ImportPenerimaResponse, error) {
+	if fh == nil {
+		return nil, errors.New("No file uploaded")
+	}
+	if fh.Size > s.maxSize {
+		return nil, fmt.Errorf("File %s exceeds maximum size of %d MB", fh.Filename, s.maxSize>>20)
+	}
+	statusDefault := strings.ToLower(strings.TrimSpace(req.StatusDefault))
+	if statusDefault != "" && !statusPenerimaValid[statusDefault] {
+		return nil, fmt.Errorf("Invalid status_default %q", req.StatusDefault)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := spreadsheet.Read(f, fh.Filename, req.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("File is empty")
+	}
+	if len(rows)-1 > maksBarisImport {
+		return nil, fmt.Errorf("File has more than %d rows", maksBarisImport)
+	}
+
+	indeks, kolom, err := petakanKolom(rows[0], req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// kunci duplikat: nomor telepon, atau nama+alamat jika sama persis setelah dinormalisasi
+	sudahAda := map[string]string{}
+	for _, p := range existing {
+		label := fmt.Sprintf("penerima %s (%s)", p.ID, p.Name)
+		for _, k := range kunciDuplikat(p.Name, p.Alamat, p.Phone) {
+			sudahAda[k] = label
+		}
+	}
+
+	res := &dto.ImportPenerimaResponse{DryRun: req.DryRun, Kolom: kolom, Baris: []dto.ImportPenerimaBaris{}}
+	var valid []*model.PenerimaDaging
+	var validIdx []int
+	for i, row := range rows[1:] {
+		sel := func(field string) string {
+			j, ok := indeks[field]
+			if !ok || j >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[j])
+		}
+		if barisKosong(row) {
+			continue
+		}
+		res.TotalBaris++
+
+		b, p := validasiBaris(i+2, sel, statusDefault)
+		if len(b.Errors) == 0 {
+			for _, k := range kunciDuplikat(p.Name, p.Alamat, p.Phone) {
+				if label, ok := sudahAda[k]; ok {
+					b.Hasil = importDuplikat
+					b.DuplikatDengan = &label
+					break
+				}
+			}
+		}
+
+		switch b.Hasil {
+		case importInvalid:
+			res.Invalid++
+		case importDuplikat:
+			res.Duplikat++
+		default:
+			res.Valid++
+			label := fmt.Sprintf("baris %d", b.Baris)
+			for _, k := range kunciDuplikat(p.Name, p.Alamat, p.Phone) {
+				sudahAda[k] = label
+			}
+			valid = append(valid, p)
+			validIdx = append(validIdx, len(res.Baris))
+		}
+		res.Baris = append(res.Baris, b)
+	}
+
+	if req.DryRun || len(valid) == 0 {
+		return res, nil
+	}
+
+	// semua baris valid disimpan bersama; satu kegagalan membatalkan seluruh impor
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		for n, p := range valid {
+			p.ID = uuid.New()
+			p.Created_At = now
+			p.Updated_At = now
+			if err := s.repo.Create(ctx, p); err != nil {
+				return fmt.Errorf("Row %d: %v", res.Baris[validIdx[n]].Baris, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for n, p := range valid {
+		id := p.ID.String()
+		b := &res.Baris[validIdx[n]]
+		b.Hasil = importDibuat
+		b.PenerimaID = &id
+	}
+	res.Dibuat = len(valid)
+	return res, nil
+}
+
+// petakanKolom mencari indeks setiap kolom tujuan: dari mapping yang diberikan, lalu dari judul yang lazim
+func petakanKolom(judul []string, mapping map[string]string) (map[string]int, map[string]string, error) {
+	posisi := map[string]int{}
+	for i, j := range judul {
+		key := normalisasiJudul(j)
+		if _, ok := posisi[key]; !ok && key != "" {
+			posisi[key] = i
+		}
+	}
+
+	indeks := map[string]int{}
+	kolom := map[string]string{}
+	for field, nama := range mapping {
+		if _, ok := kolomImport[field]; !ok {
+			return nil, nil, fmt.Errorf("Unknown mapping field %q", field)
+		}
+		i, ok := posisi[normalisasiJudul(nama)]
+		if !ok {
+			return nil, nil, fmt.Errorf("Column %q for %s not found in file", nama, field)
+		}
+		indeks[field] = i
+		kolom[field] = judul[i]
+	}
+
+	fields := make([]string, 0, len(kolomImport))
+	for field := range kolomImport {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if _, ok := indeks[field]; ok {
+			continue
+		}
+		for _, alias := range kolomImport[field] {
+			if i, ok := posisi[alias]; ok {
+				indeks[field] = i
+				kolom[field] = judul[i]
+				break
+			}
+		}
+	}
+
+	if _, ok := indeks["name"]; !ok {
+		return nil, nil, errors.New("Name column not found; map it with mapping.name")
+	}
+	return indeks, kolom, nil
+}
+
+// validasiBaris memeriksa satu baris dan menyiapkan penerima yang akan disimpan; semua kesalahan dikumpulkan
+func validasiBaris(nomor int, sel func(string) string, statusDefault string) (dto.ImportPenerimaBaris, *model.PenerimaDaging) {
+	b := dto.ImportPenerimaBaris{Baris: nomor, Hasil: importValid, Name: sel("name")}
+	p := &model.PenerimaDaging{Name: b.Name}
+
+	if b.Name == "" {
+		b.Errors = append(b.Errors, "name is required")
+	}
+
+	if v := sel("alamat"); v != "" {
+		b.Alamat = &v
+		p.Alamat = &v
+	}
+
+	if v := sel("phone"); v != "" {
+		phone, ok := normalisasiTelepon(v)
+		if ok {
+			b.Phone = &phone
+			p.Phone = &phone
+		} else {
+			b.Phone = &v
+			b.Errors = append(b.Errors, fmt.Sprintf("invalid phone %q", v))
+		}
+	}
+
+	b.Status = strings.ToLower(sel("status"))
+	if b.Status == "" {
+		b.Status = statusDefault
+	}
+	switch {
+	case b.Status == "":
+		b.Errors = append(b.Errors, "status is required")
+	case !statusPenerimaValid[b.Status]:
+		b.Errors = append(b.Errors, fmt.Sprintf("invalid status %q (warga, dhuafa, panitia, pekurban)", b.Status))
+	}
+	p.Status = b.Status
+
+	rt, rw := sel("rt"), sel("rw")
+	var err error
+	if p.RT, err = nomorWilayah(&rt); err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("invalid rt %q", rt))
+	}
+	if p.RW, err = nomorWilayah(&rw); err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("invalid rw %q", rw))
+	}
+	b.RT, b.RW = p.RT, p.RW
+
+	switch strings.ToLower(sel("antar_rumah")) {
+	case "", "0", "tidak", "t", "no", "n", "false", "-":
+	case "1", "ya", "y", "yes", "true", "x", "v":
+		p.AntarRumah = true
+		b.AntarRumah = true
+	default:
+		b.Errors = append(b.Errors, fmt.Sprintf("invalid antar_rumah %q (ya/tidak)", sel("antar_rumah")))
+	}
+
+	if len(b.Errors) > 0 {
+		b.Hasil = importInvalid
+	}
+	return b, p
+}
+
+// normalisasiTelepon menyeragamkan nomor ke format 08xx; sel angka di Excel sering kehilangan nol di depan
+func normalisasiTelepon(v string) (string, bool) {
+	s := pemisahTelepon.ReplaceAllString(v, "")
+	switch {
+	case strings.HasPrefix(s, "+62"):
+		s = "0" + s[3:]
+	case strings.HasPrefix(s, "62"):
+		s = "0" + s[2:]
+	case strings.HasPrefix(s, "8"):
+		s = "0" + s
+	}
+	return s, teleponValid.MatchString(s)
+}
+
+func kunciDuplikat(nama string, alamat, phone *string) []string {
+	var keys []string
+	if phone != nil && *phone != "" {
+		if p, ok := normalisasiTelepon(*phone); ok {
+			keys = append(keys, "phone:"+p)
+		}
+	}
+	if alamat != nil && strings.TrimSpace(*alamat) != "" {
+		keys = append(keys, "nama:"+normalisasiJudul(nama)+"|"+normalisasiJudul(*alamat))
+	}
+	return keys
+}
+
+func normalisasiJudul(s string) string {
+	return strings.TrimSpace(bukanAlfanumerik.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+func barisKosong(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,16 +21,29 @@ type PenyembelihanService interface {
 	GetAll(ctx context.Context) ([]dto.PenyembelihanResponse, error)
 	GetById(ctx context.Context, id uuid.UUID) (*dto.PenyembelihanResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req dto.UpdatePenyembelihanRequest) (*dto.PenyembelihanResponse, error)
+	UpdateHasil(ctx context.Context, id uuid.UUID, req dto.UpdateHasilPenyembelihanRequest) (*dto.PenyembelihanResponse, error)
+	GenerateJadwal(ctx context.Context, req dto.GenerateJadwalRequest) (*dto.GenerateJadwalResponse, error)
+	AssignJagal(ctx context.Context, id uuid.UUID, req dto.AssignJagalRequest) (*dto.PenyembelihanResponse, error)
+	Mulai(ctx context.Context, id uuid.UUID, req dto.MulaiPenyembelihanRequest) (*dto.PenyembelihanResponse, error)
+	Selesai(ctx context.Context, id uuid.UUID, req dto.SelesaiPenyembelihanRequest) (*dto.PenyembelihanResponse, error)
+	GetRekapHarian(ctx context.Context, tanggal time.Time) (*dto.RekapHarianPenyembelihanResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type penyembelihanService struct {
-	repo  repository.PenyembelihanRepository
-	pRepo repository.PembayaranKurbanRepository
+	repo  		repository.PenyembelihanRepository
+	hRepo 		repository.HewanKurbanRepository
+	aRepo 		repository.AtasNamaRepository
+	lRepo		repository.LokasiRepository
+	ptRepo		repository.PetugasRepository
+	sRepo		repository.ShiftRepository
+	periode		PeriodeKurbanService
+	lifecycle	HewanLifecycleService
+	tx			repository.TxManager
 }
 
-func NewPenyembelihanService(repo repository.PenyembelihanRepository, pRepo repository.PembayaranKurbanRepository) PenyembelihanService {
-	return &penyembelihanService{repo: repo, pRepo: pRepo}
+func NewPenyembelihanService(repo repository.PenyembelihanRepository, hRepo repository.HewanKurbanRepository, aRepo repository.AtasNamaRepository, lRepo repository.LokasiRepository, ptRepo repository.PetugasRepository, sRepo repository.ShiftRepository, periode PeriodeKurbanService, lifecycle HewanLifecycleService, tx repository.TxManager) PenyembelihanService {
+	return &penyembelihanService{repo: repo, hRepo: hRepo, aRepo: aRepo, lRepo: lRepo, ptRepo: ptRepo, sRepo: sRepo, periode: periode, lifecycle: lifecycle, tx: tx}
 }
 
 func (s *penyembelihanService) Create(ctx context.Context, req dto.CreatePenyembelihanRequest) (*dto.PenyembelihanResponse, error) {
@@ -35,35 +52,61 @@ func (s *penyembelihanService) Create(ctx context.Context, req dto.CreatePenyemb
 		return nil, err
 	}
 
-	isLunas, err := s.pRepo.IsHewanLunas(ctx, hewanID)
+	hewan, err := s.hRepo.GetById(ctx, hewanID)
 	if err != nil {
 		return nil, err
 	}
+	if hewan == nil {
+		return nil, errors.New("Hewan kurban not found")
+	}
 
-	if !isLunas {
+	// hanya hewan berstatus lunas yang bisa dijadwalkan
+	if hewan.Status != model.HewanLunas {
 		return nil, errors.New("Hewan is not fully paid yet and cannot be slaughtered.")
 	}
 
-	if strings.TrimSpace(req.Lokasi) == "" {
-		return nil, errors.New("Lokasi is required")
+	if err := s.periode.ValidasiTanggal(ctx, req.TanggalPenyembelihan); err != nil {
+		return nil, err
+	}
+
+	lokasiID, err := uuid.Parse(req.LokasiID)
+	if err != nil {
+		return nil, errors.New("Invalid lokasi ID")
+	}
+	lokasi, err := getLokasi(ctx, s.lRepo, lokasiID, model.LokasiPenyembelihan)
+	if err != nil {
+		return nil, err
 	}
 
 	p := &model.Penyembelihan{
 		ID: uuid.New(),
 		HewanID: hewanID,
 		TglPenyembelihan: req.TanggalPenyembelihan,
-		Lokasi: req.Lokasi,
+		LokasiID: lokasi.ID,
+		Lokasi: lokasi.Nama,
 		UrutanRencana: req.UrutanRencana,
 		UrutanAktual: nil,
+		RencanaMulai: req.RencanaMulai,
+		Jagal: req.Jagal,
 		Created_At: time.Now(),
 		Updated_At: time.Now(),
 	}
 
-	if err := s.repo.Create(ctx, p); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.lifecycle.Transition(ctx, hewanID, model.HewanDijadwalkan, nil); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, p)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	res := dto.ToPenyembelihanResponse(p)
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(p, offset)
 	return &res, nil
 }
 
@@ -73,9 +116,27 @@ func (s *penyembelihanService) GetAll(ctx context.Context) ([]dto.PenyembelihanR
 		return nil, err
 	}
 
+	// daftar atas nama dibacakan saat penyembelihan, jadi disertakan per hewan
+	atasNama, err := s.aRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	atasNamaByHewan := map[string][]dto.AtasNamaResponse{}
+	for _, a := range atasNama {
+		atasNamaByHewan[a.HewanID.String()] = append(atasNamaByHewan[a.HewanID.String()], dto.ToAtasNamaResponse(a))
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var res []dto.PenyembelihanResponse
 	for _, p := range list {
-		res = append(res, dto.ToPenyembelihanResponse(p))
+		item := dto.ToPenyembelihanResponse(p, offset)
+		if names, ok := atasNamaByHewan[item.HewanID]; ok {
+			item.AtasNama = names
+		}
+		res = append(res, item)
 	}
 
 	return res, nil
@@ -86,8 +147,23 @@ func (s *penyembelihanService) GetById(ctx context.Context, id uuid.UUID) (*dto.
 	if err != nil {
 		return nil, err
 	}
+	if p == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
 
-	res := dto.ToPenyembelihanResponse(p)
+	atasNama, err := s.aRepo.GetByHewanId(ctx, p.HewanID)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(p, offset)
+	for _, a := range atasNama {
+		res.AtasNama = append(res.AtasNama, dto.ToAtasNamaResponse(a))
+	}
 	return &res, nil
 }
 
@@ -97,21 +173,498 @@ func (s *penyembelihanService) Update(ctx context.Context, id uuid.UUID, req dto
 		return nil, err
 	}
 
+	// jadwal lama di luar periode tetap bisa diubah selama tanggalnya tidak diganti
+	if req.TanggalPenyembelihan.Format("2006-01-02") != existing.TglPenyembelihan.Format("2006-01-02") {
+		if err := s.periode.ValidasiTanggal(ctx, req.TanggalPenyembelihan); err != nil {
+			return nil, err
+		}
+	}
 	existing.TglPenyembelihan = req.TanggalPenyembelihan
-	if strings.TrimSpace(req.Lokasi) != "" {
-		existing.Lokasi = req.Lokasi
+	if req.LokasiID != "" {
+		lokasiID, err := uuid.Parse(req.LokasiID)
+		if err != nil {
+			return nil, errors.New("Invalid lokasi ID")
+		}
+		lokasi, err := getLokasi(ctx, s.lRepo, lokasiID, model.LokasiPenyembelihan)
+		if err != nil {
+			return nil, err
+		}
+		existing.LokasiID = lokasi.ID
+		existing.Lokasi = lokasi.Nama
 	}
 	existing.UrutanRencana = req.UrutanRencana
+	if req.RencanaMulai != nil {
+		existing.RencanaMulai = req.RencanaMulai
+	}
+	disembelih := existing.UrutanAktual == nil && req.UrutanAktual != nil
 	existing.UrutanAktual = req.UrutanAktual
 
-	if err = s.repo.Update(ctx, existing); err != nil {
+	// mengisi urutan aktual berarti hewan sudah disembelih
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		if disembelih {
+			return s.lifecycle.Transition(ctx, existing.HewanID, model.HewanDisembelih, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(existing, offset)
+	return &res, nil
+}
+
+// UpdateHasil mencatat berat hasil penyembelihan; hanya untuk hewan yang urutan aktualnya sudah diisi
+func (s *penyembelihanService) UpdateHasil(ctx context.Context, id uuid.UUID, req dto.UpdateHasilPenyembelihanRequest) (*dto.PenyembelihanResponse, error) {
+	existing, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
+	if existing.UrutanAktual == nil {
+		return nil, errors.New("Hewan has not been slaughtered yet")
+	}
+
+	if req.BeratHidup != nil {
+		existing.BeratHidup = req.BeratHidup
+	}
+	if req.BeratKarkas != nil {
+		existing.BeratKarkas = req.BeratKarkas
+	}
+	if req.BeratDaging != nil {
+		existing.BeratDaging = req.BeratDaging
+	}
+	if req.BeratTulang != nil {
+		existing.BeratTulang = req.BeratTulang
+	}
+	if req.BeratJeroan != nil {
+		existing.BeratJeroan = req.BeratJeroan
+	}
+	if req.JumlahPaket != nil {
+		existing.JumlahPaket = req.JumlahPaket
+	}
+
+	if err := validateHasil(existing); err != nil {
 		return nil, err
 	}
 
-	res := dto.ToPenyembelihanResponse(existing)
+	if err := s.repo.UpdateHasil(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(existing, offset)
 	return &res, nil
 }
 
 func (s *penyembelihanService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
-}
\ No newline at end of file
+	existing, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.New("Penyembelihan not found")
+	}
+
+	// jadwal yang dihapus mengembalikan hewan ke status lunas; hewan yang sudah disembelih tetap
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		hewan, err := s.hRepo.LockById(ctx, existing.HewanID)
+		if err != nil || hewan == nil {
+			return err
+		}
+		if hewan.Status == model.HewanDijadwalkan {
+			keterangan := "Jadwal penyembelihan dihapus"
+			return s.lifecycle.Transition(ctx, existing.HewanID, model.HewanLunas, &keterangan)
+		}
+		return nil
+	})
+}
+
+// GenerateJadwal menyusun tanggal, lokasi, dan urutan_rencana untuk semua hewan lunas yang belum dijadwalkan.
+// Tanpa Simpan hasilnya hanya pratinjau; dengan Simpan seluruh jadwal dibuat dalam satu transaksi.
+func (s *penyembelihanService) GenerateJadwal(ctx context.Context, req dto.GenerateJadwalRequest) (*dto.GenerateJadwalResponse, error) {
+	aturan, err := aturanPrioritas(req.Prioritas)
+	if err != nil {
+		return nil, err
+	}
+	lokasi := make([]*model.Lokasi, 0, len(req.Lokasi))
+	for _, l := range req.Lokasi {
+		lokasiID, err := uuid.Parse(l.LokasiID)
+		if err != nil {
+			return nil, errors.New("Invalid lokasi ID")
+		}
+		lok, err := getLokasi(ctx, s.lRepo, lokasiID, model.LokasiPenyembelihan)
+		if err != nil {
+			return nil, err
+		}
+		lokasi = append(lokasi, lok)
+	}
+
+	planner, err := newPenjadwal(req, lokasi)
+	if err != nil {
+		return nil, err
+	}
+	planner.offset, err = s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range planner.hari {
+		if err := s.periode.ValidasiTanggal(ctx, h.tanggal); err != nil {
+			return nil, err
+		}
+	}
+
+	hewan, err := s.hRepo.GetSiapJadwal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	terjadwal, err := s.repo.GetBelumDisembelih(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rencana, gagal := planner.susun(hewan, terjadwal, aturan)
+
+	res := &dto.GenerateJadwalResponse{Jadwal: []dto.JadwalItemResponse{}, TidakTerjadwal: gagal}
+	for _, r := range rencana {
+		res.Jadwal = append(res.Jadwal, planner.toItem(r))
+	}
+	if !req.Simpan || len(rencana) == 0 {
+		return res, nil
+	}
+
+	keterangan := "Jadwal penyembelihan dibuat otomatis"
+	now := time.Now()
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, r := range rencana {
+			if err := s.lifecycle.Transition(ctx, r.hewan.ID, model.HewanDijadwalkan, &keterangan); err != nil {
+				return err
+			}
+			err := s.repo.Create(ctx, &model.Penyembelihan{
+				ID:               uuid.New(),
+				HewanID:          r.hewan.ID,
+				TglPenyembelihan: planner.hari[r.slot.hari].tanggal,
+				LokasiID:         planner.lokasi[r.slot.lokasi].id,
+				UrutanRencana:    r.urutan,
+				RencanaMulai:     &r.slot.mulai,
+				Jagal:            jagalNama(r.slot.jagal),
+				Created_At:       now,
+				Updated_At:       now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Disimpan = true
+	return res, nil
+}
+
+// AssignJagal menugaskan petugas jagal ke penyembelihan. Jagal harus punya shift jagal di lokasi tersebut
+// yang mencakup rencana_mulai, atau shift jagal pada tanggal itu jika rencana_mulai belum ada.
+func (s *penyembelihanService) AssignJagal(ctx context.Context, id uuid.UUID, req dto.AssignJagalRequest) (*dto.PenyembelihanResponse, error) {
+	petugasID, err := uuid.Parse(req.PetugasID)
+	if err != nil {
+		return nil, errors.New("Invalid petugas ID")
+	}
+
+	existing, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
+	if existing.MulaiAt != nil || existing.UrutanAktual != nil {
+		return nil, errors.New("Penyembelihan has already started")
+	}
+
+	petugas, err := s.ptRepo.GetById(ctx, petugasID)
+	if err != nil {
+		return nil, err
+	}
+	if petugas == nil {
+		return nil, errors.New("Petugas not found")
+	}
+	if !petugas.MemilikiPeran(model.PeranJagal) {
+		return nil, fmt.Errorf("Petugas %s is not a jagal", petugas.Nama)
+	}
+
+	if existing.RencanaMulai != nil {
+		shift, err := s.sRepo.GetMeliputi(ctx, petugasID, existing.LokasiID, model.PeranJagal, *existing.RencanaMulai)
+		if err != nil {
+			return nil, err
+		}
+		if shift == nil {
+			return nil, fmt.Errorf("Jagal %s has no jagal shift at %s covering %s", petugas.Nama, existing.Lokasi, existing.RencanaMulai.In(time.Local).Format("2006-01-02 15:04"))
+		}
+	} else {
+		t := existing.TglPenyembelihan
+		tanggal := t.Format("2006-01-02")
+		shifts, err := s.sRepo.GetByPetugas(ctx, petugasID, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local))
+		if err != nil {
+			return nil, err
+		}
+		ada := false
+		for _, sh := range shifts {
+			if sh.LokasiID == existing.LokasiID && sh.Peran == model.PeranJagal && sh.Mulai.In(time.Local).Format("2006-01-02") == tanggal {
+				ada = true
+				break
+			}
+		}
+		if !ada {
+			return nil, fmt.Errorf("Jagal %s has no jagal shift at %s on %s", petugas.Nama, existing.Lokasi, tanggal)
+		}
+	}
+
+	if err := s.repo.SetJagal(ctx, existing.ID, petugas.ID, petugas.Nama); err != nil {
+		return nil, err
+	}
+	existing.JagalID = &petugas.ID
+	existing.Jagal = &petugas.Nama
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(existing, offset)
+	return &res, nil
+}
+
+// Mulai mencatat check-in awal penyembelihan beserta jagal dan saksi yang bertanggung jawab
+func (s *penyembelihanService) Mulai(ctx context.Context, id uuid.UUID, req dto.MulaiPenyembelihanRequest) (*dto.PenyembelihanResponse, error) {
+	existing, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
+	if existing.UrutanAktual != nil {
+		return nil, errors.New("Hewan has already been slaughtered")
+	}
+	if existing.MulaiAt != nil {
+		return nil, errors.New("Penyembelihan has already started")
+	}
+
+	if req.Jagal != "" {
+		jagal := strings.TrimSpace(req.Jagal)
+		existing.Jagal = &jagal
+	}
+	if existing.Jagal == nil || *existing.Jagal == "" {
+		return nil, errors.New("Jagal is required")
+	}
+	saksi := strings.TrimSpace(req.Saksi)
+	existing.Saksi = &saksi
+
+	waktu, err := waktuCheckIn(req.Waktu)
+	if err != nil {
+		return nil, err
+	}
+	existing.MulaiAt = &waktu
+
+	if err := s.repo.Mulai(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(existing, offset)
+	return &res, nil
+}
+
+// Selesai mencatat check-in akhir; urutan_aktual diberikan otomatis sesuai urutan selesai di lokasi dan
+// tanggal yang sama, lalu status hewan menjadi disembelih
+func (s *penyembelihanService) Selesai(ctx context.Context, id uuid.UUID, req dto.SelesaiPenyembelihanRequest) (*dto.PenyembelihanResponse, error) {
+	existing, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("Penyembelihan not found")
+	}
+	if existing.MulaiAt == nil {
+		return nil, errors.New("Penyembelihan has not started yet")
+	}
+	if existing.SelesaiAt != nil || existing.UrutanAktual != nil {
+		return nil, errors.New("Penyembelihan is already finished")
+	}
+
+	waktu, err := waktuCheckIn(req.Waktu)
+	if err != nil {
+		return nil, err
+	}
+	if waktu.Before(*existing.MulaiAt) {
+		return nil, errors.New("Waktu selesai must be after waktu mulai")
+	}
+	existing.SelesaiAt = &waktu
+	if req.Saksi != "" {
+		saksi := strings.TrimSpace(req.Saksi)
+		existing.Saksi = &saksi
+	}
+
+	keterangan := "Check-in selesai penyembelihan"
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Selesai(ctx, existing); err != nil {
+			return err
+		}
+		return s.lifecycle.Transition(ctx, existing.HewanID, model.HewanDisembelih, &keterangan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPenyembelihanResponse(existing, offset)
+	return &res, nil
+}
+
+// GetRekapHarian merangkum check-in satu tanggal: rata-rata durasi per jenis dan keterlambatan terhadap rencana
+func (s *penyembelihanService) GetRekapHarian(ctx context.Context, tanggal time.Time) (*dto.RekapHarianPenyembelihanResponse, error) {
+	list, err := s.repo.GetByTanggal(ctx, tanggal)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.RekapHarianPenyembelihanResponse{
+		Tanggal:        tanggal.Format("2006-01-02"),
+		TotalJadwal:    len(list),
+		DurasiPerJenis: []dto.RekapDurasiJenisResponse{},
+		Hewan:          []dto.PenyembelihanResponse{},
+	}
+
+	perJenis := map[string]*dto.RekapDurasiJenisResponse{}
+	var urutanJenis []string
+	var totalTelat float64
+	var jumlahTelat int
+	for _, p := range list {
+		switch {
+		case p.UrutanAktual != nil:
+			res.Selesai++
+		case p.MulaiAt != nil:
+			res.Berlangsung++
+		default:
+			res.BelumMulai++
+		}
+
+		if durasi := p.DurasiMenit(); durasi != nil {
+			jenis := string(p.JenisHewan)
+			r, ok := perJenis[jenis]
+			if !ok {
+				r = &dto.RekapDurasiJenisResponse{Jenis: jenis, MinDurasiMenit: *durasi, MaxDurasiMenit: *durasi}
+				perJenis[jenis] = r
+				urutanJenis = append(urutanJenis, jenis)
+			}
+			r.Selesai++
+			r.RataDurasiMenit += *durasi
+			r.MinDurasiMenit = math.Min(r.MinDurasiMenit, *durasi)
+			r.MaxDurasiMenit = math.Max(r.MaxDurasiMenit, *durasi)
+		}
+
+		if telat := p.KeterlambatanMenit(); telat != nil {
+			totalTelat += *telat
+			jumlahTelat++
+			if *telat > 0 {
+				res.Terlambat++
+			}
+			if res.MaxKeterlambatanMenit == nil || *telat > *res.MaxKeterlambatanMenit {
+				res.MaxKeterlambatanMenit = telat
+			}
+		}
+
+		item := dto.ToPenyembelihanResponse(p, offset)
+		res.Hewan = append(res.Hewan, item)
+	}
+
+	sort.Strings(urutanJenis)
+	for _, jenis := range urutanJenis {
+		r := perJenis[jenis]
+		r.RataDurasiMenit = math.Round(r.RataDurasiMenit/float64(r.Selesai)*10) / 10
+		res.DurasiPerJenis = append(res.DurasiPerJenis, *r)
+	}
+	if jumlahTelat > 0 {
+		rata := math.Round(totalTelat/float64(jumlahTelat)*10) / 10
+		res.RataKeterlambatanMenit = &rata
+	}
+	return res, nil
+}
+
+// waktuCheckIn memakai waktu sekarang jika kosong dan menolak waktu di masa depan
+func waktuCheckIn(waktu *time.Time) (time.Time, error) {
+	now := time.Now()
+	if waktu == nil {
+		return now, nil
+	}
+	if waktu.After(now.Add(time.Minute)) {
+		return time.Time{}, errors.New("Waktu check-in cannot be in the future")
+	}
+	return *waktu, nil
+}
+
+func jagalNama(j *jagalJadwal) *string {
+	if j == nil {
+		return nil
+	}
+	return &j.nama
+}
+
+// validateHasil memastikan bagian-bagian tidak melebihi keseluruhannya: karkas dan jeroan berasal dari
+// berat hidup, sedangkan daging dan tulang berasal dari karkas
+func validateHasil(p *model.Penyembelihan) error {
+	val := func(v *float64) float64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+
+	if p.BeratHidup != nil && val(p.BeratKarkas)+val(p.BeratJeroan) > *p.BeratHidup {
+		return errors.New("Berat karkas and jeroan must not exceed berat hidup")
+	}
+	if p.BeratKarkas != nil && val(p.BeratDaging)+val(p.BeratTulang) > *p.BeratKarkas {
+		return errors.New("Berat daging and tulang must not exceed berat karkas")
+	}
+	return nil
+}
+
+// isHewanSlaughtered bernilai true jika hewan sudah punya urutan aktual atau tanggal penyembelihannya sudah tiba
+func isHewanSlaughtered(ctx context.Context, repo repository.PenyembelihanRepository, hewanID uuid.UUID) (bool, error) {
+	p, err := repo.GetByHewanID(ctx, hewanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return p.UrutanAktual != nil || !time.Now().Before(p.TglPenyembelihan), nil
+}
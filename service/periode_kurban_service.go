@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/hijri"
+)
+
+type PeriodeKurbanService interface {
+	Create(ctx context.Context, req dto.CreatePeriodeKurbanRequest) (*dto.PeriodeKurbanResponse, error)
+	GetAll(ctx context.Context) ([]dto.PeriodeKurbanResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req dto.UpdatePeriodeKurbanRequest) (*dto.PeriodeKurbanResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetJendela(ctx context.Context, tahunHijri int) (*dto.JendelaKurbanResponse, error)
+	ValidasiTanggal(ctx context.Context, tanggal time.Time) error
+	// OffsetHijri mengambil offset hari periode aktif untuk menampilkan tanggal Hijriah; 0 jika belum ada periode aktif
+	OffsetHijri(ctx context.Context) (int, error)
+}
+
+type periodeKurbanService struct {
+	repo repository.PeriodeKurbanRepository
+	tx   repository.TxManager
+}
+
+func NewPeriodeKurbanService(repo repository.PeriodeKurbanRepository, tx repository.TxManager) PeriodeKurbanService {
+	return &periodeKurbanService{repo: repo, tx: tx}
+}
+
+func (s *periodeKurbanService) Create(ctx context.Context, req dto.CreatePeriodeKurbanRequest) (*dto.PeriodeKurbanResponse, error) {
+	now := time.Now()
+	p := &model.PeriodeKurban{
+		ID:         uuid.New(),
+		TahunHijri: req.TahunHijri,
+		OffsetHari: req.OffsetHari,
+		Aktif:      req.Aktif,
+		Created_At: now,
+		Updated_At: now,
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if p.Aktif {
+			if err := s.repo.NonaktifkanSemua(ctx); err != nil {
+				return err
+			}
+		}
+		return s.repo.Create(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToPeriodeKurbanResponse(p)
+	return &res, nil
+}
+
+func (s *periodeKurbanService) GetAll(ctx context.Context) ([]dto.PeriodeKurbanResponse, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.PeriodeKurbanResponse{}
+	for _, p := range list {
+		res = append(res, dto.ToPeriodeKurbanResponse(p))
+	}
+	return res, nil
+}
+
+func (s *periodeKurbanService) Update(ctx context.Context, id uuid.UUID, req dto.UpdatePeriodeKurbanRequest) (*dto.PeriodeKurbanResponse, error) {
+	p, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Periode kurban not found")
+	}
+
+	if req.TahunHijri != nil {
+		p.TahunHijri = *req.TahunHijri
+	}
+	if req.OffsetHari != nil {
+		p.OffsetHari = *req.OffsetHari
+	}
+	aktifkan := req.Aktif != nil && *req.Aktif && !p.Aktif
+	if req.Aktif != nil {
+		p.Aktif = *req.Aktif
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if aktifkan {
+			if err := s.repo.NonaktifkanSemua(ctx); err != nil {
+				return err
+			}
+		}
+		return s.repo.Update(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Updated_At = time.Now()
+	res := dto.ToPeriodeKurbanResponse(p)
+	return &res, nil
+}
+
+func (s *periodeKurbanService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// GetJendela menghitung hari kurban untuk tahun Hijriah tertentu, atau periode aktif jika tahunHijri 0
+func (s *periodeKurbanService) GetJendela(ctx context.Context, tahunHijri int) (*dto.JendelaKurbanResponse, error) {
+	var (
+		p   *model.PeriodeKurban
+		err error
+	)
+	if tahunHijri == 0 {
+		p, err = s.aktif(ctx)
+	} else {
+		p, err = s.repo.GetByTahun(ctx, tahunHijri)
+		if err == nil && p == nil {
+			p = &model.PeriodeKurban{TahunHijri: tahunHijri}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToJendelaKurbanResponse(p, p.ID != uuid.Nil)
+	return &res, nil
+}
+
+// ValidasiTanggal menolak tanggal penyembelihan di luar 10-13 Dzulhijjah periode aktif
+func (s *periodeKurbanService) ValidasiTanggal(ctx context.Context, tanggal time.Time) error {
+	p, err := s.aktif(ctx)
+	if err != nil {
+		return err
+	}
+	if p.Meliputi(tanggal) {
+		return nil
+	}
+
+	mulai, selesai := p.Jendela()
+	return fmt.Errorf("Tanggal penyembelihan %s (%s) must be between %s and %s (10-13 Dzulhijjah %d H)",
+		tanggal.Format("2006-01-02"), hijri.FromGregorian(tanggal, p.OffsetHari), mulai.Format("2006-01-02"), selesai.Format("2006-01-02"), p.TahunHijri)
+}
+
+// aktif mengambil periode aktif. Jika belum ada, dipakai tahun Hijriah dengan Idul Adha terdekat
+// yang belum lewat tanpa offset, supaya penjadwalan tetap berjalan sebelum admin mengatur periode.
+func (s *periodeKurbanService) aktif(ctx context.Context) (*model.PeriodeKurban, error) {
+	p, err := s.repo.GetAktif(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		h := hijri.FromGregorian(time.Now(), 0)
+		tahun := h.Tahun
+		if h.Bulan == hijri.Dzulhijjah && h.Hari > model.HariTasyrikAkhir {
+			tahun++
+		}
+		p = &model.PeriodeKurban{TahunHijri: tahun}
+	}
+	return p, nil
+}
+
+func (s *periodeKurbanService) OffsetHijri(ctx context.Context) (int, error) {
+	p, err := s.repo.GetAktif(ctx)
+	if err != nil || p == nil {
+		return 0, err
+	}
+	return p.OffsetHari, nil
+}
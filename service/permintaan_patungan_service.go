@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+	utilsservice "github.com/wahyujatirestu/sahabat-kurban/utils/service"
+)
+
+type PermintaanPatunganService interface {
+	Create(ctx context.Context, req dto.CreatePermintaanPatunganRequest) (*dto.PermintaanPatunganResponse, error)
+	GetAll(ctx context.Context) ([]dto.PermintaanPatunganResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.PermintaanPatunganResponse, error)
+	GetByPekurbanID(ctx context.Context, pekurbanID uuid.UUID) ([]dto.PermintaanPatunganResponse, error)
+	Cancel(ctx context.Context, id uuid.UUID) error
+	Match(ctx context.Context) (*dto.MatchPatunganResponse, error)
+}
+
+type permintaanPatunganService struct {
+	repo         repository.PermintaanPatunganRepository
+	pRepo        repository.PekurbanRepository
+	hRepo        repository.HewanKurbanRepository
+	jRepo        repository.JenisHewanRepository
+	tx           repository.TxManager
+	lifecycle    HewanLifecycleService
+	emailService utilsservice.EmailService
+	holdTTL      time.Duration
+	mu           sync.Mutex
+}
+
+func NewPermintaanPatunganService(repo repository.PermintaanPatunganRepository, pRepo repository.PekurbanRepository, hRepo repository.HewanKurbanRepository, jRepo repository.JenisHewanRepository, tx repository.TxManager, lifecycle HewanLifecycleService, emailService utilsservice.EmailService, holdTTL time.Duration) PermintaanPatunganService {
+	return &permintaanPatunganService{repo: repo, pRepo: pRepo, hRepo: hRepo, jRepo: jRepo, tx: tx, lifecycle: lifecycle, emailService: emailService, holdTTL: holdTTL}
+}
+
+func (s *permintaanPatunganService) Create(ctx context.Context, req dto.CreatePermintaanPatunganRequest) (*dto.PermintaanPatunganResponse, error) {
+	pekurbanID, err := uuid.Parse(req.PekurbanID)
+	if err != nil {
+		return nil, errors.New("Invalid pekurban ID")
+	}
+
+	pekurban, err := s.pRepo.FindById(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	if pekurban == nil {
+		return nil, errors.New("Pekurban not found")
+	}
+
+	jenis, err := getJenis(ctx, s.jRepo, model.JenisHewan(req.Jenis))
+	if err != nil {
+		return nil, err
+	}
+	if req.JumlahPorsi > jenis.MaxPorsi {
+		return nil, fmt.Errorf("The maximum number of people for a %s is %d", jenis.Nama, jenis.MaxPorsi)
+	}
+	if req.HargaMin != nil && req.HargaMax != nil && *req.HargaMin > *req.HargaMax {
+		return nil, errors.New("harga_min must not be greater than harga_max")
+	}
+
+	p := &model.PermintaanPatungan{
+		ID:          uuid.New(),
+		PekurbanID:  pekurbanID,
+		Jenis:       model.JenisHewan(req.Jenis),
+		JumlahPorsi: req.JumlahPorsi,
+		HargaMin:    req.HargaMin,
+		HargaMax:    req.HargaMax,
+		Status:      model.PermintaanMenunggu,
+		Created_At:  time.Now(),
+		Updated_At:  time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	// langsung coba pasangkan, permintaan tetap tersimpan di antrean jika belum ada hewan yang cocok
+	if _, err := s.Match(ctx); err != nil {
+		log.Printf("failed to match permintaan patungan: %v", err)
+	}
+
+	return s.GetByID(ctx, p.ID)
+}
+
+func (s *permintaanPatunganService) GetAll(ctx context.Context) ([]dto.PermintaanPatunganResponse, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.PermintaanPatunganResponse
+	for _, p := range list {
+		res = append(res, dto.ToPermintaanPatunganResponse(p))
+	}
+	return res, nil
+}
+
+func (s *permintaanPatunganService) GetByID(ctx context.Context, id uuid.UUID) (*dto.PermintaanPatunganResponse, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Permintaan patungan not found")
+	}
+
+	res := dto.ToPermintaanPatunganResponse(p)
+	return &res, nil
+}
+
+func (s *permintaanPatunganService) GetByPekurbanID(ctx context.Context, pekurbanID uuid.UUID) ([]dto.PermintaanPatunganResponse, error) {
+	list, err := s.repo.GetByPekurbanID(ctx, pekurbanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.PermintaanPatunganResponse
+	for _, p := range list {
+		res = append(res, dto.ToPermintaanPatunganResponse(p))
+	}
+	return res, nil
+}
+
+func (s *permintaanPatunganService) Cancel(ctx context.Context, id uuid.UUID) error {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errors.New("Permintaan patungan not found")
+	}
+	if p.Status != model.PermintaanMenunggu {
+		return errors.New("Only waiting permintaan patungan can be cancelled")
+	}
+
+	return s.repo.UpdateStatus(ctx, id, model.PermintaanMenunggu, model.PermintaanDibatalkan)
+}
+
+// Match memasangkan antrean permintaan ke hewan: hewan yang sudah terisi sebagian diisi lebih dulu,
+// hewan kosong baru dipakai ketika porsi yang terkumpul cukup untuk memenuhi satu hewan. Jika tidak ada
+// hewan kosong yang cocok, hewan baru didaftarkan dengan harga default jenisnya.
+func (s *permintaanPatunganService) Match(ctx context.Context) (*dto.MatchPatunganResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	waiting, err := s.repo.GetWaiting(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jenisOrder []model.JenisHewan
+	byJenis := map[model.JenisHewan][]*model.PermintaanPatungan{}
+	for _, p := range waiting {
+		if _, ok := byJenis[p.Jenis]; !ok {
+			jenisOrder = append(jenisOrder, p.Jenis)
+		}
+		byJenis[p.Jenis] = append(byJenis[p.Jenis], p)
+	}
+
+	var matched []*model.PermintaanPatungan
+	for _, jenis := range jenisOrder {
+		slots, err := s.repo.GetAvailableSlots(ctx, jenis)
+		if err != nil {
+			return nil, err
+		}
+
+		var pending []*model.PermintaanPatungan
+		for _, req := range byJenis[jenis] {
+			slot := findPartialSlot(slots, req)
+			if slot == nil {
+				pending = append(pending, req)
+				continue
+			}
+			if err := s.assign(ctx, slot, []*model.PermintaanPatungan{req}); err != nil {
+				log.Printf("failed to assign permintaan %s to hewan %s: %v", req.ID, slot.HewanID, err)
+				pending = append(pending, req)
+				continue
+			}
+			matched = append(matched, req)
+		}
+
+		for _, slot := range slots {
+			if slot.Terisi > 0 {
+				continue
+			}
+			group := collectFullGroup(slot, pending)
+			if group == nil {
+				continue
+			}
+			if err := s.assign(ctx, slot, group); err != nil {
+				log.Printf("failed to assign %d permintaan to hewan %s: %v", len(group), slot.HewanID, err)
+				continue
+			}
+			matched = append(matched, group...)
+			pending = withoutPermintaan(pending, group)
+		}
+
+		// tanpa harga default, hewan baru tetap harus didaftarkan admin lebih dulu
+		master, err := getJenis(ctx, s.jRepo, jenis)
+		if err != nil {
+			return nil, err
+		}
+		if master.HargaDefault == nil {
+			continue
+		}
+		for {
+			slot := &model.HewanSlot{Jenis: jenis, Harga: *master.HargaDefault, Kapasitas: master.MaxPorsi}
+			group := collectFullGroup(slot, pending)
+			if group == nil {
+				break
+			}
+			if err := s.assign(ctx, slot, group); err != nil {
+				log.Printf("failed to assign %d permintaan to a new %s: %v", len(group), jenis, err)
+				break
+			}
+			matched = append(matched, group...)
+			pending = withoutPermintaan(pending, group)
+		}
+	}
+
+	res := &dto.MatchPatunganResponse{
+		TotalTerpenuhi: len(matched),
+		Terpenuhi:      []dto.PermintaanPatunganResponse{},
+	}
+	for _, p := range matched {
+		res.Terpenuhi = append(res.Terpenuhi, dto.ToPermintaanPatunganResponse(p))
+	}
+	return res, nil
+}
+
+// assign menahan porsi untuk semua permintaan pada hewan slot dalam satu transaksi; slot tanpa HewanID
+// berarti hewan baru yang didaftarkan di transaksi yang sama
+func (s *permintaanPatunganService) assign(ctx context.Context, slot *model.HewanSlot, reqs []*model.PermintaanPatungan) error {
+	hewanID := slot.HewanID
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if hewanID == uuid.Nil {
+			// berat belum diketahui sampai hewan dibeli; admin melengkapinya lewat update hewan
+			now := time.Now()
+			h := &model.HewanKurban{
+				ID:                 uuid.New(),
+				Jenis:              slot.Jenis,
+				Harga:              slot.Harga,
+				TanggalPendaftaran: now,
+				Status:             model.HewanTerdaftar,
+				Created_At:         now,
+				Updated_At:         now,
+			}
+			if err := s.hRepo.Create(ctx, h); err != nil {
+				return err
+			}
+			if err := s.lifecycle.RecordInitial(ctx, h); err != nil {
+				return err
+			}
+			hewanID = h.ID
+		}
+
+		if err := s.repo.Assign(ctx, hewanID, reqs, time.Now().Add(s.holdTTL)); err != nil {
+			return err
+		}
+		return s.lifecycle.SyncKepemilikan(ctx, hewanID)
+	})
+	if err != nil {
+		return err
+	}
+	slot.HewanID = hewanID
+
+	for _, req := range reqs {
+		slot.Terisi += req.JumlahPorsi
+		slot.PekurbanIDs = append(slot.PekurbanIDs, req.PekurbanID.String())
+
+		if req.PekurbanEmail == nil || *req.PekurbanEmail == "" {
+			continue
+		}
+		if err := s.emailService.SendPatunganMatchedEmail(*req.PekurbanEmail, req.PekurbanName, string(slot.Jenis), req.JumlahPorsi, slot.HewanID.String()); err != nil {
+			log.Printf("failed to send patungan notification to %s: %v", *req.PekurbanEmail, err)
+		}
+	}
+
+	return nil
+}
+
+func findPartialSlot(slots []*model.HewanSlot, req *model.PermintaanPatungan) *model.HewanSlot {
+	for _, slot := range slots {
+		if slot.Terisi == 0 || slot.Sisa() < req.JumlahPorsi {
+			continue
+		}
+		if !priceFits(slot, req) || slotHasPekurban(slot, req.PekurbanID) {
+			continue
+		}
+		return slot
+	}
+	return nil
+}
+
+// collectFullGroup mengambil permintaan sesuai urutan antrean sampai porsinya pas satu hewan
+func collectFullGroup(slot *model.HewanSlot, pending []*model.PermintaanPatungan) []*model.PermintaanPatungan {
+	total := 0
+	seen := map[uuid.UUID]bool{}
+	var group []*model.PermintaanPatungan
+	for _, req := range pending {
+		if seen[req.PekurbanID] || total+req.JumlahPorsi > slot.Kapasitas || !priceFits(slot, req) {
+			continue
+		}
+		seen[req.PekurbanID] = true
+		group = append(group, req)
+		total += req.JumlahPorsi
+		if total == slot.Kapasitas {
+			return group
+		}
+	}
+	return nil
+}
+
+func priceFits(slot *model.HewanSlot, req *model.PermintaanPatungan) bool {
+	harga := slot.HargaPerPorsi()
+	if req.HargaMin != nil && harga < *req.HargaMin {
+		return false
+	}
+	if req.HargaMax != nil && harga > *req.HargaMax {
+		return false
+	}
+	return true
+}
+
+func slotHasPekurban(slot *model.HewanSlot, pekurbanID uuid.UUID) bool {
+	for _, id := range slot.PekurbanIDs {
+		if id == pekurbanID.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutPermintaan(list, remove []*model.PermintaanPatungan) []*model.PermintaanPatungan {
+	removed := map[uuid.UUID]bool{}
+	for _, p := range remove {
+		removed[p.ID] = true
+	}
+
+	var out []*model.PermintaanPatungan
+	for _, p := range list {
+		if !removed[p.ID] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
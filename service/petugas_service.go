@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+type PetugasService interface {
+	Create(ctx context.Context, req dto.CreatePetugasRequest) (*dto.PetugasResponse, error)
+	GetAll(ctx context.Context, peran string) ([]dto.PetugasResponse, error)
+	GetById(ctx context.Context, id uuid.UUID) (*dto.PetugasResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req dto.UpdatePetugasRequest) (*dto.PetugasResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetJadwal(ctx context.Context, id uuid.UUID, dari time.Time) (*dto.JadwalPetugasResponse, error)
+	GetJadwalByUserId(ctx context.Context, userID uuid.UUID, dari time.Time) (*dto.JadwalPetugasResponse, error)
+}
+
+type petugasService struct {
+	repo      repository.PetugasRepository
+	shiftRepo repository.ShiftRepository
+	psRepo    repository.PenyembelihanRepository
+	periode   PeriodeKurbanService
+}
+
+func NewPetugasService(repo repository.PetugasRepository, shiftRepo repository.ShiftRepository, psRepo repository.PenyembelihanRepository, periode PeriodeKurbanService) PetugasService {
+	return &petugasService{repo: repo, shiftRepo: shiftRepo, psRepo: psRepo, periode: periode}
+}
+
+func (s *petugasService) Create(ctx context.Context, req dto.CreatePetugasRequest) (*dto.PetugasResponse, error) {
+	nama := strings.TrimSpace(req.Nama)
+	if nama == "" {
+		return nil, errors.New("Nama is required")
+	}
+	userID, err := parseOptionalUUID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &model.Petugas{
+		ID:         uuid.New(),
+		Nama:       nama,
+		Phone:      req.Phone,
+		UserID:     userID,
+		Peran:      uniquePeran(req.Peran),
+		Catatan:    req.Catatan,
+		Created_At: time.Now(),
+		Updated_At: time.Now(),
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPetugasResponse(p)
+	return &res, nil
+}
+
+func (s *petugasService) GetAll(ctx context.Context, peran string) ([]dto.PetugasResponse, error) {
+	if peran != "" && !validPeran(peran) {
+		return nil, fmt.Errorf("Invalid peran %s", peran)
+	}
+
+	list, err := s.repo.GetAll(ctx, peran)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.PetugasResponse{}
+	for _, p := range list {
+		res = append(res, dto.ToPetugasResponse(p))
+	}
+	return res, nil
+}
+
+func (s *petugasService) GetById(ctx context.Context, id uuid.UUID) (*dto.PetugasResponse, error) {
+	p, err := s.getPetugas(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPetugasResponse(p)
+	return &res, nil
+}
+
+func (s *petugasService) Update(ctx context.Context, id uuid.UUID, req dto.UpdatePetugasRequest) (*dto.PetugasResponse, error) {
+	p, err := s.getPetugas(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Nama) != "" {
+		p.Nama = strings.TrimSpace(req.Nama)
+	}
+	if req.Phone != nil {
+		p.Phone = req.Phone
+	}
+	if req.UserID != nil {
+		p.UserID, err = parseOptionalUUID(req.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Peran) > 0 {
+		p.Peran = uniquePeran(req.Peran)
+
+		// peran yang masih dipakai shift mendatang tidak boleh dilepas
+		shifts, err := s.shiftRepo.GetByPetugas(ctx, p.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		for _, sh := range shifts {
+			if !p.MemilikiPeran(sh.Peran) {
+				return nil, fmt.Errorf("Petugas is still assigned to a %s shift on %s", sh.Peran, sh.Mulai.In(time.Local).Format("2006-01-02 15:04"))
+			}
+		}
+	}
+	if req.Catatan != nil {
+		p.Catatan = req.Catatan
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToPetugasResponse(p)
+	return &res, nil
+}
+
+func (s *petugasService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *petugasService) GetJadwal(ctx context.Context, id uuid.UUID, dari time.Time) (*dto.JadwalPetugasResponse, error) {
+	p, err := s.getPetugas(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.jadwal(ctx, p, dari)
+}
+
+// GetJadwalByUserId dipakai relawan untuk melihat jadwalnya sendiri lewat akun yang ditautkan
+func (s *petugasService) GetJadwalByUserId(ctx context.Context, userID uuid.UUID, dari time.Time) (*dto.JadwalPetugasResponse, error) {
+	p, err := s.repo.GetByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("You are not registered as petugas")
+	}
+	return s.jadwal(ctx, p, dari)
+}
+
+func (s *petugasService) jadwal(ctx context.Context, p *model.Petugas, dari time.Time) (*dto.JadwalPetugasResponse, error) {
+	shifts, err := s.shiftRepo.GetByPetugas(ctx, p.ID, dari)
+	if err != nil {
+		return nil, err
+	}
+	penyembelihan, err := s.psRepo.GetByJagal(ctx, p.ID, dari)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.JadwalPetugasResponse{
+		Petugas:       dto.ToPetugasResponse(p),
+		Shift:         []dto.ShiftResponse{},
+		Penyembelihan: []dto.PenyembelihanResponse{},
+	}
+	for _, sh := range shifts {
+		res.Shift = append(res.Shift, dto.ToShiftResponse(sh))
+	}
+	for _, ps := range penyembelihan {
+		item := dto.ToPenyembelihanResponse(ps, offset)
+		res.Penyembelihan = append(res.Penyembelihan, item)
+	}
+	return res, nil
+}
+
+func (s *petugasService) getPetugas(ctx context.Context, id uuid.UUID) (*model.Petugas, error) {
+	p, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Petugas not found")
+	}
+	return p, nil
+}
+
+func validPeran(peran string) bool {
+	switch peran {
+	case model.PeranJagal, model.PeranPencacah, model.PeranPengemas, model.PeranKurir:
+		return true
+	}
+	return false
+}
+
+func uniquePeran(list []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, p := range list {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// parseOptionalUUID mengembalikan nil untuk nilai kosong
+func parseOptionalUUID(v *string) (*uuid.UUID, error) {
+	if v == nil || *v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*v)
+	if err != nil {
+		return nil, errors.New("Invalid user ID")
+	}
+	return &id, nil
+}
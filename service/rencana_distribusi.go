@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// bobot bawaan per kategori penerima; dhuafa didahulukan
+var defaultBobotPenerima = map[string]int{
+	"dhuafa":   3,
+	"warga":    1,
+	"panitia":  1,
+	"pekurban": 1, // penerima berstatus pekurban yang tidak ditautkan ke data pekurban
+}
+
+// urutan kategori di ringkasan rencana
+var kategoriPenerima = []string{"dhuafa", "warga", "panitia", "pekurban"}
+
+const (
+	dasarPekurban = "pekurban"
+	dasarBobot    = "bobot"
+)
+
+// bagian maksimal pekurban dari porsi hewannya
+var bagianPekurbanMaks = big.NewRat(1, 3)
+
+type stokHewan struct {
+	id         uuid.UUID
+	dihasilkan int
+	sisa       int
+}
+
+type porsiPekurban struct {
+	pekurbanID uuid.UUID
+	porsi      model.Pecahan
+}
+
+// tanpaBatas menandai penerima tanpa alokasi tahunan, sehingga jumlah paketnya tidak dibatasi
+const tanpaBatas = -1
+
+type alokasiPenerima struct {
+	penerima *model.PenerimaDaging
+	dasar    string
+	bobot    int
+	batas    int // sisa alokasi tahun ini (alokasi - paket diterima), atau tanpaBatas
+	diterima int // paket yang sudah diterima pada gelombang sebelumnya di tahun yang sama
+	paket    int
+	hewan    []model.DistribusiHewan
+}
+
+// ruang mengembalikan jumlah paket yang masih boleh ditambahkan ke penerima pada rencana ini
+func (a *alokasiPenerima) ruang(n int) int {
+	if a.batas == tanpaBatas {
+		return n
+	}
+	return min(n, a.batas-a.paket)
+}
+
+func (a *alokasiPenerima) ambil(h *stokHewan, n int) {
+	h.sisa -= n
+	a.paket += n
+	for i := range a.hewan {
+		if a.hewan[i].HewanID == h.id {
+			a.hewan[i].JumlahPaket += n
+			return
+		}
+	}
+	a.hewan = append(a.hewan, model.DistribusiHewan{HewanID: h.id, JumlahPaket: n})
+}
+
+// perencanaDistribusi membagi paket dalam dua tahap: bagian pekurban dari porsi masing-masing hewan,
+// lalu sisa paket ke penerima lain dengan metode sisa terbesar (largest remainder) sesuai bobot kategori.
+// Asal hewan diambil berurutan dari hewan yang paling awal disembelih. Pada gelombang berikutnya, paket
+// yang sudah diterima mengurangi hak pekurban dan sisa alokasi setiap penerima.
+type perencanaDistribusi struct {
+	stok     []*stokHewan
+	porsi    map[uuid.UUID][]porsiPekurban // per hewan
+	bagian   *big.Rat
+	total    int
+	pekurban map[uuid.UUID]*alokasiPenerima // penerima yang ditautkan ke pekurban, per pekurban_id
+	umum     []*alokasiPenerima
+}
+
+// bagianPekurban menghitung hak paket pekurban pada satu hewan: paket dihasilkan x porsi x bagian, dibulatkan ke bawah
+func bagianPekurban(dihasilkan int, porsi model.Pecahan, bagian *big.Rat) int {
+	r := new(big.Rat).Mul(big.NewRat(int64(dihasilkan), 1), porsi.Rat())
+	r.Mul(r, bagian)
+	return int(new(big.Int).Quo(r.Num(), r.Denom()).Int64())
+}
+
+func (p *perencanaDistribusi) jalankan() {
+	tersisa := p.total
+
+	// hak pekurban dihitung dari semua hewan yang hasilnya tercatat, termasuk yang paketnya sudah habis,
+	// lalu dikurangi paket yang sudah diterima pada gelombang sebelumnya
+	hak := map[uuid.UUID]int{}
+	for _, h := range p.stok {
+		for _, ps := range p.porsi[h.id] {
+			if _, ok := p.pekurban[ps.pekurbanID]; ok {
+				hak[ps.pekurbanID] += bagianPekurban(h.dihasilkan, ps.porsi, p.bagian)
+			}
+		}
+	}
+	for id, a := range p.pekurban {
+		hak[id] = max(hak[id]-a.diterima, 0)
+	}
+
+	for _, h := range p.stok {
+		for _, ps := range p.porsi[h.id] {
+			a, ok := p.pekurban[ps.pekurbanID]
+			if !ok {
+				continue
+			}
+			n := a.ruang(min(bagianPekurban(h.dihasilkan, ps.porsi, p.bagian), hak[ps.pekurbanID], h.sisa, tersisa))
+			if n > 0 {
+				a.ambil(h, n)
+				hak[ps.pekurbanID] -= n
+				tersisa -= n
+			}
+		}
+	}
+
+	kuota := bagiBobot(p.umum, tersisa)
+
+	// penerima berbobot tertinggi mendapat daging dari hewan yang paling awal disembelih
+	urut := make([]int, 0, len(p.umum))
+	for i := range p.umum {
+		urut = append(urut, i)
+	}
+	sort.SliceStable(urut, func(x, y int) bool { return p.umum[urut[x]].bobot > p.umum[urut[y]].bobot })
+
+	h := 0
+	for _, i := range urut {
+		for kuota[i] > 0 && h < len(p.stok) {
+			if p.stok[h].sisa == 0 {
+				h++
+				continue
+			}
+			n := min(kuota[i], p.stok[h].sisa)
+			p.umum[i].ambil(p.stok[h], n)
+			kuota[i] -= n
+		}
+	}
+}
+
+// bagiBobot membagi paket sebanding bobot: kuota dibulatkan ke bawah dan sisa pembulatan diberikan ke pecahan
+// terbesar. Kuota yang melebihi sisa alokasi penerima dipotong, dan kelebihannya dibagi ulang ke penerima lain.
+func bagiBobot(umum []*alokasiPenerima, tersisa int) []int {
+	kuota := make([]int, len(umum))
+	for tersisa > 0 {
+		var aktif []int
+		totalBobot := 0
+		for i, a := range umum {
+			if a.ruang(kuota[i]+tersisa) > kuota[i] {
+				aktif = append(aktif, i)
+				totalBobot += a.bobot
+			}
+		}
+		if totalBobot == 0 {
+			break
+		}
+
+		tambah := make([]int, len(umum))
+		pecahan := make([]int, len(umum))
+		terbagi := 0
+		for _, i := range aktif {
+			q := tersisa * umum[i].bobot
+			tambah[i] = q / totalBobot
+			pecahan[i] = q % totalBobot
+			terbagi += tambah[i]
+		}
+		sort.SliceStable(aktif, func(x, y int) bool {
+			i, j := aktif[x], aktif[y]
+			if pecahan[i] != pecahan[j] {
+				return pecahan[i] > pecahan[j]
+			}
+			return umum[i].bobot > umum[j].bobot
+		})
+		for k := 0; k < tersisa-terbagi; k++ {
+			tambah[aktif[k]]++
+		}
+
+		dibagi := 0
+		for _, i := range aktif {
+			n := min(tambah[i], umum[i].ruang(kuota[i]+tambah[i])-kuota[i])
+			kuota[i] += n
+			dibagi += n
+		}
+		if dibagi == 0 {
+			break
+		}
+		tersisa -= dibagi
+	}
+	return kuota
+}
+
+// parseBagianPekurban membaca bagian pekurban; kosong berarti 1/3, dan tidak boleh lebih dari 1/3
+func parseBagianPekurban(pembilang, penyebut int64) (*big.Rat, error) {
+	if penyebut == 0 {
+		if pembilang != 0 {
+			return nil, errors.New("bagian_pekurban_penyebut is required")
+		}
+		return new(big.Rat).Set(bagianPekurbanMaks), nil
+	}
+	r := big.NewRat(pembilang, penyebut)
+	if r.Cmp(bagianPekurbanMaks) > 0 {
+		return nil, errors.New("Bagian pekurban must not exceed 1/3")
+	}
+	return r, nil
+}
+
+func (s *distribusiDagingService) Rencanakan(ctx context.Context, req dto.RencanaDistribusiRequest) (*dto.RencanaDistribusiResponse, error) {
+	bagian, err := parseBagianPekurban(req.BagianPekurbanPembilang, req.BagianPekurbanPenyebut)
+	if err != nil {
+		return nil, err
+	}
+	bobot := map[string]int{}
+	for k, v := range defaultBobotPenerima {
+		bobot[k] = v
+	}
+	for k, v := range req.Bobot {
+		bobot[k] = v
+	}
+	if req.Simpan && req.TanggalDistribusi == "" {
+		return nil, errors.New("tanggal_distribusi is required to save the plan")
+	}
+
+	res := &dto.RencanaDistribusiResponse{
+		BagianPekurban:  bagian.RatString(),
+		Alokasi:         []dto.AlokasiPenerimaResponse{},
+		Dilewati:        []dto.AlokasiDilewatiResponse{},
+		HewanTanpaHasil: []string{},
+	}
+
+	// stok: sisa paket hewan yang sudah disembelih dan jumlah paketnya sudah dicatat
+	rekap, err := s.repo.GetRekapHewan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := &perencanaDistribusi{
+		porsi:    map[uuid.UUID][]porsiPekurban{},
+		bagian:   bagian,
+		pekurban: map[uuid.UUID]*alokasiPenerima{},
+	}
+	for _, r := range rekap {
+		if !hewanSiapDistribusi[r.Status] {
+			continue
+		}
+		if r.PaketDihasilkan == nil {
+			res.HewanTanpaHasil = append(res.HewanTanpaHasil, r.HewanID.String())
+			continue
+		}
+		// hewan yang paketnya sudah habis tetap dimuat agar hak pekurban dari hewan tersebut ikut terhitung
+		sisa := max(*r.PaketDihasilkan-r.PaketDidistribusikan, 0)
+		p.stok = append(p.stok, &stokHewan{id: r.HewanID, dihasilkan: *r.PaketDihasilkan, sisa: sisa})
+		res.StokPaket += sisa
+
+		list, err := s.phRepo.GetByHewanId(ctx, r.HewanID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ph := range list {
+			if ph.Status != model.PorsiTerkonfirmasi {
+				continue
+			}
+			pid, err := uuid.Parse(ph.PekurbanID)
+			if err != nil {
+				continue
+			}
+			p.porsi[r.HewanID] = append(p.porsi[r.HewanID], porsiPekurban{pekurbanID: pid, porsi: ph.Porsi})
+		}
+	}
+
+	p.total = res.StokPaket
+	if req.TotalPaket > 0 {
+		if req.TotalPaket > res.StokPaket {
+			return nil, fmt.Errorf("total_paket (%d) exceeds available paket (%d)", req.TotalPaket, res.StokPaket)
+		}
+		p.total = req.TotalPaket
+	}
+	res.TotalPaket = p.total
+
+	offset, err := s.periode.OffsetHijri(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tahun := model.TahunDistribusi(time.Now(), offset)
+	if t, err := time.Parse("2006-01-02", req.TanggalDistribusi); err == nil {
+		tahun = model.TahunDistribusi(t, offset)
+	}
+	penerima, err := s.penerimaRencana(ctx, req.PenerimaIDs, tahun, offset, res)
+	if err != nil {
+		return nil, err
+	}
+	var semua []*alokasiPenerima
+	for _, rp := range penerima {
+		pn := &rp.Penerima
+		a := &alokasiPenerima{penerima: pn, batas: tanpaBatas, diterima: rp.PaketDiterima}
+		if rp.AlokasiPaket != nil {
+			a.batas = *rp.AlokasiPaket - rp.PaketDiterima
+		}
+		if pn.PekurbanID != nil {
+			a.dasar = dasarPekurban
+			p.pekurban[*pn.PekurbanID] = a
+		} else {
+			a.dasar = dasarBobot
+			a.bobot = bobot[pn.Status]
+			if a.bobot == 0 {
+				res.Dilewati = append(res.Dilewati, dilewati(pn, "Bobot kategori 0"))
+				continue
+			}
+			p.umum = append(p.umum, a)
+		}
+		semua = append(semua, a)
+	}
+
+	p.jalankan()
+
+	kategori := map[string]*dto.AlokasiKategoriResponse{}
+	for _, k := range kategoriPenerima {
+		kategori[k] = &dto.AlokasiKategoriResponse{Status: k, Bobot: bobot[k]}
+	}
+	var items []dto.BulkDistribusiItem
+	for _, a := range semua {
+		if a.paket == 0 {
+			alasan := "Paket tidak mencukupi"
+			if a.dasar == dasarPekurban {
+				alasan = "Tidak ada bagian pekurban dari hewan yang hasilnya sudah dicatat"
+			}
+			res.Dilewati = append(res.Dilewati, dilewati(a.penerima, alasan))
+			continue
+		}
+
+		item := dto.AlokasiPenerimaResponse{
+			PenerimaID:  a.penerima.ID.String(),
+			Name:        a.penerima.Name,
+			Status:      a.penerima.Status,
+			Dasar:       a.dasar,
+			Bobot:       a.bobot,
+			JumlahPaket: a.paket,
+		}
+		for _, h := range a.hewan {
+			item.Hewan = append(item.Hewan, dto.DistribusiHewanRequest{HewanID: h.HewanID.String(), JumlahPaket: h.JumlahPaket})
+		}
+		res.Alokasi = append(res.Alokasi, item)
+		items = append(items, dto.BulkDistribusiItem{PenerimaID: item.PenerimaID, JumlahPaket: item.JumlahPaket, Hewan: item.Hewan})
+
+		if a.dasar == dasarPekurban {
+			res.PaketPekurban += a.paket
+		} else {
+			res.PaketUmum += a.paket
+		}
+		if k, ok := kategori[a.penerima.Status]; ok {
+			k.Penerima++
+			k.Paket += a.paket
+		}
+	}
+	res.SisaPaket = res.TotalPaket - res.PaketPekurban - res.PaketUmum
+	for _, k := range kategoriPenerima {
+		res.Kategori = append(res.Kategori, *kategori[k])
+	}
+
+	if req.Simpan && len(items) > 0 {
+		disimpan, err := s.CreateBulk(ctx, dto.BulkDistribusiRequest{
+			TanggalDistribusi: req.TanggalDistribusi,
+			LokasiID:          req.LokasiID,
+			Distribusi:        items,
+		})
+		if err != nil {
+			return nil, err
+		}
+		res.Disimpan = true
+		res.Distribusi = disimpan
+	}
+
+	return res, nil
+}
+
+// penerimaRencana mengambil rekap penerima yang diminta (atau semua penerima) yang belum terdistribusi pada tahun
+// tersebut; penerima yang alokasinya sudah terpenuhi dicatat sebagai dilewati
+func (s *distribusiDagingService) penerimaRencana(ctx context.Context, ids []string, tahun, offset int, res *dto.RencanaDistribusiResponse) ([]*model.RekapDistribusiPenerima, error) {
+	rekap, err := s.repo.GetRekapPenerima(ctx, tahun, offset)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[uuid.UUID]*model.RekapDistribusiPenerima{}
+	for _, r := range rekap {
+		byID[r.Penerima.ID] = r
+	}
+
+	var list []*model.PenerimaDaging
+	if len(ids) == 0 {
+		all, err := s.penerimaRepo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		list = all
+	} else {
+		seen := map[uuid.UUID]bool{}
+		for _, raw := range ids {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, errors.New("Invalid Penerima ID")
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			pn, err := s.penerimaRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if pn == nil {
+				return nil, fmt.Errorf("Penerima %s not found", id)
+			}
+			list = append(list, pn)
+		}
+	}
+
+	var result []*model.RekapDistribusiPenerima
+	for _, pn := range list {
+		r, ok := byID[pn.ID]
+		if !ok {
+			r = &model.RekapDistribusiPenerima{Penerima: *pn, TahunHijri: tahun}
+		}
+		if !r.BelumTerdistribusi() {
+			if len(ids) > 0 {
+				res.Dilewati = append(res.Dilewati, dilewati(pn, "Sudah menerima distribusi"))
+			}
+			continue
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func dilewati(p *model.PenerimaDaging, alasan string) dto.AlokasiDilewatiResponse {
+	return dto.AlokasiDilewatiResponse{PenerimaID: p.ID.String(), Name: p.Name, Status: p.Status, Alasan: alasan}
+}
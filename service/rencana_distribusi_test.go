@@ -0,0 +1,93 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+)
+
+// TestRencanaDistribusiDuaGelombang memastikan gelombang kedua memperhitungkan paket yang sudah diterima:
+// hak 1/3 pekurban berkurang dan penerima beralokasi hanya mendapat sisa alokasinya.
+func TestRencanaDistribusiDuaGelombang(t *testing.T) {
+	hewanID := uuid.New()
+	pekurbanID := uuid.New()
+	porsi := map[uuid.UUID][]porsiPekurban{hewanID: {{pekurbanID: pekurbanID, porsi: model.PecahanUtuh}}}
+
+	pekurban := &model.PenerimaDaging{ID: uuid.New(), Name: "Pekurban", Status: "pekurban", PekurbanID: &pekurbanID}
+	dhuafa := &model.PenerimaDaging{ID: uuid.New(), Name: "Dhuafa", Status: "dhuafa"}
+	warga := &model.PenerimaDaging{ID: uuid.New(), Name: "Warga", Status: "warga"}
+	wargaBaru := &model.PenerimaDaging{ID: uuid.New(), Name: "Warga Baru", Status: "warga"}
+
+	// gelombang pertama: 12 dari 21 paket, dhuafa dialokasikan 6 paket setahun
+	a1 := &alokasiPenerima{penerima: pekurban, dasar: dasarPekurban, batas: tanpaBatas}
+	d1 := &alokasiPenerima{penerima: dhuafa, dasar: dasarBobot, bobot: 3, batas: 6}
+	w1 := &alokasiPenerima{penerima: warga, dasar: dasarBobot, bobot: 1, batas: tanpaBatas}
+	p1 := &perencanaDistribusi{
+		stok:     []*stokHewan{{id: hewanID, dihasilkan: 21, sisa: 21}},
+		porsi:    porsi,
+		bagian:   big.NewRat(1, 3),
+		total:    12,
+		pekurban: map[uuid.UUID]*alokasiPenerima{pekurbanID: a1},
+		umum:     []*alokasiPenerima{d1, w1},
+	}
+	p1.jalankan()
+	cekPaket(t, "gelombang 1", map[string][2]int{"pekurban": {a1.paket, 7}, "dhuafa": {d1.paket, 4}, "warga": {w1.paket, 1}})
+
+	// gelombang kedua: sisa 9 paket; pekurban sudah menerima seluruh haknya, dhuafa tinggal 2 dari alokasinya,
+	// dan warga yang sudah menerima tanpa alokasi tidak lagi ikut direncanakan
+	a2 := &alokasiPenerima{penerima: pekurban, dasar: dasarPekurban, batas: tanpaBatas, diterima: a1.paket}
+	d2 := &alokasiPenerima{penerima: dhuafa, dasar: dasarBobot, bobot: 3, batas: 6 - d1.paket, diterima: d1.paket}
+	w2 := &alokasiPenerima{penerima: wargaBaru, dasar: dasarBobot, bobot: 1, batas: tanpaBatas}
+	p2 := &perencanaDistribusi{
+		stok:     []*stokHewan{{id: hewanID, dihasilkan: 21, sisa: 21 - p1.total}},
+		porsi:    porsi,
+		bagian:   big.NewRat(1, 3),
+		total:    21 - p1.total,
+		pekurban: map[uuid.UUID]*alokasiPenerima{pekurbanID: a2},
+		umum:     []*alokasiPenerima{d2, w2},
+	}
+	p2.jalankan()
+	cekPaket(t, "gelombang 2", map[string][2]int{"pekurban": {a2.paket, 0}, "dhuafa": {d2.paket, 2}, "warga baru": {w2.paket, 7}})
+
+	if sisa := p2.stok[0].sisa; sisa != 0 {
+		t.Errorf("gelombang 2: sisa stok = %d, want 0", sisa)
+	}
+	if total := d1.paket + d2.paket; total > 6 {
+		t.Errorf("dhuafa menerima %d paket, melebihi alokasi 6", total)
+	}
+	if total := a1.paket + a2.paket; total > 7 {
+		t.Errorf("pekurban menerima %d paket, melebihi hak 1/3 (7)", total)
+	}
+}
+
+func TestBagiBobotBatasAlokasi(t *testing.T) {
+	umum := []*alokasiPenerima{
+		{bobot: 3, batas: 1},
+		{bobot: 3, batas: 2},
+		{bobot: 1, batas: tanpaBatas},
+	}
+	kuota := bagiBobot(umum, 10)
+	want := []int{1, 2, 7}
+	for i := range want {
+		if kuota[i] != want[i] {
+			t.Errorf("kuota[%d] = %d, want %d", i, kuota[i], want[i])
+		}
+	}
+
+	// semua penerima terbatas: sisa paket yang tidak tertampung dibiarkan
+	kuota = bagiBobot([]*alokasiPenerima{{bobot: 1, batas: 2}, {bobot: 1, batas: 3}}, 10)
+	if kuota[0] != 2 || kuota[1] != 3 {
+		t.Errorf("kuota = %v, want [2 3]", kuota)
+	}
+}
+
+func cekPaket(t *testing.T, gelombang string, got map[string][2]int) {
+	t.Helper()
+	for nama, v := range got {
+		if v[0] != v[1] {
+			t.Errorf("%s: %s mendapat %d paket, want %d", gelombang, nama, v[0], v[1])
+		}
+	}
+}
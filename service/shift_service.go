@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+type ShiftService interface {
+	Create(ctx context.Context, req dto.CreateShiftRequest) (*dto.ShiftResponse, error)
+	GetAll(ctx context.Context, tanggal *time.Time, lokasiID *uuid.UUID) ([]dto.ShiftResponse, error)
+	GetById(ctx context.Context, id uuid.UUID) (*dto.ShiftResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req dto.UpdateShiftRequest) (*dto.ShiftResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Assign(ctx context.Context, id uuid.UUID, req dto.AssignShiftRequest) (*dto.ShiftResponse, error)
+	Unassign(ctx context.Context, id, petugasID uuid.UUID) (*dto.ShiftResponse, error)
+}
+
+type shiftService struct {
+	repo        repository.ShiftRepository
+	petugasRepo repository.PetugasRepository
+	lRepo       repository.LokasiRepository
+	tx          repository.TxManager
+}
+
+func NewShiftService(repo repository.ShiftRepository, petugasRepo repository.PetugasRepository, lRepo repository.LokasiRepository, tx repository.TxManager) ShiftService {
+	return &shiftService{repo: repo, petugasRepo: petugasRepo, lRepo: lRepo, tx: tx}
+}
+
+func (s *shiftService) Create(ctx context.Context, req dto.CreateShiftRequest) (*dto.ShiftResponse, error) {
+	lokasi, err := s.getLokasi(ctx, req.LokasiID)
+	if err != nil {
+		return nil, err
+	}
+	mulai, selesai, err := waktuShift(req.Tanggal, req.JamMulai, req.JamSelesai)
+	if err != nil {
+		return nil, err
+	}
+
+	kebutuhan := req.Kebutuhan
+	if kebutuhan == 0 {
+		kebutuhan = 1
+	}
+
+	sh := &model.Shift{
+		ID:         uuid.New(),
+		LokasiID:   lokasi.ID,
+		Lokasi:     lokasi.Nama,
+		Peran:      req.Peran,
+		Mulai:      mulai,
+		Selesai:    selesai,
+		Kebutuhan:  kebutuhan,
+		Catatan:    req.Catatan,
+		Created_At: time.Now(),
+		Updated_At: time.Now(),
+		Petugas:    []model.PenugasanShift{},
+	}
+	if err := s.repo.Create(ctx, sh); err != nil {
+		return nil, err
+	}
+
+	res := dto.ToShiftResponse(sh)
+	return &res, nil
+}
+
+func (s *shiftService) GetAll(ctx context.Context, tanggal *time.Time, lokasiID *uuid.UUID) ([]dto.ShiftResponse, error) {
+	list, err := s.repo.GetAll(ctx, tanggal, lokasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.ShiftResponse{}
+	for _, sh := range list {
+		res = append(res, dto.ToShiftResponse(sh))
+	}
+	return res, nil
+}
+
+func (s *shiftService) GetById(ctx context.Context, id uuid.UUID) (*dto.ShiftResponse, error) {
+	sh, err := s.getShift(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToShiftResponse(sh)
+	return &res, nil
+}
+
+// Update mengubah shift; jika waktu atau peran berubah, semua petugas yang sudah ditugaskan dicek ulang
+// supaya tidak ada yang jadi bentrok atau tidak memenuhi peran
+func (s *shiftService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateShiftRequest) (*dto.ShiftResponse, error) {
+	var res dto.ShiftResponse
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Lock(ctx, id); err != nil {
+			return err
+		}
+		sh, err := s.getShift(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if req.LokasiID != "" {
+			lokasi, err := s.getLokasi(ctx, req.LokasiID)
+			if err != nil {
+				return err
+			}
+			sh.LokasiID = lokasi.ID
+			sh.Lokasi = lokasi.Nama
+		}
+		if req.Tanggal != "" || req.JamMulai != "" || req.JamSelesai != "" {
+			mulai, selesai := sh.Mulai.In(time.Local), sh.Selesai.In(time.Local)
+			tanggal, jamMulai, jamSelesai := mulai.Format("2006-01-02"), mulai.Format("15:04"), selesai.Format("15:04")
+			if req.Tanggal != "" {
+				tanggal = req.Tanggal
+			}
+			if req.JamMulai != "" {
+				jamMulai = req.JamMulai
+			}
+			if req.JamSelesai != "" {
+				jamSelesai = req.JamSelesai
+			}
+			sh.Mulai, sh.Selesai, err = waktuShift(tanggal, jamMulai, jamSelesai)
+			if err != nil {
+				return err
+			}
+		}
+		if req.Peran != "" {
+			sh.Peran = req.Peran
+		}
+		if req.Kebutuhan > 0 {
+			sh.Kebutuhan = req.Kebutuhan
+		}
+		if req.Catatan != nil {
+			sh.Catatan = req.Catatan
+		}
+		if len(sh.Petugas) > sh.Kebutuhan {
+			return fmt.Errorf("Shift already has %d petugas assigned, kebutuhan cannot be lower", len(sh.Petugas))
+		}
+
+		for _, a := range sh.Petugas {
+			p, err := s.lockPetugas(ctx, a.PetugasID)
+			if err != nil {
+				return err
+			}
+			if err := s.cekPenugasan(ctx, sh, p); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Update(ctx, sh); err != nil {
+			return err
+		}
+		res = dto.ToShiftResponse(sh)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *shiftService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Assign menugaskan petugas ke shift. Baris shift lalu baris petugas dikunci selama pengecekan (urutan yang
+// sama dengan Update) supaya penugasan bersamaan tidak melewati kebutuhan shift atau sama-sama lolos cek bentrok.
+func (s *shiftService) Assign(ctx context.Context, id uuid.UUID, req dto.AssignShiftRequest) (*dto.ShiftResponse, error) {
+	petugasID, err := uuid.Parse(req.PetugasID)
+	if err != nil {
+		return nil, errors.New("Invalid petugas ID")
+	}
+
+	var res dto.ShiftResponse
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Lock(ctx, id); err != nil {
+			return err
+		}
+		p, err := s.lockPetugas(ctx, petugasID)
+		if err != nil {
+			return err
+		}
+		sh, err := s.getShift(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(sh.Petugas) >= sh.Kebutuhan {
+			return errors.New("Shift is already full")
+		}
+		if err := s.cekPenugasan(ctx, sh, p); err != nil {
+			return err
+		}
+		if err := s.repo.Assign(ctx, sh.ID, p.ID); err != nil {
+			return err
+		}
+
+		sh, err = s.getShift(ctx, id)
+		if err != nil {
+			return err
+		}
+		res = dto.ToShiftResponse(sh)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *shiftService) Unassign(ctx context.Context, id, petugasID uuid.UUID) (*dto.ShiftResponse, error) {
+	if err := s.repo.Unassign(ctx, id, petugasID); err != nil {
+		return nil, err
+	}
+	return s.GetById(ctx, id)
+}
+
+// cekPenugasan memastikan petugas memiliki peran shift dan tidak punya shift lain yang waktunya beririsan
+func (s *shiftService) cekPenugasan(ctx context.Context, sh *model.Shift, p *model.Petugas) error {
+	if !p.MemilikiPeran(sh.Peran) {
+		return fmt.Errorf("Petugas %s does not have peran %s", p.Nama, sh.Peran)
+	}
+	bentrok, err := s.repo.GetBentrok(ctx, p.ID, sh.Mulai, sh.Selesai, sh.ID)
+	if err != nil {
+		return err
+	}
+	if len(bentrok) > 0 {
+		b := bentrok[0]
+		return fmt.Errorf("Petugas %s already has an overlapping %s shift at %s (%s - %s)",
+			p.Nama, b.Peran, b.Lokasi, b.Mulai.Format("2006-01-02 15:04"), b.Selesai.Format("15:04"))
+	}
+	return nil
+}
+
+func (s *shiftService) lockPetugas(ctx context.Context, id uuid.UUID) (*model.Petugas, error) {
+	if err := s.petugasRepo.Lock(ctx, id); err != nil {
+		return nil, err
+	}
+	p, err := s.petugasRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("Petugas not found")
+	}
+	return p, nil
+}
+
+func (s *shiftService) getShift(ctx context.Context, id uuid.UUID) (*model.Shift, error) {
+	sh, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sh == nil {
+		return nil, errors.New("Shift not found")
+	}
+	return sh, nil
+}
+
+func (s *shiftService) getLokasi(ctx context.Context, id string) (*model.Lokasi, error) {
+	lokasiID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("Invalid lokasi ID")
+	}
+	l, err := s.lRepo.GetById(ctx, lokasiID)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("Lokasi not found")
+	}
+	return l, nil
+}
+
+// waktuShift mengubah tanggal dan jam menjadi rentang waktu; jam selesai yang lebih awal dari jam mulai
+// berarti shift berakhir keesokan harinya
+func waktuShift(tanggal, jamMulai, jamSelesai string) (time.Time, time.Time, error) {
+	d, err := time.ParseInLocation("2006-01-02", tanggal, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid tanggal, use YYYY-MM-DD")
+	}
+	mulai, err := parseJam(d, jamMulai)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	selesai, err := parseJam(d, jamSelesai)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if selesai.Equal(mulai) {
+		return time.Time{}, time.Time{}, errors.New("jam_selesai must be different from jam_mulai")
+	}
+	if selesai.Before(mulai) {
+		selesai = selesai.AddDate(0, 0, 1)
+	}
+	return mulai, selesai, nil
+}
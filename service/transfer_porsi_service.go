@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/dto"
+	"github.com/wahyujatirestu/sahabat-kurban/model"
+	"github.com/wahyujatirestu/sahabat-kurban/repository"
+)
+
+type TransferPorsiService interface {
+	Transfer(ctx context.Context, fromPekurbanID, hewanID, approvedBy uuid.UUID, req dto.TransferPorsiRequest) (*dto.TransferPorsiResponse, error)
+	GetAll(ctx context.Context) ([]dto.TransferPorsiResponse, error)
+	GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]dto.TransferPorsiResponse, error)
+}
+
+type transferPorsiService struct {
+	repo    repository.TransferPorsiRepository
+	phRepo  repository.PekurbanHewanRepository
+	pRepo   repository.PekurbanRepository
+	hRepo   repository.HewanKurbanRepository
+	sRepo   repository.PenyembelihanRepository
+	payRepo repository.PembayaranKurbanRepository
+	bRepo   repository.PembatalanPatunganRepository
+	tx      repository.TxManager
+}
+
+func NewTransferPorsiService(repo repository.TransferPorsiRepository, phRepo repository.PekurbanHewanRepository, pRepo repository.PekurbanRepository, hRepo repository.HewanKurbanRepository, sRepo repository.PenyembelihanRepository, payRepo repository.PembayaranKurbanRepository, bRepo repository.PembatalanPatunganRepository, tx repository.TxManager) TransferPorsiService {
+	return &transferPorsiService{repo: repo, phRepo: phRepo, pRepo: pRepo, hRepo: hRepo, sRepo: sRepo, payRepo: payRepo, bRepo: bRepo, tx: tx}
+}
+
+// Transfer memindahkan satu porsi beserta pembayaran yang menjadi bagiannya dan atas namanya ke pekurban lain
+// dalam satu transaksi
+func (s *transferPorsiService) Transfer(ctx context.Context, fromPekurbanID, hewanID, approvedBy uuid.UUID, req dto.TransferPorsiRequest) (*dto.TransferPorsiResponse, error) {
+	toPekurbanID, err := uuid.Parse(req.KePekurbanID)
+	if err != nil {
+		return nil, errors.New("Invalid pekurban ID")
+	}
+	if toPekurbanID == fromPekurbanID {
+		return nil, errors.New("Share cannot be transferred to the same pekurban")
+	}
+
+	target, err := s.pRepo.FindById(ctx, toPekurbanID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("Target pekurban not found")
+	}
+
+	var data *model.TransferPorsi
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		hewan, err := s.hRepo.LockById(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+		if hewan == nil {
+			return errors.New("Hewan kurban not found")
+		}
+
+		slaughtered, err := isHewanSlaughtered(ctx, s.sRepo, hewanID)
+		if err != nil {
+			return err
+		}
+		if slaughtered {
+			return errors.New("Share cannot be transferred after the hewan is slaughtered")
+		}
+
+		shares, err := s.phRepo.GetByHewanId(ctx, hewanID)
+		if err != nil {
+			return err
+		}
+		var share *model.PekurbanHewanJoin
+		for _, ph := range shares {
+			switch ph.PekurbanID {
+			case fromPekurbanID.String():
+				share = ph
+			case toPekurbanID.String():
+				return errors.New("Target pekurban already has a share in this hewan")
+			}
+		}
+		if share == nil {
+			return errors.New("Patungan not found")
+		}
+
+		// hanya settlement yang menjadi bagian porsi ini yang ikut berpindah (dipecah bila perlu);
+		// pembayaran untuk porsi lain dan yang belum settle tetap milik pengirim
+		dibayar, err := alokasiPembayaran(ctx, s.payRepo, s.bRepo, fromPekurbanID, share.Porsi, hewan)
+		if err != nil {
+			return err
+		}
+		var paid float64
+		if dibayar > 0 {
+			paid, err = s.payRepo.SplitSettlement(ctx, fromPekurbanID, toPekurbanID, dibayar)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.phRepo.Transfer(ctx, fromPekurbanID, toPekurbanID, hewanID); err != nil {
+			return err
+		}
+		// catatan porsi pada pembayaran ikut berpindah sehingga settlement berikutnya mengonfirmasi porsi penerima
+		if err := s.payRepo.PindahkanPorsi(ctx, fromPekurbanID, toPekurbanID, hewanID); err != nil {
+			return err
+		}
+
+		data = &model.TransferPorsi{
+			ID:             uuid.New(),
+			HewanID:        hewanID,
+			DariPekurbanID: fromPekurbanID,
+			KePekurbanID:   toPekurbanID,
+			Porsi:          share.Porsi,
+			JumlahDibayar:  paid,
+			DisetujuiOleh:  approvedBy,
+			Catatan:        req.Catatan,
+			Created_At:     time.Now(),
+			DariPekurban:   share.Pekurban,
+		}
+		return s.repo.Create(ctx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Name != nil {
+		data.KePekurban = *target.Name
+	}
+	res := dto.ToTransferPorsiResponse(data)
+	return &res, nil
+}
+
+func (s *transferPorsiService) GetAll(ctx context.Context) ([]dto.TransferPorsiResponse, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.TransferPorsiResponse{}
+	for _, t := range list {
+		res = append(res, dto.ToTransferPorsiResponse(t))
+	}
+	return res, nil
+}
+
+func (s *transferPorsiService) GetByHewanId(ctx context.Context, hewanID uuid.UUID) ([]dto.TransferPorsiResponse, error) {
+	list, err := s.repo.GetByHewanId(ctx, hewanID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.TransferPorsiResponse{}
+	for _, t := range list {
+		res = append(res, dto.ToTransferPorsiResponse(t))
+	}
+	return res, nil
+}
@@ -71,22 +71,41 @@ CREATE TRIGGER trigger_update_pekurban
 BEFORE UPDATE ON pekurban
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
--- Enum jenis hewan
-DO $$
-BEGIN
-    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'jenis_hewan_enum') THEN
-        CREATE TYPE jenis_hewan_enum AS ENUM ('sapi', 'kambing', 'domba');
-    END IF;
-END$$;
+-- Master jenis hewan (kapasitas porsi, umur minimal, harga default), dikelola admin
+CREATE TABLE jenis_hewan (
+    nama VARCHAR(50) PRIMARY KEY,
+    max_porsi INT NOT NULL CHECK (max_porsi >= 1),
+    min_umur_bulan INT NOT NULL DEFAULT 0 CHECK (min_umur_bulan >= 0),
+    harga_default NUMERIC(12,2) CHECK (harga_default > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE TRIGGER trigger_update_jenis_hewan
+BEFORE UPDATE ON jenis_hewan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO jenis_hewan (nama, max_porsi, min_umur_bulan) VALUES
+    ('sapi', 7, 24),
+    ('kerbau', 7, 24),
+    ('unta', 7, 60),
+    ('kambing', 1, 12),
+    ('domba', 1, 6)
+ON CONFLICT (nama) DO NOTHING;
 
 -- Tabel hewan_kurban
 CREATE TABLE hewan_kurban (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    jenis jenis_hewan_enum NOT NULL,
+    jenis VARCHAR(50) NOT NULL REFERENCES jenis_hewan(nama) ON UPDATE CASCADE,
     berat NUMERIC(5,2) NOT NULL,
+    umur_bulan INT CHECK (umur_bulan > 0),
     harga NUMERIC(12,2) NOT NULL,
     is_private BOOLEAN DEFAULT FALSE,
+    foto_url TEXT,
     tanggal_pendaftaran DATE NOT NULL,
+    -- siklus hidup: terdaftar -> terisi -> lunas -> dijadwalkan -> disembelih -> dicacah -> didistribusikan
+    status VARCHAR(20) NOT NULL DEFAULT 'terdaftar'
+        CHECK (status IN ('terdaftar', 'terisi', 'lunas', 'dijadwalkan', 'disembelih', 'dicacah', 'didistribusikan')),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     CONSTRAINT hewan_kurban_harga_check
@@ -101,24 +120,275 @@ CREATE TRIGGER trigger_update_hewan_kurban
 BEFORE UPDATE ON hewan_kurban
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE INDEX idx_hewan_kurban_status ON hewan_kurban (status);
+
+-- Riwayat perubahan status siklus hidup hewan; diubah_oleh NULL berarti perubahan otomatis oleh sistem
+CREATE TABLE riwayat_status_hewan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hewan_id UUID NOT NULL REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    dari_status VARCHAR(20),
+    ke_status VARCHAR(20) NOT NULL,
+    diubah_oleh UUID REFERENCES users(id),
+    keterangan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX idx_riwayat_status_hewan ON riwayat_status_hewan (hewan_id, created_at);
+
 -- Tabel pekurban_hewan 
 CREATE TABLE pekurban_hewan (
     pekurban_id UUID NOT NULL,
     hewan_id UUID NOT NULL,
-    porsi NUMERIC(4,3) NOT NULL CHECK (porsi > 0 AND porsi <= 1),
+    -- porsi disimpan sebagai pecahan eksak; kolom porsi hanya turunan untuk laporan
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    porsi NUMERIC GENERATED ALWAYS AS (porsi_pembilang::numeric / porsi_penyebut) STORED,
+    status VARCHAR(20) NOT NULL DEFAULT 'terkonfirmasi' CHECK (status IN ('ditahan', 'terkonfirmasi')),
+    expires_at TIMESTAMP WITH TIME ZONE, -- batas waktu porsi berstatus ditahan sebelum dilepas
+    ingin_hadir BOOLEAN NOT NULL DEFAULT FALSE, -- pekurban ingin menyaksikan penyembelihan
     PRIMARY KEY (pekurban_id, hewan_id),
     FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
-    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    CONSTRAINT pekurban_hewan_hold_check CHECK (status <> 'ditahan' OR expires_at IS NOT NULL),
+    CONSTRAINT pekurban_hewan_porsi_check CHECK (porsi_pembilang <= porsi_penyebut)
+);
+
+CREATE INDEX idx_pekurban_hewan_hold ON pekurban_hewan (expires_at) WHERE status = 'ditahan';
+
+-- Batas total porsi per hewan dijaga juga di database: baris hewan dikunci lalu porsi aktif dijumlahkan,
+-- sehingga penulisan paralel yang tidak melewati service tetap tidak bisa melebihi satu hewan.
+-- Porsi turunan berupa NUMERIC hasil pembagian, jadi selisih pembulatan kecil masih ditoleransi.
+CREATE OR REPLACE FUNCTION cek_total_porsi_hewan()
+RETURNS TRIGGER AS $$
+DECLARE
+    total NUMERIC;
+BEGIN
+    IF NEW.status = 'ditahan' AND NEW.expires_at <= now() THEN
+        RETURN NEW;
+    END IF;
+
+    PERFORM 1 FROM hewan_kurban WHERE id = NEW.hewan_id FOR UPDATE;
+
+    SELECT COALESCE(SUM(ph.porsi_pembilang::numeric / ph.porsi_penyebut), 0) INTO total
+    FROM pekurban_hewan ph
+    WHERE ph.hewan_id = NEW.hewan_id
+      AND (ph.status = 'terkonfirmasi' OR ph.expires_at > now())
+      AND ph.pekurban_id <> NEW.pekurban_id
+      AND (TG_OP = 'INSERT' OR ph.pekurban_id <> OLD.pekurban_id OR ph.hewan_id <> OLD.hewan_id);
+
+    IF total + NEW.porsi_pembilang::numeric / NEW.porsi_penyebut > 1.000000001 THEN
+        RAISE EXCEPTION 'Total portion exceeds the maximum limit'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'pekurban_hewan_total_porsi_check';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER trigger_cek_total_porsi_hewan
+BEFORE INSERT OR UPDATE OF pekurban_id, hewan_id, porsi_pembilang, porsi_penyebut, status, expires_at ON pekurban_hewan
+FOR EACH ROW EXECUTE FUNCTION cek_total_porsi_hewan();
+
+-- Tabel atas_nama_kurban (niat: kurban diatasnamakan siapa untuk setiap porsi)
+CREATE TABLE atas_nama_kurban (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    nama VARCHAR(100) NOT NULL,
+    hubungan VARCHAR(50),
+    masih_hidup BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id, hewan_id) REFERENCES pekurban_hewan(pekurban_id, hewan_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_atas_nama_kurban_share ON atas_nama_kurban (pekurban_id, hewan_id);
+
+CREATE TRIGGER trigger_update_atas_nama_kurban
+BEFORE UPDATE ON atas_nama_kurban
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Riwayat pengalihan porsi antar pekurban
+CREATE TABLE transfer_porsi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hewan_id UUID NOT NULL,
+    dari_pekurban_id UUID NOT NULL,
+    ke_pekurban_id UUID NOT NULL,
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    porsi NUMERIC GENERATED ALWAYS AS (porsi_pembilang::numeric / porsi_penyebut) STORED,
+    jumlah_dibayar NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_dibayar >= 0),
+    disetujui_oleh UUID NOT NULL,
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (dari_pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (ke_pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (disetujui_oleh) REFERENCES users(id),
+    CHECK (dari_pekurban_id <> ke_pekurban_id),
+    CHECK (porsi_pembilang <= porsi_penyebut)
+);
+
+CREATE INDEX idx_transfer_porsi_hewan ON transfer_porsi (hewan_id, created_at);
+
+-- Pengajuan pembatalan patungan oleh pekurban; refund mengikuti kebijakan saat pengajuan
+CREATE TABLE pembatalan_patungan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    alasan TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'disetujui', 'ditolak')),
+    kebijakan VARCHAR(20) NOT NULL CHECK (kebijakan IN ('penuh', 'sebagian', 'tidak_ada')),
+    persentase_refund INT NOT NULL CHECK (persentase_refund BETWEEN 0 AND 100),
+    jumlah_dibayar NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_dibayar >= 0), -- pembayaran settlement yang dialokasikan ke porsi ini
+    jumlah_refund NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_refund >= 0),
+    diproses_oleh UUID,
+    catatan_admin TEXT,
+    diproses_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (diproses_oleh) REFERENCES users(id)
+);
+
+-- satu porsi hanya boleh punya satu pengajuan yang masih menunggu
+CREATE UNIQUE INDEX idx_pembatalan_patungan_menunggu ON pembatalan_patungan (pekurban_id, hewan_id) WHERE status = 'menunggu';
+
+CREATE TRIGGER trigger_update_pembatalan_patungan
+BEFORE UPDATE ON pembatalan_patungan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel antrean permintaan patungan (porsi yang belum mendapat hewan)
+CREATE TABLE permintaan_patungan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    jenis VARCHAR(50) NOT NULL REFERENCES jenis_hewan(nama) ON UPDATE CASCADE,
+    jumlah_porsi INT NOT NULL CHECK (jumlah_porsi >= 1),
+    harga_min NUMERIC(12,2) CHECK (harga_min >= 0),
+    harga_max NUMERIC(12,2) CHECK (harga_max >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terpenuhi', 'dibatalkan', 'kedaluwarsa')),
+    hewan_id UUID NULL,
+    matched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE SET NULL
 );
 
+CREATE INDEX idx_permintaan_patungan_status ON permintaan_patungan (status, jenis, created_at);
+
+CREATE TRIGGER trigger_update_permintaan_patungan
+BEFORE UPDATE ON permintaan_patungan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Periode kurban per tahun Hijriah; offset_hari menyesuaikan kalender tabular dengan hasil sidang isbat
+CREATE TABLE periode_kurban (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tahun_hijri INT NOT NULL UNIQUE CHECK (tahun_hijri BETWEEN 1400 AND 1600),
+    offset_hari INT NOT NULL DEFAULT 0 CHECK (offset_hari BETWEEN -3 AND 3),
+    aktif BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+-- hanya satu periode yang aktif
+CREATE UNIQUE INDEX periode_kurban_aktif_unique ON periode_kurban (aktif) WHERE aktif;
+
+CREATE TRIGGER trigger_update_periode_kurban
+BEFORE UPDATE ON periode_kurban
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Master lokasi penyembelihan dan titik distribusi, dikelola admin
+CREATE TABLE lokasi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(255) NOT NULL,
+    alamat TEXT,
+    latitude NUMERIC(9,6) CHECK (latitude BETWEEN -90 AND 90),
+    longitude NUMERIC(9,6) CHECK (longitude BETWEEN -180 AND 180),
+    kapasitas INT NOT NULL DEFAULT 1 CHECK (kapasitas > 0), -- jumlah titik pemotongan paralel
+    kontak_nama VARCHAR(100),
+    kontak_phone VARCHAR(20),
+    tipe VARCHAR(20) NOT NULL DEFAULT 'penyembelihan' CHECK (tipe IN ('penyembelihan', 'distribusi', 'keduanya')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX lokasi_nama_unique ON lokasi (lower(nama));
+
+CREATE TRIGGER trigger_update_lokasi
+BEFORE UPDATE ON lokasi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Profil jagal dan relawan, opsional ditautkan ke akun user agar bisa melihat jadwalnya sendiri
+CREATE TABLE petugas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(100) NOT NULL,
+    phone VARCHAR(20),
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE SET NULL,
+    peran TEXT[] NOT NULL CHECK (cardinality(peran) > 0 AND peran <@ ARRAY['jagal', 'pencacah', 'pengemas', 'kurir']),
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE TRIGGER trigger_update_petugas
+BEFORE UPDATE ON petugas
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Slot shift per lokasi dan peran; kebutuhan = jumlah petugas yang dibutuhkan
+CREATE TABLE shift (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lokasi_id UUID NOT NULL REFERENCES lokasi(id),
+    peran VARCHAR(20) NOT NULL CHECK (peran IN ('jagal', 'pencacah', 'pengemas', 'kurir')),
+    mulai TIMESTAMP WITH TIME ZONE NOT NULL,
+    selesai TIMESTAMP WITH TIME ZONE NOT NULL,
+    kebutuhan INT NOT NULL DEFAULT 1 CHECK (kebutuhan > 0),
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    CHECK (selesai > mulai)
+);
+
+CREATE INDEX shift_lokasi_mulai ON shift (lokasi_id, mulai);
+
+CREATE TRIGGER trigger_update_shift
+BEFORE UPDATE ON shift
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE penugasan_shift (
+    shift_id UUID NOT NULL REFERENCES shift(id) ON DELETE CASCADE,
+    petugas_id UUID NOT NULL REFERENCES petugas(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (shift_id, petugas_id)
+);
+
+CREATE INDEX penugasan_shift_petugas ON penugasan_shift (petugas_id);
+
 -- Tabel penyembelihan
 CREATE TABLE penyembelihan (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     hewan_id UUID NOT NULL UNIQUE,
     tanggal_penyembelihan DATE NOT NULL,
-    lokasi VARCHAR(255),
+    lokasi_id UUID NOT NULL REFERENCES lokasi(id),
     urutan_rencana INT DEFAULT 9999 NOT NULL,
     urutan_aktual INT DEFAULT NULL,
+    -- check-in penyembelihan: rencana dari penjadwal, waktu aktual, jagal, dan saksi
+    rencana_mulai TIMESTAMP WITH TIME ZONE,
+    mulai_at TIMESTAMP WITH TIME ZONE,
+    selesai_at TIMESTAMP WITH TIME ZONE CHECK (selesai_at >= mulai_at),
+    jagal VARCHAR(100),
+    jagal_id UUID REFERENCES petugas(id) ON DELETE SET NULL, -- jagal yang ditugaskan; kolom jagal tetap menyimpan namanya
+    saksi VARCHAR(100),
+    bukti_lengkap_at TIMESTAMP WITH TIME ZONE, -- bukti foto/video ditandai lengkap dan pekurban sudah dikabari
+    -- hasil penyembelihan (kg), diisi panitia setelah hewan disembelih
+    berat_hidup NUMERIC(10,2) CHECK (berat_hidup > 0),
+    berat_karkas NUMERIC(10,2) CHECK (berat_karkas >= 0),
+    berat_daging NUMERIC(10,2) CHECK (berat_daging >= 0),
+    berat_tulang NUMERIC(10,2) CHECK (berat_tulang >= 0),
+    berat_jeroan NUMERIC(10,2) CHECK (berat_jeroan >= 0),
+    jumlah_paket INT CHECK (jumlah_paket >= 0),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE
@@ -128,12 +398,65 @@ CREATE TRIGGER trigger_update_penyembelihan
 BEFORE UPDATE ON penyembelihan
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+-- Foto/video bukti penyembelihan; file disimpan di UPLOAD_DIR, path relatif terhadap direktori tersebut
+CREATE TABLE bukti_penyembelihan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    penyembelihan_id UUID NOT NULL REFERENCES penyembelihan(id) ON DELETE CASCADE,
+    tipe VARCHAR(10) NOT NULL CHECK (tipe IN ('foto', 'video')),
+    path TEXT NOT NULL UNIQUE,
+    nama_asli VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    ukuran BIGINT NOT NULL CHECK (ukuran >= 0),
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX bukti_penyembelihan_penyembelihan ON bukti_penyembelihan (penyembelihan_id);
+
+-- Antrean live: setiap perubahan jadwal penyembelihan atau status hewan terjadwal dikirim lewat NOTIFY
+-- dengan payload tanggal penyembelihan, didengarkan semua instance server
+CREATE OR REPLACE FUNCTION notify_antrean_penyembelihan()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('antrean_penyembelihan', OLD.tanggal_penyembelihan::text);
+        RETURN OLD;
+    END IF;
+    PERFORM pg_notify('antrean_penyembelihan', NEW.tanggal_penyembelihan::text);
+    IF TG_OP = 'UPDATE' AND OLD.tanggal_penyembelihan <> NEW.tanggal_penyembelihan THEN
+        PERFORM pg_notify('antrean_penyembelihan', OLD.tanggal_penyembelihan::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_antrean_penyembelihan
+AFTER INSERT OR UPDATE OR DELETE ON penyembelihan
+FOR EACH ROW EXECUTE FUNCTION notify_antrean_penyembelihan();
+
+CREATE OR REPLACE FUNCTION notify_antrean_status_hewan()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('antrean_penyembelihan', ps.tanggal_penyembelihan::text)
+    FROM penyembelihan ps WHERE ps.hewan_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_antrean_status_hewan
+AFTER UPDATE OF status ON hewan_kurban
+FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION notify_antrean_status_hewan();
+
 -- Tabel penerima_daging dengan relasi ke pekurban
 CREATE TABLE penerima_daging (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(100) NOT NULL,
     alamat TEXT,
     phone VARCHAR(20),
+    rt VARCHAR(3), -- tiga digit, mis. 003
+    rw VARCHAR(3),
+    antar_rumah BOOLEAN NOT NULL DEFAULT false, -- lansia/difabel, daging diantar ke rumah
     status status_penerima_enum NOT NULL DEFAULT 'warga',
     pekurban_id UUID UNIQUE NULL,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
@@ -148,20 +471,105 @@ FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 -- Tabel distribusi_daging
 CREATE TABLE distribusi_daging (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    penerima_id UUID UNIQUE NOT NULL,
-    hewan_id UUID NOT NULL,
+    penerima_id UUID NOT NULL, -- boleh beberapa kali per tahun, dibatasi DISTRIBUSI_MAKS_PER_PENERIMA
     jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
     tanggal_distribusi DATE NOT NULL,
+    lokasi_id UUID REFERENCES lokasi(id), -- titik distribusi, opsional
+    dicatat_oleh UUID REFERENCES users(id) ON DELETE SET NULL, -- panitia yang mencatat atau memindai kupon
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
-    FOREIGN KEY (penerima_id) REFERENCES penerima_daging(id) ON DELETE CASCADE,
-    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE
+    FOREIGN KEY (penerima_id) REFERENCES penerima_daging(id) ON DELETE CASCADE
 );
 
+CREATE INDEX idx_distribusi_daging_penerima ON distribusi_daging (penerima_id, tanggal_distribusi);
+
 CREATE TRIGGER trigger_update_distribusi_daging
 BEFORE UPDATE ON distribusi_daging
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+-- Tabel alokasi_distribusi: rencana paket per penerima per tahun Hijriah, dasar status "belum terdistribusi"
+CREATE TABLE alokasi_distribusi (
+    penerima_id UUID NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    tahun_hijri INT NOT NULL,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (penerima_id, tahun_hijri)
+);
+
+CREATE TRIGGER trigger_update_alokasi_distribusi
+BEFORE UPDATE ON alokasi_distribusi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel distribusi_hewan: asal daging setiap distribusi, paket per hewan
+CREATE TABLE distribusi_hewan (
+    distribusi_id UUID NOT NULL REFERENCES distribusi_daging(id) ON DELETE CASCADE,
+    hewan_id UUID NOT NULL REFERENCES hewan_kurban(id), -- hewan yang dagingnya sudah dibagikan tidak bisa dihapus
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    PRIMARY KEY (distribusi_id, hewan_id)
+);
+
+CREATE INDEX idx_distribusi_hewan_hewan ON distribusi_hewan (hewan_id);
+
+-- Tabel kupon_distribusi: kupon QR pengambilan daging, ditukar sekali
+CREATE TABLE kupon_distribusi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    penerima_id UUID NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    tanggal_distribusi DATE, -- kosong: berlaku di hari penukaran
+    lokasi_id UUID REFERENCES lokasi(id),
+    distribusi_id UUID UNIQUE REFERENCES distribusi_daging(id) ON DELETE SET NULL, -- terisi saat ditukar
+    ditukar_at TIMESTAMP WITH TIME ZONE,
+    ditukar_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+-- hanya satu kupon yang belum ditukar per penerima
+CREATE UNIQUE INDEX kupon_distribusi_aktif_unique ON kupon_distribusi (penerima_id) WHERE ditukar_at IS NULL;
+
+CREATE TRIGGER trigger_update_kupon_distribusi
+BEFORE UPDATE ON kupon_distribusi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel batch_antar: rombongan pengantaran daging ke rumah penerima lansia/difabel, satu kurir per batch
+CREATE TABLE batch_antar (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(100) NOT NULL,
+    tanggal DATE NOT NULL,
+    lokasi_id UUID REFERENCES lokasi(id), -- titik berangkat / wilayah, opsional
+    rt VARCHAR(3),
+    rw VARCHAR(3),
+    kurir_id UUID REFERENCES petugas(id) ON DELETE SET NULL,
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX idx_batch_antar_tanggal ON batch_antar (tanggal);
+
+CREATE TRIGGER trigger_update_batch_antar
+BEFORE UPDATE ON batch_antar
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel batch_antar_stop: satu alamat penerima dalam batch, berurutan sesuai rute
+CREATE TABLE batch_antar_stop (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    batch_id UUID NOT NULL REFERENCES batch_antar(id) ON DELETE CASCADE,
+    penerima_id UUID NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    urutan INT NOT NULL,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terkirim', 'tidak_di_rumah', 'ditolak')),
+    catatan TEXT,
+    distribusi_id UUID UNIQUE REFERENCES distribusi_daging(id) ON DELETE SET NULL, -- terisi saat terkirim
+    diperbarui_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    diperbarui_at TIMESTAMP WITH TIME ZONE
+);
+
+-- penerima hanya boleh punya satu stop terbuka (menunggu / tidak di rumah) di semua batch
+CREATE UNIQUE INDEX batch_antar_stop_terbuka_unique ON batch_antar_stop (penerima_id) WHERE status IN ('menunggu', 'tidak_di_rumah');
+CREATE INDEX idx_batch_antar_stop_batch ON batch_antar_stop (batch_id, urutan);
+
 -- Tabel pembayaran_kurban
 CREATE TABLE pembayaran_kurban (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -177,9 +585,12 @@ CREATE TABLE pembayaran_kurban (
     transaction_time TIMESTAMP WITH TIME ZONE,
     tanggal_pembayaran TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     jumlah NUMERIC(12,2) NOT NULL CHECK (jumlah > 0),
+    asal_pembayaran_id UUID, -- pecahan pembayaran yang ikut berpindah saat transfer porsi
+    perlu_refund BOOLEAN NOT NULL DEFAULT FALSE, -- settle setelah porsinya tidak lagi tersedia
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
-    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (asal_pembayaran_id) REFERENCES pembayaran_kurban(id) ON DELETE SET NULL
 );
 
 
@@ -187,6 +598,21 @@ CREATE TRIGGER trigger_update_pembayaran_kurban
 BEFORE UPDATE ON pembayaran_kurban
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+-- Tabel pembayaran_porsi: porsi yang ditagihkan pada satu pembayaran beserta pemilik porsinya saat ini
+CREATE TABLE pembayaran_porsi (
+    pembayaran_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    pekurban_id UUID NOT NULL,
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    tagihan NUMERIC(12,2) NOT NULL CHECK (tagihan >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terkonfirmasi', 'perlu_refund', 'dibatalkan', 'ditutup')),
+    PRIMARY KEY (pembayaran_id, hewan_id),
+    FOREIGN KEY (pembayaran_id) REFERENCES pembayaran_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE
+);
+
 -- Tabel refresh_tokens untuk refresh token JWT
 CREATE TABLE refresh_tokens (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -204,3 +630,12 @@ CREATE TABLE refresh_tokens (
 CREATE TRIGGER trigger_update_refresh_tokens
 BEFORE UPDATE ON refresh_tokens
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel kalender_token untuk URL feed kalender rahasia; hanya hash token yang disimpan
+CREATE TABLE kalender_token (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE, -- feed lengkap milik admin/panitia
+    pekurban_id UUID UNIQUE REFERENCES pekurban(id) ON DELETE CASCADE, -- feed milik satu pekurban
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    CONSTRAINT kalender_token_pemilik_check CHECK ((user_id IS NULL) <> (pekurban_id IS NULL))
+);
@@ -0,0 +1,45 @@
+-- Migrasi database lama: RT/RW dan penanda antar rumah pada penerima, batch pengantaran beserta stop-nya.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE penerima_daging ADD COLUMN IF NOT EXISTS rt VARCHAR(3);
+ALTER TABLE penerima_daging ADD COLUMN IF NOT EXISTS rw VARCHAR(3);
+ALTER TABLE penerima_daging ADD COLUMN IF NOT EXISTS antar_rumah BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS batch_antar (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(100) NOT NULL,
+    tanggal DATE NOT NULL,
+    lokasi_id UUID REFERENCES lokasi(id),
+    rt VARCHAR(3),
+    rw VARCHAR(3),
+    kurir_id UUID REFERENCES petugas(id) ON DELETE SET NULL,
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_antar_tanggal ON batch_antar (tanggal);
+
+DROP TRIGGER IF EXISTS trigger_update_batch_antar ON batch_antar;
+CREATE TRIGGER trigger_update_batch_antar
+BEFORE UPDATE ON batch_antar
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS batch_antar_stop (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    batch_id UUID NOT NULL REFERENCES batch_antar(id) ON DELETE CASCADE,
+    penerima_id UUID NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    urutan INT NOT NULL,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terkirim', 'tidak_di_rumah', 'ditolak')),
+    catatan TEXT,
+    distribusi_id UUID UNIQUE REFERENCES distribusi_daging(id) ON DELETE SET NULL,
+    diperbarui_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    diperbarui_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS batch_antar_stop_terbuka_unique ON batch_antar_stop (penerima_id) WHERE status IN ('menunggu', 'tidak_di_rumah');
+CREATE INDEX IF NOT EXISTS idx_batch_antar_stop_batch ON batch_antar_stop (batch_id, urutan);
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- Migrasi database lama: trigger NOTIFY untuk endpoint antrean live GET /penyembelihan/live.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE OR REPLACE FUNCTION notify_antrean_penyembelihan()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('antrean_penyembelihan', OLD.tanggal_penyembelihan::text);
+        RETURN OLD;
+    END IF;
+    PERFORM pg_notify('antrean_penyembelihan', NEW.tanggal_penyembelihan::text);
+    IF TG_OP = 'UPDATE' AND OLD.tanggal_penyembelihan <> NEW.tanggal_penyembelihan THEN
+        PERFORM pg_notify('antrean_penyembelihan', OLD.tanggal_penyembelihan::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_notify_antrean_penyembelihan ON penyembelihan;
+CREATE TRIGGER trigger_notify_antrean_penyembelihan
+AFTER INSERT OR UPDATE OR DELETE ON penyembelihan
+FOR EACH ROW EXECUTE FUNCTION notify_antrean_penyembelihan();
+
+CREATE OR REPLACE FUNCTION notify_antrean_status_hewan()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('antrean_penyembelihan', ps.tanggal_penyembelihan::text)
+    FROM penyembelihan ps WHERE ps.hewan_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_notify_antrean_status_hewan ON hewan_kurban;
+CREATE TRIGGER trigger_notify_antrean_status_hewan
+AFTER UPDATE OF status ON hewan_kurban
+FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION notify_antrean_status_hewan();
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Migrasi database lama: atas nama kurban untuk setiap porsi patungan.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS atas_nama_kurban (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    nama VARCHAR(100) NOT NULL,
+    hubungan VARCHAR(50),
+    masih_hidup BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id, hewan_id) REFERENCES pekurban_hewan(pekurban_id, hewan_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_atas_nama_kurban_share ON atas_nama_kurban (pekurban_id, hewan_id);
+
+DROP TRIGGER IF EXISTS trigger_update_atas_nama_kurban ON atas_nama_kurban;
+CREATE TRIGGER trigger_update_atas_nama_kurban
+BEFORE UPDATE ON atas_nama_kurban
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
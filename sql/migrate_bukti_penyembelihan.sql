@@ -0,0 +1,21 @@
+-- Migrasi database lama: foto/video bukti penyembelihan dan penanda bukti lengkap.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS bukti_lengkap_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS bukti_penyembelihan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    penyembelihan_id UUID NOT NULL REFERENCES penyembelihan(id) ON DELETE CASCADE,
+    tipe VARCHAR(10) NOT NULL CHECK (tipe IN ('foto', 'video')),
+    path TEXT NOT NULL UNIQUE,
+    nama_asli VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    ukuran BIGINT NOT NULL CHECK (ukuran >= 0),
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS bukti_penyembelihan_penyembelihan ON bukti_penyembelihan (penyembelihan_id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migrasi database lama: kolom check-in mulai/selesai penyembelihan beserta jagal dan saksi.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS rencana_mulai TIMESTAMP WITH TIME ZONE;
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS mulai_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS selesai_at TIMESTAMP WITH TIME ZONE CHECK (selesai_at >= mulai_at);
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS jagal VARCHAR(100);
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS saksi VARCHAR(100);
+
+COMMIT;
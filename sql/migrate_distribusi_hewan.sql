@@ -0,0 +1,28 @@
+-- Migrasi database lama: asal hewan distribusi daging dipindah dari kolom distribusi_daging.hewan_id
+-- ke tabel distribusi_hewan sehingga satu distribusi bisa berasal dari beberapa hewan.
+-- Distribusi lama dicatat seluruh paketnya berasal dari hewan_id semula.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS distribusi_hewan (
+    distribusi_id UUID NOT NULL REFERENCES distribusi_daging(id) ON DELETE CASCADE,
+    hewan_id UUID NOT NULL REFERENCES hewan_kurban(id),
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    PRIMARY KEY (distribusi_id, hewan_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_distribusi_hewan_hewan ON distribusi_hewan (hewan_id);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'distribusi_daging' AND column_name = 'hewan_id') THEN
+        INSERT INTO distribusi_hewan (distribusi_id, hewan_id, jumlah_paket)
+        SELECT id, hewan_id, jumlah_paket FROM distribusi_daging
+        ON CONFLICT DO NOTHING;
+
+        ALTER TABLE distribusi_daging DROP COLUMN hewan_id;
+    END IF;
+END$$;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migrasi database lama: penerima boleh menerima beberapa distribusi (gelombang dan antartahun),
+-- alokasi paket per penerima per tahun Hijriah, dan kupon baru setelah kupon lama ditukar.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE distribusi_daging DROP CONSTRAINT IF EXISTS distribusi_daging_penerima_id_key;
+CREATE INDEX IF NOT EXISTS idx_distribusi_daging_penerima ON distribusi_daging (penerima_id, tanggal_distribusi);
+
+CREATE TABLE IF NOT EXISTS alokasi_distribusi (
+    penerima_id UUID NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    tahun_hijri INT NOT NULL,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (penerima_id, tahun_hijri)
+);
+
+DROP TRIGGER IF EXISTS trigger_update_alokasi_distribusi ON alokasi_distribusi;
+CREATE TRIGGER trigger_update_alokasi_distribusi
+BEFORE UPDATE ON alokasi_distribusi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE kupon_distribusi DROP CONSTRAINT IF EXISTS kupon_distribusi_penerima_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS kupon_distribusi_aktif_unique ON kupon_distribusi (penerima_id) WHERE ditukar_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migrasi database lama: foto hewan untuk katalog publik.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE hewan_kurban ADD COLUMN IF NOT EXISTS foto_url TEXT;
+
+COMMIT;
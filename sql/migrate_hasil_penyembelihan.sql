@@ -0,0 +1,12 @@
+-- Migrasi database lama: menambah kolom hasil penyembelihan (berat dan jumlah paket).
+BEGIN;
+
+ALTER TABLE penyembelihan
+    ADD COLUMN berat_hidup NUMERIC(10,2) CHECK (berat_hidup > 0),
+    ADD COLUMN berat_karkas NUMERIC(10,2) CHECK (berat_karkas >= 0),
+    ADD COLUMN berat_daging NUMERIC(10,2) CHECK (berat_daging >= 0),
+    ADD COLUMN berat_tulang NUMERIC(10,2) CHECK (berat_tulang >= 0),
+    ADD COLUMN berat_jeroan NUMERIC(10,2) CHECK (berat_jeroan >= 0),
+    ADD COLUMN jumlah_paket INT CHECK (jumlah_paket >= 0);
+
+COMMIT;
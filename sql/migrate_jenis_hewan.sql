@@ -0,0 +1,40 @@
+-- Migrasi database lama: jenis_hewan_enum diganti tabel master jenis_hewan.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS jenis_hewan (
+    nama VARCHAR(50) PRIMARY KEY,
+    max_porsi INT NOT NULL CHECK (max_porsi >= 1),
+    min_umur_bulan INT NOT NULL DEFAULT 0 CHECK (min_umur_bulan >= 0),
+    harga_default NUMERIC(12,2) CHECK (harga_default > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+DROP TRIGGER IF EXISTS trigger_update_jenis_hewan ON jenis_hewan;
+CREATE TRIGGER trigger_update_jenis_hewan
+BEFORE UPDATE ON jenis_hewan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO jenis_hewan (nama, max_porsi, min_umur_bulan) VALUES
+    ('sapi', 7, 24),
+    ('kerbau', 7, 24),
+    ('unta', 7, 60),
+    ('kambing', 1, 12),
+    ('domba', 1, 6)
+ON CONFLICT (nama) DO NOTHING;
+
+ALTER TABLE hewan_kurban ALTER COLUMN jenis TYPE VARCHAR(50) USING jenis::text;
+ALTER TABLE hewan_kurban ADD CONSTRAINT hewan_kurban_jenis_fkey
+    FOREIGN KEY (jenis) REFERENCES jenis_hewan(nama) ON UPDATE CASCADE;
+ALTER TABLE hewan_kurban ADD COLUMN IF NOT EXISTS umur_bulan INT CHECK (umur_bulan > 0);
+
+ALTER TABLE permintaan_patungan ALTER COLUMN jenis TYPE VARCHAR(50) USING jenis::text;
+ALTER TABLE permintaan_patungan ADD CONSTRAINT permintaan_patungan_jenis_fkey
+    FOREIGN KEY (jenis) REFERENCES jenis_hewan(nama) ON UPDATE CASCADE;
+ALTER TABLE permintaan_patungan DROP CONSTRAINT IF EXISTS permintaan_patungan_jumlah_porsi_check;
+ALTER TABLE permintaan_patungan ADD CONSTRAINT permintaan_patungan_jumlah_porsi_check CHECK (jumlah_porsi >= 1);
+
+DROP TYPE IF EXISTS jenis_hewan_enum;
+
+COMMIT;
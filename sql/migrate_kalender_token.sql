@@ -0,0 +1,13 @@
+-- Migrasi database lama: token feed kalender (iCalendar) untuk panitia dan pekurban.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS kalender_token (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    pekurban_id UUID UNIQUE REFERENCES pekurban(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    CONSTRAINT kalender_token_pemilik_check CHECK ((user_id IS NULL) <> (pekurban_id IS NULL))
+);
+
+COMMIT;
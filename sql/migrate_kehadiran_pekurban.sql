@@ -0,0 +1,7 @@
+-- Migrasi database lama: menambah permintaan hadir pekurban saat penyembelihan (prioritas penjadwalan otomatis).
+BEGIN;
+
+ALTER TABLE pekurban_hewan
+    ADD COLUMN ingin_hadir BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
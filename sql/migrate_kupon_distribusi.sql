@@ -0,0 +1,25 @@
+-- Migrasi database lama: kupon QR pengambilan daging dan pencatat distribusi.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE distribusi_daging ADD COLUMN IF NOT EXISTS dicatat_oleh UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS kupon_distribusi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    penerima_id UUID UNIQUE NOT NULL REFERENCES penerima_daging(id) ON DELETE CASCADE,
+    jumlah_paket INT NOT NULL CHECK (jumlah_paket > 0),
+    tanggal_distribusi DATE,
+    lokasi_id UUID REFERENCES lokasi(id),
+    distribusi_id UUID UNIQUE REFERENCES distribusi_daging(id) ON DELETE SET NULL,
+    ditukar_at TIMESTAMP WITH TIME ZONE,
+    ditukar_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+DROP TRIGGER IF EXISTS trigger_update_kupon_distribusi ON kupon_distribusi;
+CREATE TRIGGER trigger_update_kupon_distribusi
+BEFORE UPDATE ON kupon_distribusi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
@@ -0,0 +1,52 @@
+-- Migrasi database lama: kolom teks penyembelihan.lokasi diganti referensi ke tabel master lokasi.
+-- Ejaan yang hanya berbeda huruf besar/kecil atau spasi disatukan memakai ejaan yang paling sering dipakai;
+-- lokasi kosong dipindahkan ke lokasi "Belum ditentukan" yang bisa digabung lewat POST /lokasi/:id/gabung.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS lokasi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(255) NOT NULL,
+    alamat TEXT,
+    latitude NUMERIC(9,6) CHECK (latitude BETWEEN -90 AND 90),
+    longitude NUMERIC(9,6) CHECK (longitude BETWEEN -180 AND 180),
+    kapasitas INT NOT NULL DEFAULT 1 CHECK (kapasitas > 0),
+    kontak_nama VARCHAR(100),
+    kontak_phone VARCHAR(20),
+    tipe VARCHAR(20) NOT NULL DEFAULT 'penyembelihan' CHECK (tipe IN ('penyembelihan', 'distribusi', 'keduanya')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS lokasi_nama_unique ON lokasi (lower(nama));
+
+DROP TRIGGER IF EXISTS trigger_update_lokasi ON lokasi;
+CREATE TRIGGER trigger_update_lokasi
+BEFORE UPDATE ON lokasi
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO lokasi (nama)
+SELECT DISTINCT ON (lower(trim(lokasi))) trim(lokasi)
+FROM penyembelihan
+WHERE trim(coalesce(lokasi, '')) <> ''
+GROUP BY lower(trim(lokasi)), trim(lokasi)
+ORDER BY lower(trim(lokasi)), COUNT(*) DESC, trim(lokasi)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO lokasi (nama)
+SELECT 'Belum ditentukan'
+WHERE EXISTS (SELECT 1 FROM penyembelihan WHERE trim(coalesce(lokasi, '')) = '')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS lokasi_id UUID REFERENCES lokasi(id);
+
+UPDATE penyembelihan ps SET lokasi_id = l.id
+FROM lokasi l
+WHERE lower(l.nama) = lower(coalesce(nullif(trim(ps.lokasi), ''), 'Belum ditentukan'));
+
+ALTER TABLE penyembelihan ALTER COLUMN lokasi_id SET NOT NULL;
+ALTER TABLE penyembelihan DROP COLUMN lokasi;
+
+ALTER TABLE distribusi_daging ADD COLUMN IF NOT EXISTS lokasi_id UUID REFERENCES lokasi(id);
+
+COMMIT;
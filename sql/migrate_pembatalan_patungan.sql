@@ -0,0 +1,34 @@
+-- Migrasi database lama: pengajuan pembatalan patungan beserta kebijakan refund.
+-- Jalankan sekali setelah migrate_porsi_pecahan.sql.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS pembatalan_patungan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    alasan TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'disetujui', 'ditolak')),
+    kebijakan VARCHAR(20) NOT NULL CHECK (kebijakan IN ('penuh', 'sebagian', 'tidak_ada')),
+    persentase_refund INT NOT NULL CHECK (persentase_refund BETWEEN 0 AND 100),
+    jumlah_dibayar NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_dibayar >= 0),
+    jumlah_refund NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_refund >= 0),
+    diproses_oleh UUID,
+    catatan_admin TEXT,
+    diproses_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (diproses_oleh) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pembatalan_patungan_menunggu ON pembatalan_patungan (pekurban_id, hewan_id) WHERE status = 'menunggu';
+
+DROP TRIGGER IF EXISTS trigger_update_pembatalan_patungan ON pembatalan_patungan;
+CREATE TRIGGER trigger_update_pembatalan_patungan
+BEFORE UPDATE ON pembatalan_patungan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
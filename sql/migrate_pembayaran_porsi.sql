@@ -0,0 +1,31 @@
+-- Migrasi database lama: porsi yang ditagihkan per pembayaran dan penanda refund.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE pembayaran_kurban ADD COLUMN IF NOT EXISTS perlu_refund BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS pembayaran_porsi (
+    pembayaran_id UUID NOT NULL,
+    hewan_id UUID NOT NULL,
+    pekurban_id UUID NOT NULL,
+    porsi_pembilang INT NOT NULL CHECK (porsi_pembilang > 0),
+    porsi_penyebut INT NOT NULL CHECK (porsi_penyebut > 0),
+    tagihan NUMERIC(12,2) NOT NULL CHECK (tagihan >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terkonfirmasi', 'perlu_refund', 'dibatalkan', 'ditutup')),
+    PRIMARY KEY (pembayaran_id, hewan_id),
+    FOREIGN KEY (pembayaran_id) REFERENCES pembayaran_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE
+);
+
+-- tabel yang dibuat versi sebelumnya belum mencatat pemilik porsi dan status dibatalkan
+ALTER TABLE pembayaran_porsi ADD COLUMN IF NOT EXISTS pekurban_id UUID REFERENCES pekurban(id) ON DELETE CASCADE;
+UPDATE pembayaran_porsi pp SET pekurban_id = pk.pekurban_id
+FROM pembayaran_kurban pk
+WHERE pp.pembayaran_id = pk.id AND pp.pekurban_id IS NULL;
+ALTER TABLE pembayaran_porsi ALTER COLUMN pekurban_id SET NOT NULL;
+
+ALTER TABLE pembayaran_porsi DROP CONSTRAINT IF EXISTS pembayaran_porsi_status_check;
+ALTER TABLE pembayaran_porsi ADD CONSTRAINT pembayaran_porsi_status_check CHECK (status IN ('menunggu', 'terkonfirmasi', 'perlu_refund', 'dibatalkan', 'ditutup'));
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migrasi database lama: pecahan pembayaran saat porsi dialihkan ke pekurban lain.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+ALTER TABLE pembayaran_kurban ADD COLUMN IF NOT EXISTS asal_pembayaran_id UUID REFERENCES pembayaran_kurban(id) ON DELETE SET NULL;
+
+COMMIT;
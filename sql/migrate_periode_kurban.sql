@@ -0,0 +1,22 @@
+-- Migrasi database lama: periode kurban per tahun Hijriah untuk validasi tanggal penyembelihan 10-13 Dzulhijjah.
+-- Jadwal lama di luar periode tidak diubah; validasi hanya berlaku saat tanggal dibuat atau diganti.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS periode_kurban (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tahun_hijri INT NOT NULL UNIQUE CHECK (tahun_hijri BETWEEN 1400 AND 1600),
+    offset_hari INT NOT NULL DEFAULT 0 CHECK (offset_hari BETWEEN -3 AND 3),
+    aktif BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS periode_kurban_aktif_unique ON periode_kurban (aktif) WHERE aktif;
+
+DROP TRIGGER IF EXISTS trigger_update_periode_kurban ON periode_kurban;
+CREATE TRIGGER trigger_update_periode_kurban
+BEFORE UPDATE ON periode_kurban
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
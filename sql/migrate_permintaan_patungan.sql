@@ -0,0 +1,28 @@
+-- Migrasi database lama: antrean permintaan patungan yang belum mendapat hewan.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya, sebelum migrate_jenis_hewan.sql.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS permintaan_patungan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pekurban_id UUID NOT NULL,
+    jenis jenis_hewan_enum NOT NULL,
+    jumlah_porsi INT NOT NULL CHECK (jumlah_porsi BETWEEN 1 AND 7),
+    harga_min NUMERIC(12,2) CHECK (harga_min >= 0),
+    harga_max NUMERIC(12,2) CHECK (harga_max >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'menunggu' CHECK (status IN ('menunggu', 'terpenuhi', 'dibatalkan')),
+    hewan_id UUID NULL,
+    matched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_permintaan_patungan_status ON permintaan_patungan (status, jenis, created_at);
+
+DROP TRIGGER IF EXISTS trigger_update_permintaan_patungan ON permintaan_patungan;
+CREATE TRIGGER trigger_update_permintaan_patungan
+BEFORE UPDATE ON permintaan_patungan
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
@@ -0,0 +1,53 @@
+-- Migrasi database lama: profil petugas (jagal/relawan), slot shift per lokasi, penugasan shift,
+-- dan referensi jagal yang ditugaskan pada penyembelihan.
+-- Jalankan sekali pada database yang dibuat dengan ddl.sql versi sebelumnya.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS petugas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nama VARCHAR(100) NOT NULL,
+    phone VARCHAR(20),
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE SET NULL,
+    peran TEXT[] NOT NULL CHECK (cardinality(peran) > 0 AND peran <@ ARRAY['jagal', 'pencacah', 'pengemas', 'kurir']),
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+DROP TRIGGER IF EXISTS trigger_update_petugas ON petugas;
+CREATE TRIGGER trigger_update_petugas
+BEFORE UPDATE ON petugas
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS shift (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lokasi_id UUID NOT NULL REFERENCES lokasi(id),
+    peran VARCHAR(20) NOT NULL CHECK (peran IN ('jagal', 'pencacah', 'pengemas', 'kurir')),
+    mulai TIMESTAMP WITH TIME ZONE NOT NULL,
+    selesai TIMESTAMP WITH TIME ZONE NOT NULL,
+    kebutuhan INT NOT NULL DEFAULT 1 CHECK (kebutuhan > 0),
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    CHECK (selesai > mulai)
+);
+
+CREATE INDEX IF NOT EXISTS shift_lokasi_mulai ON shift (lokasi_id, mulai);
+
+DROP TRIGGER IF EXISTS trigger_update_shift ON shift;
+CREATE TRIGGER trigger_update_shift
+BEFORE UPDATE ON shift
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS penugasan_shift (
+    shift_id UUID NOT NULL REFERENCES shift(id) ON DELETE CASCADE,
+    petugas_id UUID NOT NULL REFERENCES petugas(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (shift_id, petugas_id)
+);
+
+CREATE INDEX IF NOT EXISTS penugasan_shift_petugas ON penugasan_shift (petugas_id);
+
+ALTER TABLE penyembelihan ADD COLUMN IF NOT EXISTS jagal_id UUID REFERENCES petugas(id) ON DELETE SET NULL;
+
+COMMIT;
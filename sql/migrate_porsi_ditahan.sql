@@ -0,0 +1,19 @@
+-- Migrasi database lama: porsi patungan dapat ditahan sementara sebelum pembayaran.
+-- Porsi yang sudah ada dianggap terkonfirmasi.
+-- Jalankan sekali setelah migrate_permintaan_patungan.sql.
+BEGIN;
+
+ALTER TABLE pekurban_hewan
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'terkonfirmasi' CHECK (status IN ('ditahan', 'terkonfirmasi')),
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE pekurban_hewan DROP CONSTRAINT IF EXISTS pekurban_hewan_hold_check;
+ALTER TABLE pekurban_hewan ADD CONSTRAINT pekurban_hewan_hold_check CHECK (status <> 'ditahan' OR expires_at IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_pekurban_hewan_hold ON pekurban_hewan (expires_at) WHERE status = 'ditahan';
+
+ALTER TABLE permintaan_patungan DROP CONSTRAINT IF EXISTS permintaan_patungan_status_check;
+ALTER TABLE permintaan_patungan ADD CONSTRAINT permintaan_patungan_status_check
+    CHECK (status IN ('menunggu', 'terpenuhi', 'dibatalkan', 'kedaluwarsa'));
+
+COMMIT;
@@ -0,0 +1,46 @@
+-- Migrasi database lama: porsi NUMERIC(4,3) diganti pecahan eksak porsi_pembilang/porsi_penyebut.
+-- Porsi lama dikonversi ke jumlah orang terhadap kapasitas jenis hewan (mis. 0.286 pada sapi = 2/7).
+-- Jalankan sekali setelah migrate_jenis_hewan.sql.
+BEGIN;
+
+ALTER TABLE pekurban_hewan
+    ADD COLUMN porsi_pembilang INT,
+    ADD COLUMN porsi_penyebut INT;
+
+UPDATE pekurban_hewan ph
+SET porsi_pembilang = GREATEST(ROUND(ph.porsi * j.max_porsi), 1),
+    porsi_penyebut = j.max_porsi
+FROM hewan_kurban h
+JOIN jenis_hewan j ON j.nama = h.jenis
+WHERE h.id = ph.hewan_id;
+
+ALTER TABLE pekurban_hewan
+    DROP COLUMN porsi,
+    ALTER COLUMN porsi_pembilang SET NOT NULL,
+    ALTER COLUMN porsi_penyebut SET NOT NULL,
+    ADD CONSTRAINT pekurban_hewan_porsi_pembilang_check CHECK (porsi_pembilang > 0),
+    ADD CONSTRAINT pekurban_hewan_porsi_penyebut_check CHECK (porsi_penyebut > 0),
+    ADD CONSTRAINT pekurban_hewan_porsi_check CHECK (porsi_pembilang <= porsi_penyebut),
+    ADD COLUMN porsi NUMERIC GENERATED ALWAYS AS (porsi_pembilang::numeric / porsi_penyebut) STORED;
+
+ALTER TABLE transfer_porsi
+    ADD COLUMN porsi_pembilang INT,
+    ADD COLUMN porsi_penyebut INT;
+
+UPDATE transfer_porsi t
+SET porsi_pembilang = GREATEST(ROUND(t.porsi * j.max_porsi), 1),
+    porsi_penyebut = j.max_porsi
+FROM hewan_kurban h
+JOIN jenis_hewan j ON j.nama = h.jenis
+WHERE h.id = t.hewan_id;
+
+ALTER TABLE transfer_porsi
+    DROP COLUMN porsi,
+    ALTER COLUMN porsi_pembilang SET NOT NULL,
+    ALTER COLUMN porsi_penyebut SET NOT NULL,
+    ADD CONSTRAINT transfer_porsi_porsi_pembilang_check CHECK (porsi_pembilang > 0),
+    ADD CONSTRAINT transfer_porsi_porsi_penyebut_check CHECK (porsi_penyebut > 0),
+    ADD CONSTRAINT transfer_porsi_porsi_check CHECK (porsi_pembilang <= porsi_penyebut),
+    ADD COLUMN porsi NUMERIC GENERATED ALWAYS AS (porsi_pembilang::numeric / porsi_penyebut) STORED;
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Migrasi database lama: menambah status siklus hidup hewan beserta riwayatnya.
+-- Status awal diturunkan dari data yang sudah ada (jadwal penyembelihan, porsi, konfirmasi pembayaran).
+BEGIN;
+
+ALTER TABLE hewan_kurban
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'terdaftar'
+        CHECK (status IN ('terdaftar', 'terisi', 'lunas', 'dijadwalkan', 'disembelih', 'dicacah', 'didistribusikan'));
+
+CREATE INDEX idx_hewan_kurban_status ON hewan_kurban (status);
+
+CREATE TABLE riwayat_status_hewan (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hewan_id UUID NOT NULL REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    dari_status VARCHAR(20),
+    ke_status VARCHAR(20) NOT NULL,
+    diubah_oleh UUID REFERENCES users(id),
+    keterangan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX idx_riwayat_status_hewan ON riwayat_status_hewan (hewan_id, created_at);
+
+WITH porsi AS (
+    SELECT hewan_id,
+           SUM(porsi_pembilang::numeric / porsi_penyebut) AS total,
+           BOOL_AND(status = 'terkonfirmasi') AS terkonfirmasi
+    FROM pekurban_hewan
+    WHERE status = 'terkonfirmasi' OR expires_at > now()
+    GROUP BY hewan_id
+)
+UPDATE hewan_kurban h
+SET status = CASE
+    WHEN p.urutan_aktual IS NOT NULL THEN 'disembelih'
+    WHEN p.id IS NOT NULL THEN 'dijadwalkan'
+    WHEN ROUND(po.total, 6) >= 1 AND po.terkonfirmasi THEN 'lunas'
+    WHEN ROUND(po.total, 6) >= 1 THEN 'terisi'
+    ELSE 'terdaftar'
+END
+FROM hewan_kurban h2
+LEFT JOIN penyembelihan p ON p.hewan_id = h2.id
+LEFT JOIN porsi po ON po.hewan_id = h2.id
+WHERE h.id = h2.id;
+
+INSERT INTO riwayat_status_hewan (hewan_id, dari_status, ke_status, keterangan)
+SELECT id, NULL, status, 'Status awal hasil migrasi' FROM hewan_kurban;
+
+COMMIT;
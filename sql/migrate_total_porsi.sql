@@ -0,0 +1,39 @@
+-- Migrasi database lama: trigger pengaman total porsi per hewan.
+-- Jalankan sekali setelah migrate_porsi_pecahan.sql.
+BEGIN;
+
+-- Batas total porsi per hewan dijaga juga di database: baris hewan dikunci lalu porsi aktif dijumlahkan,
+-- sehingga penulisan paralel yang tidak melewati service tetap tidak bisa melebihi satu hewan.
+-- Porsi turunan berupa NUMERIC hasil pembagian, jadi selisih pembulatan kecil masih ditoleransi.
+CREATE OR REPLACE FUNCTION cek_total_porsi_hewan()
+RETURNS TRIGGER AS $$
+DECLARE
+    total NUMERIC;
+BEGIN
+    IF NEW.status = 'ditahan' AND NEW.expires_at <= now() THEN
+        RETURN NEW;
+    END IF;
+
+    PERFORM 1 FROM hewan_kurban WHERE id = NEW.hewan_id FOR UPDATE;
+
+    SELECT COALESCE(SUM(ph.porsi_pembilang::numeric / ph.porsi_penyebut), 0) INTO total
+    FROM pekurban_hewan ph
+    WHERE ph.hewan_id = NEW.hewan_id
+      AND (ph.status = 'terkonfirmasi' OR ph.expires_at > now())
+      AND ph.pekurban_id <> NEW.pekurban_id
+      AND (TG_OP = 'INSERT' OR ph.pekurban_id <> OLD.pekurban_id OR ph.hewan_id <> OLD.hewan_id);
+
+    IF total + NEW.porsi_pembilang::numeric / NEW.porsi_penyebut > 1.000000001 THEN
+        RAISE EXCEPTION 'Total portion exceeds the maximum limit'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'pekurban_hewan_total_porsi_check';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS trigger_cek_total_porsi_hewan ON pekurban_hewan;
+CREATE TRIGGER trigger_cek_total_porsi_hewan
+BEFORE INSERT OR UPDATE OF pekurban_id, hewan_id, porsi_pembilang, porsi_penyebut, status, expires_at ON pekurban_hewan
+FOR EACH ROW EXECUTE FUNCTION cek_total_porsi_hewan();
+
+COMMIT;
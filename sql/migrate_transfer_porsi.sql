@@ -0,0 +1,29 @@
+-- Migrasi database lama: riwayat pengalihan porsi antar pekurban.
+-- Atas nama ikut berpindah saat porsi dialihkan (ON UPDATE CASCADE).
+-- Jalankan sekali setelah migrate_atas_nama_kurban.sql dan sebelum migrate_porsi_pecahan.sql.
+BEGIN;
+
+ALTER TABLE atas_nama_kurban DROP CONSTRAINT IF EXISTS atas_nama_kurban_pekurban_id_hewan_id_fkey;
+ALTER TABLE atas_nama_kurban ADD CONSTRAINT atas_nama_kurban_pekurban_id_hewan_id_fkey
+    FOREIGN KEY (pekurban_id, hewan_id) REFERENCES pekurban_hewan(pekurban_id, hewan_id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE TABLE IF NOT EXISTS transfer_porsi (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hewan_id UUID NOT NULL,
+    dari_pekurban_id UUID NOT NULL,
+    ke_pekurban_id UUID NOT NULL,
+    porsi NUMERIC(4,3) NOT NULL CHECK (porsi > 0 AND porsi <= 1),
+    jumlah_dibayar NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (jumlah_dibayar >= 0),
+    disetujui_oleh UUID NOT NULL,
+    catatan TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    FOREIGN KEY (hewan_id) REFERENCES hewan_kurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (dari_pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (ke_pekurban_id) REFERENCES pekurban(id) ON DELETE CASCADE,
+    FOREIGN KEY (disetujui_oleh) REFERENCES users(id),
+    CHECK (dari_pekurban_id <> ke_pekurban_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_porsi_hewan ON transfer_porsi (hewan_id, created_at);
+
+COMMIT;
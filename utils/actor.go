@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type actorKey struct{}
+
+// WithActor menyimpan ID user yang sedang login di context request, dipakai untuk mencatat pelaku perubahan
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext mengembalikan ID user pelaku, atau nil jika perubahan dilakukan oleh sistem
+func ActorFromContext(ctx context.Context) *uuid.UUID {
+	if id, ok := ctx.Value(actorKey{}).(uuid.UUID); ok {
+		return &id
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package hijri mengonversi tanggal Masehi dan Hijriah memakai kalender Hijriah tabular (aritmetika).
+// Kalender tabular bisa berbeda satu-dua hari dari hasil sidang isbat, sehingga setiap konversi
+// menerima offset hari: tanggal resmi = tanggal tabular + offset.
+package hijri
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const Dzulhijjah = 12
+
+var namaBulan = [...]string{
+	"Muharram", "Safar", "Rabiul Awal", "Rabiul Akhir", "Jumadil Awal", "Jumadil Akhir",
+	"Rajab", "Syaban", "Ramadhan", "Syawal", "Dzulqaidah", "Dzulhijjah",
+}
+
+// epoch adalah Julian Day Number 1 Muharram 1 H (16 Juli 622 M)
+const epoch = 1948440
+
+// jdnUnix adalah Julian Day Number 1 Januari 1970
+const jdnUnix = 2440588
+
+type Tanggal struct {
+	Tahun int
+	Bulan int
+	Hari  int
+}
+
+func (t Tanggal) NamaBulan() string {
+	if t.Bulan < 1 || t.Bulan > 12 {
+		return ""
+	}
+	return namaBulan[t.Bulan-1]
+}
+
+// String memformat tanggal seperti "10 Dzulhijjah 1446 H"
+func (t Tanggal) String() string {
+	return fmt.Sprintf("%d %s %d H", t.Hari, t.NamaBulan(), t.Tahun)
+}
+
+func toJDN(t Tanggal) int {
+	return t.Hari + int(math.Ceil(29.5*float64(t.Bulan-1))) + (t.Tahun-1)*354 + (3+11*t.Tahun)/30 + epoch - 1
+}
+
+func fromJDN(jdn int) Tanggal {
+	tahun := (30*(jdn-epoch) + 10646) / 10631
+	bulan := int(math.Ceil(float64(jdn-29-toJDN(Tanggal{Tahun: tahun, Bulan: 1, Hari: 1}))/29.5)) + 1
+	if bulan > 12 {
+		bulan = 12
+	}
+	if bulan < 1 {
+		bulan = 1
+	}
+	hari := jdn - toJDN(Tanggal{Tahun: tahun, Bulan: bulan, Hari: 1}) + 1
+	return Tanggal{Tahun: tahun, Bulan: bulan, Hari: hari}
+}
+
+// FromGregorian mengubah tanggal kalender (jam diabaikan) menjadi tanggal Hijriah
+func FromGregorian(t time.Time, offset int) Tanggal {
+	days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	return fromJDN(int(days) + jdnUnix - offset)
+}
+
+// ToGregorian mengubah tanggal Hijriah menjadi tengah malam tanggal Masehi di loc
+func ToGregorian(h Tanggal, offset int, loc *time.Location) time.Time {
+	g := time.Unix(int64(toJDN(h)-jdnUnix+offset)*86400, 0).UTC()
+	return time.Date(g.Year(), g.Month(), g.Day(), 0, 0, 0, 0, loc)
+}
+
+// Format memformat tanggal Masehi sebagai tanggal Hijriah dengan offset hari
+func Format(t time.Time, offset int) string {
+	return FromGregorian(t, offset).String()
+}
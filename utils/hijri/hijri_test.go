@@ -0,0 +1,67 @@
+package hijri
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFromGregorianDzulhijjah menguji konversi di batas 10-13 Dzulhijjah (hari penyembelihan), termasuk
+// sehari sebelum dan sesudahnya, dengan dan tanpa offset sidang isbat
+func TestFromGregorianDzulhijjah(t *testing.T) {
+	tests := []struct {
+		tanggal string
+		offset  int
+		want    Tanggal
+	}{
+		// kalender tabular 1446 H: 10 Dzulhijjah jatuh pada 7 Juni 2025
+		{"2025-06-06", 0, Tanggal{1446, Dzulhijjah, 9}},
+		{"2025-06-07", 0, Tanggal{1446, Dzulhijjah, 10}},
+		{"2025-06-10", 0, Tanggal{1446, Dzulhijjah, 13}},
+		{"2025-06-11", 0, Tanggal{1446, Dzulhijjah, 14}},
+		// Idul Adha resmi sehari lebih awal dari perhitungan (offset -1): 6-9 Juni 2025
+		{"2025-06-05", -1, Tanggal{1446, Dzulhijjah, 9}},
+		{"2025-06-06", -1, Tanggal{1446, Dzulhijjah, 10}},
+		{"2025-06-09", -1, Tanggal{1446, Dzulhijjah, 13}},
+		{"2025-06-10", -1, Tanggal{1446, Dzulhijjah, 14}},
+		// offset +1: seluruh rentang bergeser sehari lebih lambat
+		{"2025-06-08", 1, Tanggal{1446, Dzulhijjah, 10}},
+		{"2025-06-11", 1, Tanggal{1446, Dzulhijjah, 13}},
+		// tahun sebelumnya dan pergantian tahun setelah Dzulhijjah
+		{"2024-06-17", 0, Tanggal{1445, Dzulhijjah, 10}},
+		{"2024-06-20", 0, Tanggal{1445, Dzulhijjah, 13}},
+		{"2025-06-26", 0, Tanggal{1446, Dzulhijjah, 29}},
+		{"2025-06-27", 0, Tanggal{1447, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		g, err := time.ParseInLocation("2006-01-02", tt.tanggal, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FromGregorian(g, tt.offset); got != tt.want {
+			t.Errorf("FromGregorian(%s, %d) = %v, want %v", tt.tanggal, tt.offset, got, tt.want)
+		}
+		if got := ToGregorian(tt.want, tt.offset, time.Local); !got.Equal(g) {
+			t.Errorf("ToGregorian(%v, %d) = %s, want %s", tt.want, tt.offset, got.Format("2006-01-02"), tt.tanggal)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		waktu  time.Time
+		offset int
+		want   string
+	}{
+		{time.Date(2025, 6, 7, 0, 0, 0, 0, time.Local), 0, "10 Dzulhijjah 1446 H"},
+		// jam diabaikan: menjelang tengah malam masih tanggal yang sama
+		{time.Date(2025, 6, 10, 23, 59, 0, 0, time.Local), 0, "13 Dzulhijjah 1446 H"},
+		{time.Date(2025, 6, 6, 7, 0, 0, 0, time.Local), -1, "10 Dzulhijjah 1446 H"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.waktu, tt.offset); got != tt.want {
+			t.Errorf("Format(%s, %d) = %q, want %q", tt.waktu.Format(time.RFC3339), tt.offset, got, tt.want)
+		}
+	}
+}
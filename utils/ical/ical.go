@@ -0,0 +1,113 @@
+// Package ical menulis kalender iCalendar (RFC 5545) sederhana berisi VEVENT.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Geo         *[2]float64 // latitude, longitude
+	Start       time.Time
+	End         time.Time
+	AllDay      bool // Start dan End dibaca sebagai tanggal; End eksklusif
+	Modified    time.Time
+}
+
+type Calendar struct {
+	Name    string
+	Refresh time.Duration // saran interval sinkron untuk aplikasi kalender
+	Events  []Event
+}
+
+const (
+	formatWaktu   = "20060102T150405Z"
+	formatTanggal = "20060102"
+)
+
+// Write menulis kalender ke w dengan akhir baris CRLF dan baris panjang dilipat sesuai RFC 5545
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Sahabat Kurban//Jadwal Kurban//ID")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		d := duration(c.Refresh)
+		line("REFRESH-INTERVAL;VALUE=DURATION", d)
+		line("X-PUBLISHED-TTL", d)
+	}
+
+	now := time.Now().UTC().Format(formatWaktu)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", now)
+		if !e.Modified.IsZero() {
+			line("LAST-MODIFIED", e.Modified.UTC().Format(formatWaktu))
+		}
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format(formatTanggal))
+			line("DTEND;VALUE=DATE", e.End.Format(formatTanggal))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(formatWaktu))
+			line("DTEND", e.End.UTC().Format(formatWaktu))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.Geo != nil {
+			line("GEO", fmt.Sprintf("%.6f;%.6f", e.Geo[0], e.Geo[1]))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// escape meloloskan karakter khusus nilai TEXT
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// writeFolded memecah baris lebih dari 75 oktet tanpa memotong karakter UTF-8
+func writeFolded(w *bufio.Writer, s string) {
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			w.WriteString("\r\n ")
+			n = 1
+		}
+		w.WriteRune(r)
+		n += size
+	}
+	w.WriteString("\r\n")
+}
+
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", int(d/time.Hour))
+	}
+	return fmt.Sprintf("PT%dM", int(d/time.Minute))
+}
@@ -0,0 +1,106 @@
+package ical
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteFolded(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string // baris fisik tanpa CRLF
+	}{
+		{
+			name: "tepat 75 oktet tidak dilipat",
+			in:   strings.Repeat("a", 75),
+			want: []string{strings.Repeat("a", 75)},
+		},
+		{
+			name: "76 oktet dilipat satu kali",
+			in:   strings.Repeat("a", 76),
+			want: []string{strings.Repeat("a", 75), " a"},
+		},
+		{
+			name: "baris lanjutan memuat 74 oktet setelah spasi",
+			in:   strings.Repeat("a", 75+74+1),
+			want: []string{strings.Repeat("a", 75), " " + strings.Repeat("a", 74), " a"},
+		},
+		{
+			// "é" 2 oktet tidak muat di oktet ke-75, jadi pindah utuh ke baris berikutnya
+			name: "rune 2 oktet tidak dipotong",
+			in:   strings.Repeat("a", 74) + "é",
+			want: []string{strings.Repeat("a", 74), " é"},
+		},
+		{
+			name: "rune 3 oktet pas di batas",
+			in:   strings.Repeat("a", 72) + "日本",
+			want: []string{strings.Repeat("a", 72) + "日", " 本"},
+		},
+		{
+			name: "rune 4 oktet tidak dipotong",
+			in:   strings.Repeat("a", 73) + "🐄",
+			want: []string{strings.Repeat("a", 73), " 🐄"},
+		},
+		{
+			name: "teks Arab panjang",
+			in:   strings.Repeat("عيد", 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			bw := bufio.NewWriter(&sb)
+			writeFolded(bw, tt.in)
+			bw.Flush()
+
+			out := sb.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output must end with CRLF: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, l := range lines {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets, want <= 75", i, len(l))
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, l)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d must start with a space: %q", i, l)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.in {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.in)
+			}
+			if tt.want != nil && strings.Join(lines, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("lines = %q, want %q", lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Sapi 1", "Sapi 1"},
+		{"Masjid Al-Ikhlas, Jl. Merdeka", `Masjid Al-Ikhlas\, Jl. Merdeka`},
+		{"jagal: Pak Slamet; saksi: Bu Ani", `jagal: Pak Slamet\; saksi: Bu Ani`},
+		{`C:\data`, `C:\\data`},
+		{"baris 1\nbaris 2", `baris 1\nbaris 2`},
+		{"baris 1\r\nbaris 2", `baris 1\nbaris 2`},
+		{"tanpa\r carriage return", "tanpa carriage return"},
+		{`\,`, `\\\,`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
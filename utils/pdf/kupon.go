@@ -0,0 +1,145 @@
+// Package pdf membuat dokumen PDF siap cetak (kartu kupon, manifest pengantaran).
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// KuponCard adalah isi satu kartu kupon pengambilan daging
+type KuponCard struct {
+	Kode        string // isi QR yang dipindai panitia
+	Nomor       string // nomor pendek untuk dicocokkan secara manual
+	Nama        string
+	Kategori    string
+	Alamat      string
+	JumlahPaket int
+	Tanggal     string // kosong jika belum ditentukan
+	Lokasi      string
+}
+
+// ukuran kartu di A4 potret: 2 kolom x 4 baris, dengan garis potong putus-putus
+const (
+	kuponMargin  = 10.0
+	kuponLebar   = 95.0
+	kuponTinggi  = 69.25
+	kuponKolom   = 2
+	kuponBaris   = 4
+	kuponQR      = 36.0
+	kuponPadding = 4.0
+)
+
+// KuponCards menulis kartu kupon ke w, delapan kartu per halaman A4
+func KuponCards(w io.Writer, judul string, cards []KuponCard) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(kuponMargin, kuponMargin, kuponMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	if len(cards) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 10, tr("Tidak ada kupon untuk dicetak."), "", 1, "L", false, 0, "")
+		return pdf.Output(w)
+	}
+
+	perHalaman := kuponKolom * kuponBaris
+	for i, c := range cards {
+		if i%perHalaman == 0 {
+			pdf.AddPage()
+		}
+		n := i % perHalaman
+		x := kuponMargin + float64(n%kuponKolom)*kuponLebar
+		y := kuponMargin + float64(n/kuponKolom)*kuponTinggi
+
+		if err := kuponCard(pdf, tr, fmt.Sprintf("qr%d", i), x, y, judul, c); err != nil {
+			return err
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+func kuponCard(pdf *fpdf.Fpdf, tr func(string) string, imgName string, x, y float64, judul string, c KuponCard) error {
+	pdf.SetDashPattern([]float64{1.5, 1.5}, 0)
+	pdf.SetDrawColor(150, 150, 150)
+	pdf.Rect(x, y, kuponLebar, kuponTinggi, "D")
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.SetDrawColor(0, 0, 0)
+
+	png, err := qrcode.Encode(c.Kode, qrcode.Medium, 512)
+	if err != nil {
+		return err
+	}
+	pdf.RegisterImageOptionsReader(imgName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	qx := x + kuponLebar - kuponPadding - kuponQR
+	qy := y + kuponPadding + 8
+	pdf.ImageOptions(imgName, qx, qy, kuponQR, kuponQR, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	left := x + kuponPadding
+	teksLebar := qx - left - 2
+
+	pdf.SetXY(left, y+kuponPadding)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(kuponLebar-2*kuponPadding, 6, tr(judul), "B", 1, "L", false, 0, "")
+
+	pdf.SetXY(left, y+kuponPadding+9)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.MultiCell(teksLebar, 5.5, potong(pdf, tr(c.Nama), teksLebar, 2), "", "L", false)
+
+	pdf.SetX(left)
+	pdf.SetFont("Helvetica", "", 8.5)
+	pdf.CellFormat(teksLebar, 4.5, tr("Kategori: "+c.Kategori), "", 1, "L", false, 0, "")
+	if c.Alamat != "" {
+		pdf.SetX(left)
+		pdf.MultiCell(teksLebar, 4, potong(pdf, tr(c.Alamat), teksLebar, 2), "", "L", false)
+	}
+
+	pdf.SetXY(left, y+kuponTinggi-kuponPadding-22)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(teksLebar, 8, fmt.Sprintf("%d paket", c.JumlahPaket), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 8.5)
+	tanggal := c.Tanggal
+	if tanggal == "" {
+		tanggal = "-"
+	}
+	pdf.SetX(left)
+	pdf.CellFormat(teksLebar, 4.5, tr("Tanggal: "+tanggal), "", 1, "L", false, 0, "")
+	if c.Lokasi != "" {
+		pdf.SetX(left)
+		pdf.CellFormat(teksLebar, 4.5, potong(pdf, tr("Lokasi: "+c.Lokasi), teksLebar, 1), "", 1, "L", false, 0, "")
+	}
+
+	pdf.SetXY(qx, qy+kuponQR+1)
+	pdf.SetFont("Courier", "B", 9)
+	pdf.CellFormat(kuponQR, 4, "No. "+c.Nomor, "", 1, "C", false, 0, "")
+
+	if pdf.Err() {
+		return pdf.Error()
+	}
+	return nil
+}
+
+// potong membatasi teks ke sejumlah baris agar kartu tidak meluber; s sudah diterjemahkan ke cp1252
+func potong(pdf *fpdf.Fpdf, s string, lebar float64, baris int) string {
+	lines := pdf.SplitText(s, lebar)
+	if len(lines) <= baris {
+		return s
+	}
+	lines = lines[:baris]
+	last := lines[baris-1]
+	for len(last) > 0 && pdf.GetStringWidth(last+"...") > lebar {
+		last = last[:len(last)-1]
+	}
+	lines[baris-1] = last + "..."
+	out := lines[0]
+	for _, l := range lines[1:] {
+		out += " " + l
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Manifest adalah kepala lembar pengantaran yang dibawa kurir
+type Manifest struct {
+	Judul      string
+	Tanggal    string
+	Wilayah    string // RT/RW atau lokasi
+	Kurir      string // kosong jika belum ditugaskan
+	KurirPhone string
+	Catatan    string
+}
+
+// ManifestStop adalah satu baris alamat tujuan, urut sesuai rute
+type ManifestStop struct {
+	Urutan      int
+	Nama        string
+	Alamat      string
+	Phone       string
+	JumlahPaket int
+	Status      string // kosong selama masih menunggu, diisi kurir di kolom keterangan
+}
+
+const (
+	manifestMargin = 12.0
+	manifestBaris  = 5.0
+)
+
+// kolom tabel manifest pada A4 lanskap: lebar total 273 mm
+var manifestKolom = []struct {
+	judul string
+	lebar float64
+	rata  string
+}{
+	{"No", 10, "C"},
+	{"Penerima", 50, "L"},
+	{"Alamat", 85, "L"},
+	{"Telepon", 32, "L"},
+	{"Paket", 14, "C"},
+	{"Keterangan (terkirim / tidak di rumah / ditolak)", 52, "L"},
+	{"Paraf", 30, "C"},
+}
+
+// ManifestAntar menulis lembar manifest pengantaran: kepala batch lalu tabel alamat dengan kolom isian kurir
+func ManifestAntar(w io.Writer, m Manifest, stops []ManifestStop) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(manifestMargin, manifestMargin, manifestMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	_, tinggiHalaman := pdf.GetPageSize()
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr(m.Judul), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	kurir := m.Kurir
+	if kurir == "" {
+		kurir = "-"
+	} else if m.KurirPhone != "" {
+		kurir += " (" + m.KurirPhone + ")"
+	}
+	total := 0
+	for _, s := range stops {
+		total += s.JumlahPaket
+	}
+	kepala := []string{
+		"Tanggal: " + m.Tanggal,
+		"Wilayah: " + m.Wilayah,
+		"Kurir: " + kurir,
+		fmt.Sprintf("Jumlah alamat: %d, total paket: %d", len(stops), total),
+	}
+	if m.Catatan != "" {
+		kepala = append(kepala, "Catatan: "+m.Catatan)
+	}
+	for _, k := range kepala {
+		pdf.CellFormat(0, 5.5, tr(k), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	judulTabel := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, k := range manifestKolom {
+			pdf.CellFormat(k.lebar, 7, tr(k.judul), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	judulTabel()
+
+	if len(stops) == 0 {
+		pdf.CellFormat(0, 8, tr("Tidak ada alamat dalam batch ini."), "1", 1, "L", false, 0, "")
+	}
+
+	for _, s := range stops {
+		isi := []string{
+			fmt.Sprintf("%d", s.Urutan),
+			tr(s.Nama),
+			tr(s.Alamat),
+			tr(s.Phone),
+			fmt.Sprintf("%d", s.JumlahPaket),
+			tr(s.Status),
+			"",
+		}
+
+		// tinggi baris mengikuti sel yang paling banyak barisnya, minimal dua baris untuk ruang paraf
+		n := 2
+		for i, k := range manifestKolom {
+			if l := len(pdf.SplitText(isi[i], k.lebar-2)); l > n {
+				n = l
+			}
+		}
+		tinggi := float64(n) * manifestBaris
+
+		if pdf.GetY()+tinggi > tinggiHalaman-manifestMargin {
+			pdf.AddPage()
+			judulTabel()
+		}
+
+		x, y := pdf.GetXY()
+		for i, k := range manifestKolom {
+			pdf.Rect(x, y, k.lebar, tinggi, "D")
+			pdf.SetXY(x+1, y)
+			pdf.MultiCell(k.lebar-2, manifestBaris, isi[i], "", k.rata, false)
+			x += k.lebar
+		}
+		pdf.SetXY(manifestMargin, y+tinggi)
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,36 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrFileURLInvalid = errors.New("Invalid or expired file link")
+
+// SignFile membuat tanda tangan HMAC-SHA256 untuk path file yang berlaku sampai exp
+func SignFile(secret []byte, p string, exp time.Time) string {
+	return base64.RawURLEncoding.EncodeToString(fileMAC(secret, p, exp.Unix()))
+}
+
+// VerifyFile memeriksa tanda tangan dan masa berlaku tautan file
+func VerifyFile(secret []byte, p, exp, sig string, now time.Time) error {
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return ErrFileURLInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(raw, fileMAC(secret, p, unix)) {
+		return ErrFileURLInvalid
+	}
+	return nil
+}
+
+func fileMAC(secret []byte, p string, exp int64) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte("file:" + strconv.FormatInt(exp, 10) + ":" + p))
+	return m.Sum(nil)
+}
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyFile(t *testing.T) {
+	secret := []byte("rahasia-file")
+	now := time.Date(2025, 6, 6, 9, 0, 0, 0, time.UTC)
+	exp := now.Add(time.Hour)
+	path := "penyembelihan/2025/bukti-1.jpg"
+	sig := SignFile(secret, path, exp)
+	expStr := strconv.FormatInt(exp.Unix(), 10)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		path   string
+		exp    string
+		sig    string
+		now    time.Time
+		valid  bool
+	}{
+		{"valid", secret, path, expStr, sig, now, true},
+		{"tepat pada batas berlaku", secret, path, expStr, sig, exp, true},
+		{"kedaluwarsa", secret, path, expStr, sig, exp.Add(time.Second), false},
+		{"masa berlaku diperpanjang", secret, path, strconv.FormatInt(exp.Add(24*time.Hour).Unix(), 10), sig, now, false},
+		{"path lain", secret, "penyembelihan/2025/bukti-2.jpg", expStr, sig, now, false},
+		{"kunci berbeda", []byte("kunci-lain"), path, expStr, sig, now, false},
+		{"tanda tangan diubah", secret, path, expStr, ubahAwal(sig), now, false},
+		{"tanda tangan bukan base64", secret, path, expStr, "!!!", now, false},
+		{"exp bukan angka", secret, path, "besok", sig, now, false},
+		{"tanpa tanda tangan", secret, path, expStr, "", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyFile(tt.secret, tt.path, tt.exp, tt.sig, tt.now)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && err != ErrFileURLInvalid {
+				t.Errorf("err = %v, want ErrFileURLInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrKuponInvalid = errors.New("Invalid kupon code")
+
+const kuponPrefix = "SK1"
+
+// SignKupon membuat isi QR kupon berisi id kupon dan tanda tangan HMAC-SHA256, misalnya "SK1.<id>.<sig>"
+func SignKupon(secret []byte, id uuid.UUID) string {
+	enc := base64.RawURLEncoding
+	return kuponPrefix + "." + enc.EncodeToString(id[:]) + "." + enc.EncodeToString(kuponMAC(secret, id))
+}
+
+// VerifyKupon memeriksa tanda tangan isi QR dan mengembalikan id kuponnya
+func VerifyKupon(secret []byte, kode string) (uuid.UUID, error) {
+	parts := strings.Split(strings.TrimSpace(kode), ".")
+	if len(parts) != 3 || parts[0] != kuponPrefix {
+		return uuid.Nil, ErrKuponInvalid
+	}
+
+	enc := base64.RawURLEncoding
+	raw, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return uuid.Nil, ErrKuponInvalid
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.Nil, ErrKuponInvalid
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, kuponMAC(secret, id)) {
+		return uuid.Nil, ErrKuponInvalid
+	}
+	return id, nil
+}
+
+// kuponMAC dipotong 16 byte agar QR tetap kecil dan mudah dipindai kamera ponsel
+func kuponMAC(secret []byte, id uuid.UUID) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte("kupon:"))
+	m.Write(id[:])
+	return m.Sum(nil)[:16]
+}
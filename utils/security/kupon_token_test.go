@@ -0,0 +1,62 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// ubahAwal mengganti karakter pertama tanpa merusak base64, sehingga yang ditolak benar-benar tanda tangannya
+func ubahAwal(s string) string {
+	c := byte('A')
+	if s[0] == 'A' {
+		c = 'B'
+	}
+	return string(c) + s[1:]
+}
+
+func TestVerifyKupon(t *testing.T) {
+	secret := []byte("rahasia-kupon")
+	id := uuid.MustParse("3f1c2a9e-5b7d-4c11-9a0e-2d6f8b4c7e10")
+	kode := SignKupon(secret, id)
+	parts := strings.Split(kode, ".")
+
+	lain := uuid.MustParse("8a6e0f3b-1c2d-4e5f-8a9b-0c1d2e3f4a5b")
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		kode    string
+		wantErr bool
+	}{
+		{"valid", secret, kode, false},
+		{"spasi di tepi diabaikan", secret, "  " + kode + "\n", false},
+		{"kunci berbeda", []byte("kunci-lain"), kode, true},
+		{"tanda tangan diubah", secret, parts[0] + "." + parts[1] + "." + ubahAwal(parts[2]), true},
+		{"id diganti", secret, parts[0] + "." + strings.Split(SignKupon(secret, lain), ".")[1] + "." + parts[2], true},
+		{"prefix salah", secret, "SK2." + parts[1] + "." + parts[2], true},
+		{"bagian kurang", secret, parts[0] + "." + parts[1], true},
+		{"base64 rusak", secret, parts[0] + ".!!!." + parts[2], true},
+		{"id bukan 16 byte", secret, parts[0] + ".YWJj." + parts[2], true},
+		{"kosong", secret, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyKupon(tt.secret, tt.kode)
+			if tt.wantErr {
+				if err != ErrKuponInvalid {
+					t.Errorf("err = %v, want ErrKuponInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got != id {
+				t.Errorf("id = %s, want %s", got, id)
+			}
+		})
+	}
+}
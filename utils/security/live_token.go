@@ -0,0 +1,67 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrLiveTokenInvalid = errors.New("Invalid or expired live token")
+
+const livePrefix = "SL1"
+
+// SignLive membuat token stream antrean live berisi masa berlaku dan id pekurban (kosong untuk panitia),
+// misalnya "SL1.<exp>.<pekurban>.<sig>". Token dikirim lewat query karena EventSource tidak bisa mengirim header.
+func SignLive(secret []byte, pekurbanID *uuid.UUID, exp time.Time) string {
+	enc := base64.RawURLEncoding
+	var pemilik string
+	if pekurbanID != nil {
+		pemilik = enc.EncodeToString(pekurbanID[:])
+	}
+	unix := strconv.FormatInt(exp.Unix(), 10)
+	return livePrefix + "." + unix + "." + pemilik + "." + enc.EncodeToString(liveMAC(secret, unix, pemilik))
+}
+
+// VerifyLive memeriksa tanda tangan dan masa berlaku token live lalu mengembalikan id pekurban dan batas berlakunya
+func VerifyLive(secret []byte, token string, now time.Time) (*uuid.UUID, time.Time, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 4 || parts[0] != livePrefix {
+		return nil, time.Time{}, ErrLiveTokenInvalid
+	}
+
+	enc := base64.RawURLEncoding
+	sig, err := enc.DecodeString(parts[3])
+	if err != nil || !hmac.Equal(sig, liveMAC(secret, parts[1], parts[2])) {
+		return nil, time.Time{}, ErrLiveTokenInvalid
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > unix {
+		return nil, time.Time{}, ErrLiveTokenInvalid
+	}
+
+	var pekurbanID *uuid.UUID
+	if parts[2] != "" {
+		raw, err := enc.DecodeString(parts[2])
+		if err != nil {
+			return nil, time.Time{}, ErrLiveTokenInvalid
+		}
+		id, err := uuid.FromBytes(raw)
+		if err != nil {
+			return nil, time.Time{}, ErrLiveTokenInvalid
+		}
+		pekurbanID = &id
+	}
+	return pekurbanID, time.Unix(unix, 0), nil
+}
+
+func liveMAC(secret []byte, exp, pemilik string) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte("live:" + exp + ":" + pemilik))
+	return m.Sum(nil)
+}
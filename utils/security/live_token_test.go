@@ -0,0 +1,61 @@
+package security
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifyLive(t *testing.T) {
+	secret := []byte("rahasia-live")
+	now := time.Date(2025, 6, 6, 7, 0, 0, 0, time.UTC)
+	exp := now.Add(15 * time.Minute)
+	pekurbanID := uuid.MustParse("3f1c2a9e-5b7d-4c11-9a0e-2d6f8b4c7e10")
+
+	milikPekurban := SignLive(secret, &pekurbanID, exp)
+	panitia := SignLive(secret, nil, exp)
+	parts := strings.Split(milikPekurban, ".")
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		token   string
+		now     time.Time
+		want    *uuid.UUID
+		wantErr bool
+	}{
+		{"pekurban", secret, milikPekurban, now, &pekurbanID, false},
+		{"panitia tanpa pekurban", secret, panitia, now, nil, false},
+		{"tepat pada batas berlaku", secret, milikPekurban, exp, &pekurbanID, false},
+		{"kedaluwarsa", secret, milikPekurban, exp.Add(time.Second), nil, true},
+		{"masa berlaku diperpanjang", secret, parts[0] + "." + "9999999999" + "." + parts[2] + "." + parts[3], now, nil, true},
+		{"pekurban dihapus dari token", secret, parts[0] + "." + parts[1] + ".." + parts[3], now, nil, true},
+		{"tanda tangan diubah", secret, parts[0] + "." + parts[1] + "." + parts[2] + "." + ubahAwal(parts[3]), now, nil, true},
+		{"kunci berbeda", []byte("kunci-lain"), milikPekurban, now, nil, true},
+		{"prefix salah", secret, "SK1." + strings.Join(parts[1:], "."), now, nil, true},
+		{"kosong", secret, "", now, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotExp, err := VerifyLive(tt.secret, tt.token, tt.now)
+			if tt.wantErr {
+				if err != ErrLiveTokenInvalid {
+					t.Errorf("err = %v, want ErrLiveTokenInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !gotExp.Equal(exp) {
+				t.Errorf("exp = %s, want %s", gotExp, exp)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("pekurban = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"fmt"
+	"html"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
@@ -11,6 +14,8 @@ import (
 type EmailService interface {
 	SendVerificationEmail(toEmail, toName, token string) error
 	SendResetPasswordEmail(toEmail, toName, token string) error
+	SendPatunganMatchedEmail(toEmail, toName, jenis string, jumlahPorsi int, hewanID string) error
+	SendBuktiPenyembelihanEmail(toEmail, toName, jenis, hewanID string, waktu time.Time, links []string) error
 }
 
 type emailService struct {
@@ -76,4 +81,45 @@ func (e *emailService) SendResetPasswordEmail(toEmail, toName, token string) err
 	return err
 }
 
+func (e *emailService) SendPatunganMatchedEmail(toEmail, toName, jenis string, jumlahPorsi int, hewanID string) error {
+	from := mail.NewEmail(e.fromName, e.fromAddress)
+	to := mail.NewEmail(toName, toEmail)
+
+	subject := "Permintaan Patungan Anda Sudah Mendapat Hewan"
+	content := fmt.Sprintf(`
+		<h2>Halo %s!</h2>
+		<p>Permintaan patungan Anda sebanyak <b>%d porsi</b> sudah dipasangkan dengan hewan kurban jenis <b>%s</b>.</p>
+		<p>ID hewan: %s</p>
+		<p>Silakan lakukan pembayaran melalui aplikasi Sahabat Kurban.</p>
+	`, toName, jumlahPorsi, jenis, hewanID)
+
+	message := mail.NewSingleEmail(from, subject, to, "", content)
+	client := sendgrid.NewSendClient(e.apiKey)
+	_, err := client.Send(message)
+	return err
+}
+
+func (e *emailService) SendBuktiPenyembelihanEmail(toEmail, toName, jenis, hewanID string, waktu time.Time, links []string) error {
+	from := mail.NewEmail(e.fromName, e.fromAddress)
+	to := mail.NewEmail(toName, toEmail)
+
+	var daftar strings.Builder
+	for i, link := range links {
+		fmt.Fprintf(&daftar, "<li><a href=\"%s\">Bukti %d</a></li>", html.EscapeString(link), i+1)
+	}
+
+	subject := "Hewan Kurban Anda Telah Disembelih"
+	content := fmt.Sprintf(`
+		<h2>Halo %s!</h2>
+		<p>Hewan kurban jenis <b>%s</b> milik Anda telah disembelih pada <b>%s</b>.</p>
+		<p>ID hewan: %s</p>
+		<p>Berikut foto/video penyembelihan sebagai bukti:</p>
+		<ul>%s</ul>
+		<p>Semoga kurban Anda diterima. Terima kasih telah berkurban bersama Sahabat Kurban.</p>
+	`, html.EscapeString(toName), jenis, waktu.Format("02-01-2006 15:04 MST"), hewanID, daftar.String())
 
+	message := mail.NewSingleEmail(from, subject, to, "", content)
+	client := sendgrid.NewSendClient(e.apiKey)
+	_, err := client.Send(message)
+	return err
+}
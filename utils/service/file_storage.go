@@ -0,0 +1,92 @@
+package service
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wahyujatirestu/sahabat-kurban/utils/security"
+)
+
+// FileStorage menyimpan file unggahan. Path yang dikembalikan relatif terhadap root penyimpanan
+// dan selalu memakai pemisah "/" sehingga bisa langsung dipakai di URL.
+type FileStorage interface {
+	Save(dir, ext string, r io.Reader) (string, error)
+	Delete(p string) error
+	URL(p string) string
+	Resolve(p, exp, sig string) (string, error)
+}
+
+type localFileStorage struct {
+	baseDir string
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+}
+
+// NewLocalFileStorage menyimpan file di baseDir; baseURL adalah alamat tempat baseDir disajikan.
+// Setiap URL ditandatangani dengan secret dan hanya berlaku selama ttl.
+func NewLocalFileStorage(baseDir, baseURL string, secret []byte, ttl time.Duration) FileStorage {
+	return &localFileStorage{baseDir: baseDir, baseURL: strings.TrimRight(baseURL, "/"), secret: secret, ttl: ttl}
+}
+
+// Save menulis isi r ke file baru bernama acak di dalam dir
+func (s *localFileStorage) Save(dir, ext string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(filepath.Join(s.baseDir, filepath.FromSlash(dir)), 0o755); err != nil {
+		return "", err
+	}
+
+	p := path.Join(dir, uuid.NewString()+strings.ToLower(ext))
+	f, err := os.OpenFile(filepath.Join(s.baseDir, filepath.FromSlash(p)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return p, nil
+}
+
+func (s *localFileStorage) Delete(p string) error {
+	err := os.Remove(s.localPath(p))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// URL mengembalikan tautan bertanda tangan yang kedaluwarsa setelah ttl
+func (s *localFileStorage) URL(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	exp := time.Now().Add(s.ttl)
+	q := url.Values{}
+	q.Set("exp", strconv.FormatInt(exp.Unix(), 10))
+	q.Set("sig", security.SignFile(s.secret, p, exp))
+	return s.baseURL + "/" + p + "?" + q.Encode()
+}
+
+// Resolve memeriksa tanda tangan tautan lalu mengembalikan path file lokalnya
+func (s *localFileStorage) Resolve(p, exp, sig string) (string, error) {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if err := security.VerifyFile(s.secret, p, exp, sig, time.Now()); err != nil {
+		return "", err
+	}
+	return s.localPath(p), nil
+}
+
+func (s *localFileStorage) localPath(p string) string {
+	return filepath.Join(s.baseDir, filepath.FromSlash(path.Clean("/"+p)))
+}
@@ -0,0 +1,79 @@
+// Package spreadsheet membaca isi berkas CSV atau XLSX sebagai baris teks untuk keperluan impor data.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrFormat = errors.New("Unsupported file type. Use .csv or .xlsx")
+
+// Read membaca semua baris berkas; jenis berkas ditentukan dari ekstensi nama berkas.
+// sheet hanya dipakai untuk XLSX, kosong berarti sheet pertama.
+func Read(r io.Reader, filename, sheet string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return readCSV(r)
+	case ".xlsx":
+		return readXLSX(r, sheet)
+	}
+	return nil, ErrFormat
+}
+
+// readCSV menerima pemisah koma maupun titik koma (ekspor Excel berlokal Indonesia) dan membuang BOM UTF-8
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	// pemisah ditebak dari baris judul yang sudah ada di buffer setelah Peek di atas
+	pemisah := ','
+	judul, _ := br.Peek(br.Buffered())
+	if i := bytes.IndexByte(judul, '\n'); i >= 0 {
+		judul = judul[:i]
+	}
+	if bytes.Count(judul, []byte{';'}) > bytes.Count(judul, []byte{','}) {
+		pemisah = ';'
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = pemisah
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CSV file: %v", err)
+	}
+	return rows, nil
+}
+
+func readXLSX(r io.Reader, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid XLSX file: %v", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		list := f.GetSheetList()
+		if len(list) == 0 {
+			return nil, errors.New("XLSX file has no sheet")
+		}
+		sheet = list[0]
+	} else if idx, err := f.GetSheetIndex(sheet); err != nil || idx < 0 {
+		return nil, fmt.Errorf("Sheet %q not found", sheet)
+	}
+
+	return f.GetRows(sheet)
+}
@@ -0,0 +1,78 @@
+// Package testdb menyiapkan database PostgreSQL sementara untuk test yang membutuhkan database sungguhan.
+package testdb
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// Open membuka database dari TEST_DATABASE_DSN dengan skema sementara berisi sql/ddl.sql,
+// sehingga test tidak menyentuh tabel yang sudah ada. Test dilewati jika variabel tidak diisi.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`) })
+
+	// search_path dikirim sebagai parameter koneksi agar setiap koneksi di pool memakai skema sementara
+	sep := " "
+	if strings.Contains(dsn, "://") {
+		sep = "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+	}
+	db, err := sql.Open("postgres", dsn+sep+"search_path="+schema+",public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ddl, err := os.ReadFile(ddlPath(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(ddl)); err != nil {
+		t.Fatalf("apply ddl.sql: %v", err)
+	}
+	return db
+}
+
+// ddlPath mencari sql/ddl.sql dari direktori paket yang sedang dites ke atas sampai root modul
+func ddlPath(t testing.TB) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		p := filepath.Join(dir, "sql", "ddl.sql")
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("sql/ddl.sql not found")
+		}
+		dir = parent
+	}
+}